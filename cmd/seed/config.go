@@ -6,11 +6,66 @@ import (
 )
 
 var envMap = map[string]string{
-	"OTEL_COLLECTOR_GRPC":       telemetry.OtelCollectorGrpcOptKey,
-	"OTEL_COLLECTOR_CA_CRT":     telemetry.OtelCollectorCaCrtOptKey,
-	"OTEL_COLLECTOR_CLIENT_CRT": telemetry.OtelCollectorClientCrtOptKey,
-	"OTEL_COLLECTOR_CLIENT_KEY": telemetry.OtelCollectorClientKeyOptKey,
-	"USERS_SERVICE_NAME":        internalConfig.UsersServiceNameOptKey,
-	"USERS_ADMIN_ROLE":          internalConfig.UsersAdminRoleOptKey,
-	"STORE_LOCAL_ROOT_PATH":     internalConfig.StoreLocalRootPathOptKey,
+	"OTEL_COLLECTOR_GRPC":        telemetry.OtelCollectorGrpcOptKey,
+	"OTEL_COLLECTOR_CA_CRT":      telemetry.OtelCollectorCaCrtOptKey,
+	"OTEL_COLLECTOR_CLIENT_CRT":  telemetry.OtelCollectorClientCrtOptKey,
+	"OTEL_COLLECTOR_CLIENT_KEY":  telemetry.OtelCollectorClientKeyOptKey,
+	"USERS_SERVICE_NAME":         internalConfig.UsersServiceNameOptKey,
+	"USERS_ADMIN_ROLE":           internalConfig.UsersAdminRoleOptKey,
+	"STORE_LOCAL_ROOT_PATH":      internalConfig.StoreLocalRootPathOptKey,
+	"STORE_LOCAL_TEMP_PATH":      internalConfig.StoreLocalTempPathOptKey,
+	"STORE_LOCAL_TRASH_PATH":     internalConfig.StoreLocalTrashPathOptKey,
+	"STORE_DIR_MAX_ENTRIES":      internalConfig.StoreDirMaxEntriesOptKey,
+	"STORE_FILE_MAX_SIZE":        internalConfig.StoreFileMaxSizeOptKey,
+	"STORE_LIST_MAX_ENTRIES":     internalConfig.StoreListMaxEntriesOptKey,
+	"STORE_LIST_INLINE_MAX_SIZE": internalConfig.StoreListInlineMaxSizeOptKey,
+	"UPLOAD_IDEMPOTENCY_TTL":     internalConfig.UploadIdempotencyTtlOptKey,
+	"PREVIEW_MAX_BYTES":          internalConfig.PreviewMaxBytesOptKey,
+	"STORE_REQUIRE_EXTENSION":    internalConfig.StoreRequireExtensionOptKey,
+	"STORE_FOLLOW_SYMLINKS":      internalConfig.StoreFollowSymlinksOptKey,
+	"STORE_OPERATION_TIMEOUT":    internalConfig.StoreOperationTimeoutOptKey,
+	"STORE_CASE_INSENSITIVE":     internalConfig.StoreCaseInsensitiveOptKey,
+	"TRASH_TTL":                  internalConfig.TrashTtlOptKey,
+	"TRASH_SWEEP_INTERVAL":       internalConfig.TrashSweepIntervalOptKey,
+	"STORE_LIST_DEFAULT_PATH":    internalConfig.StoreListDefaultPathOptKey,
+	"STORE_HIDDEN_NAMES":         internalConfig.StoreHiddenNamesOptKey,
+	"HTTP_CANONICAL_PATHS":       internalConfig.HttpCanonicalPathsOptKey,
+	"DOWNLOAD_INDEX_FILE":        internalConfig.DownloadIndexFileOptKey,
+	"STORE_NAMESPACES":           internalConfig.StoreNamespacesOptKey,
+	"DOWNLOAD_DIR_LISTING":       internalConfig.DownloadDirListingOptKey,
+	"RENAME_SAME_PATH_NOOP":      internalConfig.RenameSamePathNoopOptKey,
+	"UPLOAD_MAX_DURATION":        internalConfig.UploadMaxDurationOptKey,
+	"STORE_MIME_DETECTION":       internalConfig.StoreMimeDetectionOptKey,
+	"STORE_LOCAL_BACKUP_PATH":    internalConfig.StoreLocalBackupPathOptKey,
+	"BACKUP_TTL":                 internalConfig.BackupTtlOptKey,
+	"DOWNLOAD_CONCAT_MAX_SIZE":   internalConfig.DownloadConcatMaxSizeOptKey,
+	"STORE_MIME_ROUTES":          internalConfig.StoreMimeRoutesOptKey,
+	"STORE_TOP_DIRS":             internalConfig.StoreTopDirsOptKey,
+	"DOWNLOAD_CACHE_CONTROL":     internalConfig.DownloadCacheControlOptKey,
+	"DELETE_BATCH_THRESHOLD":     internalConfig.DeleteBatchThresholdOptKey,
+	"STORE_CHECK_FREE_SPACE":     internalConfig.StoreCheckFreeSpaceOptKey,
+	"STORE_FREE_SPACE_MARGIN":    internalConfig.StoreFreeSpaceMarginOptKey,
+	"STORE_LOCAL_VERSIONS_PATH":  internalConfig.StoreLocalVersionsPathOptKey,
+	"VERSIONS_RETENTION":         internalConfig.VersionsRetentionOptKey,
+	"STORE_LIST_ORDER":           internalConfig.StoreListOrderOptKey,
+	"DOWNLOAD_MAX_CONCURRENT":    internalConfig.DownloadMaxConcurrentOptKey,
+	"IMPORT_ALLOWED_HOSTS":       internalConfig.ImportAllowedHostsOptKey,
+	"IMPORT_DENIED_HOSTS":        internalConfig.ImportDeniedHostsOptKey,
+	"IMPORT_MAX_SIZE":            internalConfig.ImportMaxSizeOptKey,
+	"IMPORT_MIME_TYPES":          internalConfig.ImportMimeTypesOptKey,
+	"STORE_LOCAL_EXPIRY_PATH":    internalConfig.StoreLocalExpiryPathOptKey,
+	"EXPIRY_SWEEP_INTERVAL":      internalConfig.ExpirySweepIntervalOptKey,
+	"DOWNLOAD_IMAGE_MAX_PIXELS":  internalConfig.DownloadImageMaxPixelsOptKey,
+	"DOWNLOAD_IMAGE_CACHE_SIZE":  internalConfig.DownloadImageCacheSizeOptKey,
+	"UPLOAD_STREAM_THRESHOLD":    internalConfig.UploadStreamThresholdOptKey,
+	"UPLOAD_DEDUP":               internalConfig.UploadDedupOptKey,
+	"DIR_SIZE_REFRESH_INTERVAL":  internalConfig.DirSizeRefreshIntervalOptKey,
+	"UPLOAD_SESSION_SECRET":      internalConfig.UploadSessionSecretOptKey,
+	"UPLOAD_SESSION_TOKEN_TTL":   internalConfig.UploadSessionTokenTtlOptKey,
+	"UPLOAD_FIX_ORIENTATION":     internalConfig.UploadFixOrientationOptKey,
+	"UPLOAD_STRIP_EXIF":          internalConfig.UploadStripExifOptKey,
+	"DOWNLOAD_AUTOINDEX":         internalConfig.DownloadAutoIndexOptKey,
+	"DOWNLOAD_AUTOINDEX_LIMIT":   internalConfig.DownloadAutoIndexLimitOptKey,
+	"STORE_RETRY_ATTEMPTS":       internalConfig.StoreRetryAttemptsOptKey,
+	"STORE_RETRY_BACKOFF":        internalConfig.StoreRetryBackoffOptKey,
 }
@@ -1,9 +1,183 @@
 package main
 
-import "time"
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	httpFilesHandlerAdapterImpl "github.com/flash-go/files-service/internal/adapter/handler/files/http"
+	filesRepositoryAdapterImpl "github.com/flash-go/files-service/internal/adapter/repository/files"
+)
+
+// Build info, injected via -ldflags "-X main.version=... -X main.commit=..."
+var (
+	version = "dev"
+	commit  = "unknown"
+)
 
 const (
 	collectGoRuntimeMetricsTimeout = 10 * time.Second
 	serverMaxRequestBodySize       = 1024 * 1024 * 1024 * 8 // 8GB
 	serverReadTimeout              = 10 * time.Minute
 )
+
+// Split a comma-separated config value, dropping empty items
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Store namespace served from its own root with its own limits
+type storeNamespace struct {
+	Name          string
+	RootPath      string
+	DirMaxEntries int
+	FileMaxSize   int64
+}
+
+/*
+parseNamespaces parses the store namespaces config value: comma-separated
+"name=rootPath" items, each optionally followed by ";key=value" limit overrides
+(dir_max_entries, file_max_size). Limits not overridden inherit the given
+global ones. Names must start with a letter and contain only letters, digits,
+"-" and "_".
+
+| Value                                                      | Namespaces                   |
+|------------------------------------------------------------|------------------------------|
+| "media=/srv/media;file_max_size=1073741824,docs=/srv/docs" | media (1GB file limit), docs |
+| "a=/srv/a;dir_max_entries=100"                             | a (100 entries per dir)      |
+*/
+func parseNamespaces(value string, dirMaxEntries int, fileMaxSize int64) ([]storeNamespace, error) {
+	namespaces := []storeNamespace{}
+	seen := map[string]bool{}
+	for _, item := range splitList(value) {
+		fields := strings.Split(item, ";")
+		name, rootPath, ok := strings.Cut(fields[0], "=")
+		name, rootPath = strings.TrimSpace(name), strings.TrimSpace(rootPath)
+		if !ok || !validNamespaceName(name) || rootPath == "" {
+			return nil, fmt.Errorf("invalid store namespace %q", item)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate store namespace %q", name)
+		}
+		seen[name] = true
+		ns := storeNamespace{
+			Name:          name,
+			RootPath:      rootPath,
+			DirMaxEntries: dirMaxEntries,
+			FileMaxSize:   fileMaxSize,
+		}
+		for _, field := range fields[1:] {
+			key, raw, _ := strings.Cut(field, "=")
+			limit, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+			if err != nil || limit < 0 {
+				return nil, fmt.Errorf("invalid limit %q of store namespace %q", field, name)
+			}
+			switch strings.TrimSpace(key) {
+			case "dir_max_entries":
+				ns.DirMaxEntries = int(limit)
+			case "file_max_size":
+				ns.FileMaxSize = limit
+			default:
+				return nil, fmt.Errorf("unknown limit %q of store namespace %q", key, name)
+			}
+		}
+		namespaces = append(namespaces, ns)
+	}
+	return namespaces, nil
+}
+
+// Report whether a store namespace name starts with a letter and contains
+// only letters, digits, "-" and "_"
+func validNamespaceName(name string) bool {
+	for i, c := range name {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case i > 0 && (c >= '0' && c <= '9' || c == '-' || c == '_'):
+		default:
+			return false
+		}
+	}
+	return name != ""
+}
+
+// Check that the dir at path (e.g. the trash) and the store roots do not
+// contain one another, so the store never lists, moves or deletes what is kept
+// there and the dir never swallows a store
+func checkOutsideRoots(name, path string, roots []string) error {
+	pathAbs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("invalid %s %q: %w", name, path, err)
+	}
+	for _, root := range roots {
+		rootAbs, err := filepath.Abs(root)
+		if err != nil {
+			return fmt.Errorf("invalid store root %q: %w", root, err)
+		}
+		if within(rootAbs, pathAbs) || within(pathAbs, rootAbs) {
+			return fmt.Errorf("%s %q overlaps store root %q", name, path, root)
+		}
+	}
+	return nil
+}
+
+// Report whether pathAbs is dirAbs or below it
+func within(dirAbs, pathAbs string) bool {
+	rel, err := filepath.Rel(dirAbs, pathAbs)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+/*
+parseMimeRoutes parses the MIME routes config value: comma-separated
+"pattern=dir" items mapping a media type, a "type/*" wildcard or "*" to a
+subdirectory. Dirs must be local relative paths. Routes are tried in order.
+
+| Value                                         | Routes                           |
+|-----------------------------------------------|----------------------------------|
+| "image/*=images,application/pdf=documents"    | Images, PDFs                     |
+| "video/*=media/video,*=other"                 | Videos, everything else to other |
+*/
+func parseMimeRoutes(value string) ([]filesRepositoryAdapterImpl.MimeRoute, error) {
+	routes := []filesRepositoryAdapterImpl.MimeRoute{}
+	for _, item := range splitList(value) {
+		pattern, dir, ok := strings.Cut(item, "=")
+		pattern, dir = strings.ToLower(strings.TrimSpace(pattern)), strings.TrimSpace(dir)
+		if !ok || (pattern != "*" && !strings.Contains(pattern, "/")) || !filepath.IsLocal(dir) {
+			return nil, fmt.Errorf("invalid mime route %q", item)
+		}
+		routes = append(routes, filesRepositoryAdapterImpl.MimeRoute{
+			Pattern: pattern,
+			Dir:     filepath.Clean(dir),
+		})
+	}
+	return routes, nil
+}
+
+// Parse the download cache policy: semicolon-separated "pattern=directives"
+// items, where pattern is a media type, a "type/*" wildcard or "*", e.g.
+// "image/*=public, max-age=31536000, immutable;*=no-cache"
+func parseCacheRules(value string) ([]httpFilesHandlerAdapterImpl.CacheRule, error) {
+	rules := []httpFilesHandlerAdapterImpl.CacheRule{}
+	for _, item := range strings.Split(value, ";") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		pattern, directives, ok := strings.Cut(item, "=")
+		pattern, directives = strings.ToLower(strings.TrimSpace(pattern)), strings.TrimSpace(directives)
+		if !ok || (pattern != "*" && !strings.Contains(pattern, "/")) || directives == "" || strings.ContainsAny(directives, "\r\n") {
+			return nil, fmt.Errorf("invalid cache rule %q", item)
+		}
+		rules = append(rules, httpFilesHandlerAdapterImpl.CacheRule{
+			Pattern:      pattern,
+			CacheControl: directives,
+		})
+	}
+	return rules, nil
+}
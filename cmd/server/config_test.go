@@ -0,0 +1,24 @@
+package main
+
+import "testing"
+
+func TestCheckOutsideRoots(t *testing.T) {
+	roots := []string{"/srv/store", "/srv/media"}
+	tests := []struct {
+		path string
+		ok   bool
+	}{
+		{"/srv/trash", true},
+		{"/srv/store-trash", true},
+		{"/srv/store", false},
+		{"/srv/store/.trash", false},
+		{"/srv/media/trash/", false},
+		{"/srv", false},
+		{"/srv/store/../trash", true},
+	}
+	for _, tt := range tests {
+		if err := checkOutsideRoots("trash path", tt.path, roots); (err == nil) != tt.ok {
+			t.Errorf("checkOutsideRoots(%q) = %v, want ok %t", tt.path, err, tt.ok)
+		}
+	}
+}
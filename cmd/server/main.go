@@ -9,7 +9,10 @@ package main
 // @name Authorization
 
 import (
+	"context"
 	"os"
+	"path/filepath"
+	"time"
 
 	// Framework
 	//
@@ -32,23 +35,62 @@ import (
 	"github.com/flash-go/sdk/state"
 	"github.com/flash-go/sdk/telemetry"
 
+	// OpenTelemetry
+	//
+	// Instrument options of the metrics recorded through the telemetry
+	// service.
+
+	"go.opentelemetry.io/otel/metric"
+
+	// Fasthttp
+	//
+	// Server settings the framework does not expose (request body
+	// streaming).
+
+	"github.com/valyala/fasthttp"
+
 	// Implementations
 
 	//// Handlers
 	httpDirsHandlerAdapterImpl "github.com/flash-go/files-service/internal/adapter/handler/dirs/http"
 	httpFilesHandlerAdapterImpl "github.com/flash-go/files-service/internal/adapter/handler/files/http"
+	httpSystemHandlerAdapterImpl "github.com/flash-go/files-service/internal/adapter/handler/system/http"
 
 	//// Repository
 	dirsRepositoryAdapterImpl "github.com/flash-go/files-service/internal/adapter/repository/dirs"
 	filesRepositoryAdapterImpl "github.com/flash-go/files-service/internal/adapter/repository/files"
+	storeRepositoryAdapterImpl "github.com/flash-go/files-service/internal/adapter/repository/store"
 
 	//// Services
 	dirsServiceImpl "github.com/flash-go/files-service/internal/service/dirs"
 	filesServiceImpl "github.com/flash-go/files-service/internal/service/files"
+	systemServiceImpl "github.com/flash-go/files-service/internal/service/system"
 
 	// Config
 	internalConfig "github.com/flash-go/files-service/internal/config"
 
+	// Errors
+	internalErrors "github.com/flash-go/files-service/internal/errors"
+
+	// Path locks
+	"github.com/flash-go/files-service/internal/pathlock"
+
+	// Store namespaces
+	"github.com/flash-go/files-service/internal/namespace"
+
+	// Rejected path audit
+	"github.com/flash-go/files-service/internal/audit"
+
+	// Import host lists
+	"github.com/flash-go/files-service/internal/hostlist"
+
+	// Framework server access
+
+	// Ports
+	dirsRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/dirs"
+	filesRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/files"
+	dirsServicePort "github.com/flash-go/files-service/internal/port/service/dirs"
+
 	// Other
 	_ "github.com/flash-go/files-service/docs"
 	_ "github.com/joho/godotenv/autoload"
@@ -116,6 +158,13 @@ func main() {
 			errors.ErrUnauthorized: 401,
 			errors.ErrForbidden:    403,
 			errors.ErrNotFound:     404,
+
+			internalErrors.ErrPreconditionFailed:  412,
+			internalErrors.ErrPayloadTooLarge:     413,
+			internalErrors.ErrInsufficientStorage: 507,
+			internalErrors.ErrGatewayTimeout:      504,
+			internalErrors.ErrRangeNotSatisfiable: 416,
+			internalErrors.ErrBadGateway:          502,
 		},
 	)
 
@@ -125,42 +174,372 @@ func main() {
 	// Set read timeout
 	httpServer.SetServerReadTimeout(serverReadTimeout)
 
+	// Stream multipart request bodies beyond the upload stream threshold
+	if uploadStreamThreshold := cfg.GetInt(internalConfig.UploadStreamThresholdOptKey); uploadStreamThreshold > 0 {
+		httpServer.SetServerStreamRequestBody(true)
+		httpServer.SetServerDisablePreParseMultipartForm(true)
+		httpServer.SetServerHeaderReceived(func(header *fasthttp.RequestHeader) fasthttp.RequestConfig {
+			if len(header.MultipartFormBoundary()) > 0 {
+				return fasthttp.RequestConfig{MaxRequestBodySize: uploadStreamThreshold}
+			}
+			return fasthttp.RequestConfig{}
+		})
+	}
+
 	// Get local store root path
 	localStoreRootPath := cfg.Get(internalConfig.StoreLocalRootPathOptKey)
 
-	// Create repository
-	dirsRepository := dirsRepositoryAdapterImpl.New(
-		&dirsRepositoryAdapterImpl.Config{
-			StoreLocalRootPath: localStoreRootPath,
-		},
+	// Get max entries per directory
+	dirMaxEntries := cfg.GetInt(internalConfig.StoreDirMaxEntriesOptKey)
+
+	// Get names hidden from listings
+	hiddenNames := splitList(cfg.Get(internalConfig.StoreHiddenNamesOptKey))
+
+	// Get whether renaming a path onto itself succeeds as a no-op
+	renameSamePathNoop := cfg.Get(internalConfig.RenameSamePathNoopOptKey) == "true"
+
+	// Get storage operation timeout
+	storeOperationTimeout := time.Duration(cfg.GetInt(internalConfig.StoreOperationTimeoutOptKey)) * time.Second
+
+	// Get retries of storage reads failing with transient errors
+	storeRetryAttempts := cfg.GetInt(internalConfig.StoreRetryAttemptsOptKey)
+	storeRetryBackoff := time.Duration(cfg.GetInt(internalConfig.StoreRetryBackoffOptKey)) * time.Millisecond
+	storeRetries, err := telemetryService.NewMetricInt64Counter(
+		"files.store.retries",
+		true,
+		metric.WithDescription("Number of storage reads retried after a transient error"),
+	)
+	if err != nil {
+		loggerService.Log().Fatal().Err(err).Send()
+	}
+
+	// Get max file size
+	fileMaxSize := int64(cfg.GetInt(internalConfig.StoreFileMaxSizeOptKey))
+
+	// Get MIME detection strategy
+	mimeDetection := cfg.Get(internalConfig.StoreMimeDetectionOptKey)
+	switch mimeDetection {
+	case "", filesRepositoryAdapterImpl.MimeDetectionContent, filesRepositoryAdapterImpl.MimeDetectionExtension, filesRepositoryAdapterImpl.MimeDetectionHybrid:
+	default:
+		loggerService.Log().Fatal().Msgf("invalid mime detection strategy %q", mimeDetection)
+	}
+
+	// Get default listing order
+	listOrder := cfg.Get(internalConfig.StoreListOrderOptKey)
+	switch listOrder {
+	case "", filesRepositoryAdapterPort.ListOrderDirsFirst, filesRepositoryAdapterPort.ListOrderFilesFirst, filesRepositoryAdapterPort.ListOrderMixed:
+	default:
+		loggerService.Log().Fatal().Msgf("invalid list order %q", listOrder)
+	}
+
+	// Get default duplicate content policy of uploads
+	uploadDedup := cfg.Get(internalConfig.UploadDedupOptKey)
+	switch uploadDedup {
+	case "", filesRepositoryAdapterPort.DedupAllow, filesRepositoryAdapterPort.DedupReject, filesRepositoryAdapterPort.DedupLink:
+	default:
+		loggerService.Log().Fatal().Msgf("invalid upload dedup policy %q", uploadDedup)
+	}
+
+	// Get MIME type routes of uploads
+	mimeRoutes, err := parseMimeRoutes(cfg.Get(internalConfig.StoreMimeRoutesOptKey))
+	if err != nil {
+		loggerService.Log().Fatal().Err(err).Send()
+	}
+
+	// Get top-level directory names that may be created
+	topDirs := splitList(cfg.Get(internalConfig.StoreTopDirsOptKey))
+
+	// Get hosts imports may and may not fetch from
+	importAllowedHosts, err := hostlist.Parse(splitList(cfg.Get(internalConfig.ImportAllowedHostsOptKey)))
+	if err != nil {
+		loggerService.Log().Fatal().Err(err).Send()
+	}
+	importDeniedHosts, err := hostlist.Parse(splitList(cfg.Get(internalConfig.ImportDeniedHostsOptKey)))
+	if err != nil {
+		loggerService.Log().Fatal().Err(err).Send()
+	}
+
+	// Get store namespaces
+	storeNamespaces, err := parseNamespaces(
+		cfg.Get(internalConfig.StoreNamespacesOptKey),
+		dirMaxEntries,
+		fileMaxSize,
 	)
-	filesRepository := filesRepositoryAdapterImpl.New(
-		&filesRepositoryAdapterImpl.Config{
+	if err != nil {
+		loggerService.Log().Fatal().Err(err).Send()
+	}
+
+	// Get roots of all store namespaces, which renames may not cross
+	namespaceRoots := []string{}
+	if len(storeNamespaces) > 0 {
+		namespaceRoots = append(namespaceRoots, localStoreRootPath)
+		for _, ns := range storeNamespaces {
+			namespaceRoots = append(namespaceRoots, ns.RootPath)
+		}
+	}
+
+	// Check the trash lies outside every store root
+	if trashPath := cfg.Get(internalConfig.StoreLocalTrashPathOptKey); trashPath != "" {
+		storeRoots := []string{localStoreRootPath}
+		for _, ns := range storeNamespaces {
+			storeRoots = append(storeRoots, ns.RootPath)
+		}
+		if err := checkOutsideRoots("trash path", trashPath, storeRoots); err != nil {
+			loggerService.Log().Fatal().Err(err).Send()
+		}
+	}
+
+	// Create repository
+	dirsRepositoryConfig := dirsRepositoryAdapterImpl.Config{
+		StoreLocalRootPath:   localStoreRootPath,
+		DirMaxEntries:        dirMaxEntries,
+		OperationTimeout:     storeOperationTimeout,
+		RetryAttempts:        storeRetryAttempts,
+		RetryBackoff:         storeRetryBackoff,
+		RetryCounter:         storeRetries,
+		HiddenNames:          hiddenNames,
+		RenameSamePathNoop:   renameSamePathNoop,
+		TopDirs:              topDirs,
+		DeleteBatchThreshold: cfg.GetInt(internalConfig.DeleteBatchThresholdOptKey),
+		NamespaceRoots:       namespaceRoots,
+	}
+	filesRepositoryConfig := filesRepositoryAdapterImpl.Config{
+		StoreLocalRootPath:     localStoreRootPath,
+		StoreLocalTempPath:     cfg.Get(internalConfig.StoreLocalTempPathOptKey),
+		StoreLocalTrashPath:    cfg.Get(internalConfig.StoreLocalTrashPathOptKey),
+		StoreLocalBackupPath:   cfg.Get(internalConfig.StoreLocalBackupPathOptKey),
+		StoreLocalVersionsPath: cfg.Get(internalConfig.StoreLocalVersionsPathOptKey),
+		StoreLocalExpiryPath:   cfg.Get(internalConfig.StoreLocalExpiryPathOptKey),
+		VersionsRetention:      cfg.GetInt(internalConfig.VersionsRetentionOptKey),
+		DirMaxEntries:          dirMaxEntries,
+		FileMaxSize:            fileMaxSize,
+		ListMaxEntries:         cfg.GetInt(internalConfig.StoreListMaxEntriesOptKey),
+		ListInlineMaxSize:      int64(cfg.GetInt(internalConfig.StoreListInlineMaxSizeOptKey)),
+		PreviewMaxBytes:        int64(cfg.GetInt(internalConfig.PreviewMaxBytesOptKey)),
+		RequireExtension:       cfg.Get(internalConfig.StoreRequireExtensionOptKey) == "true",
+		FollowSymlinks:         cfg.Get(internalConfig.StoreFollowSymlinksOptKey) == "true",
+		CaseInsensitiveNames:   cfg.Get(internalConfig.StoreCaseInsensitiveOptKey) == "true",
+		OperationTimeout:       storeOperationTimeout,
+		RetryAttempts:          storeRetryAttempts,
+		RetryBackoff:           storeRetryBackoff,
+		RetryCounter:           storeRetries,
+		ListDefaultPath:        cfg.Get(internalConfig.StoreListDefaultPathOptKey),
+		HiddenNames:            hiddenNames,
+		RenameSamePathNoop:     renameSamePathNoop,
+		MimeDetection:          mimeDetection,
+		MimeRoutes:             mimeRoutes,
+		TopDirs:                topDirs,
+		CheckFreeSpace:         cfg.Get(internalConfig.StoreCheckFreeSpaceOptKey) == "true",
+		FreeSpaceMargin:        int64(cfg.GetInt(internalConfig.StoreFreeSpaceMarginOptKey)),
+		ListOrder:              listOrder,
+		UploadDedup:            uploadDedup,
+		UploadOrientation:      cfg.Get(internalConfig.UploadFixOrientationOptKey) == "true",
+		UploadStripExif:        cfg.Get(internalConfig.UploadStripExifOptKey) == "true",
+		ImageMaxPixels:         int64(cfg.GetInt(internalConfig.DownloadImageMaxPixelsOptKey)),
+		NamespaceRoots:         namespaceRoots,
+	}
+	dirsRepository := dirsRepositoryAdapterImpl.New(&dirsRepositoryConfig)
+	filesRepository := filesRepositoryAdapterImpl.New(&filesRepositoryConfig)
+
+	// Create repositories of store namespaces, routed by the request context
+	namespaceNames := []string{}
+	if len(storeNamespaces) > 0 {
+		namespaceDirsRepositories := map[string]dirsRepositoryAdapterPort.Interface{}
+		namespaceFilesRepositories := map[string]filesRepositoryAdapterPort.Interface{}
+		for _, ns := range storeNamespaces {
+			dirsConfig := dirsRepositoryConfig
+			dirsConfig.StoreLocalRootPath = ns.RootPath
+			dirsConfig.DirMaxEntries = ns.DirMaxEntries
+			namespaceDirsRepositories[ns.Name] = dirsRepositoryAdapterImpl.New(&dirsConfig)
+
+			filesConfig := filesRepositoryConfig
+			filesConfig.StoreLocalRootPath = ns.RootPath
+			filesConfig.DirMaxEntries = ns.DirMaxEntries
+			filesConfig.FileMaxSize = ns.FileMaxSize
+			if filesConfig.StoreLocalTrashPath != "" {
+				filesConfig.StoreLocalTrashPath = filepath.Join(filesConfig.StoreLocalTrashPath, ns.Name)
+			}
+			if filesConfig.StoreLocalBackupPath != "" {
+				filesConfig.StoreLocalBackupPath = filepath.Join(filesConfig.StoreLocalBackupPath, ns.Name)
+			}
+			if filesConfig.StoreLocalVersionsPath != "" {
+				filesConfig.StoreLocalVersionsPath = filepath.Join(filesConfig.StoreLocalVersionsPath, ns.Name)
+			}
+			if filesConfig.StoreLocalExpiryPath != "" {
+				filesConfig.StoreLocalExpiryPath = filepath.Join(filesConfig.StoreLocalExpiryPath, ns.Name)
+			}
+			namespaceFilesRepositories[ns.Name] = filesRepositoryAdapterImpl.New(&filesConfig)
+
+			namespaceNames = append(namespaceNames, ns.Name)
+		}
+		dirsRepository = dirsRepositoryAdapterImpl.NewNamespaced(dirsRepository, namespaceDirsRepositories)
+		filesRepository = filesRepositoryAdapterImpl.NewNamespaced(filesRepository, namespaceFilesRepositories)
+	}
+
+	storeRepository := storeRepositoryAdapterImpl.New(
+		&storeRepositoryAdapterImpl.Config{
 			StoreLocalRootPath: localStoreRootPath,
 		},
 	)
 
+	// Create path locks shared by services
+	pathLocks := pathlock.New()
+
 	// Create services
 	dirsService := dirsServiceImpl.New(
 		&dirsServiceImpl.Config{
 			DirsRepository: dirsRepository,
+			PathLocks:      pathLocks,
 		},
 	)
 	filesService := filesServiceImpl.New(
 		&filesServiceImpl.Config{
-			FilesRepository: filesRepository,
+			FilesRepository:    filesRepository,
+			DirsRepository:     dirsRepository,
+			IdempotencyTtl:     time.Duration(cfg.GetInt(internalConfig.UploadIdempotencyTtlOptKey)) * time.Second,
+			TrashTtl:           time.Duration(cfg.GetInt(internalConfig.TrashTtlOptKey)) * time.Second,
+			BackupTtl:          time.Duration(cfg.GetInt(internalConfig.BackupTtlOptKey)) * time.Second,
+			PathLocks:          pathLocks,
+			IndexFile:          cfg.Get(internalConfig.DownloadIndexFileOptKey),
+			DirListing:         cfg.Get(internalConfig.DownloadDirListingOptKey) == "true",
+			UploadMaxDuration:  time.Duration(cfg.GetInt(internalConfig.UploadMaxDurationOptKey)) * time.Second,
+			ConcatMaxSize:      int64(cfg.GetInt(internalConfig.DownloadConcatMaxSizeOptKey)),
+			ImportAllowedHosts: importAllowedHosts,
+			ImportDeniedHosts:  importDeniedHosts,
+			ImportMaxSize:      int64(cfg.GetInt(internalConfig.ImportMaxSizeOptKey)),
+			ImportMimeTypes:    splitList(cfg.Get(internalConfig.ImportMimeTypesOptKey)),
+			ImageMaxPixels:     int64(cfg.GetInt(internalConfig.DownloadImageMaxPixelsOptKey)),
+			ImageCacheSize:     int64(cfg.GetInt(internalConfig.DownloadImageCacheSizeOptKey)),
+			SessionSecret:      []byte(cfg.Get(internalConfig.UploadSessionSecretOptKey)),
+			SessionTokenTtl:    time.Duration(cfg.GetInt(internalConfig.UploadSessionTokenTtlOptKey)) * time.Second,
+		},
+	)
+	systemService := systemServiceImpl.New(
+		&systemServiceImpl.Config{
+			ServiceName:     os.Getenv("SERVICE_NAME"),
+			Version:         version,
+			Commit:          commit,
+			StoreRepository: storeRepository,
 		},
 	)
 
+	// Start trash and backup reaper
+	if trashSweepInterval := time.Duration(cfg.GetInt(internalConfig.TrashSweepIntervalOptKey)) * time.Second; trashSweepInterval > 0 {
+		go func() {
+			ticker := time.NewTicker(trashSweepInterval)
+			defer ticker.Stop()
+			for range ticker.C {
+				for _, name := range append([]string{""}, namespaceNames...) {
+					ctx := namespace.WithName(context.Background(), name)
+					if _, err := filesService.PurgeTrash(ctx); err != nil {
+						loggerService.Log().Err(err).Send()
+					}
+					if _, err := filesService.PurgeBackups(ctx); err != nil {
+						loggerService.Log().Err(err).Send()
+					}
+				}
+			}
+		}()
+	}
+
+	// Start expired file reaper
+	if expirySweepInterval := time.Duration(cfg.GetInt(internalConfig.ExpirySweepIntervalOptKey)) * time.Second; expirySweepInterval > 0 {
+		go func() {
+			ticker := time.NewTicker(expirySweepInterval)
+			defer ticker.Stop()
+			for range ticker.C {
+				for _, name := range append([]string{""}, namespaceNames...) {
+					ctx := namespace.WithName(context.Background(), name)
+					if _, err := filesService.PurgeExpired(ctx); err != nil {
+						loggerService.Log().Err(err).Send()
+					}
+				}
+			}
+		}()
+	}
+
+	// Start dir size refresher
+	if dirSizeRefreshInterval := time.Duration(cfg.GetInt(internalConfig.DirSizeRefreshIntervalOptKey)) * time.Second; dirSizeRefreshInterval > 0 {
+		go func() {
+			ticker := time.NewTicker(dirSizeRefreshInterval)
+			defer ticker.Stop()
+			for range ticker.C {
+				for _, name := range append([]string{""}, namespaceNames...) {
+					ctx := namespace.WithName(context.Background(), name)
+					if _, err := dirsService.RefreshDirSizes(ctx, &dirsServicePort.RefreshDirSizesData{}); err != nil {
+						loggerService.Log().Err(err).Send()
+					}
+				}
+			}
+		}()
+	}
+
+	// Get request path canonicalization
+	canonicalPaths := cfg.Get(internalConfig.HttpCanonicalPathsOptKey) == "true"
+
+	// Get download cache policy
+	cacheRules, err := parseCacheRules(cfg.Get(internalConfig.DownloadCacheControlOptKey))
+	if err != nil {
+		loggerService.Log().Fatal().Err(err).Send()
+	}
+
+	// Create download metrics
+	activeDownloads, err := telemetryService.NewMetricInt64UpDownCounter(
+		"files.downloads.active",
+		true,
+		metric.WithDescription("Number of download streams being served"),
+	)
+	if err != nil {
+		loggerService.Log().Fatal().Err(err).Send()
+	}
+	rejectedDownloads, err := telemetryService.NewMetricInt64Counter(
+		"files.downloads.rejected",
+		true,
+		metric.WithDescription("Number of downloads rejected for lack of a free download slot"),
+	)
+	if err != nil {
+		loggerService.Log().Fatal().Err(err).Send()
+	}
+
+	// Get the largest file any namespace accepts and the dir of upload spool
+	// files, for file parts streamed before the upload metadata
+	uploadMaxSize := fileMaxSize
+	for _, ns := range storeNamespaces {
+		if uploadMaxSize > 0 && (ns.FileMaxSize == 0 || ns.FileMaxSize > uploadMaxSize) {
+			uploadMaxSize = ns.FileMaxSize
+		}
+	}
+	uploadTempPath := cfg.Get(internalConfig.StoreLocalTempPathOptKey)
+	if uploadTempPath == "" {
+		uploadTempPath = localStoreRootPath
+	}
+
 	// Create handlers
 	dirsHandler := httpDirsHandlerAdapterImpl.New(
 		&httpDirsHandlerAdapterImpl.Config{
-			DirsService: dirsService,
+			DirsService:    dirsService,
+			CanonicalPaths: canonicalPaths,
 		},
 	)
 	filesHandler := httpFilesHandlerAdapterImpl.New(
 		&httpFilesHandlerAdapterImpl.Config{
-			FilesService: filesService,
+			FilesService:   filesService,
+			CanonicalPaths: canonicalPaths,
+			CacheRules:     cacheRules,
+			MaxDownloads:   cfg.GetInt(internalConfig.DownloadMaxConcurrentOptKey),
+			DownloadMetrics: httpFilesHandlerAdapterImpl.DownloadMetrics{
+				Active:   activeDownloads,
+				Rejected: rejectedDownloads,
+			},
+			AutoIndex:      cfg.Get(internalConfig.DownloadAutoIndexOptKey) == "true",
+			AutoIndexLimit: cfg.GetInt(internalConfig.DownloadAutoIndexLimitOptKey),
+			UploadMaxSize:  uploadMaxSize,
+			UploadTempPath: uploadTempPath,
+		},
+	)
+	systemHandler := httpSystemHandlerAdapterImpl.New(
+		&httpSystemHandlerAdapterImpl.Config{
+			SystemService: systemService,
 		},
 	)
 
@@ -175,8 +554,23 @@ func main() {
 	// Get admin role
 	adminRole := cfg.Get(internalConfig.UsersAdminRoleOptKey)
 
+	// Create store namespace middleware
+	namespaceMiddleware := namespace.Middleware(namespaceNames)
+
+	// Create rejected path audit middleware
+	auditMiddleware := audit.Middleware(loggerService)
+
 	// Add routes
 	httpServer.
+		// System
+
+		// Get version
+		AddRoute(
+			http.MethodGet,
+			"/version",
+			systemHandler.Version,
+		).
+
 		// Dirs
 
 		// Create dir (admin)
@@ -187,6 +581,8 @@ func main() {
 			usersMiddleware.Auth(
 				users.WithAuthRolesOption(adminRole),
 			),
+			namespaceMiddleware,
+			auditMiddleware,
 		).
 		// Delete dir (admin)
 		AddRoute(
@@ -196,6 +592,8 @@ func main() {
 			usersMiddleware.Auth(
 				users.WithAuthRolesOption(adminRole),
 			),
+			namespaceMiddleware,
+			auditMiddleware,
 		).
 		// Rename dir (admin)
 		AddRoute(
@@ -205,6 +603,118 @@ func main() {
 			usersMiddleware.Auth(
 				users.WithAuthRolesOption(adminRole),
 			),
+			namespaceMiddleware,
+			auditMiddleware,
+		).
+		// Snapshot dir (admin)
+		AddRoute(
+			http.MethodPost,
+			"/admin/dirs/snapshot",
+			dirsHandler.AdminSnapshotDir,
+			usersMiddleware.Auth(
+				users.WithAuthRolesOption(adminRole),
+			),
+			namespaceMiddleware,
+			auditMiddleware,
+		).
+		// Prune old dirs (admin)
+		AddRoute(
+			http.MethodPost,
+			"/admin/dirs/prune",
+			dirsHandler.AdminPruneDirs,
+			usersMiddleware.Auth(
+				users.WithAuthRolesOption(adminRole),
+			),
+			namespaceMiddleware,
+			auditMiddleware,
+		).
+		// Flatten dir (admin)
+		AddRoute(
+			http.MethodPost,
+			"/admin/dirs/flatten",
+			dirsHandler.AdminFlattenDir,
+			usersMiddleware.Auth(
+				users.WithAuthRolesOption(adminRole),
+			),
+			namespaceMiddleware,
+			auditMiddleware,
+		).
+		// Empty dir (admin)
+		AddRoute(
+			http.MethodPost,
+			"/admin/dirs/empty",
+			dirsHandler.AdminEmptyDir,
+			usersMiddleware.Auth(
+				users.WithAuthRolesOption(adminRole),
+			),
+			namespaceMiddleware,
+			auditMiddleware,
+		).
+		// Get dir size (admin)
+		AddRoute(
+			http.MethodPost,
+			"/admin/dirs/size",
+			dirsHandler.AdminDirSize,
+			usersMiddleware.Auth(
+				users.WithAuthRolesOption(adminRole),
+			),
+			namespaceMiddleware,
+			auditMiddleware,
+		).
+		// Refresh dir sizes (admin)
+		AddRoute(
+			http.MethodPost,
+			"/admin/dirs/size/refresh",
+			dirsHandler.AdminRefreshDirSizes,
+			usersMiddleware.Auth(
+				users.WithAuthRolesOption(adminRole),
+			),
+			namespaceMiddleware,
+			auditMiddleware,
+		).
+		// Get dir digest (admin)
+		AddRoute(
+			http.MethodPost,
+			"/admin/dirs/digest",
+			dirsHandler.AdminDigestDir,
+			usersMiddleware.Auth(
+				users.WithAuthRolesOption(adminRole),
+			),
+			namespaceMiddleware,
+			auditMiddleware,
+		).
+		// Get dir tree (admin)
+		AddRoute(
+			http.MethodPost,
+			"/admin/dirs/tree",
+			dirsHandler.AdminDirTree,
+			usersMiddleware.Auth(
+				users.WithAuthRolesOption(adminRole),
+			),
+			namespaceMiddleware,
+			auditMiddleware,
+		).
+		// Get dir tree as text (admin)
+		AddRoute(
+			http.MethodPost,
+			"/admin/dirs/tree/text",
+			dirsHandler.AdminDirTreeText,
+			usersMiddleware.Auth(
+				users.WithAuthRolesOption(adminRole),
+			),
+			namespaceMiddleware,
+			auditMiddleware,
+		).
+		// Ensure dir layout (admin)
+		AddRoute(
+			http.MethodPost,
+			"/admin/dirs/layout",
+			dirsHandler.AdminEnsureDirLayout,
+			usersMiddleware.Auth(
+				users.WithAuthRolesOption(adminRole),
+			),
+			namespaceMiddleware,
+			auditMiddleware,
 		).
 
 		// Files
@@ -217,6 +727,8 @@ func main() {
 			usersMiddleware.Auth(
 				users.WithAuthRolesOption(adminRole),
 			),
+			namespaceMiddleware,
+			auditMiddleware,
 		).
 		// Get files (admin)
 		AddRoute(
@@ -226,6 +738,41 @@ func main() {
 			usersMiddleware.Auth(
 				users.WithAuthRolesOption(adminRole),
 			),
+			namespaceMiddleware,
+			auditMiddleware,
+		).
+		// Get list token (admin)
+		AddRoute(
+			http.MethodPost,
+			"/admin/files/list/token",
+			filesHandler.AdminListToken,
+			usersMiddleware.Auth(
+				users.WithAuthRolesOption(adminRole),
+			),
+			namespaceMiddleware,
+			auditMiddleware,
+		).
+		// Find files (admin)
+		AddRoute(
+			http.MethodPost,
+			"/admin/files/find",
+			filesHandler.AdminFind,
+			usersMiddleware.Auth(
+				users.WithAuthRolesOption(adminRole),
+			),
+			namespaceMiddleware,
+			auditMiddleware,
+		).
+		// Recent files (admin)
+		AddRoute(
+			http.MethodPost,
+			"/admin/files/recent",
+			filesHandler.AdminRecentFiles,
+			usersMiddleware.Auth(
+				users.WithAuthRolesOption(adminRole),
+			),
+			namespaceMiddleware,
+			auditMiddleware,
 		).
 		// Delete file (admin)
 		AddRoute(
@@ -235,6 +782,8 @@ func main() {
 			usersMiddleware.Auth(
 				users.WithAuthRolesOption(adminRole),
 			),
+			namespaceMiddleware,
+			auditMiddleware,
 		).
 		// Rename file (admin)
 		AddRoute(
@@ -244,6 +793,217 @@ func main() {
 			usersMiddleware.Auth(
 				users.WithAuthRolesOption(adminRole),
 			),
+			namespaceMiddleware,
+			auditMiddleware,
+		).
+		// Move file (admin)
+		AddRoute(
+			http.MethodPost,
+			"/admin/files/move",
+			filesHandler.AdminMoveFile,
+			usersMiddleware.Auth(
+				users.WithAuthRolesOption(adminRole),
+			),
+			namespaceMiddleware,
+			auditMiddleware,
+		).
+		// Copy file (admin)
+		AddRoute(
+			http.MethodPost,
+			"/admin/files/copy",
+			filesHandler.AdminCopyFile,
+			usersMiddleware.Auth(
+				users.WithAuthRolesOption(adminRole),
+			),
+			namespaceMiddleware,
+			auditMiddleware,
+		).
+		// Write file at offset (admin)
+		AddRoute(
+			http.MethodPost,
+			"/admin/files/write",
+			filesHandler.AdminWriteAt,
+			usersMiddleware.Auth(
+				users.WithAuthRolesOption(adminRole),
+			),
+			namespaceMiddleware,
+			auditMiddleware,
+		).
+		// Allocate file (admin)
+		AddRoute(
+			http.MethodPost,
+			"/admin/files/allocate",
+			filesHandler.AdminAllocateFile,
+			usersMiddleware.Auth(
+				users.WithAuthRolesOption(adminRole),
+			),
+			namespaceMiddleware,
+			auditMiddleware,
+		).
+		// Get file hash (admin)
+		AddRoute(
+			http.MethodPost,
+			"/admin/files/hash",
+			filesHandler.AdminFileHash,
+			usersMiddleware.Auth(
+				users.WithAuthRolesOption(adminRole),
+			),
+			namespaceMiddleware,
+			auditMiddleware,
+		).
+		// Write file range (admin)
+		AddRoute(
+			http.MethodPut,
+			"/admin/files/range",
+			filesHandler.AdminWriteRange,
+			usersMiddleware.Auth(
+				users.WithAuthRolesOption(adminRole),
+			),
+			namespaceMiddleware,
+			auditMiddleware,
+		).
+		// Move file or dir (admin)
+		AddRoute(
+			http.MethodPost,
+			"/admin/move",
+			filesHandler.AdminMove,
+			usersMiddleware.Auth(
+				users.WithAuthRolesOption(adminRole),
+			),
+			namespaceMiddleware,
+			auditMiddleware,
+		).
+		// Preview text file (admin)
+		AddRoute(
+			http.MethodPost,
+			"/admin/files/preview",
+			filesHandler.AdminPreviewFile,
+			usersMiddleware.Auth(
+				users.WithAuthRolesOption(adminRole),
+			),
+			namespaceMiddleware,
+			auditMiddleware,
+		).
+		// Download file (admin)
+		AddRoute(
+			http.MethodGet,
+			"/admin/files/download",
+			filesHandler.AdminDownloadFile,
+			usersMiddleware.Auth(
+				users.WithAuthRolesOption(adminRole),
+			),
+			namespaceMiddleware,
+			auditMiddleware,
+		).
+		// Download concatenated files (admin)
+		AddRoute(
+			http.MethodPost,
+			"/admin/files/concat",
+			filesHandler.AdminConcatFiles,
+			usersMiddleware.Auth(
+				users.WithAuthRolesOption(adminRole),
+			),
+			namespaceMiddleware,
+			auditMiddleware,
+		).
+		// Verify file checksums (admin)
+		AddRoute(
+			http.MethodPost,
+			"/admin/files/verify",
+			filesHandler.AdminVerifyFiles,
+			usersMiddleware.Auth(
+				users.WithAuthRolesOption(adminRole),
+			),
+			namespaceMiddleware,
+			auditMiddleware,
+		).
+		// List file versions (admin)
+		AddRoute(
+			http.MethodPost,
+			"/admin/files/versions",
+			filesHandler.AdminListFileVersions,
+			usersMiddleware.Auth(
+				users.WithAuthRolesOption(adminRole),
+			),
+			namespaceMiddleware,
+			auditMiddleware,
+		).
+		// Download file version (admin)
+		AddRoute(
+			http.MethodGet,
+			"/admin/files/versions/download",
+			filesHandler.AdminDownloadFileVersion,
+			usersMiddleware.Auth(
+				users.WithAuthRolesOption(adminRole),
+			),
+			namespaceMiddleware,
+			auditMiddleware,
+		).
+		// Restore file version (admin)
+		AddRoute(
+			http.MethodPost,
+			"/admin/files/versions/restore",
+			filesHandler.AdminRestoreFileVersion,
+			usersMiddleware.Auth(
+				users.WithAuthRolesOption(adminRole),
+			),
+			namespaceMiddleware,
+			auditMiddleware,
+		).
+		// Split file (admin)
+		AddRoute(
+			http.MethodPost,
+			"/admin/files/split",
+			filesHandler.AdminSplitFile,
+			usersMiddleware.Auth(
+				users.WithAuthRolesOption(adminRole),
+			),
+			namespaceMiddleware,
+			auditMiddleware,
+		).
+		// Resolve path (admin)
+		AddRoute(
+			http.MethodPost,
+			"/admin/files/resolve",
+			filesHandler.AdminResolvePath,
+			usersMiddleware.Auth(
+				users.WithAuthRolesOption(adminRole),
+			),
+			namespaceMiddleware,
+			auditMiddleware,
+		).
+		// Check path (admin)
+		AddRoute(
+			http.MethodPost,
+			"/admin/files/resolve/check",
+			filesHandler.AdminCheckPath,
+			usersMiddleware.Auth(
+				users.WithAuthRolesOption(adminRole),
+			),
+			namespaceMiddleware,
+			auditMiddleware,
+		).
+		// Import file from url (admin)
+		AddRoute(
+			http.MethodPost,
+			"/admin/files/import",
+			filesHandler.AdminImportFile,
+			usersMiddleware.Auth(
+				users.WithAuthRolesOption(adminRole),
+			),
+			namespaceMiddleware,
+			auditMiddleware,
+		).
+		// Stat file (admin)
+		AddRoute(
+			http.MethodPost,
+			"/admin/files/stat",
+			filesHandler.AdminStatFile,
+			usersMiddleware.Auth(
+				users.WithAuthRolesOption(adminRole),
+			),
+			namespaceMiddleware,
+			auditMiddleware,
 		)
 
 	// Register service
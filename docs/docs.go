@@ -26,7 +26,7 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "produces": [
-                    "text/plain"
+                    "application/json"
                 ],
                 "tags": [
                     "dirs"
@@ -41,16 +41,37 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/dto.AdminCreateDirRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Store namespace (default root if omitted)",
+                        "name": "X-Store-Namespace",
+                        "in": "header"
                     }
                 ],
                 "responses": {
+                    "200": {
+                        "description": "Dir already exists (get_or_create only)",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateDirResponse"
+                        }
+                    },
                     "201": {
-                        "description": "Created"
+                        "description": "Body only if get_or_create was requested",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateDirResponse"
+                        }
                     },
                     "400": {
-                        "description": "Possible error codes: bad_request, bad_request:invalid_path, bad_request:dir_exist",
+                        "description": "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:dir_exist, bad_request:dir_not_found, bad_request:dir_full",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Possible error codes: forbidden:path_escape, forbidden:top_dir_not_allowed",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
                         }
                     }
                 }
@@ -80,6 +101,18 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/dto.AdminDeleteDirRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Refuse if the dir was modified after this HTTP date",
+                        "name": "If-Unmodified-Since",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Store namespace (default root if omitted)",
+                        "name": "X-Store-Namespace",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -87,9 +120,21 @@ const docTemplate = `{
                         "description": "OK"
                     },
                     "400": {
-                        "description": "Possible error codes: bad_request, bad_request:invalid_path, bad_request:dir_not_found",
+                        "description": "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:dir_not_found",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Possible error codes: forbidden:path_escape",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Possible error codes: precondition_failed:dir_modified",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
                         }
                     }
                 }
@@ -119,6 +164,18 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/dto.AdminRenameDirRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Refuse if the dir was modified after this HTTP date",
+                        "name": "If-Unmodified-Since",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Store namespace (default root if omitted)",
+                        "name": "X-Store-Namespace",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -126,139 +183,201 @@ const docTemplate = `{
                         "description": "OK"
                     },
                     "400": {
-                        "description": "Possible error codes: bad_request, bad_request:invalid_old_path, bad_request:invalid_new_path, bad_request:old_dir_not_found, bad_request:new_dir_exist",
+                        "description": "Possible error codes: bad_request, bad_request:invalid_old_path, bad_request:invalid_new_path, bad_request:invalid_characters, bad_request:old_dir_not_found, bad_request:new_dir_exist, bad_request:cross_namespace",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Possible error codes: forbidden:path_escape",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Possible error codes: precondition_failed:dir_modified",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/admin/files": {
+        "/admin/dirs/digest": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "Returns the SHA-256 digest of a dir subtree: records \"D\" + path + NUL + LF for dirs and \"F\" + path + NUL + hex(sha256(content)) + LF for files, sorted by relative path and hashed in order. Symlinks and hidden names are skipped.",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
+                    "application/json",
                     "text/plain"
                 ],
                 "tags": [
-                    "files"
+                    "dirs"
                 ],
-                "summary": "Create file (admin)",
+                "summary": "Get dir digest (admin)",
                 "parameters": [
                     {
-                        "type": "file",
-                        "description": "File to upload",
-                        "name": "file",
-                        "in": "formData",
-                        "required": true
+                        "description": "Get dir digest (admin)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AdminDigestDirRequest"
+                        }
                     },
                     {
                         "type": "string",
-                        "description": "Metadata",
-                        "name": "meta",
-                        "in": "formData",
-                        "required": true
+                        "description": "Store namespace (default root if omitted)",
+                        "name": "X-Store-Namespace",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.DigestDirResponse"
+                        }
                     },
                     "400": {
-                        "description": "Possible error codes: bad_request, bad_request:dir_not_found, bad_request:file_exist",
+                        "description": "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:dir_not_found, bad_request:dir_too_large",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Possible error codes: forbidden:path_escape",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/admin/dirs/empty": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "Removes every entry of a dir but keeps the dir itself. Applies the same depth and symlink guards as dir deletion. Without best_effort the first entry that cannot be removed fails the request; with best_effort removal goes on and failed entries are reported. Returns the number of removed files and subdirectories.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
+                    "application/json",
                     "text/plain"
                 ],
                 "tags": [
-                    "files"
+                    "dirs"
                 ],
-                "summary": "Delete file (admin)",
+                "summary": "Empty dir (admin)",
                 "parameters": [
                     {
-                        "description": "Delete file (admin)",
+                        "description": "Empty dir (admin)",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.AdminDeleteFileRequest"
+                            "$ref": "#/definitions/dto.AdminEmptyDirRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Store namespace (default root if omitted)",
+                        "name": "X-Store-Namespace",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.EmptyDirResponse"
+                        }
                     },
                     "400": {
-                        "description": "Possible error codes: bad_request, bad_request:invalid_path, bad_request:file_not_found",
+                        "description": "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:dir_not_found",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Possible error codes: forbidden:path_escape",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
                         }
                     }
                 }
-            },
-            "patch": {
+            }
+        },
+        "/admin/dirs/flatten": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "Moves every file below a path into one target dir (the path itself if target_path is empty). Name collisions are resolved by on_conflict: rename (default, adds a numbered suffix), skip or overwrite. With prefix_names, files are named after their relative path (\"a/b.txt\" becomes \"a_b.txt\"). Reports the outcome per file.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
+                    "application/json",
                     "text/plain"
                 ],
                 "tags": [
-                    "files"
+                    "dirs"
                 ],
-                "summary": "Rename file (admin)",
+                "summary": "Flatten dir (admin)",
                 "parameters": [
                     {
-                        "description": "Rename file (admin)",
+                        "description": "Flatten dir (admin)",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.AdminRenameFileRequest"
+                            "$ref": "#/definitions/dto.AdminFlattenDirRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Store namespace (default root if omitted)",
+                        "name": "X-Store-Namespace",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.FlattenDirResponse"
+                        }
                     },
                     "400": {
-                        "description": "Possible error codes: bad_request, bad_request:invalid_old_path, bad_request:invalid_new_path, bad_request:old_file_not_found, bad_request:new_file_exist",
+                        "description": "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:invalid_policy, bad_request:dir_not_found, bad_request:dir_too_large",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Possible error codes: forbidden:path_escape",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/admin/files/list": {
+        "/admin/dirs/layout": {
             "post": {
                 "security": [
                     {
@@ -273,111 +392,3140 @@ const docTemplate = `{
                     "text/plain"
                 ],
                 "tags": [
-                    "files"
+                    "dirs"
                 ],
-                "summary": "List files (admin)",
+                "summary": "Ensure dir layout (admin)",
                 "parameters": [
                     {
-                        "description": "List files (admin)",
+                        "description": "Ensure dir layout (admin)",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.AdminListFilesRequest"
+                            "$ref": "#/definitions/dto.AdminEnsureDirLayoutRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Store namespace (default root if omitted)",
+                        "name": "X-Store-Namespace",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/dto.FileResponse"
-                            }
+                            "$ref": "#/definitions/dto.EnsureDirLayoutResponse"
                         }
                     },
                     "400": {
-                        "description": "Possible error codes: bad_request, bad_request:invalid_path",
+                        "description": "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:invalid_name, bad_request:invalid_tree, bad_request:layout_too_large, bad_request:dir_full",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Possible error codes: forbidden:path_escape, forbidden:top_dir_not_allowed",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "dto.AdminCreateDirRequest": {
-            "type": "object",
-            "properties": {
-                "path": {
-                    "type": "string"
-                }
-            }
-        },
-        "dto.AdminDeleteDirRequest": {
-            "type": "object",
-            "properties": {
-                "path": {
-                    "type": "string"
-                }
-            }
-        },
-        "dto.AdminDeleteFileRequest": {
-            "type": "object",
-            "properties": {
-                "path": {
-                    "type": "string"
-                }
-            }
         },
-        "dto.AdminListFilesRequest": {
-            "type": "object",
-            "properties": {
-                "path": {
-                    "type": "string"
+        "/admin/dirs/prune": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deletes the subdirectories of a path last modified more than older_than seconds ago (at most 9223372036, about 292 years).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/plain"
+                ],
+                "tags": [
+                    "dirs"
+                ],
+                "summary": "Prune old dirs (admin)",
+                "parameters": [
+                    {
+                        "description": "Prune old dirs (admin)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AdminPruneDirsRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Store namespace (default root if omitted)",
+                        "name": "X-Store-Namespace",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PruneDirsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:invalid_age, bad_request:dir_not_found",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Possible error codes: forbidden:path_escape",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    }
                 }
             }
         },
-        "dto.AdminRenameDirRequest": {
-            "type": "object",
-            "properties": {
-                "new_path": {
-                    "type": "string"
-                },
-                "old_path": {
-                    "type": "string"
+        "/admin/dirs/size": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the total size and number of the files below a dir (the store root if path is empty) and the number of its subdirectories. Sizes are cached per dir and dropped for a dir and its ancestors when something below it changes through the service, so only changed dirs are read again. Symlinks and hidden names are skipped. computed_at tells when the size of the dir was last computed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/plain"
+                ],
+                "tags": [
+                    "dirs"
+                ],
+                "summary": "Get dir size (admin)",
+                "parameters": [
+                    {
+                        "description": "Get dir size (admin)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AdminDirSizeRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Store namespace (default root if omitted)",
+                        "name": "X-Store-Namespace",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.DirSizeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:dir_not_found",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Possible error codes: forbidden:path_escape",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    }
                 }
             }
         },
-        "dto.AdminRenameFileRequest": {
+        "/admin/dirs/size/refresh": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Walks a dir (the whole store if path is empty) without using cached sizes and caches the size of every dir below it, so later size queries are answered from memory. Picks up changes made outside the service. Returns the size of the walked dir.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/plain"
+                ],
+                "tags": [
+                    "dirs"
+                ],
+                "summary": "Refresh dir sizes (admin)",
+                "parameters": [
+                    {
+                        "description": "Refresh dir sizes (admin)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AdminRefreshDirSizesRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Store namespace (default root if omitted)",
+                        "name": "X-Store-Namespace",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.DirSizeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:dir_not_found",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Possible error codes: forbidden:path_escape",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/dirs/snapshot": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/plain"
+                ],
+                "tags": [
+                    "dirs"
+                ],
+                "summary": "Snapshot dir (admin)",
+                "parameters": [
+                    {
+                        "description": "Snapshot dir (admin)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AdminSnapshotDirRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Store namespace (default root if omitted)",
+                        "name": "X-Store-Namespace",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SnapshotDirResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:invalid_old_path, bad_request:invalid_new_path, bad_request:invalid_characters, bad_request:old_dir_not_found, bad_request:new_dir_exist, bad_request:dir_full, bad_request:dir_too_large",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Possible error codes: forbidden:path_escape",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/dirs/tree": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/plain"
+                ],
+                "tags": [
+                    "dirs"
+                ],
+                "summary": "Get dir tree (admin)",
+                "parameters": [
+                    {
+                        "description": "Get dir tree (admin)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AdminDirTreeRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Store namespace (default root if omitted)",
+                        "name": "X-Store-Namespace",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.DirTreeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:invalid_depth, bad_request:dir_not_found",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Possible error codes: forbidden:path_escape",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/dirs/tree/text": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Renders a dir subtree like the Unix tree command: the dir itself (\".\" for the store root), one indented line per entry and a final \"N directories, M files\" count. Symlinks and hidden names are skipped.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "dirs"
+                ],
+                "summary": "Get dir tree as text (admin)",
+                "parameters": [
+                    {
+                        "description": "Get dir tree as text (admin)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AdminDirTreeRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Store namespace (default root if omitted)",
+                        "name": "X-Store-Namespace",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:invalid_depth, bad_request:dir_not_found",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Possible error codes: forbidden:path_escape",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/files": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "If upload streaming is enabled, the file part is written to the store as it arrives when the meta part precedes it (otherwise it is buffered first). Uploads whose Content-Length or declared size shows the file exceeds the max file size are rejected with file_too_large before the file is read.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Create file (admin)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Metadata",
+                        "name": "meta",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "File to upload",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Replay the result of a successful upload with the same key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Store namespace (default root if omitted)",
+                        "name": "X-Store-Namespace",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Final file path (see MIME routing); backup_path is null unless a backup was made; with dedup link, the path of an existing file with the same content and duplicate set",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateFileResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Possible error codes: bad_request, bad_request:invalid_file, bad_request:invalid_path, bad_request:invalid_characters, bad_request:invalid_mode, bad_request:missing_extension, bad_request:dir_not_found, bad_request:file_exist, bad_request:file_not_found, bad_request:dir_full, bad_request:idempotency_key_reused, bad_request:backup_unavailable, bad_request:invalid_ttl, bad_request:expiry_unavailable, bad_request:invalid_dedup, bad_request:duplicate_content, bad_request:invalid_image, bad_request:unsupported_image",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Possible error codes: forbidden:path_escape, forbidden:top_dir_not_allowed",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Possible error codes: payload_too_large:file_too_large, payload_too_large:image_too_large",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Possible error codes: gateway_timeout:upload_timeout",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "507": {
+                        "description": "Possible error codes: insufficient_storage:storage_full",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Delete file (admin)",
+                "parameters": [
+                    {
+                        "description": "Delete file (admin)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AdminDeleteFileRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Refuse if the file was modified after this HTTP date",
+                        "name": "If-Unmodified-Since",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Store namespace (default root if omitted)",
+                        "name": "X-Store-Namespace",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:file_not_found",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Possible error codes: forbidden:path_escape",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Possible error codes: precondition_failed:file_modified",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Rename file (admin)",
+                "parameters": [
+                    {
+                        "description": "Rename file (admin)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AdminRenameFileRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Refuse if the file was modified after this HTTP date",
+                        "name": "If-Unmodified-Since",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Store namespace (default root if omitted)",
+                        "name": "X-Store-Namespace",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Possible error codes: bad_request, bad_request:invalid_old_path, bad_request:invalid_new_path, bad_request:old_file_not_found, bad_request:new_file_exist, bad_request:cross_namespace",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Possible error codes: forbidden:path_escape",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Possible error codes: precondition_failed:file_modified",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/files/allocate": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates a file of the given size without writing data, reserving its blocks up front unless sparse is set.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Allocate file (admin)",
+                "parameters": [
+                    {
+                        "description": "Allocate file (admin)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AdminAllocateFileRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Store namespace (default root if omitted)",
+                        "name": "X-Store-Namespace",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created"
+                    },
+                    "400": {
+                        "description": "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:invalid_size, bad_request:dir_not_found, bad_request:file_exist, bad_request:dir_full",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Possible error codes: forbidden:path_escape",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Possible error codes: payload_too_large:file_too_large",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "507": {
+                        "description": "Possible error codes: insufficient_storage:storage_full",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/files/concat": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Streams the content of the given files, in order, as a single body (at most 100 files). Fails before streaming if any file is missing or the total size exceeds the configured limit.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/octet-stream",
+                    "application/json"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Download concatenated files (admin)",
+                "parameters": [
+                    {
+                        "description": "Download concatenated files (admin)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AdminConcatFilesRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Store namespace (default root if omitted)",
+                        "name": "X-Store-Namespace",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Possible error codes: bad_request, bad_request:invalid_paths, bad_request:invalid_path, bad_request:invalid_characters, bad_request:file_not_found, bad_request:is_directory",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Possible error codes: forbidden:path_escape",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Possible error codes: payload_too_large:concat_too_large",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Possible error codes: too_many_downloads (retry after Retry-After seconds)",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/files/copy": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Copies a file to another path within the store, possibly in another dir, which must exist (dir_not_found otherwise). The copy keeps the modification time of the source. An existing destination is rejected with file_exist.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/plain"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Copy file (admin)",
+                "parameters": [
+                    {
+                        "description": "Copy file (admin)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AdminCopyFileRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Store namespace (default root if omitted)",
+                        "name": "X-Store-Namespace",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CopyFileResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Possible error codes: bad_request, bad_request:invalid_source_path, bad_request:invalid_dest_path, bad_request:invalid_characters, bad_request:invalid_path, bad_request:file_not_found, bad_request:dir_not_found, bad_request:file_exist, bad_request:is_directory, bad_request:dir_full, bad_request:cross_namespace",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Possible error codes: forbidden:path_escape",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "507": {
+                        "description": "Possible error codes: insufficient_storage:storage_full",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/files/download": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Streams a file. A dir is served by its configured index file if present, else by its listing if dir listings are enabled (404 otherwise). Text files are compressed with br or gzip as negotiated by Accept-Encoding, unless a Range is requested. If dir indexes are enabled, a dir without an index file is instead served to clients accepting text/html as an HTML page linking to its entries, paged by the configured index page size with cursor for the following pages. Files get Cache-Control (and Expires for a max-age) per the configured download cache policy, and ETag and Last-Modified validators. A single byte Range is served as 206 (multiple ranges are ignored); with If-Range, only while the validator still matches, else the whole current file is sent. With format, an image is decoded and re-encoded in that format (at quality for jpeg) and sent whole and uncompressed; conversions are cached until the file changes.",
+                "produces": [
+                    "application/octet-stream",
+                    "application/json",
+                    "text/plain"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Download file (admin)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "File or dir path",
+                        "name": "path",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Image format to convert a JPEG, PNG or GIF image to (jpeg, png, gif)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "JPEG quality (1-100, jpeg format only)",
+                        "name": "quality",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Page cursor of an HTML dir index",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Accepted media types (text/html for an HTML dir index)",
+                        "name": "Accept",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Accepted content encodings (br, gzip)",
+                        "name": "Accept-Encoding",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Byte range to download: bytes=start-end, bytes=start- or bytes=-suffix",
+                        "name": "Range",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag or Last-Modified date the Range applies to",
+                        "name": "If-Range",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Store namespace (default root if omitted)",
+                        "name": "X-Store-Namespace",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Listing of a dir without an index file",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.FileResponse"
+                            }
+                        }
+                    },
+                    "206": {
+                        "description": "Requested byte range",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Possible error codes: bad_request:invalid_path, bad_request:invalid_characters, bad_request:invalid_format, bad_request:invalid_quality, bad_request:invalid_cursor, bad_request:file_not_found, bad_request:not_image",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Possible error codes: forbidden:path_escape",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Possible error codes: not_found:index_not_found",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Possible error codes: payload_too_large:image_too_large",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "416": {
+                        "description": "Possible error codes: range_not_satisfiable:range_not_satisfiable",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Possible error codes: too_many_downloads (retry after Retry-After seconds)",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/files/find": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Recursively searches a subtree for names matching a glob or substring, streaming one JSON object per line as matches are found. Unreadable subdirectories are skipped. If the search fails after the first match was sent (e.g. on timeout), the stream ends with an error object whose code is search_incomplete.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/x-ndjson",
+                    "text/plain"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Find files (admin)",
+                "parameters": [
+                    {
+                        "description": "Find files (admin)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AdminFindRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Store namespace (default root if omitted)",
+                        "name": "X-Store-Namespace",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.FindResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:invalid_pattern, bad_request:invalid_depth, bad_request:dir_not_found",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Possible error codes: forbidden:path_escape",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/files/hash": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the SHA-256 of a file (and its MD5 and CRC-32 if with_md5 and with_crc32 are set) without transferring the content. Hashes of unchanged files are served from a cache.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/plain"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Get file hash (admin)",
+                "parameters": [
+                    {
+                        "description": "Get file hash (admin)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AdminFileHashRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Store namespace (default root if omitted)",
+                        "name": "X-Store-Namespace",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.FileHashResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:file_not_found, bad_request:is_directory",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Possible error codes: forbidden:path_escape",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/files/import": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Fetches url server-side (http or https, redirects not followed) and stores the content in path like an upload, named after name or else the last url path segment. The host must be allowed and not denied by the configured import host lists, which also match the addresses it resolves to; the content must fit the import size limit and, if configured, its Content-Type the allowed import media types.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/plain"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Import file from url (admin)",
+                "parameters": [
+                    {
+                        "description": "Import file from url (admin)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AdminImportFileRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Store namespace (default root if omitted)",
+                        "name": "X-Store-Namespace",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Final file path (see MIME routing); backup_path is null unless a backup was made",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateFileResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Possible error codes: bad_request, bad_request:invalid_url, bad_request:invalid_name, bad_request:invalid_file, bad_request:invalid_path, bad_request:invalid_characters, bad_request:invalid_mode, bad_request:mime_not_allowed, bad_request:missing_extension, bad_request:dir_not_found, bad_request:file_exist, bad_request:file_not_found, bad_request:dir_full, bad_request:backup_unavailable, bad_request:invalid_image, bad_request:unsupported_image",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Possible error codes: forbidden:path_escape, forbidden:top_dir_not_allowed, forbidden:import_disabled, forbidden:host_not_allowed",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Possible error codes: payload_too_large:file_too_large, payload_too_large:import_too_large, payload_too_large:image_too_large",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Possible error codes: bad_gateway:fetch_failed",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Possible error codes: gateway_timeout:upload_timeout",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "507": {
+                        "description": "Possible error codes: insufficient_storage:storage_full",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/files/list": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Every listing of an existing dir returns its change token in the X-List-Token header, computed from the same read as the entries. If since holds a token from X-List-Token or /admin/files/list/token and the listed dir did not change, responds 304 with only that header and no body. If group_by_type is set, responds with an object holding separate dirs and files arrays instead of a flat array. If limit is set, responds with one page of at most limit entries as an object (entries, or dirs and files if grouped) with has_more and, if set, the next_cursor to pass as cursor for the following page. Entries are sorted by order: dirs_first, files_first or mixed (interleaved by name), the configured default if omitted; pages of one listing must use the same order. If error_on_missing is false, a path that does not exist (below an existing dir) is listed as empty instead of failing with dir_not_found; invalid and escaping paths fail regardless. If mime_category is set (application, audio, font, image, model, text or video), only files whose detected MIME type has that top-level type are listed, leaving out dirs; pages and cursors apply to the filtered listing. If include_self is set, the first page starts with an entry for the listed dir itself (the parent dir when path is a file), named \".\" and marked with is_self, holding its mod_time and, per with_dir_stats and with_dir_size, its child_count and children_size; it does not count toward limit and is listed with the dirs when grouped. A missing path listed as empty has no such entry.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/plain"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "List files (admin)",
+                "parameters": [
+                    {
+                        "description": "List files (admin)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AdminListFilesRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Store namespace (default root if omitted)",
+                        "name": "X-Store-Namespace",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.FileResponse"
+                            }
+                        },
+                        "headers": {
+                            "X-List-Token": {
+                                "type": "string",
+                                "description": "Change token of the listed dir"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified",
+                        "headers": {
+                            "X-List-Token": {
+                                "type": "string",
+                                "description": "Change token of the listed dir"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:invalid_limit, bad_request:invalid_cursor, bad_request:invalid_order, bad_request:invalid_mime_category, bad_request:dir_not_found, bad_request:too_many_entries",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Possible error codes: forbidden:path_escape",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/files/list/token": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns a change token for the dir /admin/files/list would list, the same one listings return in X-List-Token. It changes when a direct entry is added, removed, renamed or modified; pass it as since to skip unchanged listings.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/plain"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Get list token (admin)",
+                "parameters": [
+                    {
+                        "description": "Get list token (admin)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AdminListTokenRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Store namespace (default root if omitted)",
+                        "name": "X-Store-Namespace",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListTokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:dir_not_found, bad_request:too_many_entries",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Possible error codes: forbidden:path_escape",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/files/move": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Moves a file to another path, possibly in another dir, which must exist (dir_not_found otherwise). An existing destination file is rejected with file_exist unless overwrite is set. Moving a file onto its own path changes nothing and responds with moved false. Across filesystems the file is copied and the source removed, reported with copied true.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/plain"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Move file (admin)",
+                "parameters": [
+                    {
+                        "description": "Move file (admin)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AdminMoveFileRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Refuse if the file was modified after this HTTP date",
+                        "name": "If-Unmodified-Since",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Store namespace (default root if omitted)",
+                        "name": "X-Store-Namespace",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.MoveFileResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Possible error codes: bad_request, bad_request:invalid_source_path, bad_request:invalid_dest_path, bad_request:invalid_characters, bad_request:invalid_path, bad_request:file_not_found, bad_request:dir_not_found, bad_request:file_exist, bad_request:is_directory, bad_request:dir_full, bad_request:cross_namespace",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Possible error codes: forbidden:path_escape",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Possible error codes: precondition_failed:file_modified",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "507": {
+                        "description": "Possible error codes: insufficient_storage:storage_full",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/files/preview": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/plain"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Preview text file (admin)",
+                "parameters": [
+                    {
+                        "description": "Preview text file (admin)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AdminPreviewFileRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Store namespace (default root if omitted)",
+                        "name": "X-Store-Namespace",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.FilePreviewResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:invalid_limit, bad_request:file_not_found, bad_request:file_not_text",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Possible error codes: forbidden:path_escape",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/files/range": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/octet-stream"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/plain"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Write file range (admin)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "File path",
+                        "name": "path",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Create mode of the upload, read from the range starting at 0: create (default, fails if the file exists), replace (fails if absent) or upsert",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Byte range of the body: bytes start-end/total",
+                        "name": "Content-Range",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "session_token of the previous range, to continue the upload on any instance",
+                        "name": "Upload-Session-Token",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Range bytes",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Store namespace (default root if omitted)",
+                        "name": "X-Store-Namespace",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "session_token is null once complete or if sessions are not configured",
+                        "schema": {
+                            "$ref": "#/definitions/dto.FileRangeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Possible error codes: bad_request:invalid_path, bad_request:invalid_characters, bad_request:invalid_mode, bad_request:invalid_range, bad_request:dir_not_found, bad_request:file_exist, bad_request:file_not_found, bad_request:dir_full, bad_request:range_out_of_order, bad_request:range_overlap, bad_request:range_total_mismatch, bad_request:invalid_session_token, bad_request:session_token_expired",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Possible error codes: forbidden:path_escape",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Possible error codes: payload_too_large:file_too_large",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "507": {
+                        "description": "Possible error codes: insufficient_storage:storage_full",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/files/recent": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the most recently modified files of a subtree (the whole store if path is empty), newest first. At most limit files are returned (50 if omitted, at most 1000), searching depth levels below the path (32 if omitted). Hidden entries are skipped and symlinks are not followed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/plain"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Recent files (admin)",
+                "parameters": [
+                    {
+                        "description": "Recent files (admin)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AdminRecentFilesRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Store namespace (default root if omitted)",
+                        "name": "X-Store-Namespace",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.RecentFileResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:invalid_limit, bad_request:invalid_depth, bad_request:dir_not_found",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Possible error codes: forbidden:path_escape",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/files/resolve": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the canonical form of a path as every other endpoint resolves it (slash-separated and relative to the store root, \"\" for the root itself, so \"/uploads/\", \"./uploads\" and \"uploads/x/..\" all resolve to \"uploads\"), whether it exists and its type (file, dir, symlink or other, null if missing). Symlinks are reported, not followed. Nothing is listed or read.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/plain"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Resolve path (admin)",
+                "parameters": [
+                    {
+                        "description": "Resolve path (admin)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AdminResolvePathRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Store namespace (default root if omitted)",
+                        "name": "X-Store-Namespace",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ResolvePathResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Possible error codes: forbidden:path_escape",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/files/resolve/check": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Runs the path safety rules of an operation (read, delete, list, upload or create_dir) on a path and reports each rule applied, in order, and whether it passed: characters (no control characters), empty (the store root only for list and upload), absolute (always passes, absolute paths are anchored at the store root), traversal (no \"..\" escaping the root), depth (nesting limits, and at most 5 missing directories for upload) and symlink (no symlinked parent directory). Rules stop at the first rejection, reported in rule with the error code the operation would fail with. Nothing is changed and the filesystem is only inspected with lstat; whether the entry exists is not checked.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/plain"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Check path (admin)",
+                "parameters": [
+                    {
+                        "description": "Check path (admin)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AdminCheckPathRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Store namespace (default root if omitted)",
+                        "name": "X-Store-Namespace",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CheckPathResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Possible error codes: bad_request, bad_request:invalid_operation",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/files/split": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Writes the content of a file into sequential parts of chunk_size bytes (name.part0001, name.part0002, ...) in target_path (the file's own dir if empty), the inverse of concatenating them, and returns the parts in order with their SHA-256. Fails without writing anything if a part name is taken or the parts do not fit, and removes written parts if interrupted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/plain"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Split file (admin)",
+                "parameters": [
+                    {
+                        "description": "Split file (admin)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AdminSplitFileRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Store namespace (default root if omitted)",
+                        "name": "X-Store-Namespace",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SplitFileResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:invalid_chunk_size, bad_request:too_many_parts, bad_request:file_not_found, bad_request:is_directory, bad_request:dir_not_found, bad_request:file_exist, bad_request:dir_full",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Possible error codes: forbidden:path_escape",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "507": {
+                        "description": "Possible error codes: insufficient_storage:storage_full",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/files/stat": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the metadata of a file or dir without listing or reading it: name, size and detected MIME type of a file (and its text encoding if with_encoding is set), and, for a file uploaded with a ttl, its expires_at and the seconds left until then in expires_in.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/plain"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Stat file (admin)",
+                "parameters": [
+                    {
+                        "description": "Stat file (admin)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AdminStatFileRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Store namespace (default root if omitted)",
+                        "name": "X-Store-Namespace",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.FileResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:file_not_found",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Possible error codes: forbidden:path_escape",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/files/verify": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Compares the current SHA-256 of each given file (at most 1000) against the expected one and reports, in request order, match, mismatch or missing (also for a dir). Hashes of unchanged files are served from a cache.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/plain"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Verify file checksums (admin)",
+                "parameters": [
+                    {
+                        "description": "Verify file checksums (admin)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AdminVerifyFilesRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Store namespace (default root if omitted)",
+                        "name": "X-Store-Namespace",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.VerifyFilesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Possible error codes: bad_request, bad_request:invalid_paths, bad_request:invalid_path, bad_request:invalid_characters, bad_request:invalid_hash",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Possible error codes: forbidden:path_escape",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/files/versions": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the kept versions of a file path, newest first. A version is the previous content of a file replaced by an upload with backup while a versions path is configured. A path without versions yields an empty list.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/plain"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "List file versions (admin)",
+                "parameters": [
+                    {
+                        "description": "List file versions (admin)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AdminListFileVersionsRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Store namespace (default root if omitted)",
+                        "name": "X-Store-Namespace",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.FileVersionResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:versions_disabled",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Possible error codes: forbidden:path_escape",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/files/versions/download": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Streams a kept version of a file path, as listed by the versions endpoint.",
+                "produces": [
+                    "application/octet-stream",
+                    "application/json",
+                    "text/plain"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Download file version (admin)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "File path",
+                        "name": "path",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Version id",
+                        "name": "version",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Store namespace (default root if omitted)",
+                        "name": "X-Store-Namespace",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Possible error codes: bad_request:invalid_path, bad_request:invalid_characters, bad_request:versions_disabled, bad_request:version_not_found",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Possible error codes: forbidden:path_escape",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Possible error codes: too_many_downloads (retry after Retry-After seconds)",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/files/versions/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Makes a kept version of a file path the current file again. The current file, if any, is kept as a new version first, whose id is returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/plain"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Restore file version (admin)",
+                "parameters": [
+                    {
+                        "description": "Restore file version (admin)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AdminRestoreFileVersionRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Store namespace (default root if omitted)",
+                        "name": "X-Store-Namespace",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.RestoreFileVersionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:invalid_version, bad_request:versions_disabled, bad_request:version_not_found, bad_request:dir_not_found, bad_request:dir_full",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Possible error codes: forbidden:path_escape",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "507": {
+                        "description": "Possible error codes: insufficient_storage:storage_full",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/files/write": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Writes the file bytes at offset within an existing file. The end of the write may not exceed the max file size; if none is configured, offset may not exceed the current file size.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Write file at offset (admin)",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Bytes to write",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Metadata",
+                        "name": "meta",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Store namespace (default root if omitted)",
+                        "name": "X-Store-Namespace",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:invalid_offset, bad_request:invalid_range, bad_request:file_not_found",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Possible error codes: forbidden:path_escape",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Possible error codes: payload_too_large:file_too_large",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "507": {
+                        "description": "Possible error codes: insufficient_storage:storage_full",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/move": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Move file or dir (admin)",
+                "parameters": [
+                    {
+                        "description": "Move file or dir (admin)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AdminMoveRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Refuse if the source was modified after this HTTP date",
+                        "name": "If-Unmodified-Since",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Store namespace (default root if omitted)",
+                        "name": "X-Store-Namespace",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:invalid_old_path, bad_request:invalid_new_path, bad_request:old_file_not_found, bad_request:new_file_exist, bad_request:new_dir_exist, bad_request:is_directory, bad_request:not_directory, bad_request:cross_namespace",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Possible error codes: forbidden:path_escape",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Possible error codes: precondition_failed:file_modified, precondition_failed:dir_modified",
+                        "schema": {
+                            "$ref": "#/definitions/httpctx.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/version": {
+            "get": {
+                "produces": [
+                    "application/json",
+                    "text/plain"
+                ],
+                "tags": [
+                    "system"
+                ],
+                "summary": "Get version",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.VersionResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "dto.AdminAllocateFileRequest": {
+            "type": "object",
+            "properties": {
+                "path": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "sparse": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "dto.AdminCheckPathRequest": {
+            "type": "object",
+            "properties": {
+                "operation": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AdminConcatFilesRequest": {
+            "type": "object",
+            "properties": {
+                "paths": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dto.AdminCopyFileRequest": {
+            "type": "object",
+            "properties": {
+                "dest_path": {
+                    "type": "string"
+                },
+                "source_path": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AdminCreateDirRequest": {
+            "type": "object",
+            "properties": {
+                "get_or_create": {
+                    "type": "boolean"
+                },
+                "path": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AdminDeleteDirRequest": {
+            "type": "object",
+            "properties": {
+                "path": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AdminDeleteFileRequest": {
+            "type": "object",
+            "properties": {
+                "path": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AdminDigestDirRequest": {
+            "type": "object",
+            "properties": {
+                "path": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AdminDirSizeRequest": {
+            "type": "object",
+            "properties": {
+                "path": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AdminDirTreeRequest": {
+            "type": "object",
+            "properties": {
+                "depth": {
+                    "type": "integer"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "with_files": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "dto.AdminEmptyDirRequest": {
+            "type": "object",
+            "properties": {
+                "best_effort": {
+                    "type": "boolean"
+                },
+                "path": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AdminEnsureDirLayoutRequest": {
+            "type": "object",
+            "properties": {
+                "path": {
+                    "type": "string"
+                },
+                "tree": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.DirLayoutNodeRequest"
+                    }
+                }
+            }
+        },
+        "dto.AdminFileHashRequest": {
+            "type": "object",
+            "properties": {
+                "path": {
+                    "type": "string"
+                },
+                "with_crc32": {
+                    "type": "boolean"
+                },
+                "with_md5": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "dto.AdminFindRequest": {
+            "type": "object",
+            "properties": {
+                "depth": {
+                    "type": "integer"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "pattern": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AdminFlattenDirRequest": {
+            "type": "object",
+            "properties": {
+                "on_conflict": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "prefix_names": {
+                    "type": "boolean"
+                },
+                "target_path": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AdminImportFileRequest": {
+            "type": "object",
+            "properties": {
+                "backup": {
+                    "type": "boolean"
+                },
+                "create_dir": {
+                    "type": "boolean"
+                },
+                "mode": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AdminListFileVersionsRequest": {
+            "type": "object",
+            "properties": {
+                "path": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AdminListFilesRequest": {
+            "type": "object",
+            "properties": {
+                "cursor": {
+                    "type": "string"
+                },
+                "error_on_missing": {
+                    "type": "boolean"
+                },
+                "group_by_type": {
+                    "type": "boolean"
+                },
+                "include_self": {
+                    "type": "boolean"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "mime_category": {
+                    "type": "string"
+                },
+                "order": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "since": {
+                    "type": "string"
+                },
+                "skip_errors": {
+                    "type": "boolean"
+                },
+                "with_content": {
+                    "type": "boolean"
+                },
+                "with_dir_size": {
+                    "type": "boolean"
+                },
+                "with_dir_stats": {
+                    "type": "boolean"
+                },
+                "with_encoding": {
+                    "type": "boolean"
+                },
+                "with_path": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "dto.AdminListTokenRequest": {
+            "type": "object",
+            "properties": {
+                "path": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AdminMoveFileRequest": {
+            "type": "object",
+            "properties": {
+                "dest_path": {
+                    "type": "string"
+                },
+                "overwrite": {
+                    "type": "boolean"
+                },
+                "source_path": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AdminMoveRequest": {
+            "type": "object",
+            "properties": {
+                "new_path": {
+                    "type": "string"
+                },
+                "old_path": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AdminPreviewFileRequest": {
+            "type": "object",
+            "properties": {
+                "bytes": {
+                    "type": "integer"
+                },
+                "lines": {
+                    "type": "integer"
+                },
+                "path": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AdminPruneDirsRequest": {
+            "type": "object",
+            "properties": {
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "older_than": {
+                    "type": "integer"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "with_contents": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "dto.AdminRecentFilesRequest": {
+            "type": "object",
+            "properties": {
+                "depth": {
+                    "type": "integer"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "path": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AdminRefreshDirSizesRequest": {
+            "type": "object",
+            "properties": {
+                "path": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AdminRenameDirRequest": {
+            "type": "object",
+            "properties": {
+                "new_path": {
+                    "type": "string"
+                },
+                "old_path": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AdminRenameFileRequest": {
+            "type": "object",
+            "properties": {
+                "new_path": {
+                    "type": "string"
+                },
+                "old_path": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AdminResolvePathRequest": {
+            "type": "object",
+            "properties": {
+                "path": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AdminRestoreFileVersionRequest": {
+            "type": "object",
+            "properties": {
+                "path": {
+                    "type": "string"
+                },
+                "version_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AdminSnapshotDirRequest": {
+            "type": "object",
+            "properties": {
+                "new_path": {
+                    "type": "string"
+                },
+                "old_path": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AdminSplitFileRequest": {
+            "type": "object",
+            "properties": {
+                "chunk_size": {
+                    "type": "integer"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "target_path": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AdminStatFileRequest": {
+            "type": "object",
+            "properties": {
+                "path": {
+                    "type": "string"
+                },
+                "with_encoding": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "dto.AdminVerifyFilesRequest": {
+            "type": "object",
+            "properties": {
+                "files": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.VerifyFileRequest"
+                    }
+                }
+            }
+        },
+        "dto.CheckPathResponse": {
+            "type": "object",
+            "properties": {
+                "allowed": {
+                    "type": "boolean"
+                },
+                "checks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.PathCheckResponse"
+                    }
+                },
+                "error": {
+                    "type": "string"
+                },
+                "operation": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "rule": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CopyFileResponse": {
+            "type": "object",
+            "properties": {
+                "path": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.CreateDirResponse": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "boolean"
+                },
+                "path": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CreateFileResponse": {
+            "type": "object",
+            "properties": {
+                "backup_path": {
+                    "type": "string"
+                },
+                "duplicate": {
+                    "type": "boolean"
+                },
+                "path": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.DigestDirResponse": {
+            "type": "object",
+            "properties": {
+                "digest": {
+                    "type": "string"
+                },
+                "dirs": {
+                    "type": "integer"
+                },
+                "files": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.DirLayoutNodeRequest": {
+            "type": "object",
+            "properties": {
+                "children": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.DirLayoutNodeRequest"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.DirSizeResponse": {
+            "type": "object",
+            "properties": {
+                "computed_at": {
+                    "type": "string"
+                },
+                "dirs": {
+                    "type": "integer"
+                },
+                "files": {
+                    "type": "integer"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.DirTreeResponse": {
+            "type": "object",
+            "properties": {
+                "children": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.DirTreeResponse"
+                    }
+                },
+                "is_dir": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.EmptyDirFailureResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.EmptyDirResponse": {
+            "type": "object",
+            "properties": {
+                "dirs": {
+                    "type": "integer"
+                },
+                "failed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.EmptyDirFailureResponse"
+                    }
+                },
+                "files": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.EnsureDirLayoutResponse": {
             "type": "object",
             "properties": {
-                "new_path": {
+                "created": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dto.FileHashResponse": {
+            "type": "object",
+            "properties": {
+                "crc32": {
                     "type": "string"
                 },
-                "old_path": {
+                "md5": {
+                    "type": "string"
+                },
+                "sha256": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.FilePartResponse": {
+            "type": "object",
+            "properties": {
+                "path": {
+                    "type": "string"
+                },
+                "sha256": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.FilePreviewResponse": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "mime_type": {
+                    "type": "string"
+                },
+                "truncated": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "dto.FileRangeResponse": {
+            "type": "object",
+            "properties": {
+                "complete": {
+                    "type": "boolean"
+                },
+                "received": {
+                    "type": "integer"
+                },
+                "session_token": {
                     "type": "string"
+                },
+                "total": {
+                    "type": "integer"
                 }
             }
         },
         "dto.FileResponse": {
             "type": "object",
             "properties": {
+                "access_time": {
+                    "type": "string"
+                },
+                "child_count": {
+                    "type": "integer"
+                },
+                "children_size": {
+                    "type": "integer"
+                },
+                "content": {
+                    "type": "string"
+                },
+                "encoding": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "expires_in": {
+                    "type": "integer"
+                },
                 "is_dir": {
                     "type": "boolean"
                 },
+                "is_self": {
+                    "type": "boolean"
+                },
+                "is_symlink": {
+                    "type": "boolean"
+                },
                 "mime_type": {
                     "type": "string"
                 },
+                "mod_time": {
+                    "type": "string"
+                },
                 "name": {
                     "type": "string"
                 },
+                "path": {
+                    "type": "string"
+                },
+                "selected": {
+                    "type": "boolean"
+                },
+                "size": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.FileVersionResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "version_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.FindResponse": {
+            "type": "object",
+            "properties": {
+                "is_dir": {
+                    "type": "boolean"
+                },
+                "is_symlink": {
+                    "type": "boolean"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.FlattenDirResponse": {
+            "type": "object",
+            "properties": {
+                "files": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.FlattenedFileResponse"
+                    }
+                }
+            }
+        },
+        "dto.FlattenedFileResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "new_path": {
+                    "type": "string"
+                },
+                "old_path": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ListTokenResponse": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.MoveFileResponse": {
+            "type": "object",
+            "properties": {
+                "copied": {
+                    "type": "boolean"
+                },
+                "moved": {
+                    "type": "boolean"
+                },
+                "path": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.PathCheckResponse": {
+            "type": "object",
+            "properties": {
+                "passed": {
+                    "type": "boolean"
+                },
+                "rule": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.PruneDirsResponse": {
+            "type": "object",
+            "properties": {
+                "deleted": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "skipped": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dto.RecentFileResponse": {
+            "type": "object",
+            "properties": {
+                "mod_time": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.ResolvePathResponse": {
+            "type": "object",
+            "properties": {
+                "exists": {
+                    "type": "boolean"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.RestoreFileVersionResponse": {
+            "type": "object",
+            "properties": {
+                "archived_version_id": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
                 "size": {
                     "type": "integer"
                 }
             }
+        },
+        "dto.SnapshotDirResponse": {
+            "type": "object",
+            "properties": {
+                "strategy": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.SplitFileResponse": {
+            "type": "object",
+            "properties": {
+                "parts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.FilePartResponse"
+                    }
+                }
+            }
+        },
+        "dto.VerifiedFileResponse": {
+            "type": "object",
+            "properties": {
+                "path": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.VerifyFileRequest": {
+            "type": "object",
+            "properties": {
+                "path": {
+                    "type": "string"
+                },
+                "sha256": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.VerifyFilesResponse": {
+            "type": "object",
+            "properties": {
+                "files": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.VerifiedFileResponse"
+                    }
+                }
+            }
+        },
+        "dto.VersionResponse": {
+            "type": "object",
+            "properties": {
+                "commit": {
+                    "type": "string"
+                },
+                "service_name": {
+                    "type": "string"
+                },
+                "store_available": {
+                    "type": "boolean"
+                },
+                "store_read_only": {
+                    "type": "boolean"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "httpctx.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
         }
     },
     "securityDefinitions": {
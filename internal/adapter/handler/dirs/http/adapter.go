@@ -1,7 +1,10 @@
 package adapter
 
 import (
+	"time"
+
 	dto "github.com/flash-go/files-service/internal/dto/dirs"
+	"github.com/flash-go/files-service/internal/httpctx"
 	httpDirsHandlerAdapterPort "github.com/flash-go/files-service/internal/port/adapter/handler/dirs/http"
 	dirsServicePort "github.com/flash-go/files-service/internal/port/service/dirs"
 	"github.com/flash-go/flash/http/server"
@@ -9,39 +12,50 @@ import (
 )
 
 type Config struct {
-	DirsService dirsServicePort.Interface
+	DirsService    dirsServicePort.Interface
+	CanonicalPaths bool
 }
 
 func New(config *Config) httpDirsHandlerAdapterPort.Interface {
 	return &adapter{
 		config.DirsService,
+		config.CanonicalPaths,
 	}
 }
 
 type adapter struct {
-	dirsService dirsServicePort.Interface
+	dirsService    dirsServicePort.Interface
+	canonicalPaths bool
 }
 
 // @Summary Create dir (admin)
 // @Tags dirs
 // @Security BearerAuth
 // @Accept json
-// @Produce plain
+// @Produce json
 // @Param request body dto.AdminCreateDirRequest true "Create dir (admin)"
-// @Success 201
-// @Failure 400 {string} string "Possible error codes: bad_request, bad_request:invalid_path, bad_request:dir_exist"
+// @Success 200 {object} dto.CreateDirResponse "Dir already exists (get_or_create only)"
+// @Success 201 {object} dto.CreateDirResponse "Body only if get_or_create was requested"
+// @Failure 400 {object} httpctx.ErrorResponse "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:dir_exist, bad_request:dir_not_found, bad_request:dir_full"
+// @Failure 403 {object} httpctx.ErrorResponse "Possible error codes: forbidden:path_escape, forbidden:top_dir_not_allowed"
+// @Param X-Store-Namespace header string false "Store namespace (default root if omitted)"
 // @Router /admin/dirs [post]
 func (a *adapter) AdminCreateDir(ctx server.ReqCtx) {
 	// Parse request json body
 	var request dto.AdminCreateDirRequest
 	if err := ctx.ReadJson(&request); err != nil {
-		ctx.WriteErrorResponse(errors.ErrBadRequest)
+		httpctx.WriteError(ctx, errors.ErrBadRequest)
 		return
 	}
 
+	// Canonicalize request paths
+	if a.canonicalPaths {
+		request.Canonicalize()
+	}
+
 	// Validate request
 	if err := request.Validate(); err != nil {
-		ctx.WriteErrorResponse(err)
+		httpctx.WriteError(ctx, err)
 		return
 	}
 
@@ -49,16 +63,25 @@ func (a *adapter) AdminCreateDir(ctx server.ReqCtx) {
 	data := dirsServicePort.CreateDirData(request)
 
 	// Create dir
-	if err := a.dirsService.CreateDir(
+	result, err := a.dirsService.CreateDir(
 		ctx.Context(),
 		&data,
-	); err != nil {
-		ctx.WriteErrorResponse(err)
+	)
+	if err != nil {
+		httpctx.WriteError(ctx, err)
 		return
 	}
 
 	// Write success response
-	ctx.WriteResponse(201, nil)
+	if !request.GetOrCreate {
+		ctx.WriteResponse(201, nil)
+		return
+	}
+	status := 201
+	if !result.Created {
+		status = 200
+	}
+	ctx.WriteResponse(status, dto.CreateDirResponse(*result))
 }
 
 // @Summary Delete dir (admin)
@@ -67,32 +90,44 @@ func (a *adapter) AdminCreateDir(ctx server.ReqCtx) {
 // @Accept json
 // @Produce plain
 // @Param request body dto.AdminDeleteDirRequest true "Delete dir (admin)"
+// @Param If-Unmodified-Since header string false "Refuse if the dir was modified after this HTTP date"
 // @Success 200
-// @Failure 400 {string} string "Possible error codes: bad_request, bad_request:invalid_path, bad_request:dir_not_found"
+// @Failure 400 {object} httpctx.ErrorResponse "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:dir_not_found"
+// @Failure 403 {object} httpctx.ErrorResponse "Possible error codes: forbidden:path_escape"
+// @Failure 412 {object} httpctx.ErrorResponse "Possible error codes: precondition_failed:dir_modified"
+// @Param X-Store-Namespace header string false "Store namespace (default root if omitted)"
 // @Router /admin/dirs [delete]
 func (a *adapter) AdminDeleteDir(ctx server.ReqCtx) {
 	// Parse request json body
 	var request dto.AdminDeleteDirRequest
 	if err := ctx.ReadJson(&request); err != nil {
-		ctx.WriteErrorResponse(errors.ErrBadRequest)
+		httpctx.WriteError(ctx, errors.ErrBadRequest)
 		return
 	}
 
+	// Canonicalize request paths
+	if a.canonicalPaths {
+		request.Canonicalize()
+	}
+
 	// Validate request
 	if err := request.Validate(); err != nil {
-		ctx.WriteErrorResponse(err)
+		httpctx.WriteError(ctx, err)
 		return
 	}
 
 	// Create data
-	data := dirsServicePort.DeleteDirData(request)
+	data := dirsServicePort.DeleteDirData{
+		Path:            request.Path,
+		UnmodifiedSince: httpctx.UnmodifiedSince(ctx),
+	}
 
 	// Delete dir
 	if err := a.dirsService.DeleteDir(
 		ctx.Context(),
 		&data,
 	); err != nil {
-		ctx.WriteErrorResponse(err)
+		httpctx.WriteError(ctx, err)
 		return
 	}
 
@@ -106,35 +141,577 @@ func (a *adapter) AdminDeleteDir(ctx server.ReqCtx) {
 // @Accept json
 // @Produce plain
 // @Param request body dto.AdminRenameDirRequest true "Rename dir (admin)"
+// @Param If-Unmodified-Since header string false "Refuse if the dir was modified after this HTTP date"
 // @Success 200
-// @Failure 400 {string} string "Possible error codes: bad_request, bad_request:invalid_old_path, bad_request:invalid_new_path, bad_request:old_dir_not_found, bad_request:new_dir_exist"
+// @Failure 400 {object} httpctx.ErrorResponse "Possible error codes: bad_request, bad_request:invalid_old_path, bad_request:invalid_new_path, bad_request:invalid_characters, bad_request:old_dir_not_found, bad_request:new_dir_exist, bad_request:cross_namespace"
+// @Failure 403 {object} httpctx.ErrorResponse "Possible error codes: forbidden:path_escape"
+// @Failure 412 {object} httpctx.ErrorResponse "Possible error codes: precondition_failed:dir_modified"
+// @Param X-Store-Namespace header string false "Store namespace (default root if omitted)"
 // @Router /admin/dirs [patch]
 func (a *adapter) AdminRenameDir(ctx server.ReqCtx) {
 	// Parse request json body
 	var request dto.AdminRenameDirRequest
 	if err := ctx.ReadJson(&request); err != nil {
-		ctx.WriteErrorResponse(errors.ErrBadRequest)
+		httpctx.WriteError(ctx, errors.ErrBadRequest)
 		return
 	}
 
+	// Canonicalize request paths
+	if a.canonicalPaths {
+		request.Canonicalize()
+	}
+
 	// Validate request
 	if err := request.Validate(); err != nil {
-		ctx.WriteErrorResponse(err)
+		httpctx.WriteError(ctx, err)
 		return
 	}
 
 	// Create data
-	data := dirsServicePort.RenameDirData(request)
+	data := dirsServicePort.RenameDirData{
+		OldPath:         request.OldPath,
+		NewPath:         request.NewPath,
+		UnmodifiedSince: httpctx.UnmodifiedSince(ctx),
+	}
 
 	// Rename dir
 	if err := a.dirsService.RenameDir(
 		ctx.Context(),
 		&data,
 	); err != nil {
-		ctx.WriteErrorResponse(err)
+		httpctx.WriteError(ctx, err)
 		return
 	}
 
 	// Write success response
 	ctx.WriteResponse(200, nil)
 }
+
+// @Summary Snapshot dir (admin)
+// @Tags dirs
+// @Security BearerAuth
+// @Accept json
+// @Produce json,plain
+// @Param request body dto.AdminSnapshotDirRequest true "Snapshot dir (admin)"
+// @Success 201 {object} dto.SnapshotDirResponse
+// @Failure 400 {object} httpctx.ErrorResponse "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:invalid_old_path, bad_request:invalid_new_path, bad_request:invalid_characters, bad_request:old_dir_not_found, bad_request:new_dir_exist, bad_request:dir_full, bad_request:dir_too_large"
+// @Failure 403 {object} httpctx.ErrorResponse "Possible error codes: forbidden:path_escape"
+// @Param X-Store-Namespace header string false "Store namespace (default root if omitted)"
+// @Router /admin/dirs/snapshot [post]
+func (a *adapter) AdminSnapshotDir(ctx server.ReqCtx) {
+	// Parse request json body
+	var request dto.AdminSnapshotDirRequest
+	if err := ctx.ReadJson(&request); err != nil {
+		httpctx.WriteError(ctx, errors.ErrBadRequest)
+		return
+	}
+
+	// Canonicalize request paths
+	if a.canonicalPaths {
+		request.Canonicalize()
+	}
+
+	// Validate request
+	if err := request.Validate(); err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Create data
+	data := dirsServicePort.SnapshotDirData(request)
+
+	// Snapshot dir
+	result, err := a.dirsService.SnapshotDir(
+		ctx.Context(),
+		&data,
+	)
+	if err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Write success response
+	ctx.WriteResponse(201, dto.SnapshotDirResponse(*result))
+}
+
+// @Summary Prune old dirs (admin)
+// @Description Deletes the subdirectories of a path last modified more than older_than seconds ago (at most 9223372036, about 292 years).
+// @Tags dirs
+// @Security BearerAuth
+// @Accept json
+// @Produce json,plain
+// @Param request body dto.AdminPruneDirsRequest true "Prune old dirs (admin)"
+// @Success 200 {object} dto.PruneDirsResponse
+// @Failure 400 {object} httpctx.ErrorResponse "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:invalid_age, bad_request:dir_not_found"
+// @Failure 403 {object} httpctx.ErrorResponse "Possible error codes: forbidden:path_escape"
+// @Param X-Store-Namespace header string false "Store namespace (default root if omitted)"
+// @Router /admin/dirs/prune [post]
+func (a *adapter) AdminPruneDirs(ctx server.ReqCtx) {
+	// Parse request json body
+	var request dto.AdminPruneDirsRequest
+	if err := ctx.ReadJson(&request); err != nil {
+		httpctx.WriteError(ctx, errors.ErrBadRequest)
+		return
+	}
+
+	// Canonicalize request paths
+	if a.canonicalPaths {
+		request.Canonicalize()
+	}
+
+	// Validate request
+	if err := request.Validate(); err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Create data
+	data := dirsServicePort.PruneDirsData{
+		Path:         request.Path,
+		MaxAge:       time.Duration(request.OlderThan) * time.Second,
+		WithContents: request.WithContents,
+		DryRun:       request.DryRun,
+	}
+
+	// Prune dirs
+	result, err := a.dirsService.PruneDirs(
+		ctx.Context(),
+		&data,
+	)
+	if err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Write success response
+	ctx.WriteResponse(200, dto.PruneDirsResponse(*result))
+}
+
+// @Summary Get dir tree (admin)
+// @Tags dirs
+// @Security BearerAuth
+// @Accept json
+// @Produce json,plain
+// @Param request body dto.AdminDirTreeRequest true "Get dir tree (admin)"
+// @Success 200 {object} dto.DirTreeResponse
+// @Failure 400 {object} httpctx.ErrorResponse "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:invalid_depth, bad_request:dir_not_found"
+// @Failure 403 {object} httpctx.ErrorResponse "Possible error codes: forbidden:path_escape"
+// @Param X-Store-Namespace header string false "Store namespace (default root if omitted)"
+// @Router /admin/dirs/tree [post]
+func (a *adapter) AdminDirTree(ctx server.ReqCtx) {
+	// Parse request json body
+	var request dto.AdminDirTreeRequest
+	if err := ctx.ReadJson(&request); err != nil {
+		httpctx.WriteError(ctx, errors.ErrBadRequest)
+		return
+	}
+
+	// Canonicalize request paths
+	if a.canonicalPaths {
+		request.Canonicalize()
+	}
+
+	// Validate request
+	if err := request.Validate(); err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Create data
+	data := dirsServicePort.GetDirTreeData(request)
+
+	// Get dir tree
+	tree, err := a.dirsService.GetDirTree(
+		ctx.Context(),
+		&data,
+	)
+	if err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Write success response
+	ctx.WriteResponse(200, convertDirTree(tree))
+}
+
+// @Summary Get dir tree as text (admin)
+// @Description Renders a dir subtree like the Unix tree command: the dir itself ("." for the store root), one indented line per entry and a final "N directories, M files" count. Symlinks and hidden names are skipped.
+// @Tags dirs
+// @Security BearerAuth
+// @Accept json
+// @Produce plain
+// @Param request body dto.AdminDirTreeRequest true "Get dir tree as text (admin)"
+// @Success 200 {string} string
+// @Failure 400 {object} httpctx.ErrorResponse "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:invalid_depth, bad_request:dir_not_found"
+// @Failure 403 {object} httpctx.ErrorResponse "Possible error codes: forbidden:path_escape"
+// @Param X-Store-Namespace header string false "Store namespace (default root if omitted)"
+// @Router /admin/dirs/tree/text [post]
+func (a *adapter) AdminDirTreeText(ctx server.ReqCtx) {
+	// Parse request json body
+	var request dto.AdminDirTreeRequest
+	if err := ctx.ReadJson(&request); err != nil {
+		httpctx.WriteError(ctx, errors.ErrBadRequest)
+		return
+	}
+
+	// Canonicalize request paths
+	if a.canonicalPaths {
+		request.Canonicalize()
+	}
+
+	// Validate request
+	if err := request.Validate(); err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Create data
+	data := dirsServicePort.GetDirTreeData(request)
+
+	// Get dir tree
+	tree, err := a.dirsService.GetDirTree(
+		ctx.Context(),
+		&data,
+	)
+	if err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Write success response
+	ctx.SetStatusCode(200)
+	ctx.SetContentType("text/plain; charset=utf-8")
+	ctx.SetTraceIdHeader()
+	ctx.WriteString(renderDirTree(tree))
+}
+
+// @Summary Get dir digest (admin)
+// @Description Returns the SHA-256 digest of a dir subtree: records "D" + path + NUL + LF for dirs and "F" + path + NUL + hex(sha256(content)) + LF for files, sorted by relative path and hashed in order. Symlinks and hidden names are skipped.
+// @Tags dirs
+// @Security BearerAuth
+// @Accept json
+// @Produce json,plain
+// @Param request body dto.AdminDigestDirRequest true "Get dir digest (admin)"
+// @Success 200 {object} dto.DigestDirResponse
+// @Failure 400 {object} httpctx.ErrorResponse "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:dir_not_found, bad_request:dir_too_large"
+// @Failure 403 {object} httpctx.ErrorResponse "Possible error codes: forbidden:path_escape"
+// @Param X-Store-Namespace header string false "Store namespace (default root if omitted)"
+// @Router /admin/dirs/digest [post]
+func (a *adapter) AdminDigestDir(ctx server.ReqCtx) {
+	// Parse request json body
+	var request dto.AdminDigestDirRequest
+	if err := ctx.ReadJson(&request); err != nil {
+		httpctx.WriteError(ctx, errors.ErrBadRequest)
+		return
+	}
+
+	// Canonicalize request paths
+	if a.canonicalPaths {
+		request.Canonicalize()
+	}
+
+	// Validate request
+	if err := request.Validate(); err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Create data
+	data := dirsServicePort.DigestDirData(request)
+
+	// Get dir digest
+	result, err := a.dirsService.DigestDir(
+		ctx.Context(),
+		&data,
+	)
+	if err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Write success response
+	ctx.WriteResponse(200, dto.DigestDirResponse(*result))
+}
+
+// @Summary Flatten dir (admin)
+// @Description Moves every file below a path into one target dir (the path itself if target_path is empty). Name collisions are resolved by on_conflict: rename (default, adds a numbered suffix), skip or overwrite. With prefix_names, files are named after their relative path ("a/b.txt" becomes "a_b.txt"). Reports the outcome per file.
+// @Tags dirs
+// @Security BearerAuth
+// @Accept json
+// @Produce json,plain
+// @Param request body dto.AdminFlattenDirRequest true "Flatten dir (admin)"
+// @Success 200 {object} dto.FlattenDirResponse
+// @Failure 400 {object} httpctx.ErrorResponse "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:invalid_policy, bad_request:dir_not_found, bad_request:dir_too_large"
+// @Failure 403 {object} httpctx.ErrorResponse "Possible error codes: forbidden:path_escape"
+// @Param X-Store-Namespace header string false "Store namespace (default root if omitted)"
+// @Router /admin/dirs/flatten [post]
+func (a *adapter) AdminFlattenDir(ctx server.ReqCtx) {
+	// Parse request json body
+	var request dto.AdminFlattenDirRequest
+	if err := ctx.ReadJson(&request); err != nil {
+		httpctx.WriteError(ctx, errors.ErrBadRequest)
+		return
+	}
+
+	// Canonicalize request paths
+	if a.canonicalPaths {
+		request.Canonicalize()
+	}
+
+	// Validate request
+	if err := request.Validate(); err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Create data
+	data := dirsServicePort.FlattenDirData(request)
+
+	// Flatten dir
+	result, err := a.dirsService.FlattenDir(
+		ctx.Context(),
+		&data,
+	)
+	if err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Map result to response
+	files := make([]dto.FlattenedFileResponse, len(result.Files))
+	for i, file := range result.Files {
+		files[i] = dto.FlattenedFileResponse(file)
+	}
+
+	// Write success response
+	ctx.WriteResponse(200, dto.FlattenDirResponse{Files: files})
+}
+
+// @Summary Empty dir (admin)
+// @Description Removes every entry of a dir but keeps the dir itself. Applies the same depth and symlink guards as dir deletion. Without best_effort the first entry that cannot be removed fails the request; with best_effort removal goes on and failed entries are reported. Returns the number of removed files and subdirectories.
+// @Tags dirs
+// @Security BearerAuth
+// @Accept json
+// @Produce json,plain
+// @Param request body dto.AdminEmptyDirRequest true "Empty dir (admin)"
+// @Success 200 {object} dto.EmptyDirResponse
+// @Failure 400 {object} httpctx.ErrorResponse "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:dir_not_found"
+// @Failure 403 {object} httpctx.ErrorResponse "Possible error codes: forbidden:path_escape"
+// @Param X-Store-Namespace header string false "Store namespace (default root if omitted)"
+// @Router /admin/dirs/empty [post]
+func (a *adapter) AdminEmptyDir(ctx server.ReqCtx) {
+	// Parse request json body
+	var request dto.AdminEmptyDirRequest
+	if err := ctx.ReadJson(&request); err != nil {
+		httpctx.WriteError(ctx, errors.ErrBadRequest)
+		return
+	}
+
+	// Canonicalize request paths
+	if a.canonicalPaths {
+		request.Canonicalize()
+	}
+
+	// Validate request
+	if err := request.Validate(); err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Create data
+	data := dirsServicePort.EmptyDirData(request)
+
+	// Empty dir
+	result, err := a.dirsService.EmptyDir(
+		ctx.Context(),
+		&data,
+	)
+	if err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Map result to response
+	failed := make([]dto.EmptyDirFailureResponse, len(result.Failed))
+	for i, failure := range result.Failed {
+		failed[i] = dto.EmptyDirFailureResponse(failure)
+	}
+
+	// Write success response
+	ctx.WriteResponse(200, dto.EmptyDirResponse{
+		Files:  result.Files,
+		Dirs:   result.Dirs,
+		Failed: failed,
+	})
+}
+
+// @Summary Get dir size (admin)
+// @Description Returns the total size and number of the files below a dir (the store root if path is empty) and the number of its subdirectories. Sizes are cached per dir and dropped for a dir and its ancestors when something below it changes through the service, so only changed dirs are read again. Symlinks and hidden names are skipped. computed_at tells when the size of the dir was last computed.
+// @Tags dirs
+// @Security BearerAuth
+// @Accept json
+// @Produce json,plain
+// @Param request body dto.AdminDirSizeRequest true "Get dir size (admin)"
+// @Success 200 {object} dto.DirSizeResponse
+// @Failure 400 {object} httpctx.ErrorResponse "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:dir_not_found"
+// @Failure 403 {object} httpctx.ErrorResponse "Possible error codes: forbidden:path_escape"
+// @Param X-Store-Namespace header string false "Store namespace (default root if omitted)"
+// @Router /admin/dirs/size [post]
+func (a *adapter) AdminDirSize(ctx server.ReqCtx) {
+	// Parse request json body
+	var request dto.AdminDirSizeRequest
+	if err := ctx.ReadJson(&request); err != nil {
+		httpctx.WriteError(ctx, errors.ErrBadRequest)
+		return
+	}
+
+	// Canonicalize request paths
+	if a.canonicalPaths {
+		request.Canonicalize()
+	}
+
+	// Validate request
+	if err := request.Validate(); err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Create data
+	data := dirsServicePort.GetDirSizeData(request)
+
+	// Get dir size
+	result, err := a.dirsService.GetDirSize(
+		ctx.Context(),
+		&data,
+	)
+	if err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Write success response
+	ctx.WriteResponse(200, dto.DirSizeResponse(*result))
+}
+
+// @Summary Refresh dir sizes (admin)
+// @Description Walks a dir (the whole store if path is empty) without using cached sizes and caches the size of every dir below it, so later size queries are answered from memory. Picks up changes made outside the service. Returns the size of the walked dir.
+// @Tags dirs
+// @Security BearerAuth
+// @Accept json
+// @Produce json,plain
+// @Param request body dto.AdminRefreshDirSizesRequest true "Refresh dir sizes (admin)"
+// @Success 200 {object} dto.DirSizeResponse
+// @Failure 400 {object} httpctx.ErrorResponse "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:dir_not_found"
+// @Failure 403 {object} httpctx.ErrorResponse "Possible error codes: forbidden:path_escape"
+// @Param X-Store-Namespace header string false "Store namespace (default root if omitted)"
+// @Router /admin/dirs/size/refresh [post]
+func (a *adapter) AdminRefreshDirSizes(ctx server.ReqCtx) {
+	// Parse request json body
+	var request dto.AdminRefreshDirSizesRequest
+	if err := ctx.ReadJson(&request); err != nil {
+		httpctx.WriteError(ctx, errors.ErrBadRequest)
+		return
+	}
+
+	// Canonicalize request paths
+	if a.canonicalPaths {
+		request.Canonicalize()
+	}
+
+	// Validate request
+	if err := request.Validate(); err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Create data
+	data := dirsServicePort.RefreshDirSizesData(request)
+
+	// Refresh dir sizes
+	result, err := a.dirsService.RefreshDirSizes(
+		ctx.Context(),
+		&data,
+	)
+	if err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Write success response
+	ctx.WriteResponse(200, dto.DirSizeResponse(*result))
+}
+
+// Convert a service dir tree node and its children into a response
+func convertDirTree(node *dirsServicePort.DirTreeResult) dto.DirTreeResponse {
+	children := make([]dto.DirTreeResponse, len(node.Children))
+	for i := range node.Children {
+		children[i] = convertDirTree(&node.Children[i])
+	}
+	return dto.DirTreeResponse{
+		Name:     node.Name,
+		IsDir:    node.IsDir,
+		Children: children,
+	}
+}
+
+// @Summary Ensure dir layout (admin)
+// @Tags dirs
+// @Security BearerAuth
+// @Accept json
+// @Produce json,plain
+// @Param request body dto.AdminEnsureDirLayoutRequest true "Ensure dir layout (admin)"
+// @Success 200 {object} dto.EnsureDirLayoutResponse
+// @Failure 400 {object} httpctx.ErrorResponse "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:invalid_name, bad_request:invalid_tree, bad_request:layout_too_large, bad_request:dir_full"
+// @Failure 403 {object} httpctx.ErrorResponse "Possible error codes: forbidden:path_escape, forbidden:top_dir_not_allowed"
+// @Param X-Store-Namespace header string false "Store namespace (default root if omitted)"
+// @Router /admin/dirs/layout [post]
+func (a *adapter) AdminEnsureDirLayout(ctx server.ReqCtx) {
+	// Parse request json body
+	var request dto.AdminEnsureDirLayoutRequest
+	if err := ctx.ReadJson(&request); err != nil {
+		httpctx.WriteError(ctx, errors.ErrBadRequest)
+		return
+	}
+
+	// Canonicalize request paths
+	if a.canonicalPaths {
+		request.Canonicalize()
+	}
+
+	// Validate request
+	if err := request.Validate(); err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Create data
+	data := dirsServicePort.EnsureDirLayoutData{
+		Path: request.Path,
+		Tree: convertDirLayout(request.Tree),
+	}
+
+	// Ensure dir layout
+	result, err := a.dirsService.EnsureDirLayout(
+		ctx.Context(),
+		&data,
+	)
+	if err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Write success response
+	ctx.WriteResponse(200, dto.EnsureDirLayoutResponse(*result))
+}
+
+// Convert requested dir layout nodes into service data
+func convertDirLayout(nodes []dto.DirLayoutNodeRequest) []dirsServicePort.DirLayoutNode {
+	result := make([]dirsServicePort.DirLayoutNode, len(nodes))
+	for i, node := range nodes {
+		result[i] = dirsServicePort.DirLayoutNode{
+			Name:     node.Name,
+			Children: convertDirLayout(node.Children),
+		}
+	}
+	return result
+}
@@ -0,0 +1,55 @@
+package adapter
+
+import (
+	"fmt"
+	"strings"
+
+	dirsServicePort "github.com/flash-go/files-service/internal/port/service/dirs"
+)
+
+/*
+Render a dir tree like the Unix tree command. The root line holds the dir name
+("." for the store root), every entry below it is drawn with box characters
+and the output ends with the number of dirs and files below the root:
+
+	uploads
+	├── images
+	│   └── logo.png
+	└── notes.txt
+
+	1 directories, 2 files
+*/
+func renderDirTree(tree *dirsServicePort.DirTreeResult) string {
+	var b strings.Builder
+	name := tree.Name
+	if name == "" {
+		name = "."
+	}
+	b.WriteString(name + "\n")
+	dirs, files := renderDirTreeChildren(&b, tree.Children, "")
+	fmt.Fprintf(&b, "\n%d directories, %d files\n", dirs, files)
+	return b.String()
+}
+
+// Write the lines of a node's children under prefix, returning the number of
+// dirs and files written
+func renderDirTreeChildren(b *strings.Builder, children []dirsServicePort.DirTreeResult, prefix string) (int, int) {
+	dirs, files := 0, 0
+	for i := range children {
+		child := &children[i]
+		connector, indent := "├── ", "│   "
+		if i == len(children)-1 {
+			connector, indent = "└── ", "    "
+		}
+		b.WriteString(prefix + connector + child.Name + "\n")
+		if !child.IsDir {
+			files++
+			continue
+		}
+		dirs++
+		d, f := renderDirTreeChildren(b, child.Children, prefix+indent)
+		dirs += d
+		files += f
+	}
+	return dirs, files
+}
@@ -1,71 +1,179 @@
 package adapter
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
 
+	"github.com/flash-go/files-service/internal/clientpath"
 	dto "github.com/flash-go/files-service/internal/dto/files"
+	"github.com/flash-go/files-service/internal/httpctx"
 	httpFilesHandlerAdapterPort "github.com/flash-go/files-service/internal/port/adapter/handler/files/http"
 	filesServicePort "github.com/flash-go/files-service/internal/port/service/files"
 	"github.com/flash-go/flash/http/server"
 	"github.com/flash-go/sdk/errors"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	// Matches of a search queued between the walk and the response
+	findBufferSize = 64
+
+	// Code of the error line ending a search that failed mid-stream
+	findIncomplete = "search_incomplete"
 )
 
 type Config struct {
-	FilesService filesServicePort.Interface
+	FilesService    filesServicePort.Interface
+	CanonicalPaths  bool
+	CacheRules      []CacheRule
+	MaxDownloads    int
+	DownloadMetrics DownloadMetrics
+	AutoIndex       bool
+	AutoIndexLimit  int
+	UploadMaxSize   int64
+	UploadTempPath  string
 }
 
 func New(config *Config) httpFilesHandlerAdapterPort.Interface {
+	var downloadSlots chan struct{}
+	if config.MaxDownloads > 0 {
+		downloadSlots = make(chan struct{}, config.MaxDownloads)
+	}
+	autoIndexLimit := config.AutoIndexLimit
+	if autoIndexLimit <= 0 {
+		autoIndexLimit = defaultAutoIndexLimit
+	}
 	return &adapter{
 		config.FilesService,
+		config.CanonicalPaths,
+		config.CacheRules,
+		downloadSlots,
+		config.DownloadMetrics.Active,
+		config.DownloadMetrics.Rejected,
+		config.AutoIndex,
+		autoIndexLimit,
+		config.UploadMaxSize,
+		config.UploadTempPath,
 	}
 }
 
 type adapter struct {
-	filesService filesServicePort.Interface
+	filesService      filesServicePort.Interface
+	canonicalPaths    bool
+	cacheRules        []CacheRule
+	downloadSlots     chan struct{}
+	activeDownloads   metric.Int64UpDownCounter
+	rejectedDownloads metric.Int64Counter
+	autoIndex         bool
+	autoIndexLimit    int
+	uploadMaxSize     int64
+	uploadTempPath    string
 }
 
 // @Summary Create file (admin)
 // @Tags files
 // @Security BearerAuth
 // @Accept multipart/form-data
-// @Produce plain
-// @Param file formData file true "File to upload"
+// @Produce json
+// @Description If upload streaming is enabled, the file part is written to the store as it arrives when the meta part precedes it (otherwise it is buffered first). Uploads whose Content-Length or declared size shows the file exceeds the max file size are rejected with file_too_large before the file is read.
 // @Param meta formData string true "Metadata"
-// @Success 201
-// @Failure 400 {string} string "Possible error codes: bad_request, bad_request:dir_not_found, bad_request:file_exist"
+// @Param file formData file true "File to upload"
+// @Param Idempotency-Key header string false "Replay the result of a successful upload with the same key"
+// @Success 201 {object} dto.CreateFileResponse "Final file path (see MIME routing); backup_path is null unless a backup was made; with dedup link, the path of an existing file with the same content and duplicate set"
+// @Failure 400 {object} httpctx.ErrorResponse "Possible error codes: bad_request, bad_request:invalid_file, bad_request:invalid_path, bad_request:invalid_characters, bad_request:invalid_mode, bad_request:missing_extension, bad_request:dir_not_found, bad_request:file_exist, bad_request:file_not_found, bad_request:dir_full, bad_request:idempotency_key_reused, bad_request:backup_unavailable, bad_request:invalid_ttl, bad_request:expiry_unavailable, bad_request:invalid_dedup, bad_request:duplicate_content, bad_request:invalid_image, bad_request:unsupported_image"
+// @Failure 403 {object} httpctx.ErrorResponse "Possible error codes: forbidden:path_escape, forbidden:top_dir_not_allowed"
+// @Failure 413 {object} httpctx.ErrorResponse "Possible error codes: payload_too_large:file_too_large, payload_too_large:image_too_large"
+// @Failure 507 {object} httpctx.ErrorResponse "Possible error codes: insufficient_storage:storage_full"
+// @Failure 504 {object} httpctx.ErrorResponse "Possible error codes: gateway_timeout:upload_timeout"
+// @Param X-Store-Namespace header string false "Store namespace (default root if omitted)"
 // @Router /admin/files [post]
 func (a *adapter) AdminCreateFile(ctx server.ReqCtx) {
-	// Get request file
-	file, err := ctx.FormFile("file")
-	if err != nil {
-		ctx.WriteErrorResponse(err)
+	// Check request length
+	if err := a.checkUploadLength(ctx); err != nil {
+		httpctx.WriteError(ctx, err)
 		return
 	}
 
+	// Get request file and metadata, reading a streamed body part by part
+	var (
+		file   *multipart.FileHeader
+		stream *filesServicePort.FileStream
+		meta   []byte
+	)
+	if ctx.Request().IsBodyStream() {
+		upload, err := a.readStreamedUpload(ctx)
+		if err != nil {
+			httpctx.WriteError(ctx, err)
+			return
+		}
+		defer upload.Close()
+		stream, meta = upload.file, upload.meta
+	} else {
+		f, err := ctx.FormFile("file")
+		if err != nil {
+			httpctx.WriteError(ctx, err)
+			return
+		}
+		file, meta = f, ctx.FormValue("meta")
+	}
+
 	// Parse request json metadata
 	var request dto.AdminCreateFileRequest
 	if err := json.Unmarshal(
-		ctx.FormValue("meta"),
+		meta,
 		&request,
 	); err != nil {
-		ctx.WriteErrorResponse(errors.ErrBadRequest)
+		httpctx.WriteError(ctx, errors.ErrBadRequest)
+		return
+	}
+
+	// Canonicalize request paths
+	if a.canonicalPaths {
+		request.Canonicalize()
+	}
+
+	// Validate request
+	if err := request.Validate(); err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Check declared size, before a streamed file part is read
+	if a.uploadMaxSize > 0 && request.Size != nil && *request.Size > a.uploadMaxSize {
+		httpctx.WriteError(ctx, dto.ErrFileTooLarge)
 		return
 	}
 
 	// Create file
-	if err := a.filesService.CreateFile(
+	result, err := a.filesService.CreateFile(
 		ctx.Context(),
 		&filesServicePort.CreateFileData{
-			Path: request.Path,
-			File: file,
+			Path:           request.Path,
+			File:           file,
+			Stream:         stream,
+			DeclaredSize:   request.Size,
+			Mode:           request.Mode,
+			CreateDir:      request.CreateDir,
+			Backup:         request.Backup,
+			Ttl:            request.Ttl,
+			Dedup:          request.Dedup,
+			IdempotencyKey: ctx.GetHeader("Idempotency-Key"),
 		},
-	); err != nil {
-		ctx.WriteErrorResponse(err)
+	)
+	if err != nil {
+		httpctx.WriteError(ctx, err)
 		return
 	}
 
 	// Write success response
-	ctx.WriteResponse(201, nil)
+	ctx.WriteResponse(201, dto.CreateFileResponse(*result))
 }
 
 // @Summary List files (admin)
@@ -73,37 +181,320 @@ func (a *adapter) AdminCreateFile(ctx server.ReqCtx) {
 // @Security BearerAuth
 // @Accept json
 // @Produce json,plain
+// @Description Every listing of an existing dir returns its change token in the X-List-Token header, computed from the same read as the entries. If since holds a token from X-List-Token or /admin/files/list/token and the listed dir did not change, responds 304 with only that header and no body. If group_by_type is set, responds with an object holding separate dirs and files arrays instead of a flat array. If limit is set, responds with one page of at most limit entries as an object (entries, or dirs and files if grouped) with has_more and, if set, the next_cursor to pass as cursor for the following page. Entries are sorted by order: dirs_first, files_first or mixed (interleaved by name), the configured default if omitted; pages of one listing must use the same order. If error_on_missing is false, a path that does not exist (below an existing dir) is listed as empty instead of failing with dir_not_found; invalid and escaping paths fail regardless. If mime_category is set (application, audio, font, image, model, text or video), only files whose detected MIME type has that top-level type are listed, leaving out dirs; pages and cursors apply to the filtered listing. If include_self is set, the first page starts with an entry for the listed dir itself (the parent dir when path is a file), named "." and marked with is_self, holding its mod_time and, per with_dir_stats and with_dir_size, its child_count and children_size; it does not count toward limit and is listed with the dirs when grouped. A missing path listed as empty has no such entry.
 // @Param request body dto.AdminListFilesRequest true "List files (admin)"
 // @Success 200 {array} dto.FileResponse
-// @Failure 400 {string} string "Possible error codes: bad_request, bad_request:invalid_path"
+// @Success 304
+// @Header 200,304 {string} X-List-Token "Change token of the listed dir"
+// @Failure 400 {object} httpctx.ErrorResponse "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:invalid_limit, bad_request:invalid_cursor, bad_request:invalid_order, bad_request:invalid_mime_category, bad_request:dir_not_found, bad_request:too_many_entries"
+// @Failure 403 {object} httpctx.ErrorResponse "Possible error codes: forbidden:path_escape"
+// @Param X-Store-Namespace header string false "Store namespace (default root if omitted)"
 // @Router /admin/files/list [post]
 func (a *adapter) AdminListFiles(ctx server.ReqCtx) {
 	// Parse request json body
 	var request dto.AdminListFilesRequest
 	if err := ctx.ReadJson(&request); err != nil {
-		ctx.WriteErrorResponse(errors.ErrBadRequest)
+		httpctx.WriteError(ctx, errors.ErrBadRequest)
+		return
+	}
+
+	// Canonicalize request paths
+	if a.canonicalPaths {
+		request.Canonicalize()
+	}
+
+	// Validate request
+	if err := request.Validate(); err != nil {
+		httpctx.WriteError(ctx, err)
 		return
 	}
 
 	// Create data
-	data := filesServicePort.GetFilesData(request)
+	data := filesServicePort.GetFilesData{
+		Path:           request.Path,
+		WithPath:       request.WithPath,
+		SkipErrors:     request.SkipErrors,
+		WithEncoding:   request.WithEncoding,
+		WithContent:    request.WithContent,
+		WithDirStats:   request.WithDirStats,
+		WithDirSize:    request.WithDirSize,
+		Order:          request.Order,
+		EmptyIfMissing: request.ErrorOnMissing != nil && !*request.ErrorOnMissing,
+		MimeCategory:   request.MimeCategory,
+		IncludeSelf:    request.IncludeSelf,
+		Since:          request.Since,
+	}
+
+	// Page the listing, asking for one extra entry to learn whether more follow
+	if request.Limit > 0 {
+		data.Limit = request.Limit + 1
+		data.AfterDir, data.AfterName, _ = dto.DecodeListCursor(request.Cursor)
+	}
 
 	// Get files
-	files, err := a.filesService.GetFiles(
+	result, err := a.filesService.GetFiles(
 		ctx.Context(),
 		&data,
 	)
 	if err != nil {
-		ctx.WriteErrorResponse(err)
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Return the change token of the listed dir, or only the token if
+	// nothing changed since the given one
+	if result.Token != "" {
+		ctx.Response().Header.Set("X-List-Token", result.Token)
+	}
+	if result.Unchanged {
+		ctx.SetStatusCode(304)
 		return
 	}
 
 	// Build response
-	response := make([]dto.FileResponse, len(*files))
-	for i, file := range *files {
+	response := make([]dto.FileResponse, len(result.Files))
+	for i, file := range result.Files {
 		response[i] = dto.FileResponse(file)
 	}
 
+	// Set the entry of the listed dir itself aside from paging
+	var self []dto.FileResponse
+	if len(response) > 0 && response[0].IsSelf {
+		self, response = []dto.FileResponse{response[0]}, response[1:]
+	}
+
+	// Cut the extra entry and point the cursor at the last one kept
+	hasMore := false
+	var nextCursor *string
+	if request.Limit > 0 && len(response) > request.Limit {
+		response = response[:request.Limit]
+		hasMore = true
+		last := response[len(response)-1]
+		c := dto.EncodeListCursor(last.IsDir, last.Name)
+		nextCursor = &c
+	}
+	response = append(self, response...)
+
+	// Write success response
+	switch {
+	case request.GroupByType && request.Limit > 0:
+		dirs, files := groupFiles(response)
+		ctx.WriteResponse(200, dto.GroupedFilesPageResponse{
+			Dirs:       dirs,
+			Files:      files,
+			HasMore:    hasMore,
+			NextCursor: nextCursor,
+		})
+	case request.GroupByType:
+		dirs, files := groupFiles(response)
+		ctx.WriteResponse(200, dto.GroupedFilesResponse{
+			Dirs:  dirs,
+			Files: files,
+		})
+	case request.Limit > 0:
+		ctx.WriteResponse(200, dto.FilesPageResponse{
+			Entries:    response,
+			HasMore:    hasMore,
+			NextCursor: nextCursor,
+		})
+	default:
+		ctx.WriteResponse(200, response)
+	}
+}
+
+// @Summary Get list token (admin)
+// @Description Returns a change token for the dir /admin/files/list would list, the same one listings return in X-List-Token. It changes when a direct entry is added, removed, renamed or modified; pass it as since to skip unchanged listings.
+// @Tags files
+// @Security BearerAuth
+// @Accept json
+// @Produce json,plain
+// @Param request body dto.AdminListTokenRequest true "Get list token (admin)"
+// @Success 200 {object} dto.ListTokenResponse
+// @Failure 400 {object} httpctx.ErrorResponse "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:dir_not_found, bad_request:too_many_entries"
+// @Failure 403 {object} httpctx.ErrorResponse "Possible error codes: forbidden:path_escape"
+// @Param X-Store-Namespace header string false "Store namespace (default root if omitted)"
+// @Router /admin/files/list/token [post]
+func (a *adapter) AdminListToken(ctx server.ReqCtx) {
+	// Parse request json body
+	var request dto.AdminListTokenRequest
+	if err := ctx.ReadJson(&request); err != nil {
+		httpctx.WriteError(ctx, errors.ErrBadRequest)
+		return
+	}
+
+	// Canonicalize request paths
+	if a.canonicalPaths {
+		request.Canonicalize()
+	}
+
+	// Validate request
+	if err := request.Validate(); err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Create data
+	data := filesServicePort.ListTokenData(request)
+
+	// Get list token
+	result, err := a.filesService.ListToken(
+		ctx.Context(),
+		&data,
+	)
+	if err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Write success response
+	ctx.WriteResponse(200, dto.ListTokenResponse(*result))
+}
+
+// @Summary Find files (admin)
+// @Description Recursively searches a subtree for names matching a glob or substring, streaming one JSON object per line as matches are found. Unreadable subdirectories are skipped. If the search fails after the first match was sent (e.g. on timeout), the stream ends with an error object whose code is search_incomplete.
+// @Tags files
+// @Security BearerAuth
+// @Accept json
+// @Produce application/x-ndjson,plain
+// @Param request body dto.AdminFindRequest true "Find files (admin)"
+// @Success 200 {object} dto.FindResponse
+// @Failure 400 {object} httpctx.ErrorResponse "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:invalid_pattern, bad_request:invalid_depth, bad_request:dir_not_found"
+// @Failure 403 {object} httpctx.ErrorResponse "Possible error codes: forbidden:path_escape"
+// @Param X-Store-Namespace header string false "Store namespace (default root if omitted)"
+// @Router /admin/files/find [post]
+func (a *adapter) AdminFind(ctx server.ReqCtx) {
+	// Parse request json body
+	var request dto.AdminFindRequest
+	if err := ctx.ReadJson(&request); err != nil {
+		httpctx.WriteError(ctx, errors.ErrBadRequest)
+		return
+	}
+
+	// Canonicalize request paths
+	if a.canonicalPaths {
+		request.Canonicalize()
+	}
+
+	// Validate request
+	if err := request.Validate(); err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Find files in the background, handing matches over as they are found;
+	// the search stops once the response no longer takes them
+	searchCtx, cancel := context.WithCancel(ctx.Context())
+	matches := make(chan dto.FindResponse, findBufferSize)
+	done := make(chan error, 1)
+	data := filesServicePort.FindFilesData{
+		Path:    request.Path,
+		Pattern: request.Pattern,
+		Depth:   request.Depth,
+		Found: func(result filesServicePort.FindResult) error {
+			select {
+			case matches <- dto.FindResponse(result):
+				return nil
+			case <-searchCtx.Done():
+				return context.Cause(searchCtx)
+			}
+		},
+	}
+	go func() {
+		done <- a.filesService.FindFiles(searchCtx, &data)
+		close(matches)
+	}()
+
+	// Wait for the first match, so a search failing up front (missing or
+	// escaping root) still gets its error status
+	first, ok := <-matches
+	if !ok {
+		cancel()
+		if err := <-done; err != nil {
+			httpctx.WriteError(ctx, err)
+			return
+		}
+		ctx.SetStatusCode(200)
+		ctx.SetContentType("application/x-ndjson")
+		return
+	}
+
+	// Write success response as NDJSON, streaming the remaining matches
+	ctx.SetStatusCode(200)
+	ctx.SetContentType("application/x-ndjson")
+	ctx.Response().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer cancel()
+		encoder := json.NewEncoder(w)
+		for match := first; ok; match, ok = <-matches {
+			if encoder.Encode(match) != nil {
+				return
+			}
+			if len(matches) == 0 && w.Flush() != nil {
+				return
+			}
+		}
+
+		// The status is already sent, so a search failing past the first
+		// match ends the stream with an error line instead
+		if err := <-done; err != nil {
+			encoder.Encode(httpctx.ErrorResponse{
+				Code:    findIncomplete,
+				Message: findIncomplete,
+			})
+			w.Flush()
+		}
+	})
+}
+
+// @Summary Recent files (admin)
+// @Description Returns the most recently modified files of a subtree (the whole store if path is empty), newest first. At most limit files are returned (50 if omitted, at most 1000), searching depth levels below the path (32 if omitted). Hidden entries are skipped and symlinks are not followed.
+// @Tags files
+// @Security BearerAuth
+// @Accept json
+// @Produce json,plain
+// @Param request body dto.AdminRecentFilesRequest true "Recent files (admin)"
+// @Success 200 {array} dto.RecentFileResponse
+// @Failure 400 {object} httpctx.ErrorResponse "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:invalid_limit, bad_request:invalid_depth, bad_request:dir_not_found"
+// @Failure 403 {object} httpctx.ErrorResponse "Possible error codes: forbidden:path_escape"
+// @Param X-Store-Namespace header string false "Store namespace (default root if omitted)"
+// @Router /admin/files/recent [post]
+func (a *adapter) AdminRecentFiles(ctx server.ReqCtx) {
+	// Parse request json body
+	var request dto.AdminRecentFilesRequest
+	if err := ctx.ReadJson(&request); err != nil {
+		httpctx.WriteError(ctx, errors.ErrBadRequest)
+		return
+	}
+
+	// Canonicalize request paths
+	if a.canonicalPaths {
+		request.Canonicalize()
+	}
+
+	// Validate request
+	if err := request.Validate(); err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Create data
+	data := filesServicePort.RecentFilesData(request)
+
+	// Get recent files
+	results, err := a.filesService.RecentFiles(
+		ctx.Context(),
+		&data,
+	)
+	if err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Map results to response
+	response := make([]dto.RecentFileResponse, len(*results))
+	for i, result := range *results {
+		response[i] = dto.RecentFileResponse(result)
+	}
+
 	// Write success response
 	ctx.WriteResponse(200, response)
 }
@@ -114,32 +505,44 @@ func (a *adapter) AdminListFiles(ctx server.ReqCtx) {
 // @Accept json
 // @Produce plain
 // @Param request body dto.AdminDeleteFileRequest true "Delete file (admin)"
+// @Param If-Unmodified-Since header string false "Refuse if the file was modified after this HTTP date"
 // @Success 200
-// @Failure 400 {string} string "Possible error codes: bad_request, bad_request:invalid_path, bad_request:file_not_found"
+// @Failure 400 {object} httpctx.ErrorResponse "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:file_not_found"
+// @Failure 403 {object} httpctx.ErrorResponse "Possible error codes: forbidden:path_escape"
+// @Failure 412 {object} httpctx.ErrorResponse "Possible error codes: precondition_failed:file_modified"
+// @Param X-Store-Namespace header string false "Store namespace (default root if omitted)"
 // @Router /admin/files [delete]
 func (a *adapter) AdminDeleteFile(ctx server.ReqCtx) {
 	// Parse request json body
 	var request dto.AdminDeleteFileRequest
 	if err := ctx.ReadJson(&request); err != nil {
-		ctx.WriteErrorResponse(errors.ErrBadRequest)
+		httpctx.WriteError(ctx, errors.ErrBadRequest)
 		return
 	}
 
+	// Canonicalize request paths
+	if a.canonicalPaths {
+		request.Canonicalize()
+	}
+
 	// Validate request
 	if err := request.Validate(); err != nil {
-		ctx.WriteErrorResponse(err)
+		httpctx.WriteError(ctx, err)
 		return
 	}
 
 	// Create data
-	data := filesServicePort.DeleteFileData(request)
+	data := filesServicePort.DeleteFileData{
+		Path:            request.Path,
+		UnmodifiedSince: httpctx.UnmodifiedSince(ctx),
+	}
 
 	// Delete file
 	if err := a.filesService.DeleteFile(
 		ctx.Context(),
 		&data,
 	); err != nil {
-		ctx.WriteErrorResponse(err)
+		httpctx.WriteError(ctx, err)
 		return
 	}
 
@@ -153,35 +556,1262 @@ func (a *adapter) AdminDeleteFile(ctx server.ReqCtx) {
 // @Accept json
 // @Produce plain
 // @Param request body dto.AdminRenameFileRequest true "Rename file (admin)"
+// @Param If-Unmodified-Since header string false "Refuse if the file was modified after this HTTP date"
 // @Success 200
-// @Failure 400 {string} string "Possible error codes: bad_request, bad_request:invalid_old_path, bad_request:invalid_new_path, bad_request:old_file_not_found, bad_request:new_file_exist"
+// @Failure 400 {object} httpctx.ErrorResponse "Possible error codes: bad_request, bad_request:invalid_old_path, bad_request:invalid_new_path, bad_request:old_file_not_found, bad_request:new_file_exist, bad_request:cross_namespace"
+// @Failure 403 {object} httpctx.ErrorResponse "Possible error codes: forbidden:path_escape"
+// @Failure 412 {object} httpctx.ErrorResponse "Possible error codes: precondition_failed:file_modified"
+// @Param X-Store-Namespace header string false "Store namespace (default root if omitted)"
 // @Router /admin/files [patch]
 func (a *adapter) AdminRenameFile(ctx server.ReqCtx) {
 	// Parse request json body
 	var request dto.AdminRenameFileRequest
 	if err := ctx.ReadJson(&request); err != nil {
-		ctx.WriteErrorResponse(errors.ErrBadRequest)
+		httpctx.WriteError(ctx, errors.ErrBadRequest)
 		return
 	}
 
+	// Canonicalize request paths
+	if a.canonicalPaths {
+		request.Canonicalize()
+	}
+
 	// Validate request
 	if err := request.Validate(); err != nil {
-		ctx.WriteErrorResponse(err)
+		httpctx.WriteError(ctx, err)
 		return
 	}
 
 	// Create data
-	data := filesServicePort.RenameFileData(request)
+	data := filesServicePort.RenameFileData{
+		OldPath:         request.OldPath,
+		NewPath:         request.NewPath,
+		UnmodifiedSince: httpctx.UnmodifiedSince(ctx),
+	}
 
 	// Rename file
 	if err := a.filesService.RenameFile(
 		ctx.Context(),
 		&data,
 	); err != nil {
-		ctx.WriteErrorResponse(err)
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Write success response
+	ctx.WriteResponse(200, nil)
+}
+
+// @Summary Move file (admin)
+// @Description Moves a file to another path, possibly in another dir, which must exist (dir_not_found otherwise). An existing destination file is rejected with file_exist unless overwrite is set. Moving a file onto its own path changes nothing and responds with moved false. Across filesystems the file is copied and the source removed, reported with copied true.
+// @Tags files
+// @Security BearerAuth
+// @Accept json
+// @Produce json,plain
+// @Param request body dto.AdminMoveFileRequest true "Move file (admin)"
+// @Param If-Unmodified-Since header string false "Refuse if the file was modified after this HTTP date"
+// @Success 200 {object} dto.MoveFileResponse
+// @Failure 400 {object} httpctx.ErrorResponse "Possible error codes: bad_request, bad_request:invalid_source_path, bad_request:invalid_dest_path, bad_request:invalid_characters, bad_request:invalid_path, bad_request:file_not_found, bad_request:dir_not_found, bad_request:file_exist, bad_request:is_directory, bad_request:dir_full, bad_request:cross_namespace"
+// @Failure 403 {object} httpctx.ErrorResponse "Possible error codes: forbidden:path_escape"
+// @Failure 412 {object} httpctx.ErrorResponse "Possible error codes: precondition_failed:file_modified"
+// @Failure 507 {object} httpctx.ErrorResponse "Possible error codes: insufficient_storage:storage_full"
+// @Param X-Store-Namespace header string false "Store namespace (default root if omitted)"
+// @Router /admin/files/move [post]
+func (a *adapter) AdminMoveFile(ctx server.ReqCtx) {
+	// Parse request json body
+	var request dto.AdminMoveFileRequest
+	if err := ctx.ReadJson(&request); err != nil {
+		httpctx.WriteError(ctx, errors.ErrBadRequest)
+		return
+	}
+
+	// Canonicalize request paths
+	if a.canonicalPaths {
+		request.Canonicalize()
+	}
+
+	// Validate request
+	if err := request.Validate(); err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Create data
+	data := filesServicePort.MoveFileData{
+		SourcePath:      request.SourcePath,
+		DestPath:        request.DestPath,
+		Overwrite:       request.Overwrite,
+		UnmodifiedSince: httpctx.UnmodifiedSince(ctx),
+	}
+
+	// Move file
+	result, err := a.filesService.MoveFile(
+		ctx.Context(),
+		&data,
+	)
+	if err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Write success response
+	ctx.WriteResponse(200, dto.MoveFileResponse(*result))
+}
+
+// @Summary Copy file (admin)
+// @Description Copies a file to another path within the store, possibly in another dir, which must exist (dir_not_found otherwise). The copy keeps the modification time of the source. An existing destination is rejected with file_exist.
+// @Tags files
+// @Security BearerAuth
+// @Accept json
+// @Produce json,plain
+// @Param request body dto.AdminCopyFileRequest true "Copy file (admin)"
+// @Success 200 {object} dto.CopyFileResponse
+// @Failure 400 {object} httpctx.ErrorResponse "Possible error codes: bad_request, bad_request:invalid_source_path, bad_request:invalid_dest_path, bad_request:invalid_characters, bad_request:invalid_path, bad_request:file_not_found, bad_request:dir_not_found, bad_request:file_exist, bad_request:is_directory, bad_request:dir_full, bad_request:cross_namespace"
+// @Failure 403 {object} httpctx.ErrorResponse "Possible error codes: forbidden:path_escape"
+// @Failure 507 {object} httpctx.ErrorResponse "Possible error codes: insufficient_storage:storage_full"
+// @Param X-Store-Namespace header string false "Store namespace (default root if omitted)"
+// @Router /admin/files/copy [post]
+func (a *adapter) AdminCopyFile(ctx server.ReqCtx) {
+	// Parse request json body
+	var request dto.AdminCopyFileRequest
+	if err := ctx.ReadJson(&request); err != nil {
+		httpctx.WriteError(ctx, errors.ErrBadRequest)
+		return
+	}
+
+	// Canonicalize request paths
+	if a.canonicalPaths {
+		request.Canonicalize()
+	}
+
+	// Validate request
+	if err := request.Validate(); err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Create data
+	data := filesServicePort.CopyFileData{
+		SourcePath: request.SourcePath,
+		DestPath:   request.DestPath,
+	}
+
+	// Copy file
+	result, err := a.filesService.CopyFile(
+		ctx.Context(),
+		&data,
+	)
+	if err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Write success response
+	ctx.WriteResponse(200, dto.CopyFileResponse(*result))
+}
+
+// @Summary Write file at offset (admin)
+// @Tags files
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce plain
+// @Description Writes the file bytes at offset within an existing file. The end of the write may not exceed the max file size; if none is configured, offset may not exceed the current file size.
+// @Param file formData file true "Bytes to write"
+// @Param meta formData string true "Metadata"
+// @Success 200
+// @Failure 400 {object} httpctx.ErrorResponse "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:invalid_offset, bad_request:invalid_range, bad_request:file_not_found"
+// @Failure 403 {object} httpctx.ErrorResponse "Possible error codes: forbidden:path_escape"
+// @Failure 413 {object} httpctx.ErrorResponse "Possible error codes: payload_too_large:file_too_large"
+// @Failure 507 {object} httpctx.ErrorResponse "Possible error codes: insufficient_storage:storage_full"
+// @Param X-Store-Namespace header string false "Store namespace (default root if omitted)"
+// @Router /admin/files/write [post]
+func (a *adapter) AdminWriteAt(ctx server.ReqCtx) {
+	// Get request file
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Parse request json metadata
+	var request dto.AdminWriteAtRequest
+	if err := json.Unmarshal(
+		ctx.FormValue("meta"),
+		&request,
+	); err != nil {
+		httpctx.WriteError(ctx, errors.ErrBadRequest)
+		return
+	}
+
+	// Canonicalize request paths
+	if a.canonicalPaths {
+		request.Canonicalize()
+	}
+
+	// Validate request
+	if err := request.Validate(); err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Write file
+	if err := a.filesService.WriteFileAt(
+		ctx.Context(),
+		&filesServicePort.WriteFileAtData{
+			Path:   request.Path,
+			Offset: request.Offset,
+			File:   file,
+		},
+	); err != nil {
+		httpctx.WriteError(ctx, err)
 		return
 	}
 
 	// Write success response
 	ctx.WriteResponse(200, nil)
 }
+
+// @Summary Allocate file (admin)
+// @Description Creates a file of the given size without writing data, reserving its blocks up front unless sparse is set.
+// @Tags files
+// @Security BearerAuth
+// @Accept json
+// @Produce plain
+// @Param request body dto.AdminAllocateFileRequest true "Allocate file (admin)"
+// @Success 201
+// @Failure 400 {object} httpctx.ErrorResponse "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:invalid_size, bad_request:dir_not_found, bad_request:file_exist, bad_request:dir_full"
+// @Failure 403 {object} httpctx.ErrorResponse "Possible error codes: forbidden:path_escape"
+// @Failure 413 {object} httpctx.ErrorResponse "Possible error codes: payload_too_large:file_too_large"
+// @Failure 507 {object} httpctx.ErrorResponse "Possible error codes: insufficient_storage:storage_full"
+// @Param X-Store-Namespace header string false "Store namespace (default root if omitted)"
+// @Router /admin/files/allocate [post]
+func (a *adapter) AdminAllocateFile(ctx server.ReqCtx) {
+	// Parse request json body
+	var request dto.AdminAllocateFileRequest
+	if err := ctx.ReadJson(&request); err != nil {
+		httpctx.WriteError(ctx, errors.ErrBadRequest)
+		return
+	}
+
+	// Canonicalize request paths
+	if a.canonicalPaths {
+		request.Canonicalize()
+	}
+
+	// Validate request
+	if err := request.Validate(); err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Create data
+	data := filesServicePort.AllocateFileData(request)
+
+	// Allocate file
+	if err := a.filesService.AllocateFile(
+		ctx.Context(),
+		&data,
+	); err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Write success response
+	ctx.WriteResponse(201, nil)
+}
+
+// @Summary Get file hash (admin)
+// @Description Returns the SHA-256 of a file (and its MD5 and CRC-32 if with_md5 and with_crc32 are set) without transferring the content. Hashes of unchanged files are served from a cache.
+// @Tags files
+// @Security BearerAuth
+// @Accept json
+// @Produce json,plain
+// @Param request body dto.AdminFileHashRequest true "Get file hash (admin)"
+// @Success 200 {object} dto.FileHashResponse
+// @Failure 400 {object} httpctx.ErrorResponse "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:file_not_found, bad_request:is_directory"
+// @Failure 403 {object} httpctx.ErrorResponse "Possible error codes: forbidden:path_escape"
+// @Param X-Store-Namespace header string false "Store namespace (default root if omitted)"
+// @Router /admin/files/hash [post]
+func (a *adapter) AdminFileHash(ctx server.ReqCtx) {
+	// Parse request json body
+	var request dto.AdminFileHashRequest
+	if err := ctx.ReadJson(&request); err != nil {
+		httpctx.WriteError(ctx, errors.ErrBadRequest)
+		return
+	}
+
+	// Canonicalize request paths
+	if a.canonicalPaths {
+		request.Canonicalize()
+	}
+
+	// Validate request
+	if err := request.Validate(); err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Create data
+	data := filesServicePort.HashFileData(request)
+
+	// Hash file
+	result, err := a.filesService.HashFile(
+		ctx.Context(),
+		&data,
+	)
+	if err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Write success response
+	ctx.WriteResponse(200, dto.FileHashResponse(*result))
+}
+
+// @Summary Write file range (admin)
+// @Tags files
+// @Security BearerAuth
+// @Accept octet-stream
+// @Produce json,plain
+// @Param path query string true "File path"
+// @Param mode query string false "Create mode of the upload, read from the range starting at 0: create (default, fails if the file exists), replace (fails if absent) or upsert"
+// @Param Content-Range header string true "Byte range of the body: bytes start-end/total"
+// @Param Upload-Session-Token header string false "session_token of the previous range, to continue the upload on any instance"
+// @Param request body string true "Range bytes"
+// @Success 200 {object} dto.FileRangeResponse "session_token is null once complete or if sessions are not configured"
+// @Failure 400 {object} httpctx.ErrorResponse "Possible error codes: bad_request:invalid_path, bad_request:invalid_characters, bad_request:invalid_mode, bad_request:invalid_range, bad_request:dir_not_found, bad_request:file_exist, bad_request:file_not_found, bad_request:dir_full, bad_request:range_out_of_order, bad_request:range_overlap, bad_request:range_total_mismatch, bad_request:invalid_session_token, bad_request:session_token_expired"
+// @Failure 403 {object} httpctx.ErrorResponse "Possible error codes: forbidden:path_escape"
+// @Failure 413 {object} httpctx.ErrorResponse "Possible error codes: payload_too_large:file_too_large"
+// @Failure 507 {object} httpctx.ErrorResponse "Possible error codes: insufficient_storage:storage_full"
+// @Param X-Store-Namespace header string false "Store namespace (default root if omitted)"
+// @Router /admin/files/range [put]
+func (a *adapter) AdminWriteRange(ctx server.ReqCtx) {
+	// Get request path
+	path := string(ctx.Request().URI().QueryArgs().Peek("path"))
+	if a.canonicalPaths {
+		path = clientpath.Canonical(path)
+	}
+	if path == "" {
+		httpctx.WriteError(ctx, dto.ErrDirInvalidPath)
+		return
+	}
+	if clientpath.HasControlChars(path) {
+		httpctx.WriteError(ctx, dto.ErrFileInvalidCharacters)
+		return
+	}
+
+	// Get create mode
+	mode := string(ctx.Request().URI().QueryArgs().Peek("mode"))
+	switch mode {
+	case "", "create", "replace", "upsert":
+	default:
+		httpctx.WriteError(ctx, dto.ErrFileInvalidMode)
+		return
+	}
+
+	// Parse Content-Range header
+	start, end, total, ok := parseContentRange(ctx.GetHeader("Content-Range"))
+	if !ok {
+		httpctx.WriteError(ctx, dto.ErrFileInvalidRange)
+		return
+	}
+
+	// Validate body against the range
+	body := ctx.Body()
+	if int64(len(body)) != end-start+1 {
+		httpctx.WriteError(ctx, dto.ErrFileInvalidRange)
+		return
+	}
+
+	// Create data
+	// (the body is copied, as the request buffer is reused once this handler returns)
+	data := filesServicePort.WriteFileRangeData{
+		Path:         path,
+		Mode:         mode,
+		Start:        start,
+		Total:        total,
+		Content:      bytes.Clone(body),
+		SessionToken: ctx.GetHeader("Upload-Session-Token"),
+	}
+
+	// Write file range
+	result, err := a.filesService.WriteFileRange(
+		ctx.Context(),
+		&data,
+	)
+	if err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Write success response
+	ctx.WriteResponse(200, dto.FileRangeResponse(*result))
+}
+
+// @Summary Move file or dir (admin)
+// @Tags files
+// @Security BearerAuth
+// @Accept json
+// @Produce plain
+// @Param request body dto.AdminMoveRequest true "Move file or dir (admin)"
+// @Param If-Unmodified-Since header string false "Refuse if the source was modified after this HTTP date"
+// @Success 200
+// @Failure 400 {object} httpctx.ErrorResponse "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:invalid_old_path, bad_request:invalid_new_path, bad_request:old_file_not_found, bad_request:new_file_exist, bad_request:new_dir_exist, bad_request:is_directory, bad_request:not_directory, bad_request:cross_namespace"
+// @Failure 403 {object} httpctx.ErrorResponse "Possible error codes: forbidden:path_escape"
+// @Failure 412 {object} httpctx.ErrorResponse "Possible error codes: precondition_failed:file_modified, precondition_failed:dir_modified"
+// @Param X-Store-Namespace header string false "Store namespace (default root if omitted)"
+// @Router /admin/move [post]
+func (a *adapter) AdminMove(ctx server.ReqCtx) {
+	// Parse request json body
+	var request dto.AdminMoveRequest
+	if err := ctx.ReadJson(&request); err != nil {
+		httpctx.WriteError(ctx, errors.ErrBadRequest)
+		return
+	}
+
+	// Canonicalize request paths
+	if a.canonicalPaths {
+		request.Canonicalize()
+	}
+
+	// Validate request
+	if err := request.Validate(); err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Create data
+	data := filesServicePort.MoveData{
+		OldPath:         request.OldPath,
+		NewPath:         request.NewPath,
+		UnmodifiedSince: httpctx.UnmodifiedSince(ctx),
+	}
+
+	// Move file or dir
+	if err := a.filesService.Move(
+		ctx.Context(),
+		&data,
+	); err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Write success response
+	ctx.WriteResponse(200, nil)
+}
+
+// @Summary Preview text file (admin)
+// @Tags files
+// @Security BearerAuth
+// @Accept json
+// @Produce json,plain
+// @Param request body dto.AdminPreviewFileRequest true "Preview text file (admin)"
+// @Success 200 {object} dto.FilePreviewResponse
+// @Failure 400 {object} httpctx.ErrorResponse "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:invalid_limit, bad_request:file_not_found, bad_request:file_not_text"
+// @Failure 403 {object} httpctx.ErrorResponse "Possible error codes: forbidden:path_escape"
+// @Param X-Store-Namespace header string false "Store namespace (default root if omitted)"
+// @Router /admin/files/preview [post]
+func (a *adapter) AdminPreviewFile(ctx server.ReqCtx) {
+	// Parse request json body
+	var request dto.AdminPreviewFileRequest
+	if err := ctx.ReadJson(&request); err != nil {
+		httpctx.WriteError(ctx, errors.ErrBadRequest)
+		return
+	}
+
+	// Canonicalize request paths
+	if a.canonicalPaths {
+		request.Canonicalize()
+	}
+
+	// Validate request
+	if err := request.Validate(); err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Create data
+	data := filesServicePort.PreviewFileData(request)
+
+	// Preview file
+	preview, err := a.filesService.PreviewFile(
+		ctx.Context(),
+		&data,
+	)
+	if err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Write success response
+	ctx.WriteResponse(200, dto.FilePreviewResponse(*preview))
+}
+
+// @Summary Download file (admin)
+// @Description Streams a file. A dir is served by its configured index file if present, else by its listing if dir listings are enabled (404 otherwise). Text files are compressed with br or gzip as negotiated by Accept-Encoding, unless a Range is requested. If dir indexes are enabled, a dir without an index file is instead served to clients accepting text/html as an HTML page linking to its entries, paged by the configured index page size with cursor for the following pages. Files get Cache-Control (and Expires for a max-age) per the configured download cache policy, and ETag and Last-Modified validators. A single byte Range is served as 206 (multiple ranges are ignored); with If-Range, only while the validator still matches, else the whole current file is sent. With format, an image is decoded and re-encoded in that format (at quality for jpeg) and sent whole and uncompressed; conversions are cached until the file changes.
+// @Tags files
+// @Security BearerAuth
+// @Produce octet-stream,json,plain
+// @Param path query string false "File or dir path"
+// @Param format query string false "Image format to convert a JPEG, PNG or GIF image to (jpeg, png, gif)"
+// @Param quality query int false "JPEG quality (1-100, jpeg format only)"
+// @Param cursor query string false "Page cursor of an HTML dir index"
+// @Param Accept header string false "Accepted media types (text/html for an HTML dir index)"
+// @Param Accept-Encoding header string false "Accepted content encodings (br, gzip)"
+// @Param Range header string false "Byte range to download: bytes=start-end, bytes=start- or bytes=-suffix"
+// @Param If-Range header string false "ETag or Last-Modified date the Range applies to"
+// @Success 200 {array} dto.FileResponse "Listing of a dir without an index file"
+// @Success 206 {file} file "Requested byte range"
+// @Failure 400 {object} httpctx.ErrorResponse "Possible error codes: bad_request:invalid_path, bad_request:invalid_characters, bad_request:invalid_format, bad_request:invalid_quality, bad_request:invalid_cursor, bad_request:file_not_found, bad_request:not_image"
+// @Failure 403 {object} httpctx.ErrorResponse "Possible error codes: forbidden:path_escape"
+// @Failure 404 {object} httpctx.ErrorResponse "Possible error codes: not_found:index_not_found"
+// @Failure 413 {object} httpctx.ErrorResponse "Possible error codes: payload_too_large:image_too_large"
+// @Failure 416 {object} httpctx.ErrorResponse "Possible error codes: range_not_satisfiable:range_not_satisfiable"
+// @Failure 503 {object} httpctx.ErrorResponse "Possible error codes: too_many_downloads (retry after Retry-After seconds)"
+// @Param X-Store-Namespace header string false "Store namespace (default root if omitted)"
+// @Router /admin/files/download [get]
+func (a *adapter) AdminDownloadFile(ctx server.ReqCtx) {
+	// Get request path
+	path := string(ctx.Request().URI().QueryArgs().Peek("path"))
+	if a.canonicalPaths {
+		path = clientpath.Canonical(path)
+	}
+	if clientpath.HasControlChars(path) {
+		httpctx.WriteError(ctx, dto.ErrFileInvalidCharacters)
+		return
+	}
+
+	// Get image conversion
+	format := string(ctx.Request().URI().QueryArgs().Peek("format"))
+	switch format {
+	case "", "jpeg", "png", "gif":
+	default:
+		httpctx.WriteError(ctx, dto.ErrFileInvalidFormat)
+		return
+	}
+	quality := 0
+	if arg := ctx.Request().URI().QueryArgs().Peek("quality"); arg != nil {
+		q, err := strconv.Atoi(string(arg))
+		if err != nil || q < 1 || q > 100 || format != "jpeg" {
+			httpctx.WriteError(ctx, dto.ErrFileInvalidQuality)
+			return
+		}
+		quality = q
+	}
+
+	// Page a dir as an HTML index for clients accepting HTML, asking for one
+	// extra entry to learn whether more follow
+	data := filesServicePort.DownloadFileData{
+		Path:    path,
+		Format:  format,
+		Quality: quality,
+	}
+	cursor := string(ctx.Request().URI().QueryArgs().Peek("cursor"))
+	if a.autoIndex && acceptsHtml(ctx.GetHeader("Accept")) {
+		data.AutoIndex = true
+		data.Limit = a.autoIndexLimit + 1
+		if cursor != "" {
+			var ok bool
+			if data.AfterDir, data.AfterName, ok = dto.DecodeListCursor(cursor); !ok {
+				httpctx.WriteError(ctx, dto.ErrFileInvalidCursor)
+				return
+			}
+		}
+	}
+
+	// Open file
+	result, err := a.filesService.DownloadFile(
+		ctx.Context(),
+		&data,
+	)
+	if err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Write dir index
+	if result.Listing != nil && data.AutoIndex {
+		a.writeAutoIndex(ctx, path, cursor, *result.Listing)
+		return
+	}
+
+	// Write dir listing
+	if result.Listing != nil {
+		response := make([]dto.FileResponse, len(*result.Listing))
+		for i, file := range *result.Listing {
+			response[i] = dto.FileResponse(file)
+		}
+		ctx.WriteResponse(200, response)
+		return
+	}
+
+	// Reserve a download slot
+	release, ok := a.acquireDownload(ctx)
+	if !ok {
+		if result.File != nil {
+			result.File.Close()
+		}
+		return
+	}
+
+	// Write converted image (never as a range or compressed)
+	if result.Content != nil {
+		response := ctx.Response()
+		response.Header.Set("ETag", fileETag(result.Size, result.ModTime))
+		response.Header.Set("Last-Modified", result.ModTime.UTC().Format(http.TimeFormat))
+		ctx.SetStatusCode(200)
+		ctx.SetContentType(result.MimeType)
+		ctx.SetTraceIdHeader()
+		a.setCacheHeaders(ctx, result.MimeType)
+		writeStream(ctx, io.NopCloser(bytes.NewReader(result.Content)), result.Size, release)
+		return
+	}
+
+	// Set validators, and drop a Range whose If-Range no longer matches so
+	// a changed file is sent whole rather than as a stale part
+	response := ctx.Response()
+	etag := fileETag(result.Size, result.ModTime)
+	response.Header.Set("ETag", etag)
+	response.Header.Set("Last-Modified", result.ModTime.UTC().Format(http.TimeFormat))
+	response.Header.Set("Accept-Ranges", "bytes")
+	rangeHeader := ""
+	if ifRangeMatches(ctx.GetHeader("If-Range"), etag, result.ModTime) {
+		rangeHeader = ctx.GetHeader("Range")
+	}
+
+	// Write the requested byte range
+	if rangeHeader != "" {
+		if start, end, satisfiable, ok := parseByteRange(rangeHeader, result.Size); ok {
+			if !satisfiable {
+				result.File.Close()
+				release()
+				httpctx.WriteError(ctx, dto.ErrFileRangeUnsatisfied)
+				response.Header.Set("Content-Range", "bytes */"+strconv.FormatInt(result.Size, 10))
+				return
+			}
+			if _, err := result.File.Seek(start, io.SeekStart); err != nil {
+				result.File.Close()
+				release()
+				httpctx.WriteError(ctx, err)
+				return
+			}
+			ctx.SetStatusCode(206)
+			ctx.SetContentType(result.MimeType)
+			ctx.SetTraceIdHeader()
+			a.setCacheHeaders(ctx, result.MimeType)
+			response.Header.Set("Content-Range", "bytes "+strconv.FormatInt(start, 10)+"-"+strconv.FormatInt(end, 10)+"/"+strconv.FormatInt(result.Size, 10))
+			part := readCloser{io.LimitReader(result.File, end-start+1), result.File}
+			writeStream(ctx, part, end-start+1, release)
+			return
+		}
+	}
+
+	// Write compressed response for text files if the client accepts it
+	// (never for ranges, whose offsets refer to the uncompressed bytes)
+	if result.Size >= minCompressSize &&
+		compressibleMimeType(result.MimeType) &&
+		rangeHeader == "" {
+		response.Header.Add("Vary", "Accept-Encoding")
+		if encoding := negotiateEncoding(ctx.GetHeader("Accept-Encoding")); encoding != "" {
+			ctx.SetStatusCode(200)
+			ctx.SetContentType(result.MimeType)
+			ctx.SetTraceIdHeader()
+			a.setCacheHeaders(ctx, result.MimeType)
+			response.Header.Set("Content-Encoding", encoding)
+			file := result.File
+			response.SetBodyStreamWriter(func(w *bufio.Writer) {
+				defer release()
+				defer file.Close()
+				compressTo(w, file, encoding)
+			})
+			return
+		}
+	}
+
+	// Write success response
+	ctx.SetStatusCode(200)
+	ctx.SetContentType(result.MimeType)
+	ctx.SetTraceIdHeader()
+	a.setCacheHeaders(ctx, result.MimeType)
+	writeStream(ctx, result.File, result.Size, release)
+}
+
+// @Summary Download concatenated files (admin)
+// @Description Streams the content of the given files, in order, as a single body (at most 100 files). Fails before streaming if any file is missing or the total size exceeds the configured limit.
+// @Tags files
+// @Security BearerAuth
+// @Accept json
+// @Produce octet-stream,json
+// @Param request body dto.AdminConcatFilesRequest true "Download concatenated files (admin)"
+// @Success 200 {file} file
+// @Failure 400 {object} httpctx.ErrorResponse "Possible error codes: bad_request, bad_request:invalid_paths, bad_request:invalid_path, bad_request:invalid_characters, bad_request:file_not_found, bad_request:is_directory"
+// @Failure 403 {object} httpctx.ErrorResponse "Possible error codes: forbidden:path_escape"
+// @Failure 413 {object} httpctx.ErrorResponse "Possible error codes: payload_too_large:concat_too_large"
+// @Failure 503 {object} httpctx.ErrorResponse "Possible error codes: too_many_downloads (retry after Retry-After seconds)"
+// @Param X-Store-Namespace header string false "Store namespace (default root if omitted)"
+// @Router /admin/files/concat [post]
+func (a *adapter) AdminConcatFiles(ctx server.ReqCtx) {
+	// Parse request json body
+	var request dto.AdminConcatFilesRequest
+	if err := ctx.ReadJson(&request); err != nil {
+		httpctx.WriteError(ctx, errors.ErrBadRequest)
+		return
+	}
+
+	// Canonicalize request paths
+	if a.canonicalPaths {
+		request.Canonicalize()
+	}
+
+	// Validate request
+	if err := request.Validate(); err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Open files
+	result, err := a.filesService.ConcatFiles(
+		ctx.Context(),
+		&filesServicePort.ConcatFilesData{
+			Paths: request.Paths,
+		},
+	)
+	if err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Reserve a download slot
+	release, ok := a.acquireDownload(ctx)
+	if !ok {
+		result.Reader.Close()
+		return
+	}
+
+	// Write success response
+	ctx.SetStatusCode(200)
+	ctx.SetContentType("application/octet-stream")
+	ctx.SetTraceIdHeader()
+	writeStream(ctx, result.Reader, result.Size, release)
+}
+
+// Split listed entries into dirs and files, keeping the listing order within
+// each group
+func groupFiles(entries []dto.FileResponse) ([]dto.FileResponse, []dto.FileResponse) {
+	dirs := []dto.FileResponse{}
+	files := []dto.FileResponse{}
+	for _, entry := range entries {
+		if entry.IsDir {
+			dirs = append(dirs, entry)
+		} else {
+			files = append(files, entry)
+		}
+	}
+	return dirs, files
+}
+
+// Parse a "bytes start-end/total" Content-Range header with a known total
+func parseContentRange(header string) (start, end, total int64, ok bool) {
+	spec, found := strings.CutPrefix(header, "bytes ")
+	if !found {
+		return 0, 0, 0, false
+	}
+	rng, size, found := strings.Cut(spec, "/")
+	if !found {
+		return 0, 0, 0, false
+	}
+	first, last, found := strings.Cut(rng, "-")
+	if !found {
+		return 0, 0, 0, false
+	}
+	var err error
+	if start, err = strconv.ParseInt(first, 10, 64); err != nil || start < 0 {
+		return 0, 0, 0, false
+	}
+	if end, err = strconv.ParseInt(last, 10, 64); err != nil || end < start {
+		return 0, 0, 0, false
+	}
+	if total, err = strconv.ParseInt(size, 10, 64); err != nil || end >= total {
+		return 0, 0, 0, false
+	}
+	return start, end, total, true
+}
+
+// @Summary Verify file checksums (admin)
+// @Description Compares the current SHA-256 of each given file (at most 1000) against the expected one and reports, in request order, match, mismatch or missing (also for a dir). Hashes of unchanged files are served from a cache.
+// @Tags files
+// @Security BearerAuth
+// @Accept json
+// @Produce json,plain
+// @Param request body dto.AdminVerifyFilesRequest true "Verify file checksums (admin)"
+// @Success 200 {object} dto.VerifyFilesResponse
+// @Failure 400 {object} httpctx.ErrorResponse "Possible error codes: bad_request, bad_request:invalid_paths, bad_request:invalid_path, bad_request:invalid_characters, bad_request:invalid_hash"
+// @Failure 403 {object} httpctx.ErrorResponse "Possible error codes: forbidden:path_escape"
+// @Param X-Store-Namespace header string false "Store namespace (default root if omitted)"
+// @Router /admin/files/verify [post]
+func (a *adapter) AdminVerifyFiles(ctx server.ReqCtx) {
+	// Parse request json body
+	var request dto.AdminVerifyFilesRequest
+	if err := ctx.ReadJson(&request); err != nil {
+		httpctx.WriteError(ctx, errors.ErrBadRequest)
+		return
+	}
+
+	// Canonicalize request paths
+	if a.canonicalPaths {
+		request.Canonicalize()
+	}
+
+	// Validate request
+	if err := request.Validate(); err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Create data
+	data := filesServicePort.VerifyFilesData{
+		Files: make([]filesServicePort.VerifyFileData, len(request.Files)),
+	}
+	for i, file := range request.Files {
+		data.Files[i] = filesServicePort.VerifyFileData(file)
+	}
+
+	// Verify files
+	result, err := a.filesService.VerifyFiles(
+		ctx.Context(),
+		&data,
+	)
+	if err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Write success response
+	response := dto.VerifyFilesResponse{
+		Files: make([]dto.VerifiedFileResponse, len(result.Files)),
+	}
+	for i, file := range result.Files {
+		response.Files[i] = dto.VerifiedFileResponse(file)
+	}
+	ctx.WriteResponse(200, response)
+}
+
+// @Summary List file versions (admin)
+// @Description Returns the kept versions of a file path, newest first. A version is the previous content of a file replaced by an upload with backup while a versions path is configured. A path without versions yields an empty list.
+// @Tags files
+// @Security BearerAuth
+// @Accept json
+// @Produce json,plain
+// @Param request body dto.AdminListFileVersionsRequest true "List file versions (admin)"
+// @Success 200 {array} dto.FileVersionResponse
+// @Failure 400 {object} httpctx.ErrorResponse "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:versions_disabled"
+// @Failure 403 {object} httpctx.ErrorResponse "Possible error codes: forbidden:path_escape"
+// @Param X-Store-Namespace header string false "Store namespace (default root if omitted)"
+// @Router /admin/files/versions [post]
+func (a *adapter) AdminListFileVersions(ctx server.ReqCtx) {
+	// Parse request json body
+	var request dto.AdminListFileVersionsRequest
+	if err := ctx.ReadJson(&request); err != nil {
+		httpctx.WriteError(ctx, errors.ErrBadRequest)
+		return
+	}
+
+	// Canonicalize request paths
+	if a.canonicalPaths {
+		request.Canonicalize()
+	}
+
+	// Validate request
+	if err := request.Validate(); err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Create data
+	data := filesServicePort.ListFileVersionsData(request)
+
+	// List versions
+	result, err := a.filesService.ListFileVersions(
+		ctx.Context(),
+		&data,
+	)
+	if err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Write success response
+	response := make([]dto.FileVersionResponse, len(*result))
+	for i, version := range *result {
+		response[i] = dto.FileVersionResponse(version)
+	}
+	ctx.WriteResponse(200, response)
+}
+
+// @Summary Download file version (admin)
+// @Description Streams a kept version of a file path, as listed by the versions endpoint.
+// @Tags files
+// @Security BearerAuth
+// @Produce octet-stream,json,plain
+// @Param path query string true "File path"
+// @Param version query string true "Version id"
+// @Success 200 {file} file
+// @Failure 400 {object} httpctx.ErrorResponse "Possible error codes: bad_request:invalid_path, bad_request:invalid_characters, bad_request:versions_disabled, bad_request:version_not_found"
+// @Failure 403 {object} httpctx.ErrorResponse "Possible error codes: forbidden:path_escape"
+// @Failure 503 {object} httpctx.ErrorResponse "Possible error codes: too_many_downloads (retry after Retry-After seconds)"
+// @Param X-Store-Namespace header string false "Store namespace (default root if omitted)"
+// @Router /admin/files/versions/download [get]
+func (a *adapter) AdminDownloadFileVersion(ctx server.ReqCtx) {
+	// Get request path and version
+	args := ctx.Request().URI().QueryArgs()
+	path := string(args.Peek("path"))
+	if a.canonicalPaths {
+		path = clientpath.Canonical(path)
+	}
+	if path == "" {
+		httpctx.WriteError(ctx, dto.ErrDirInvalidPath)
+		return
+	}
+	if clientpath.HasControlChars(path) {
+		httpctx.WriteError(ctx, dto.ErrFileInvalidCharacters)
+		return
+	}
+
+	// Open version
+	result, err := a.filesService.DownloadFileVersion(
+		ctx.Context(),
+		&filesServicePort.DownloadFileVersionData{
+			Path:      path,
+			VersionId: string(args.Peek("version")),
+		},
+	)
+	if err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Reserve a download slot
+	release, ok := a.acquireDownload(ctx)
+	if !ok {
+		result.File.Close()
+		return
+	}
+
+	// Write success response
+	ctx.SetStatusCode(200)
+	ctx.SetContentType(result.MimeType)
+	ctx.SetTraceIdHeader()
+	writeStream(ctx, result.File, result.Size, release)
+}
+
+// @Summary Restore file version (admin)
+// @Description Makes a kept version of a file path the current file again. The current file, if any, is kept as a new version first, whose id is returned.
+// @Tags files
+// @Security BearerAuth
+// @Accept json
+// @Produce json,plain
+// @Param request body dto.AdminRestoreFileVersionRequest true "Restore file version (admin)"
+// @Success 200 {object} dto.RestoreFileVersionResponse
+// @Failure 400 {object} httpctx.ErrorResponse "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:invalid_version, bad_request:versions_disabled, bad_request:version_not_found, bad_request:dir_not_found, bad_request:dir_full"
+// @Failure 403 {object} httpctx.ErrorResponse "Possible error codes: forbidden:path_escape"
+// @Failure 507 {object} httpctx.ErrorResponse "Possible error codes: insufficient_storage:storage_full"
+// @Param X-Store-Namespace header string false "Store namespace (default root if omitted)"
+// @Router /admin/files/versions/restore [post]
+func (a *adapter) AdminRestoreFileVersion(ctx server.ReqCtx) {
+	// Parse request json body
+	var request dto.AdminRestoreFileVersionRequest
+	if err := ctx.ReadJson(&request); err != nil {
+		httpctx.WriteError(ctx, errors.ErrBadRequest)
+		return
+	}
+
+	// Canonicalize request paths
+	if a.canonicalPaths {
+		request.Canonicalize()
+	}
+
+	// Validate request
+	if err := request.Validate(); err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Create data
+	data := filesServicePort.RestoreFileVersionData(request)
+
+	// Restore version
+	result, err := a.filesService.RestoreFileVersion(
+		ctx.Context(),
+		&data,
+	)
+	if err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Write success response
+	ctx.WriteResponse(200, dto.RestoreFileVersionResponse(*result))
+}
+
+// @Summary Split file (admin)
+// @Description Writes the content of a file into sequential parts of chunk_size bytes (name.part0001, name.part0002, ...) in target_path (the file's own dir if empty), the inverse of concatenating them, and returns the parts in order with their SHA-256. Fails without writing anything if a part name is taken or the parts do not fit, and removes written parts if interrupted.
+// @Tags files
+// @Security BearerAuth
+// @Accept json
+// @Produce json,plain
+// @Param request body dto.AdminSplitFileRequest true "Split file (admin)"
+// @Success 201 {object} dto.SplitFileResponse
+// @Failure 400 {object} httpctx.ErrorResponse "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:invalid_chunk_size, bad_request:too_many_parts, bad_request:file_not_found, bad_request:is_directory, bad_request:dir_not_found, bad_request:file_exist, bad_request:dir_full"
+// @Failure 403 {object} httpctx.ErrorResponse "Possible error codes: forbidden:path_escape"
+// @Failure 507 {object} httpctx.ErrorResponse "Possible error codes: insufficient_storage:storage_full"
+// @Param X-Store-Namespace header string false "Store namespace (default root if omitted)"
+// @Router /admin/files/split [post]
+func (a *adapter) AdminSplitFile(ctx server.ReqCtx) {
+	// Parse request json body
+	var request dto.AdminSplitFileRequest
+	if err := ctx.ReadJson(&request); err != nil {
+		httpctx.WriteError(ctx, errors.ErrBadRequest)
+		return
+	}
+
+	// Canonicalize request paths
+	if a.canonicalPaths {
+		request.Canonicalize()
+	}
+
+	// Validate request
+	if err := request.Validate(); err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Create data
+	data := filesServicePort.SplitFileData(request)
+
+	// Split file
+	result, err := a.filesService.SplitFile(
+		ctx.Context(),
+		&data,
+	)
+	if err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Write success response
+	response := dto.SplitFileResponse{
+		Parts: make([]dto.FilePartResponse, len(result.Parts)),
+	}
+	for i, part := range result.Parts {
+		response.Parts[i] = dto.FilePartResponse(part)
+	}
+	ctx.WriteResponse(201, response)
+}
+
+// @Summary Resolve path (admin)
+// @Description Returns the canonical form of a path as every other endpoint resolves it (slash-separated and relative to the store root, "" for the root itself, so "/uploads/", "./uploads" and "uploads/x/.." all resolve to "uploads"), whether it exists and its type (file, dir, symlink or other, null if missing). Symlinks are reported, not followed. Nothing is listed or read.
+// @Tags files
+// @Security BearerAuth
+// @Accept json
+// @Produce json,plain
+// @Param request body dto.AdminResolvePathRequest true "Resolve path (admin)"
+// @Success 200 {object} dto.ResolvePathResponse
+// @Failure 400 {object} httpctx.ErrorResponse "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters"
+// @Failure 403 {object} httpctx.ErrorResponse "Possible error codes: forbidden:path_escape"
+// @Param X-Store-Namespace header string false "Store namespace (default root if omitted)"
+// @Router /admin/files/resolve [post]
+func (a *adapter) AdminResolvePath(ctx server.ReqCtx) {
+	// Parse request json body
+	var request dto.AdminResolvePathRequest
+	if err := ctx.ReadJson(&request); err != nil {
+		httpctx.WriteError(ctx, errors.ErrBadRequest)
+		return
+	}
+
+	// Canonicalize request paths
+	if a.canonicalPaths {
+		request.Canonicalize()
+	}
+
+	// Validate request
+	if err := request.Validate(); err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Create data
+	data := filesServicePort.ResolvePathData(request)
+
+	// Resolve path
+	result, err := a.filesService.ResolvePath(
+		ctx.Context(),
+		&data,
+	)
+	if err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Write success response
+	ctx.WriteResponse(200, dto.ResolvePathResponse(*result))
+}
+
+// @Summary Check path (admin)
+// @Description Runs the path safety rules of an operation (read, delete, list, upload or create_dir) on a path and reports each rule applied, in order, and whether it passed: characters (no control characters), empty (the store root only for list and upload), absolute (always passes, absolute paths are anchored at the store root), traversal (no ".." escaping the root), depth (nesting limits, and at most 5 missing directories for upload) and symlink (no symlinked parent directory). Rules stop at the first rejection, reported in rule with the error code the operation would fail with. Nothing is changed and the filesystem is only inspected with lstat; whether the entry exists is not checked.
+// @Tags files
+// @Security BearerAuth
+// @Accept json
+// @Produce json,plain
+// @Param request body dto.AdminCheckPathRequest true "Check path (admin)"
+// @Success 200 {object} dto.CheckPathResponse
+// @Failure 400 {object} httpctx.ErrorResponse "Possible error codes: bad_request, bad_request:invalid_operation"
+// @Param X-Store-Namespace header string false "Store namespace (default root if omitted)"
+// @Router /admin/files/resolve/check [post]
+func (a *adapter) AdminCheckPath(ctx server.ReqCtx) {
+	// Parse request json body
+	var request dto.AdminCheckPathRequest
+	if err := ctx.ReadJson(&request); err != nil {
+		httpctx.WriteError(ctx, errors.ErrBadRequest)
+		return
+	}
+
+	// Canonicalize request paths
+	if a.canonicalPaths {
+		request.Canonicalize()
+	}
+
+	// Validate request
+	if err := request.Validate(); err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Create data
+	data := filesServicePort.CheckPathData(request)
+
+	// Check path
+	result, err := a.filesService.CheckPath(
+		ctx.Context(),
+		&data,
+	)
+	if err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Map result to response
+	response := dto.CheckPathResponse{
+		Path:      result.Path,
+		Operation: result.Operation,
+		Allowed:   result.Allowed,
+		Rule:      result.Rule,
+		Error:     result.Error,
+		Checks:    make([]dto.PathCheckResponse, len(result.Checks)),
+	}
+	for i, check := range result.Checks {
+		response.Checks[i] = dto.PathCheckResponse(check)
+	}
+
+	// Write success response
+	ctx.WriteResponse(200, response)
+}
+
+// @Summary Import file from url (admin)
+// @Description Fetches url server-side (http or https, redirects not followed) and stores the content in path like an upload, named after name or else the last url path segment. The host must be allowed and not denied by the configured import host lists, which also match the addresses it resolves to; the content must fit the import size limit and, if configured, its Content-Type the allowed import media types.
+// @Tags files
+// @Security BearerAuth
+// @Accept json
+// @Produce json,plain
+// @Param request body dto.AdminImportFileRequest true "Import file from url (admin)"
+// @Success 201 {object} dto.CreateFileResponse "Final file path (see MIME routing); backup_path is null unless a backup was made"
+// @Failure 400 {object} httpctx.ErrorResponse "Possible error codes: bad_request, bad_request:invalid_url, bad_request:invalid_name, bad_request:invalid_file, bad_request:invalid_path, bad_request:invalid_characters, bad_request:invalid_mode, bad_request:mime_not_allowed, bad_request:missing_extension, bad_request:dir_not_found, bad_request:file_exist, bad_request:file_not_found, bad_request:dir_full, bad_request:backup_unavailable, bad_request:invalid_image, bad_request:unsupported_image"
+// @Failure 403 {object} httpctx.ErrorResponse "Possible error codes: forbidden:path_escape, forbidden:top_dir_not_allowed, forbidden:import_disabled, forbidden:host_not_allowed"
+// @Failure 413 {object} httpctx.ErrorResponse "Possible error codes: payload_too_large:file_too_large, payload_too_large:import_too_large, payload_too_large:image_too_large"
+// @Failure 502 {object} httpctx.ErrorResponse "Possible error codes: bad_gateway:fetch_failed"
+// @Failure 507 {object} httpctx.ErrorResponse "Possible error codes: insufficient_storage:storage_full"
+// @Failure 504 {object} httpctx.ErrorResponse "Possible error codes: gateway_timeout:upload_timeout"
+// @Param X-Store-Namespace header string false "Store namespace (default root if omitted)"
+// @Router /admin/files/import [post]
+func (a *adapter) AdminImportFile(ctx server.ReqCtx) {
+	// Parse request json body
+	var request dto.AdminImportFileRequest
+	if err := ctx.ReadJson(&request); err != nil {
+		httpctx.WriteError(ctx, errors.ErrBadRequest)
+		return
+	}
+
+	// Canonicalize request paths
+	if a.canonicalPaths {
+		request.Canonicalize()
+	}
+
+	// Validate request
+	if err := request.Validate(); err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Create data
+	data := filesServicePort.ImportFileData(request)
+
+	// Import file
+	result, err := a.filesService.ImportFile(
+		ctx.Context(),
+		&data,
+	)
+	if err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Write success response
+	ctx.WriteResponse(201, dto.CreateFileResponse(*result))
+}
+
+// @Summary Stat file (admin)
+// @Description Returns the metadata of a file or dir without listing or reading it: name, size and detected MIME type of a file (and its text encoding if with_encoding is set), and, for a file uploaded with a ttl, its expires_at and the seconds left until then in expires_in.
+// @Tags files
+// @Security BearerAuth
+// @Accept json
+// @Produce json,plain
+// @Param request body dto.AdminStatFileRequest true "Stat file (admin)"
+// @Success 200 {object} dto.FileResponse
+// @Failure 400 {object} httpctx.ErrorResponse "Possible error codes: bad_request, bad_request:invalid_path, bad_request:invalid_characters, bad_request:file_not_found"
+// @Failure 403 {object} httpctx.ErrorResponse "Possible error codes: forbidden:path_escape"
+// @Param X-Store-Namespace header string false "Store namespace (default root if omitted)"
+// @Router /admin/files/stat [post]
+func (a *adapter) AdminStatFile(ctx server.ReqCtx) {
+	// Parse request json body
+	var request dto.AdminStatFileRequest
+	if err := ctx.ReadJson(&request); err != nil {
+		httpctx.WriteError(ctx, errors.ErrBadRequest)
+		return
+	}
+
+	// Canonicalize request paths
+	if a.canonicalPaths {
+		request.Canonicalize()
+	}
+
+	// Validate request
+	if err := request.Validate(); err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Create data
+	data := filesServicePort.StatFileData(request)
+
+	// Stat file
+	result, err := a.filesService.StatFile(
+		ctx.Context(),
+		&data,
+	)
+	if err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Write success response
+	ctx.WriteResponse(200, dto.FileResponse(*result))
+}
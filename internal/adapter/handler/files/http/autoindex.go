@@ -0,0 +1,139 @@
+package adapter
+
+import (
+	"html/template"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	dto "github.com/flash-go/files-service/internal/dto/files"
+	"github.com/flash-go/files-service/internal/httpctx"
+	filesServicePort "github.com/flash-go/files-service/internal/port/service/files"
+	"github.com/flash-go/flash/http/server"
+)
+
+// Number of entries per page of an HTML dir index when no limit is configured
+const defaultAutoIndexLimit = 100
+
+var autoIndexTemplate = template.Must(template.New("autoindex").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Index of /{{.Path}}</title>
+</head>
+<body>
+<h1>Index of /{{.Path}}</h1>
+<table>
+<tr><th>Name</th><th>Size</th></tr>
+{{- if .Parent}}
+<tr><td><a href="{{.Parent}}">../</a></td><td></td></tr>
+{{- end}}
+{{- range .Entries}}
+<tr><td><a href="{{.Href}}">{{.Name}}</a></td><td>{{.Size}}</td></tr>
+{{- end}}
+</table>
+{{- if or .First .Next}}
+<p>{{if .First}}<a href="{{.First}}">First page</a>{{end}}{{if and .First .Next}} | {{end}}{{if .Next}}<a href="{{.Next}}">Next page</a>{{end}}</p>
+{{- end}}
+</body>
+</html>
+`))
+
+// Entry of an HTML dir index
+type autoIndexEntry struct {
+	Name string
+	Href string
+	Size string
+}
+
+// Report whether an Accept header lists text/html (or XHTML) with a non-zero
+// quality
+func acceptsHtml(accept string) bool {
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, _ := strings.Cut(mediaRange, ";")
+		mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+		if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+			continue
+		}
+		accepted := true
+		for _, param := range strings.Split(params, ";") {
+			name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(name, "q") {
+				q, err := strconv.ParseFloat(value, 64)
+				accepted = err == nil && q > 0
+			}
+		}
+		if accepted {
+			return true
+		}
+	}
+	return false
+}
+
+// Return the relative download link of a path, with a page cursor if set
+func autoIndexHref(p, cursor string) string {
+	query := url.Values{"path": {p}}
+	if cursor != "" {
+		query.Set("cursor", cursor)
+	}
+	return "?" + query.Encode()
+}
+
+// Write one page of a dir listing as an HTML index. The listing holds at most
+// one entry more than the page size, telling that another page follows.
+// Entries link to the download of their path, so dirs are browsed in turn.
+func (a *adapter) writeAutoIndex(ctx server.ReqCtx, dirPath, cursor string, listing []filesServicePort.FileResult) {
+	dirPath = strings.Trim(dirPath, "/")
+	page := struct {
+		Path    string
+		Parent  string
+		Entries []autoIndexEntry
+		First   string
+		Next    string
+	}{
+		Path: dirPath,
+	}
+	if dirPath != "" {
+		parent := path.Dir(dirPath)
+		if parent == "." {
+			parent = ""
+		}
+		page.Parent = autoIndexHref(parent, "")
+	}
+	if cursor != "" {
+		page.First = autoIndexHref(dirPath, "")
+	}
+
+	// Cut the extra entry and point the next page at the last one kept
+	if len(listing) > a.autoIndexLimit {
+		listing = listing[:a.autoIndexLimit]
+		last := listing[len(listing)-1]
+		page.Next = autoIndexHref(dirPath, dto.EncodeListCursor(last.IsDir, last.Name))
+	}
+
+	page.Entries = make([]autoIndexEntry, len(listing))
+	for i, file := range listing {
+		entry := autoIndexEntry{
+			Name: file.Name,
+			Href: autoIndexHref(path.Join(dirPath, file.Name), ""),
+			Size: "-",
+		}
+		if file.IsDir {
+			entry.Name += "/"
+		} else if file.Size != nil {
+			entry.Size = strconv.FormatInt(*file.Size, 10)
+		}
+		page.Entries[i] = entry
+	}
+
+	var b strings.Builder
+	if err := autoIndexTemplate.Execute(&b, page); err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+	ctx.SetStatusCode(200)
+	ctx.SetContentType("text/html; charset=utf-8")
+	ctx.SetTraceIdHeader()
+	ctx.WriteString(b.String())
+}
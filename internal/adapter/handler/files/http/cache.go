@@ -0,0 +1,51 @@
+package adapter
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flash-go/flash/http/server"
+)
+
+// Cache-Control directives sent with downloads whose MIME type matches
+// Pattern: a media type ("text/html"), a top-level type wildcard ("image/*")
+// or "*"
+type CacheRule struct {
+	Pattern      string
+	CacheControl string
+}
+
+// Set the Cache-Control header of the first rule matching mimeType, and an
+// Expires header for HTTP/1.0 caches if it holds a max-age directive. Nothing
+// is set if no rule matches.
+func (a *adapter) setCacheHeaders(ctx server.ReqCtx, mimeType string) {
+	response := ctx.Response()
+	mediaType, _, _ := strings.Cut(mimeType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	topType, _, _ := strings.Cut(mediaType, "/")
+	for _, rule := range a.cacheRules {
+		switch rule.Pattern {
+		case "*", mediaType, topType + "/*":
+			response.Header.Set("Cache-Control", rule.CacheControl)
+			if maxAge, ok := cacheMaxAge(rule.CacheControl); ok {
+				expires := time.Now().Add(time.Duration(maxAge) * time.Second)
+				response.Header.Set("Expires", expires.UTC().Format(http.TimeFormat))
+			}
+			return
+		}
+	}
+}
+
+// Return the max-age of Cache-Control directives, if any
+func cacheMaxAge(cacheControl string) (int, bool) {
+	for _, directive := range strings.Split(cacheControl, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		if strings.EqualFold(name, "max-age") {
+			seconds, err := strconv.Atoi(value)
+			return seconds, err == nil && seconds >= 0
+		}
+	}
+	return 0, false
+}
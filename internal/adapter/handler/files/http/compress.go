@@ -0,0 +1,98 @@
+package adapter
+
+import (
+	"compress/gzip"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+)
+
+// Files smaller than this are sent uncompressed, as the encoding overhead
+// outweighs the savings
+const minCompressSize = 1024
+
+// Pooled stream compressor
+type compressor interface {
+	io.WriteCloser
+	Reset(w io.Writer)
+}
+
+var compressorPools = map[string]*sync.Pool{
+	"br": {
+		New: func() any {
+			return brotli.NewWriterLevel(nil, brotli.DefaultCompression)
+		},
+	},
+	"gzip": {
+		New: func() any {
+			return gzip.NewWriter(nil)
+		},
+	},
+}
+
+// Report whether a MIME type is textual and not already compressed
+func compressibleMimeType(mimeType string) bool {
+	mediaType, _, _ := strings.Cut(mimeType, ";")
+	switch strings.TrimSpace(mediaType) {
+	case "application/json", "application/javascript", "application/xml", "image/svg+xml":
+		return true
+	}
+	return strings.HasPrefix(mediaType, "text/")
+}
+
+/*
+negotiateEncoding picks the response content encoding from an Accept-Encoding
+header: "br" is preferred over "gzip" at equal quality, codings with q=0 are
+refused and "*" stands for any coding not listed. Returns "" if neither is
+acceptable.
+
+| Accept-Encoding         | Encoding |
+|-------------------------|----------|
+| "gzip, deflate, br"     | "br"     |
+| "gzip;q=1.0, br;q=0.5"  | "gzip"   |
+| "*;q=0.1, br;q=0"       | "gzip"   |
+| "identity"              | ""       |
+*/
+func negotiateEncoding(header string) string {
+	qualities := map[string]float64{}
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding == "" {
+			continue
+		}
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+		qualities[coding] = q
+	}
+	best, bestQ := "", 0.0
+	for _, coding := range []string{"br", "gzip"} {
+		q, ok := qualities[coding]
+		if !ok {
+			q = qualities["*"]
+		}
+		if q > bestQ {
+			best, bestQ = coding, q
+		}
+	}
+	return best
+}
+
+// Copy src to w through a pooled compressor for the given encoding
+func compressTo(w io.Writer, src io.Reader, encoding string) error {
+	pool := compressorPools[encoding]
+	c := pool.Get().(compressor)
+	defer pool.Put(c)
+	c.Reset(w)
+	if _, err := io.Copy(c, src); err != nil {
+		return err
+	}
+	return c.Close()
+}
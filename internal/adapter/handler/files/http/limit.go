@@ -0,0 +1,74 @@
+package adapter
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"sync"
+
+	dto "github.com/flash-go/files-service/internal/dto/files"
+	"github.com/flash-go/files-service/internal/httpctx"
+	"github.com/flash-go/flash/http/server"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Seconds a client is asked to wait when all download slots are taken
+const downloadRetryAfter = 5
+
+// Reserve a download slot for a response body stream. If maxDownloads
+// streams are already running, writes a 503 response with Retry-After and
+// reports false. The returned release must be called once the stream ends
+// (further calls do nothing).
+func (a *adapter) acquireDownload(ctx server.ReqCtx) (func(), bool) {
+	if a.downloadSlots != nil {
+		select {
+		case a.downloadSlots <- struct{}{}:
+		default:
+			if a.rejectedDownloads != nil {
+				a.rejectedDownloads.Add(context.Background(), 1)
+			}
+			// Written directly: WriteError would log every rejection as an
+			// unexpected 503
+			ctx.Response().Header.Set("Retry-After", strconv.Itoa(downloadRetryAfter))
+			ctx.WriteResponse(503, httpctx.ErrorResponse{
+				Code:    "too_many_downloads",
+				Message: dto.ErrFileTooManyDownloads.Error(),
+			})
+			return nil, false
+		}
+	}
+	if a.activeDownloads != nil {
+		a.activeDownloads.Add(context.Background(), 1)
+	}
+	return sync.OnceFunc(func() {
+		if a.activeDownloads != nil {
+			a.activeDownloads.Add(context.Background(), -1)
+		}
+		if a.downloadSlots != nil {
+			<-a.downloadSlots
+		}
+	}), true
+}
+
+// Stream body as the response body, releasing the download slot once the
+// server closes it after sending
+func writeStream(ctx server.ReqCtx, body io.ReadCloser, size int64, release func()) {
+	ctx.Response().SetBodyStream(releasingBody{body, release}, int(size))
+}
+
+// Response body releasing its download slot on close
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b releasingBody) Close() error {
+	defer b.release()
+	return b.ReadCloser.Close()
+}
+
+// Download telemetry instruments (either may be nil)
+type DownloadMetrics struct {
+	Active   metric.Int64UpDownCounter
+	Rejected metric.Int64Counter
+}
@@ -0,0 +1,93 @@
+package adapter
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Body of a range response: reads the part, closes the whole file once sent
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// Return the entity tag of a file version, derived from its size and
+// modification time so it changes whenever the content is rewritten
+func fileETag(size int64, modTime time.Time) string {
+	return `"` + strconv.FormatInt(size, 16) + "-" + strconv.FormatInt(modTime.UnixNano(), 16) + `"`
+}
+
+// Report whether an If-Range validator still matches the file, so a Range
+// request may be served partially. An entity tag must match strongly (weak
+// tags never match); a date must equal the Last-Modified time. An empty
+// header matches.
+func ifRangeMatches(ifRange, etag string, modTime time.Time) bool {
+	ifRange = strings.TrimSpace(ifRange)
+	switch {
+	case ifRange == "":
+		return true
+	case strings.HasPrefix(ifRange, `"`):
+		return ifRange == etag
+	case strings.HasPrefix(ifRange, "W/"):
+		return false
+	}
+	t, err := http.ParseTime(ifRange)
+	return err == nil && t.Equal(modTime.Truncate(time.Second))
+}
+
+/*
+parseByteRange resolves a "Range: bytes=..." header against a file size into
+the inclusive offsets of the single range to serve.
+
+| Header             | Size | Result               |
+|--------------------|------|----------------------|
+| "bytes=0-99"       | 1000 | 0-99                 |
+| "bytes=900-"       | 1000 | 900-999              |
+| "bytes=-100"       | 1000 | 900-999              |
+| "bytes=500-5000"   | 1000 | 500-999              |
+| "bytes=1000-"      | 1000 | unsatisfiable        |
+| "bytes=0-1,5-9"    | 1000 | ignored (multiple)   |
+| "items=0-1"        | 1000 | ignored (unit)       |
+
+ok is false if the header is to be ignored and the whole file served;
+satisfiable is false if the range lies beyond the end of the file.
+*/
+func parseByteRange(header string, size int64) (start, end int64, satisfiable, ok bool) {
+	spec, found := strings.CutPrefix(header, "bytes=")
+	if !found || strings.Contains(spec, ",") {
+		return 0, 0, false, false
+	}
+	first, last, found := strings.Cut(strings.TrimSpace(spec), "-")
+	if !found {
+		return 0, 0, false, false
+	}
+	var err error
+	if first == "" {
+		// Suffix range: the last N bytes
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n < 0 {
+			return 0, 0, false, false
+		}
+		if n == 0 || size == 0 {
+			return 0, 0, false, true
+		}
+		return max(size-n, 0), size - 1, true, true
+	}
+	if start, err = strconv.ParseInt(first, 10, 64); err != nil || start < 0 {
+		return 0, 0, false, false
+	}
+	end = size - 1
+	if last != "" {
+		if end, err = strconv.ParseInt(last, 10, 64); err != nil || end < start {
+			return 0, 0, false, false
+		}
+		end = min(end, size-1)
+	}
+	if start >= size {
+		return 0, 0, false, true
+	}
+	return start, end, true, true
+}
@@ -0,0 +1,132 @@
+package adapter
+
+import (
+	"io"
+	"mime/multipart"
+	"os"
+
+	dto "github.com/flash-go/files-service/internal/dto/files"
+	filesServicePort "github.com/flash-go/files-service/internal/port/service/files"
+	"github.com/flash-go/flash/http/server"
+	"github.com/flash-go/sdk/errors"
+)
+
+// Maximum size of the meta part of a streamed upload
+const maxStreamedMetaSize = 1024 * 1024
+
+// Room in the Content-Length of an upload beyond its file, for the meta part
+// and the multipart framing
+const uploadFormOverhead = maxStreamedMetaSize + 64*1024
+
+// Upload read from a streamed multipart request body
+type streamedUpload struct {
+	meta  []byte
+	file  *filesServicePort.FileStream
+	spool *os.File
+}
+
+/*
+readStreamedUpload reads the "meta" and "file" parts of a multipart upload
+from the request body stream, in order of arrival.
+
+If meta comes first, reading stops at the file part, which is returned as a
+stream so its content goes straight from the connection to the store. If the
+file part comes first, it is spooled to a temp file in uploadTempPath until
+meta is read, as a buffered form would be, failing with ErrFileTooLarge once
+it exceeds uploadMaxSize (unless 0). Other parts are skipped. The caller must
+Close the upload to remove a spool file.
+*/
+func (a *adapter) readStreamedUpload(ctx server.ReqCtx) (*streamedUpload, error) {
+	boundary := string(ctx.Request().Header.MultipartFormBoundary())
+	if boundary == "" {
+		return nil, errors.ErrBadRequest
+	}
+	reader := multipart.NewReader(ctx.Request().BodyStream(), boundary)
+
+	upload := &streamedUpload{}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			upload.Close()
+			return nil, errors.ErrBadRequest
+		}
+		switch part.FormName() {
+		case "meta":
+			meta, err := io.ReadAll(io.LimitReader(part, maxStreamedMetaSize+1))
+			if err != nil || len(meta) > maxStreamedMetaSize {
+				upload.Close()
+				return nil, errors.ErrBadRequest
+			}
+			upload.meta = meta
+		case "file":
+			if upload.file != nil {
+				continue
+			}
+			if upload.meta != nil {
+				upload.file = &filesServicePort.FileStream{
+					Filename: part.FileName(),
+					Reader:   part,
+				}
+				return upload, nil
+			}
+			if err := upload.spoolFile(part, a.uploadTempPath, a.uploadMaxSize); err != nil {
+				upload.Close()
+				return nil, err
+			}
+		}
+	}
+	if upload.file == nil {
+		upload.Close()
+		return nil, errors.ErrBadRequest
+	}
+	return upload, nil
+}
+
+// Reject an upload whose Content-Length shows that its file exceeds
+// uploadMaxSize (unless 0), before the form is parsed
+func (a *adapter) checkUploadLength(ctx server.ReqCtx) error {
+	if a.uploadMaxSize > 0 && int64(ctx.Request().Header.ContentLength()) > a.uploadMaxSize+uploadFormOverhead {
+		return dto.ErrFileTooLarge
+	}
+	return nil
+}
+
+// Copy a file part of at most maxSize bytes (unless 0) to a temp file in
+// tempDir and read the upload from there
+func (u *streamedUpload) spoolFile(part *multipart.Part, tempDir string, maxSize int64) error {
+	spool, err := os.CreateTemp(tempDir, ".upload.tmp-*")
+	if err != nil {
+		return err
+	}
+	u.spool = spool
+	var reader io.Reader = part
+	if maxSize > 0 {
+		reader = io.LimitReader(part, maxSize+1)
+	}
+	n, err := io.Copy(spool, reader)
+	if err != nil {
+		return errors.ErrBadRequest
+	}
+	if maxSize > 0 && n > maxSize {
+		return dto.ErrFileTooLarge
+	}
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	u.file = &filesServicePort.FileStream{
+		Filename: part.FileName(),
+		Reader:   spool,
+	}
+	return nil
+}
+
+// Remove the spool file, if any
+func (u *streamedUpload) Close() {
+	if u.spool != nil {
+		u.spool.Close()
+		os.Remove(u.spool.Name())
+	}
+}
@@ -0,0 +1,40 @@
+package adapter
+
+import (
+	dto "github.com/flash-go/files-service/internal/dto/system"
+	"github.com/flash-go/files-service/internal/httpctx"
+	httpSystemHandlerAdapterPort "github.com/flash-go/files-service/internal/port/adapter/handler/system/http"
+	systemServicePort "github.com/flash-go/files-service/internal/port/service/system"
+	"github.com/flash-go/flash/http/server"
+)
+
+type Config struct {
+	SystemService systemServicePort.Interface
+}
+
+func New(config *Config) httpSystemHandlerAdapterPort.Interface {
+	return &adapter{
+		config.SystemService,
+	}
+}
+
+type adapter struct {
+	systemService systemServicePort.Interface
+}
+
+// @Summary Get version
+// @Tags system
+// @Produce json,plain
+// @Success 200 {object} dto.VersionResponse
+// @Router /version [get]
+func (a *adapter) Version(ctx server.ReqCtx) {
+	// Get version
+	version, err := a.systemService.GetVersion(ctx.Context())
+	if err != nil {
+		httpctx.WriteError(ctx, err)
+		return
+	}
+
+	// Write success response
+	ctx.WriteResponse(200, dto.VersionResponse(*version))
+}
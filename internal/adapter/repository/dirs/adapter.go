@@ -2,27 +2,75 @@ package adapter
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	dirsRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/dirs"
+	"github.com/flash-go/files-service/internal/storefs"
+	"go.opentelemetry.io/otel/metric"
 )
 
 type Config struct {
-	StoreLocalRootPath string
+	StoreLocalRootPath   string
+	DirMaxEntries        int
+	OperationTimeout     time.Duration
+	RetryAttempts        int
+	RetryBackoff         time.Duration
+	RetryCounter         metric.Int64Counter
+	HiddenNames          []string
+	RenameSamePathNoop   bool
+	TopDirs              []string
+	DeleteBatchThreshold int
+	NamespaceRoots       []string
 }
 
 func New(config *Config) dirsRepositoryAdapterPort.Interface {
-	return &adapter{
-		storeLocalRootPath: config.StoreLocalRootPath,
+	a := &adapter{
+		storeLocalRootPath:   config.StoreLocalRootPath,
+		dirMaxEntries:        config.DirMaxEntries,
+		hiddenNames:          config.HiddenNames,
+		renameSamePathNoop:   config.RenameSamePathNoop,
+		topDirs:              config.TopDirs,
+		deleteBatchThreshold: config.DeleteBatchThreshold,
+		namespaceRoots:       absRoots(config.NamespaceRoots),
+		sizes:                newSizeCache(),
 	}
+	var repository dirsRepositoryAdapterPort.Interface = a
+	if config.RetryAttempts > 0 {
+		repository = &retryAdapter{
+			next: repository,
+			policy: retryPolicy{
+				attempts: config.RetryAttempts,
+				backoff:  config.RetryBackoff,
+				counter:  config.RetryCounter,
+			},
+		}
+	}
+	if config.OperationTimeout > 0 {
+		return &timeoutAdapter{
+			next:             repository,
+			operationTimeout: config.OperationTimeout,
+		}
+	}
+	return repository
 }
 
 type adapter struct {
-	storeLocalRootPath string
+	storeLocalRootPath   string
+	dirMaxEntries        int
+	hiddenNames          []string
+	renameSamePathNoop   bool
+	topDirs              []string
+	deleteBatchThreshold int
+	namespaceRoots       []string
+	sizes                *sizeCache
 }
 
 /*
@@ -43,16 +91,32 @@ Safety measures implemented:
 
 3. **Existing path checks**
   - If the target already exists:
-  - Returns `ErrDirExist` if it's a directory.
+  - Returns `ErrDirExist` if it's a directory, or if `GetOrCreate` is set,
+    succeeds without creating anything once the parents pass the symlink
+    checks below (`Created` is false).
   - Returns an error if it's not a directory.
+  - A missing parent directory yields `ErrDirNotFound`; parents are never
+    created implicitly.
+  - The same rules apply when the final create fails with `EEXIST`, which is
+    the authoritative signal for a directory created concurrently.
 
 4. **Symlink protection**
   - Traverses parent directories up to the base path.
   - If any parent directory is a symlink, aborts creation.
-  - This prevents symlink race attacks where a path component is replaced
-    with a symlink pointing outside the base.
+  - Creates the directory with a single `Mkdir` through an `os.Root` handle
+    on the base, which refuses to follow a component swapped for a symlink
+    pointing outside the base after the walk (symlink race attacks).
+
+5. **Capacity check**
+  - If `dirMaxEntries` is set, rejects creation inside a parent directory that
+    already holds that many entries (`ErrDirFull`).
 
-5. **Secure directory creation**
+6. **Top-level allowlist**
+  - If `topDirs` is set, rejects creating a top-level directory whose name is
+    not listed (`ErrTopDirNotAllowed`). Existing top-level directories stay
+    usable.
+
+7. **Secure directory creation**
   - Creates directories with permission `0700` (owner-only access).
 
 Allowed paths:
@@ -74,66 +138,118 @@ Rejected paths:
 | `symlink_to_outside`  | Parent dir is a symlink pointing outside |
 | `nested/../../escape` | Path traversal escape                    |
 
+The result holds the cleaned, slash-separated path relative to the base.
+
 By enforcing these checks, this function ensures that directories can only be created
 inside the intended storage root and prevents malicious attempts to write outside of it.
 */
-func (a *adapter) CreateDir(ctx context.Context, data *dirsRepositoryAdapterPort.CreateDirData) error {
+func (a *adapter) CreateDir(ctx context.Context, data *dirsRepositoryAdapterPort.CreateDirData) (*dirsRepositoryAdapterPort.CreateDirResult, error) {
 	// Validate input path
 	if data.Path == "" {
-		return dirsRepositoryAdapterPort.ErrInvalidPath
+		return nil, dirsRepositoryAdapterPort.ErrInvalidPath
 	}
 	cleanPath := filepath.Clean(data.Path)
-	if cleanPath == "." || cleanPath == "/" || strings.HasPrefix(cleanPath, "..") {
-		return dirsRepositoryAdapterPort.ErrInvalidPath
+	if cleanPath == "." || cleanPath == "/" {
+		return nil, dirsRepositoryAdapterPort.ErrInvalidPath
+	}
+	if strings.HasPrefix(cleanPath, "..") {
+		return nil, dirsRepositoryAdapterPort.ErrPathEscape
 	}
 
 	// Resolve absolute paths
 	baseAbs, err := filepath.Abs(a.storeLocalRootPath)
 	if err != nil {
-		return fmt.Errorf("failed to resolve base path: %w", err)
+		return nil, fmt.Errorf("failed to resolve base path: %w", err)
 	}
 	targetAbs, err := filepath.Abs(filepath.Join(baseAbs, cleanPath))
 	if err != nil {
-		return dirsRepositoryAdapterPort.ErrInvalidPath
+		return nil, dirsRepositoryAdapterPort.ErrInvalidPath
 	}
 
 	// Ensure targetAbs is inside baseAbs
 	relToBase, err := filepath.Rel(baseAbs, targetAbs)
 	if err != nil {
-		return fmt.Errorf("failed to compute relative path: %w", err)
+		return nil, fmt.Errorf("failed to compute relative path: %w", err)
 	}
-	if strings.HasPrefix(relToBase, "..") || relToBase == "." {
-		return dirsRepositoryAdapterPort.ErrInvalidPath
+	if relToBase == "." {
+		return nil, dirsRepositoryAdapterPort.ErrInvalidPath
+	}
+	if strings.HasPrefix(relToBase, "..") {
+		return nil, dirsRepositoryAdapterPort.ErrPathEscape
+	}
+
+	result := dirsRepositoryAdapterPort.CreateDirResult{
+		Path: filepath.ToSlash(relToBase),
+	}
+
+	// Check parent directories for symlinks
+	if err := checkParents(baseAbs, filepath.Dir(targetAbs)); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, dirsRepositoryAdapterPort.ErrDirNotFound
+		}
+		return nil, err
 	}
 
 	// Check if it already exists
 	if info, err := os.Lstat(targetAbs); err == nil {
-		if info.IsDir() {
-			return dirsRepositoryAdapterPort.ErrDirExist
-		}
-		return dirsRepositoryAdapterPort.ErrInvalidPath
+		return existingDir(info, data.GetOrCreate, &result)
 	} else if !os.IsNotExist(err) {
-		return fmt.Errorf("failed to stat target: %w", err)
+		return nil, fmt.Errorf("failed to stat target: %w", err)
 	}
 
-	// Check parent directories for symlinks (symlink race prevention)
-	current := filepath.Dir(targetAbs)
-	for {
-		if current == baseAbs || current == string(filepath.Separator) {
-			break
-		}
-		info, err := os.Lstat(current)
-		if err != nil {
-			return fmt.Errorf("failed to stat %q: %w", current, err)
+	// Check the top-level directory against the allowlist
+	if !a.topDirAllowed(baseAbs, relToBase) {
+		return nil, dirsRepositoryAdapterPort.ErrTopDirNotAllowed
+	}
+
+	// Check parent directory capacity
+	if full, err := storefs.DirFull(filepath.Dir(targetAbs), a.dirMaxEntries); err != nil {
+		return nil, fmt.Errorf("failed to count entries: %w", err)
+	} else if full {
+		return nil, dirsRepositoryAdapterPort.ErrDirFull
+	}
+
+	// Create the directory through a handle on the base directory, so a
+	// component swapped for an escaping symlink after the checks above is
+	// refused instead of followed. The create itself is the authoritative
+	// existence check: a concurrent creation surfaces as fs.ErrExist.
+	root, err := os.OpenRoot(baseAbs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open base path: %w", err)
+	}
+	defer root.Close()
+	if err := root.Mkdir(relToBase, 0700); err != nil {
+		switch {
+		case errors.Is(err, fs.ErrExist):
+			info, err := root.Lstat(relToBase)
+			if err != nil {
+				return nil, fmt.Errorf("failed to stat target: %w", err)
+			}
+			return existingDir(info, data.GetOrCreate, &result)
+		case errors.Is(err, fs.ErrNotExist):
+			return nil, dirsRepositoryAdapterPort.ErrDirNotFound
 		}
-		if info.Mode()&os.ModeSymlink != 0 {
-			return dirsRepositoryAdapterPort.ErrInvalidPath
+
+		// Report a parent swapped for a symlink as an escape
+		if err := checkParents(baseAbs, filepath.Dir(targetAbs)); errors.Is(err, dirsRepositoryAdapterPort.ErrPathEscape) {
+			return nil, err
 		}
-		current = filepath.Dir(current)
+		return nil, err
 	}
+	result.Created = true
+	return &result, nil
+}
 
-	// Create directory
-	return os.MkdirAll(targetAbs, 0700)
+// Resolve a CreateDir call whose target already exists: ErrDirExist for a
+// directory unless getOrCreate is set, ErrInvalidPath for anything else
+func existingDir(info os.FileInfo, getOrCreate bool, result *dirsRepositoryAdapterPort.CreateDirResult) (*dirsRepositoryAdapterPort.CreateDirResult, error) {
+	if !info.IsDir() {
+		return nil, dirsRepositoryAdapterPort.ErrInvalidPath
+	}
+	if !getOrCreate {
+		return nil, dirsRepositoryAdapterPort.ErrDirExist
+	}
+	return result, nil
 }
 
 /*
@@ -160,6 +276,7 @@ ALGORITHM:
 
 3. **Existence and Type Check**
   - Confirms that the target exists and is a directory.
+  - If `UnmodifiedSince` is set, rejects a directory modified after that time.
 
 4. **Recursive Walk & Symlink Check**
   - Traverses directory contents with `filepath.WalkDir`.
@@ -171,6 +288,10 @@ ALGORITHM:
 
 6. **Deletion**
   - If all checks pass, deletes the target directory with `os.RemoveAll`.
+  - If the walk counted more than `deleteBatchThreshold` entries (when set),
+    removes them in batches of `deleteBatchSize` instead, checking `ctx`
+    between batches, so a huge delete stops once the request is cancelled
+    or times out (leaving the rest of the tree in place).
 
 SECURITY EXAMPLES:
 
@@ -228,17 +349,17 @@ This function is designed for production use with high safety guarantees against
 or malicious deletion outside the designated storage root.
 */
 func (a *adapter) DeleteDir(ctx context.Context, data *dirsRepositoryAdapterPort.DeleteDirData) error {
-	// Maximum allowed directory depth
-	const maxDepth = 5
-
 	// Validate input path
 	if data.Path == "" {
 		return dirsRepositoryAdapterPort.ErrInvalidPath
 	}
 	cleanPath := filepath.Clean(data.Path)
-	if cleanPath == "." || cleanPath == "/" || strings.HasPrefix(cleanPath, "..") {
+	if cleanPath == "." || cleanPath == "/" {
 		return dirsRepositoryAdapterPort.ErrInvalidPath
 	}
+	if strings.HasPrefix(cleanPath, "..") {
+		return dirsRepositoryAdapterPort.ErrPathEscape
+	}
 
 	// Resolve absolute paths
 	baseAbs, err := filepath.Abs(a.storeLocalRootPath)
@@ -255,9 +376,12 @@ func (a *adapter) DeleteDir(ctx context.Context, data *dirsRepositoryAdapterPort
 	if err != nil {
 		return fmt.Errorf("failed to compute relative path: %w", err)
 	}
-	if strings.HasPrefix(relToBase, "..") || relToBase == "." {
+	if relToBase == "." {
 		return dirsRepositoryAdapterPort.ErrInvalidPath
 	}
+	if strings.HasPrefix(relToBase, "..") {
+		return dirsRepositoryAdapterPort.ErrPathEscape
+	}
 
 	// Check that the target exists and is a directory
 	info, err := os.Lstat(targetAbs)
@@ -271,11 +395,41 @@ func (a *adapter) DeleteDir(ctx context.Context, data *dirsRepositoryAdapterPort
 		return dirsRepositoryAdapterPort.ErrInvalidPath
 	}
 
-	// Walk through and check for symlinks
-	err = filepath.WalkDir(targetAbs, func(path string, d fs.DirEntry, walkErr error) error {
+	// Check precondition (HTTP dates have second precision)
+	if data.UnmodifiedSince != nil && info.ModTime().Truncate(time.Second).After(*data.UnmodifiedSince) {
+		return dirsRepositoryAdapterPort.ErrDirModified
+	}
+
+	// Walk through and check for symlinks, counting entries
+	entries, err := checkTree(ctx, baseAbs, targetAbs)
+	if err != nil {
+		return err
+	}
+
+	// Perform deletion
+	if a.deleteBatchThreshold > 0 && entries > a.deleteBatchThreshold {
+		return removeBatched(ctx, targetAbs)
+	}
+	return os.RemoveAll(targetAbs)
+}
+
+// Maximum directory depth below a directory removed by DeleteDir or EmptyDir
+const maxDepth = 5
+
+// Walk a directory tree and return the number of entries in it (the
+// directory included), failing if it is nested deeper than maxDepth or holds
+// a symlink resolving outside baseAbs. Stops with the context error once ctx
+// is done.
+func checkTree(ctx context.Context, baseAbs, targetAbs string) (int, error) {
+	entries := 0
+	err := filepath.WalkDir(targetAbs, func(path string, d fs.DirEntry, walkErr error) error {
 		if walkErr != nil {
 			return walkErr
 		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		entries++
 
 		// DoS protection: check directory depth
 		rel, _ := filepath.Rel(targetAbs, path)
@@ -302,12 +456,45 @@ func (a *adapter) DeleteDir(ctx context.Context, data *dirsRepositoryAdapterPort
 
 		return nil
 	})
-	if err != nil {
-		return err
-	}
+	return entries, err
+}
 
-	// Perform deletion
-	return os.RemoveAll(targetAbs)
+// Number of entries read and removed per batch by removeBatched
+const deleteBatchSize = 1000
+
+// Remove a directory tree bottom-up, reading and removing at most
+// deleteBatchSize entries of a directory at a time and stopping with the
+// context error between batches. Symlinks are removed, never followed.
+func removeBatched(ctx context.Context, dirAbs string) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		f, err := os.Open(dirAbs)
+		if err != nil {
+			return err
+		}
+		batch, err := f.ReadDir(deleteBatchSize)
+		f.Close()
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if len(batch) == 0 {
+			return os.Remove(dirAbs)
+		}
+		for _, entry := range batch {
+			entryAbs := filepath.Join(dirAbs, entry.Name())
+			if entry.IsDir() {
+				if err := removeBatched(ctx, entryAbs); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := os.Remove(entryAbs); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
 }
 
 /*
@@ -323,6 +510,14 @@ This function performs multiple safety checks before performing the rename:
  5. Walks through parent directories of both old and new paths to ensure no symlinks
     exist, preventing symlink race attacks.
  6. Protects against excessive depth in the directory structure to mitigate DoS risks.
+ 7. If UnmodifiedSince is set, refuses to rename a directory modified after that time.
+ 8. Renaming a path onto itself returns ErrSamePath, or succeeds without
+    touching the directory if renameSamePathNoop is set. A new path differing
+    only in case that resolves to the old directory (case-insensitive
+    filesystem) is renamed in place instead of reported as existing.
+ 9. Rejects moves between the roots of different store namespaces
+    (namespaceRoots) nested inside one another with ErrCrossNamespace, as
+    well as moves of a directory holding the root of another namespace.
 
 Allowed paths (example, assuming base is /var/data):
 
@@ -370,11 +565,16 @@ func (a *adapter) RenameDir(ctx context.Context, data *dirsRepositoryAdapterPort
 	// Ensure old and new paths are inside base
 	relOld, err := filepath.Rel(baseAbs, oldAbs)
 	if err != nil || strings.HasPrefix(relOld, "..") {
-		return dirsRepositoryAdapterPort.ErrInvalidPath
+		return dirsRepositoryAdapterPort.ErrPathEscape
 	}
 	relNew, err := filepath.Rel(baseAbs, newAbs)
 	if err != nil || strings.HasPrefix(relNew, "..") {
-		return dirsRepositoryAdapterPort.ErrInvalidPath
+		return dirsRepositoryAdapterPort.ErrPathEscape
+	}
+
+	// Ensure both paths belong to the same store namespace
+	if a.crossesNamespace(oldAbs, newAbs) {
+		return dirsRepositoryAdapterPort.ErrCrossNamespace
 	}
 
 	// Check old directory exists
@@ -388,30 +588,184 @@ func (a *adapter) RenameDir(ctx context.Context, data *dirsRepositoryAdapterPort
 	if !info.IsDir() {
 		return dirsRepositoryAdapterPort.ErrInvalidPath
 	}
+	if data.UnmodifiedSince != nil && info.ModTime().Truncate(time.Second).After(*data.UnmodifiedSince) {
+		return dirsRepositoryAdapterPort.ErrDirModified
+	}
+	if oldClean == newClean {
+		if a.renameSamePathNoop {
+			return nil
+		}
+		return dirsRepositoryAdapterPort.ErrSamePath
+	}
 
-	// Check new directory does not exist
-	if _, err := os.Lstat(newAbs); err == nil {
-		return dirsRepositoryAdapterPort.ErrDirNewExist
+	// Check new directory does not exist, unless only its case differs
+	if newInfo, err := os.Lstat(newAbs); err == nil {
+		if !strings.EqualFold(oldClean, newClean) || !os.SameFile(info, newInfo) {
+			return dirsRepositoryAdapterPort.ErrDirNewExist
+		}
 	}
 
 	// Check for symlinks in parent directories of old and new
 	for _, path := range []string{oldAbs, newAbs} {
-		current := filepath.Dir(path)
-		for {
-			if current == baseAbs || current == string(filepath.Separator) {
-				break
-			}
-			info, err := os.Lstat(current)
-			if err != nil {
-				return dirsRepositoryAdapterPort.ErrInvalidPath
-			}
-			if info.Mode()&os.ModeSymlink != 0 {
-				return dirsRepositoryAdapterPort.ErrInvalidPath
-			}
-			current = filepath.Dir(current)
+		if err := checkParents(baseAbs, filepath.Dir(path)); err != nil {
+			return parentsError(err)
 		}
 	}
 
 	// Perform rename
 	return os.Rename(oldAbs, newAbs)
 }
+
+/*
+GetDirTree returns the directory hierarchy below a path inside the adapter's base
+path as a nested tree, skipping files unless WithFiles is set.
+
+This function performs the following safety checks:
+
+ 1. Rejects paths that traverse outside the base directory using "..".
+ 2. Resolves the absolute path and ensures it is inside storeLocalRootPath.
+ 3. Walks through parent directories to reject symlinked path components.
+ 4. Confirms the target exists and is a directory.
+ 5. Does not follow symlinked directories found during the walk, so the tree
+    never leaves the base directory. With WithFiles, regular files are
+    included as leaves; symlinks and other entries are always skipped.
+ 6. Limits the walk to Depth levels (at most maxDepth, which is also the default
+    when Depth is 0) to avoid DoS from deeply nested structures.
+
+Example (Depth = 2, assuming base is /var/data):
+
+	Path: "uploads" → uploads
+	                  ├── images
+	                  │   └── 2025
+	                  └── docs
+*/
+func (a *adapter) GetDirTree(ctx context.Context, data *dirsRepositoryAdapterPort.GetDirTreeData) (*dirsRepositoryAdapterPort.DirTreeResult, error) {
+	// Maximum allowed tree depth
+	const maxDepth = 5
+
+	// Validate input path
+	cleanPath := filepath.Clean(data.Path)
+	if strings.HasPrefix(cleanPath, "..") {
+		return nil, dirsRepositoryAdapterPort.ErrPathEscape
+	}
+
+	// Resolve absolute paths
+	baseAbs, err := filepath.Abs(a.storeLocalRootPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve base path: %w", err)
+	}
+	targetAbs, err := filepath.Abs(filepath.Join(baseAbs, cleanPath))
+	if err != nil {
+		return nil, dirsRepositoryAdapterPort.ErrInvalidPath
+	}
+
+	// Ensure targetAbs is inside baseAbs
+	relToBase, err := filepath.Rel(baseAbs, targetAbs)
+	if err != nil || strings.HasPrefix(relToBase, "..") {
+		return nil, dirsRepositoryAdapterPort.ErrPathEscape
+	}
+
+	// Check parent directories for symlinks
+	if err := checkParents(baseAbs, targetAbs); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, dirsRepositoryAdapterPort.ErrDirNotFound
+		}
+		return nil, err
+	}
+
+	// Check that the target exists and is a directory
+	info, err := os.Stat(targetAbs)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, dirsRepositoryAdapterPort.ErrDirNotFound
+		}
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, dirsRepositoryAdapterPort.ErrInvalidPath
+	}
+
+	// Clamp depth
+	depth := data.Depth
+	if depth <= 0 || depth > maxDepth {
+		depth = maxDepth
+	}
+
+	// Build tree
+	name := ""
+	if relToBase != "." {
+		name = filepath.Base(targetAbs)
+	}
+	tree, err := a.dirTree(targetAbs, name, depth, data.WithFiles)
+	if err != nil {
+		return nil, err
+	}
+	return &tree, nil
+}
+
+// Build the tree node for a directory, descending at most depth levels.
+// Symlinks are skipped, so symlinked directories are never followed, as are
+// entries matching hiddenNames. Regular files are skipped unless withFiles.
+func (a *adapter) dirTree(dirAbs, name string, depth int, withFiles bool) (dirsRepositoryAdapterPort.DirTreeResult, error) {
+	node := dirsRepositoryAdapterPort.DirTreeResult{
+		Name:     name,
+		IsDir:    true,
+		Children: []dirsRepositoryAdapterPort.DirTreeResult{},
+	}
+	if depth == 0 {
+		return node, nil
+	}
+	entries, err := os.ReadDir(dirAbs)
+	if err != nil {
+		return node, err
+	}
+	for _, entry := range entries {
+		if entry.Type()&os.ModeSymlink != 0 || storefs.Hidden(a.hiddenNames, entry.Name()) {
+			continue
+		}
+		if !entry.IsDir() {
+			if withFiles && entry.Type().IsRegular() {
+				node.Children = append(node.Children, dirsRepositoryAdapterPort.DirTreeResult{
+					Name:     entry.Name(),
+					Children: []dirsRepositoryAdapterPort.DirTreeResult{},
+				})
+			}
+			continue
+		}
+		child, err := a.dirTree(filepath.Join(dirAbs, entry.Name()), entry.Name(), depth-1, withFiles)
+		if err != nil {
+			return node, err
+		}
+		node.Children = append(node.Children, child)
+	}
+	return node, nil
+}
+
+// Report whether creating relPath (relative to the base) may add the top-level
+// directory it starts with: always if topDirs is empty or that directory
+// already exists, otherwise only if its name is listed in topDirs
+func (a *adapter) topDirAllowed(baseAbs, relPath string) bool {
+	if len(a.topDirs) == 0 {
+		return true
+	}
+	top, _, _ := strings.Cut(relPath, string(filepath.Separator))
+	if _, err := os.Lstat(filepath.Join(baseAbs, top)); !os.IsNotExist(err) {
+		return true
+	}
+	return slices.Contains(a.topDirs, top)
+}
+
+// Report a failed parent walk as ErrPathEscape if it found a symlink or left
+// the base, else (e.g. a missing parent) as ErrInvalidPath
+func parentsError(err error) error {
+	if errors.Is(err, dirsRepositoryAdapterPort.ErrPathEscape) {
+		return err
+	}
+	return dirsRepositoryAdapterPort.ErrInvalidPath
+}
+
+// Walk from start up to the base directory, rejecting symlinked components
+// with ErrPathEscape, see storefs.CheckParents
+func checkParents(baseAbs, start string) error {
+	return storefs.CheckParents(baseAbs, start, dirsRepositoryAdapterPort.ErrPathEscape)
+}
@@ -0,0 +1,49 @@
+package adapter
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Resolve the namespace roots to absolute paths, dropping unresolvable ones
+func absRoots(roots []string) []string {
+	abs := make([]string, 0, len(roots))
+	for _, root := range roots {
+		if rootAbs, err := filepath.Abs(root); err == nil {
+			abs = append(abs, rootAbs)
+		}
+	}
+	return abs
+}
+
+// Return the deepest namespace root containing pathAbs ("" if none)
+func (a *adapter) namespaceRoot(pathAbs string) string {
+	deepest := ""
+	for _, root := range a.namespaceRoots {
+		if within(root, pathAbs) && len(root) > len(deepest) {
+			deepest = root
+		}
+	}
+	return deepest
+}
+
+// Report whether moving oldAbs to newAbs crosses the boundary of a store
+// namespace: both paths must belong to the same namespace root, and no other
+// namespace root may lie below oldAbs, since it would be moved along
+func (a *adapter) crossesNamespace(oldAbs, newAbs string) bool {
+	if a.namespaceRoot(oldAbs) != a.namespaceRoot(newAbs) {
+		return true
+	}
+	for _, root := range a.namespaceRoots {
+		if root != oldAbs && within(oldAbs, root) {
+			return true
+		}
+	}
+	return false
+}
+
+// Report whether pathAbs is dirAbs or below it
+func within(dirAbs, pathAbs string) bool {
+	rel, err := filepath.Rel(dirAbs, pathAbs)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,54 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	dirsRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/dirs"
+)
+
+func TestRenameDirRejectsCrossNamespace(t *testing.T) {
+	// The root store holds the root of the "media" namespace below shared
+	dir := t.TempDir()
+	media := filepath.Join(dir, "shared", "media")
+	for _, d := range []string{media, filepath.Join(dir, "docs"), filepath.Join(media, "albums")} {
+		if err := os.MkdirAll(d, 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a := &adapter{storeLocalRootPath: dir, namespaceRoots: absRoots([]string{dir, media})}
+
+	tests := []struct {
+		name    string
+		oldPath string
+		newPath string
+	}{
+		{"into another namespace", "docs", "shared/media/docs"},
+		{"out of another namespace", "shared/media/albums", "albums"},
+		{"holding another namespace", "shared", "moved"},
+		{"namespace root itself", "shared/media", "media"},
+	}
+	for _, tt := range tests {
+		err := a.RenameDir(context.Background(), &dirsRepositoryAdapterPort.RenameDirData{
+			OldPath: tt.oldPath,
+			NewPath: tt.newPath,
+		})
+		if !errors.Is(err, dirsRepositoryAdapterPort.ErrCrossNamespace) {
+			t.Errorf("%s: RenameDir(%q, %q) = %v, want ErrCrossNamespace", tt.name, tt.oldPath, tt.newPath, err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, tt.oldPath)); err != nil {
+			t.Errorf("%s: %s after rejected rename: %v", tt.name, tt.oldPath, err)
+		}
+	}
+
+	// Renames within one namespace are still allowed
+	if err := a.RenameDir(context.Background(), &dirsRepositoryAdapterPort.RenameDirData{
+		OldPath: "shared/media/albums",
+		NewPath: "shared/media/collections",
+	}); err != nil {
+		t.Errorf("RenameDir within media: %v", err)
+	}
+}
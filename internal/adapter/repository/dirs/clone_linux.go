@@ -0,0 +1,21 @@
+//go:build linux
+
+package adapter
+
+import (
+	"os"
+	"syscall"
+)
+
+// FICLONE ioctl request number (linux/fs.h)
+const ficlone = 0x40049409
+
+// Share the data blocks of src with dst (reflink) on filesystems supporting
+// it, such as XFS and Btrfs
+func cloneFile(dst, src *os.File) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, dst.Fd(), ficlone, src.Fd())
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
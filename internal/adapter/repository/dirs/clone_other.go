@@ -0,0 +1,13 @@
+//go:build !linux
+
+package adapter
+
+import (
+	"errors"
+	"os"
+)
+
+// Reflinks are only supported on linux
+func cloneFile(dst, src *os.File) error {
+	return errors.ErrUnsupported
+}
@@ -0,0 +1,165 @@
+package adapter
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	dirsRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/dirs"
+	"github.com/flash-go/files-service/internal/storefs"
+)
+
+// Maximum number of entries hashed by a single digest
+const maxDigestEntries = 100000
+
+/*
+DigestDir computes a deterministic SHA-256 digest over the whole subtree of a
+directory inside the adapter's base path, so two trees (or two states of one
+tree) can be compared with a single value.
+
+ 1. Rejects paths that traverse outside the base directory, resolves the
+    absolute path and ensures it is inside storeLocalRootPath.
+ 2. Walks through parent directories to reject symlinked path components and
+    confirms the target exists and is a directory.
+ 3. Walks the subtree, skipping symlinks and names matching hiddenNames. At
+    most maxDigestEntries entries are hashed (ErrDirTooLarge otherwise).
+ 4. Builds one record per entry and hashes the records sorted by path.
+
+Records use slash-separated paths relative to the digested directory (so the
+digest does not depend on where the tree lives) and are encoded as:
+
+| Entry | Record                                            |
+|-------|---------------------------------------------------|
+| Dir   | "D" + path + "\x00" + "\n"                        |
+| File  | "F" + path + "\x00" + hex(sha256(content)) + "\n" |
+
+Records are sorted by path as raw bytes and fed to SHA-256 in that order; the
+digest is the lowercase hex of the result. An empty directory digests to the
+SHA-256 of the empty string. Path names cannot contain NUL, so the encoding is
+unambiguous.
+*/
+func (a *adapter) DigestDir(ctx context.Context, data *dirsRepositoryAdapterPort.DigestDirData) (*dirsRepositoryAdapterPort.DigestDirResult, error) {
+	// Validate input path
+	cleanPath := filepath.Clean(data.Path)
+	if strings.HasPrefix(cleanPath, "..") {
+		return nil, dirsRepositoryAdapterPort.ErrPathEscape
+	}
+
+	// Resolve absolute paths
+	baseAbs, err := filepath.Abs(a.storeLocalRootPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve base path: %w", err)
+	}
+	targetAbs, err := filepath.Abs(filepath.Join(baseAbs, cleanPath))
+	if err != nil {
+		return nil, dirsRepositoryAdapterPort.ErrInvalidPath
+	}
+
+	// Ensure targetAbs is inside baseAbs
+	if rel, err := filepath.Rel(baseAbs, targetAbs); err != nil || strings.HasPrefix(rel, "..") {
+		return nil, dirsRepositoryAdapterPort.ErrPathEscape
+	}
+
+	// Check parent directories for symlinks
+	if err := checkParents(baseAbs, targetAbs); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, dirsRepositoryAdapterPort.ErrDirNotFound
+		}
+		return nil, err
+	}
+
+	// Check that the target exists and is a directory
+	info, err := os.Stat(targetAbs)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, dirsRepositoryAdapterPort.ErrDirNotFound
+		}
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, dirsRepositoryAdapterPort.ErrInvalidPath
+	}
+
+	// Build records
+	type record struct {
+		path string
+		line string
+	}
+	var records []record
+	result := dirsRepositoryAdapterPort.DigestDirResult{}
+	err = filepath.WalkDir(targetAbs, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == targetAbs {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.Type()&os.ModeSymlink != 0 || storefs.Hidden(a.hiddenNames, d.Name()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if len(records) >= maxDigestEntries {
+			return dirsRepositoryAdapterPort.ErrDirTooLarge
+		}
+		rel, err := filepath.Rel(targetAbs, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		switch {
+		case d.IsDir():
+			records = append(records, record{rel, "D" + rel + "\x00\n"})
+			result.Dirs++
+		case d.Type().IsRegular():
+			sum, err := fileDigest(p)
+			if err != nil {
+				return err
+			}
+			records = append(records, record{rel, "F" + rel + "\x00" + sum + "\n"})
+			result.Files++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Hash records sorted by path
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].path < records[j].path
+	})
+	h := sha256.New()
+	for _, r := range records {
+		io.WriteString(h, r.line)
+	}
+	result.Digest = hex.EncodeToString(h.Sum(nil))
+
+	return &result, nil
+}
+
+// Return the hex SHA-256 of a file's content
+func fileDigest(p string) (string, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,199 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	dirsRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/dirs"
+)
+
+/*
+EmptyDir removes every entry of a directory inside the adapter's base path
+while keeping the directory itself.
+
+ 1. Rejects empty paths, the root of the base directory and paths that
+    traverse outside it, then walks through parent directories to reject
+    symlinked path components.
+ 2. Confirms the directory exists and is a directory (not a symlink).
+ 3. Applies the same guards as DeleteDir before removing anything: the tree
+    must not be nested deeper than maxDepth and every symlink in it must
+    resolve inside the base directory.
+ 4. Removes the tree bottom-up, reading at most deleteBatchSize entries of a
+    directory at a time. Symlinks are removed, never followed.
+ 5. Stops with the context error once ctx is done. Entries removed until then
+    stay removed.
+
+Without BestEffort the first entry that cannot be removed aborts the call with
+its error. With BestEffort removal goes on, and every entry that could not be
+removed is reported with its slash-separated path relative to the base
+directory and the reason. Directories left non-empty by a failure below them
+are not reported on their own.
+
+Files counts removed non-directory entries (symlinks included) and Dirs counts
+removed subdirectories.
+*/
+func (a *adapter) EmptyDir(ctx context.Context, data *dirsRepositoryAdapterPort.EmptyDirData) (*dirsRepositoryAdapterPort.EmptyDirResult, error) {
+	// Validate input path
+	if data.Path == "" {
+		return nil, dirsRepositoryAdapterPort.ErrInvalidPath
+	}
+	cleanPath := filepath.Clean(data.Path)
+	if cleanPath == "." || cleanPath == "/" {
+		return nil, dirsRepositoryAdapterPort.ErrInvalidPath
+	}
+	if strings.HasPrefix(cleanPath, "..") {
+		return nil, dirsRepositoryAdapterPort.ErrPathEscape
+	}
+
+	// Resolve absolute paths
+	baseAbs, err := filepath.Abs(a.storeLocalRootPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve base path: %w", err)
+	}
+	targetAbs, err := filepath.Abs(filepath.Join(baseAbs, cleanPath))
+	if err != nil {
+		return nil, dirsRepositoryAdapterPort.ErrInvalidPath
+	}
+
+	// Ensure targetAbs is inside baseAbs
+	relToBase, err := filepath.Rel(baseAbs, targetAbs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute relative path: %w", err)
+	}
+	if relToBase == "." {
+		return nil, dirsRepositoryAdapterPort.ErrInvalidPath
+	}
+	if strings.HasPrefix(relToBase, "..") {
+		return nil, dirsRepositoryAdapterPort.ErrPathEscape
+	}
+
+	// Check parent directories for symlinks (symlink race prevention)
+	if err := checkParents(baseAbs, targetAbs); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, dirsRepositoryAdapterPort.ErrDirNotFound
+		}
+		return nil, err
+	}
+
+	// Check that the target exists and is a directory
+	info, err := os.Lstat(targetAbs)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, dirsRepositoryAdapterPort.ErrDirNotFound
+		}
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, dirsRepositoryAdapterPort.ErrInvalidPath
+	}
+
+	// Walk through and check for symlinks and depth
+	if _, err := checkTree(ctx, baseAbs, targetAbs); err != nil {
+		return nil, err
+	}
+
+	// Remove contents
+	e := emptier{
+		ctx:        ctx,
+		baseAbs:    baseAbs,
+		bestEffort: data.BestEffort,
+		result: dirsRepositoryAdapterPort.EmptyDirResult{
+			Failed: []dirsRepositoryAdapterPort.EmptyDirFailure{},
+		},
+	}
+	if _, err := e.empty(targetAbs); err != nil {
+		return nil, err
+	}
+
+	return &e.result, nil
+}
+
+// State of a single EmptyDir removal
+type emptier struct {
+	ctx        context.Context
+	baseAbs    string
+	bestEffort bool
+	result     dirsRepositoryAdapterPort.EmptyDirResult
+}
+
+// Remove every entry of dirAbs bottom-up and report whether it was left
+// empty. Returns an error only when the removal must stop: on cancellation,
+// or on the first failure without bestEffort.
+func (e *emptier) empty(dirAbs string) (bool, error) {
+	f, err := os.Open(dirAbs)
+	if err != nil {
+		return false, e.fail(dirAbs, err)
+	}
+	defer f.Close()
+
+	emptied := true
+	for {
+		if err := e.ctx.Err(); err != nil {
+			return false, err
+		}
+		batch, err := f.ReadDir(deleteBatchSize)
+		if err != nil && err != io.EOF {
+			return false, e.fail(dirAbs, err)
+		}
+		if len(batch) == 0 {
+			return emptied, nil
+		}
+		for _, entry := range batch {
+			entryAbs := filepath.Join(dirAbs, entry.Name())
+			if entry.IsDir() {
+				empty, err := e.empty(entryAbs)
+				if err != nil {
+					return false, err
+				}
+				if !empty {
+					emptied = false
+					continue
+				}
+			}
+			if err := os.Remove(entryAbs); err != nil && !os.IsNotExist(err) {
+				emptied = false
+				if err := e.fail(entryAbs, err); err != nil {
+					return false, err
+				}
+				continue
+			}
+			if entry.IsDir() {
+				e.result.Dirs++
+			} else {
+				e.result.Files++
+			}
+		}
+	}
+}
+
+// Record an entry that could not be removed, or return the error to stop
+// the removal without bestEffort
+func (e *emptier) fail(entryAbs string, err error) error {
+	if !e.bestEffort {
+		return err
+	}
+	rel, _ := filepath.Rel(e.baseAbs, entryAbs)
+	e.result.Failed = append(e.result.Failed, dirsRepositoryAdapterPort.EmptyDirFailure{
+		Path:  filepath.ToSlash(rel),
+		Error: emptyError(err),
+	})
+	return nil
+}
+
+// Describe why an entry could not be removed
+func emptyError(err error) string {
+	switch {
+	case os.IsPermission(err):
+		return "permission_denied"
+	case os.IsNotExist(err):
+		return "not_found"
+	default:
+		return "unremovable"
+	}
+}
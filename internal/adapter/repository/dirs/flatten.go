@@ -0,0 +1,265 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	dirsRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/dirs"
+	"github.com/flash-go/files-service/internal/storefs"
+)
+
+// Maximum number of files moved by a single flatten
+const maxFlattenFiles = 100000
+
+// Maximum numbered suffix tried when renaming a colliding file
+const maxFlattenSuffix = 1000
+
+// Reported when a flattened file cannot take its destination name
+var errNameTaken = errors.New("name taken")
+
+/*
+FlattenDir moves every file below a directory inside the adapter's base path
+into a single target directory (the directory itself if TargetPath is empty).
+
+ 1. Rejects paths that traverse outside the base directory, resolves both
+    absolute paths and ensures they are inside storeLocalRootPath.
+ 2. Walks through parent directories to reject symlinked path components and
+    confirms both the directory and the target exist and are directories.
+ 3. Walks the subtree without following symlinks, skipping names matching
+    hiddenNames, and collects regular files not already directly inside the
+    target. At most maxFlattenFiles files are moved (ErrDirTooLarge otherwise).
+ 4. Names each file after its base name or, if PrefixNames is set, after its
+    path relative to the directory with separators replaced by "_".
+ 5. Resolves name collisions in the target according to OnConflict (see
+    below, "rename" if empty). Existing entries that are not regular files
+    are never replaced.
+ 6. Re-checks the parents of the file and the target for symlinks before
+    every move and honors dirMaxEntries for the target.
+ 7. Stops with the context error once ctx is done.
+
+Collision policies (file "a/b/c.txt", target already holds "c.txt"):
+
+| OnConflict  | Result                                        |
+|-------------|-----------------------------------------------|
+| "rename"    | Moved to "c-1.txt" (first free suffix)        |
+| "skip"      | Left in place, reported as skipped            |
+| "overwrite" | Moved to "c.txt", replacing the existing file |
+
+Emptied subdirectories are left in place. Paths in the result are
+slash-separated and relative to the base directory.
+*/
+func (a *adapter) FlattenDir(ctx context.Context, data *dirsRepositoryAdapterPort.FlattenDirData) (*dirsRepositoryAdapterPort.FlattenDirResult, error) {
+	switch data.OnConflict {
+	case "", dirsRepositoryAdapterPort.FlattenConflictRename,
+		dirsRepositoryAdapterPort.FlattenConflictSkip,
+		dirsRepositoryAdapterPort.FlattenConflictOverwrite:
+	default:
+		return nil, dirsRepositoryAdapterPort.ErrInvalidPolicy
+	}
+
+	// Validate input paths
+	sourceClean := filepath.Clean(data.Path)
+	targetClean := sourceClean
+	if data.TargetPath != "" {
+		targetClean = filepath.Clean(data.TargetPath)
+	}
+	if strings.HasPrefix(sourceClean, "..") || strings.HasPrefix(targetClean, "..") {
+		return nil, dirsRepositoryAdapterPort.ErrPathEscape
+	}
+
+	// Resolve absolute paths
+	baseAbs, err := filepath.Abs(a.storeLocalRootPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve base path: %w", err)
+	}
+	sourceAbs, err := filepath.Abs(filepath.Join(baseAbs, sourceClean))
+	if err != nil {
+		return nil, dirsRepositoryAdapterPort.ErrInvalidPath
+	}
+	targetAbs, err := filepath.Abs(filepath.Join(baseAbs, targetClean))
+	if err != nil {
+		return nil, dirsRepositoryAdapterPort.ErrInvalidPath
+	}
+
+	// Ensure both paths are inside baseAbs and are directories
+	for _, p := range []string{sourceAbs, targetAbs} {
+		if rel, err := filepath.Rel(baseAbs, p); err != nil || strings.HasPrefix(rel, "..") {
+			return nil, dirsRepositoryAdapterPort.ErrPathEscape
+		}
+		if err := checkParents(baseAbs, p); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil, dirsRepositoryAdapterPort.ErrDirNotFound
+			}
+			return nil, err
+		}
+		info, err := os.Stat(p)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, dirsRepositoryAdapterPort.ErrDirNotFound
+			}
+			return nil, err
+		}
+		if !info.IsDir() {
+			return nil, dirsRepositoryAdapterPort.ErrInvalidPath
+		}
+	}
+
+	// Collect files to move
+	var files []string
+	err = filepath.WalkDir(sourceAbs, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == sourceAbs {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.Type()&os.ModeSymlink != 0 || storefs.Hidden(a.hiddenNames, d.Name()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || filepath.Dir(p) == targetAbs {
+			return nil
+		}
+		if len(files) >= maxFlattenFiles {
+			return dirsRepositoryAdapterPort.ErrDirTooLarge
+		}
+		files = append(files, p)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Move files
+	result := dirsRepositoryAdapterPort.FlattenDirResult{
+		Files: []dirsRepositoryAdapterPort.FlattenedFile{},
+	}
+	for _, fileAbs := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		rel, _ := filepath.Rel(sourceAbs, fileAbs)
+		name := filepath.Base(fileAbs)
+		if data.PrefixNames {
+			name = strings.ReplaceAll(rel, string(filepath.Separator), "_")
+		}
+		oldRel, _ := filepath.Rel(baseAbs, fileAbs)
+		file := dirsRepositoryAdapterPort.FlattenedFile{
+			OldPath: filepath.ToSlash(oldRel),
+		}
+		newAbs, status, err := a.flattenFile(baseAbs, fileAbs, targetAbs, name, data.OnConflict)
+		if err != nil {
+			e := flattenError(err)
+			file.Status = dirsRepositoryAdapterPort.FlattenStatusFailed
+			file.Error = &e
+		} else {
+			file.Status = status
+			if newAbs != "" {
+				newRel, _ := filepath.Rel(baseAbs, newAbs)
+				newPath := filepath.ToSlash(newRel)
+				file.NewPath = &newPath
+			}
+		}
+		result.Files = append(result.Files, file)
+	}
+
+	return &result, nil
+}
+
+// Move one file into the target directory under name, resolving a collision
+// according to onConflict. Returns the new absolute path (empty if skipped)
+// and the resulting status.
+func (a *adapter) flattenFile(baseAbs, fileAbs, targetAbs, name, onConflict string) (string, string, error) {
+	// Check parent directories for symlinks (symlink race prevention)
+	for _, p := range []string{filepath.Dir(fileAbs), targetAbs} {
+		if err := checkParents(baseAbs, p); err != nil {
+			return "", "", parentsError(err)
+		}
+	}
+	if info, err := os.Lstat(fileAbs); err != nil {
+		return "", "", err
+	} else if !info.Mode().IsRegular() {
+		return "", "", dirsRepositoryAdapterPort.ErrInvalidPath
+	}
+
+	// Resolve the destination name
+	status := dirsRepositoryAdapterPort.FlattenStatusMoved
+	newAbs := filepath.Join(targetAbs, name)
+	existing, err := os.Lstat(newAbs)
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return "", "", err
+	case onConflict == dirsRepositoryAdapterPort.FlattenConflictSkip:
+		return "", dirsRepositoryAdapterPort.FlattenStatusSkipped, nil
+	case onConflict == dirsRepositoryAdapterPort.FlattenConflictOverwrite:
+		if !existing.Mode().IsRegular() {
+			return "", "", errNameTaken
+		}
+		status = dirsRepositoryAdapterPort.FlattenStatusOverwritten
+	default:
+		if newAbs, err = freeName(targetAbs, name); err != nil {
+			return "", "", err
+		}
+	}
+
+	// Check target capacity
+	if status != dirsRepositoryAdapterPort.FlattenStatusOverwritten {
+		if full, err := storefs.DirFull(targetAbs, a.dirMaxEntries); err != nil {
+			return "", "", err
+		} else if full {
+			return "", "", dirsRepositoryAdapterPort.ErrDirFull
+		}
+	}
+
+	if err := os.Rename(fileAbs, newAbs); err != nil {
+		return "", "", err
+	}
+	return newAbs, status, nil
+}
+
+// Find the first "name-N.ext" not present in dirAbs
+func freeName(dirAbs, name string) (string, error) {
+	ext := filepath.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	for i := 1; i <= maxFlattenSuffix; i++ {
+		candidate := filepath.Join(dirAbs, stem+"-"+strconv.Itoa(i)+ext)
+		if _, err := os.Lstat(candidate); os.IsNotExist(err) {
+			return candidate, nil
+		} else if err != nil {
+			return "", err
+		}
+	}
+	return "", errNameTaken
+}
+
+// Describe why a file could not be moved
+func flattenError(err error) string {
+	switch {
+	case errors.Is(err, dirsRepositoryAdapterPort.ErrInvalidPath):
+		return "invalid_path"
+	case errors.Is(err, dirsRepositoryAdapterPort.ErrPathEscape):
+		return "path_escape"
+	case errors.Is(err, dirsRepositoryAdapterPort.ErrDirFull):
+		return "dir_full"
+	case errors.Is(err, errNameTaken):
+		return "name_taken"
+	case os.IsPermission(err):
+		return "permission_denied"
+	case os.IsNotExist(err):
+		return "not_found"
+	default:
+		return "unmovable"
+	}
+}
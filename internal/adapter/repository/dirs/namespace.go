@@ -0,0 +1,131 @@
+package adapter
+
+import (
+	"context"
+
+	"github.com/flash-go/files-service/internal/namespace"
+	dirsRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/dirs"
+)
+
+// Repository decorator routing every call to the repository of the store
+// namespace selected in the context (see namespace.WithName), or to the
+// default repository if none is selected.
+type namespaceAdapter struct {
+	fallback   dirsRepositoryAdapterPort.Interface
+	namespaces map[string]dirsRepositoryAdapterPort.Interface
+}
+
+func NewNamespaced(fallback dirsRepositoryAdapterPort.Interface, namespaces map[string]dirsRepositoryAdapterPort.Interface) dirsRepositoryAdapterPort.Interface {
+	return &namespaceAdapter{
+		fallback:   fallback,
+		namespaces: namespaces,
+	}
+}
+
+// Return the repository of the namespace selected in ctx
+func (n *namespaceAdapter) repository(ctx context.Context) (dirsRepositoryAdapterPort.Interface, error) {
+	name := namespace.Name(ctx)
+	if name == "" {
+		return n.fallback, nil
+	}
+	if repository, ok := n.namespaces[name]; ok {
+		return repository, nil
+	}
+	return nil, namespace.ErrUnknownNamespace
+}
+
+func (n *namespaceAdapter) CreateDir(ctx context.Context, data *dirsRepositoryAdapterPort.CreateDirData) (*dirsRepositoryAdapterPort.CreateDirResult, error) {
+	repository, err := n.repository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repository.CreateDir(ctx, data)
+}
+
+func (n *namespaceAdapter) DeleteDir(ctx context.Context, data *dirsRepositoryAdapterPort.DeleteDirData) error {
+	repository, err := n.repository(ctx)
+	if err != nil {
+		return err
+	}
+	return repository.DeleteDir(ctx, data)
+}
+
+func (n *namespaceAdapter) RenameDir(ctx context.Context, data *dirsRepositoryAdapterPort.RenameDirData) error {
+	repository, err := n.repository(ctx)
+	if err != nil {
+		return err
+	}
+	return repository.RenameDir(ctx, data)
+}
+
+func (n *namespaceAdapter) GetDirTree(ctx context.Context, data *dirsRepositoryAdapterPort.GetDirTreeData) (*dirsRepositoryAdapterPort.DirTreeResult, error) {
+	repository, err := n.repository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repository.GetDirTree(ctx, data)
+}
+
+func (n *namespaceAdapter) SnapshotDir(ctx context.Context, data *dirsRepositoryAdapterPort.SnapshotDirData) (*dirsRepositoryAdapterPort.SnapshotDirResult, error) {
+	repository, err := n.repository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repository.SnapshotDir(ctx, data)
+}
+
+func (n *namespaceAdapter) PruneDirs(ctx context.Context, data *dirsRepositoryAdapterPort.PruneDirsData) (*dirsRepositoryAdapterPort.PruneDirsResult, error) {
+	repository, err := n.repository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repository.PruneDirs(ctx, data)
+}
+
+func (n *namespaceAdapter) DigestDir(ctx context.Context, data *dirsRepositoryAdapterPort.DigestDirData) (*dirsRepositoryAdapterPort.DigestDirResult, error) {
+	repository, err := n.repository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repository.DigestDir(ctx, data)
+}
+
+func (n *namespaceAdapter) FlattenDir(ctx context.Context, data *dirsRepositoryAdapterPort.FlattenDirData) (*dirsRepositoryAdapterPort.FlattenDirResult, error) {
+	repository, err := n.repository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repository.FlattenDir(ctx, data)
+}
+
+func (n *namespaceAdapter) EmptyDir(ctx context.Context, data *dirsRepositoryAdapterPort.EmptyDirData) (*dirsRepositoryAdapterPort.EmptyDirResult, error) {
+	repository, err := n.repository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repository.EmptyDir(ctx, data)
+}
+
+func (n *namespaceAdapter) GetDirSize(ctx context.Context, data *dirsRepositoryAdapterPort.GetDirSizeData) (*dirsRepositoryAdapterPort.DirSizeResult, error) {
+	repository, err := n.repository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repository.GetDirSize(ctx, data)
+}
+
+func (n *namespaceAdapter) RefreshDirSizes(ctx context.Context, data *dirsRepositoryAdapterPort.RefreshDirSizesData) (*dirsRepositoryAdapterPort.DirSizeResult, error) {
+	repository, err := n.repository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repository.RefreshDirSizes(ctx, data)
+}
+
+func (n *namespaceAdapter) InvalidateDirSizes(ctx context.Context, data *dirsRepositoryAdapterPort.InvalidateDirSizesData) error {
+	repository, err := n.repository(ctx)
+	if err != nil {
+		return err
+	}
+	return repository.InvalidateDirSizes(ctx, data)
+}
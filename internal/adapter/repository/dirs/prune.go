@@ -0,0 +1,147 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	dirsRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/dirs"
+	"github.com/flash-go/files-service/internal/storefs"
+)
+
+/*
+PruneDirs deletes the subdirectories of a directory inside the adapter's base
+path that were last modified before ModifiedBefore.
+
+ 1. Rejects paths that traverse outside the base directory, resolves the
+    absolute path and ensures it is inside storeLocalRootPath.
+ 2. Walks through parent directories to reject symlinked path components and
+    confirms the target exists and is a directory.
+ 3. Considers direct child directories only; files, symlinks and names
+    matching hiddenNames are left alone.
+ 4. A child is old when its own mtime is before ModifiedBefore. If
+    WithContents is set, every entry below it must be old as well, so a
+    directory that just received a file survives even if its own mtime is old.
+ 5. Old children are deleted through DeleteDir, so all its safety checks
+    (symlink escapes, depth limit) apply. Children that are fresh or fail
+    those checks are reported as Skipped.
+ 6. If DryRun is set, nothing is deleted; Deleted lists what would be.
+
+Paths in the result are slash-separated and relative to the base directory.
+*/
+func (a *adapter) PruneDirs(ctx context.Context, data *dirsRepositoryAdapterPort.PruneDirsData) (*dirsRepositoryAdapterPort.PruneDirsResult, error) {
+	// Validate input path
+	cleanPath := filepath.Clean(data.Path)
+	if strings.HasPrefix(cleanPath, "..") {
+		return nil, dirsRepositoryAdapterPort.ErrPathEscape
+	}
+
+	// Resolve absolute paths
+	baseAbs, err := filepath.Abs(a.storeLocalRootPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve base path: %w", err)
+	}
+	targetAbs, err := filepath.Abs(filepath.Join(baseAbs, cleanPath))
+	if err != nil {
+		return nil, dirsRepositoryAdapterPort.ErrInvalidPath
+	}
+
+	// Ensure targetAbs is inside baseAbs
+	relToBase, err := filepath.Rel(baseAbs, targetAbs)
+	if err != nil || strings.HasPrefix(relToBase, "..") {
+		return nil, dirsRepositoryAdapterPort.ErrPathEscape
+	}
+
+	// Check parent directories for symlinks
+	if err := checkParents(baseAbs, targetAbs); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, dirsRepositoryAdapterPort.ErrDirNotFound
+		}
+		return nil, err
+	}
+
+	// Check that the target exists and is a directory
+	info, err := os.Stat(targetAbs)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, dirsRepositoryAdapterPort.ErrDirNotFound
+		}
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, dirsRepositoryAdapterPort.ErrInvalidPath
+	}
+
+	entries, err := os.ReadDir(targetAbs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := dirsRepositoryAdapterPort.PruneDirsResult{
+		Deleted: []string{},
+		Skipped: []string{},
+	}
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if entry.Type()&os.ModeSymlink != 0 || !entry.IsDir() || storefs.Hidden(a.hiddenNames, entry.Name()) {
+			continue
+		}
+		rel := filepath.ToSlash(filepath.Join(relToBase, entry.Name()))
+
+		// Check age
+		old, err := olderThan(filepath.Join(targetAbs, entry.Name()), data.ModifiedBefore, data.WithContents)
+		if err != nil || !old {
+			result.Skipped = append(result.Skipped, rel)
+			continue
+		}
+
+		// Delete
+		if !data.DryRun {
+			if err := a.DeleteDir(ctx, &dirsRepositoryAdapterPort.DeleteDirData{Path: rel}); err != nil {
+				result.Skipped = append(result.Skipped, rel)
+				continue
+			}
+		}
+		result.Deleted = append(result.Deleted, rel)
+	}
+
+	return &result, nil
+}
+
+// Report whether a directory (and, if withContents is set, every entry below
+// it) was last modified before the given time
+func olderThan(dirAbs string, before time.Time, withContents bool) (bool, error) {
+	info, err := os.Lstat(dirAbs)
+	if err != nil {
+		return false, err
+	}
+	if !info.ModTime().Before(before) {
+		return false, nil
+	}
+	if !withContents {
+		return true, nil
+	}
+	old := true
+	err = filepath.WalkDir(dirAbs, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if !info.ModTime().Before(before) {
+			old = false
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return old, err
+}
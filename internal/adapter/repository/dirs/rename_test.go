@@ -0,0 +1,58 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	dirsRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/dirs"
+)
+
+func TestRenameDirOntoItself(t *testing.T) {
+	tests := []struct {
+		name    string
+		newPath string
+		noop    bool
+		other   bool
+		want    error
+		exist   []string
+	}{
+		{"identical path", "photos", false, false, dirsRepositoryAdapterPort.ErrSamePath, []string{"photos"}},
+		{"identical path as no-op", "photos", true, false, nil, []string{"photos"}},
+		{"equivalent spelling", "./docs/../photos/", false, false, dirsRepositoryAdapterPort.ErrSamePath, []string{"photos"}},
+		{"equivalent spelling as no-op", "./docs/../photos/", true, false, nil, []string{"photos"}},
+		{"case variant", "Photos", false, false, nil, []string{"Photos"}},
+		{"case variant of another dir", "Photos", false, true, dirsRepositoryAdapterPort.ErrDirNewExist, []string{"photos", "Photos"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, name := range []string{"docs", "photos"} {
+				if err := os.Mkdir(filepath.Join(dir, name), 0700); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.other {
+				if err := os.Mkdir(filepath.Join(dir, "Photos"), 0700); err != nil {
+					t.Fatal(err)
+				}
+			}
+			a := &adapter{storeLocalRootPath: dir, renameSamePathNoop: tt.noop}
+
+			err := a.RenameDir(context.Background(), &dirsRepositoryAdapterPort.RenameDirData{
+				OldPath: "photos",
+				NewPath: tt.newPath,
+			})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("RenameDir = %v, want %v", err, tt.want)
+			}
+			for _, name := range tt.exist {
+				if info, err := os.Stat(filepath.Join(dir, name)); err != nil || !info.IsDir() {
+					t.Errorf("%s after rename: %v", name, err)
+				}
+			}
+		})
+	}
+}
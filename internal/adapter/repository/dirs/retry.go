@@ -0,0 +1,132 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"syscall"
+	"time"
+
+	dirsRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/dirs"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Longest wait between two attempts of a call
+const maxRetryBackoff = 5 * time.Second
+
+// Errors of a storage backend that is momentarily unavailable (e.g. an NFS
+// server restarting or a throttled FUSE mount), worth trying again
+var transientErrors = []error{
+	syscall.EAGAIN,
+	syscall.EINTR,
+	syscall.EBUSY,
+	syscall.ETIMEDOUT,
+	syscall.ESTALE,
+}
+
+// Repository decorator retrying the calls that only read the store when they
+// fail with a transient error. Calls that change the store are passed through
+// unchanged, as retrying a partly applied change is not safe.
+type retryAdapter struct {
+	next   dirsRepositoryAdapterPort.Interface
+	policy retryPolicy
+}
+
+// Number of retries of a failed call, the wait before the first one (doubled
+// before each next one, up to maxRetryBackoff) and the counter of retries
+type retryPolicy struct {
+	attempts int
+	backoff  time.Duration
+	counter  metric.Int64Counter
+}
+
+// Report whether an error is transient. Not found, exists, permission and
+// validation errors never are.
+func transient(err error) bool {
+	for _, target := range transientErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Run fn, running it again up to policy.attempts times while it fails with a
+// transient error. The waits are jittered between half and all of the
+// backoff, and cut short when ctx is done, returning the last error. Every
+// retry is counted with the operation name as attribute.
+func withRetry[T any](ctx context.Context, policy *retryPolicy, operation string, fn func() (T, error)) (T, error) {
+	value, err := fn()
+	backoff := policy.backoff
+	for attempt := 0; attempt < policy.attempts && err != nil && transient(err); attempt++ {
+		wait := backoff/2 + rand.N(backoff/2+1)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return value, err
+		case <-timer.C:
+		}
+		if policy.counter != nil {
+			policy.counter.Add(ctx, 1, metric.WithAttributes(attribute.String("operation", operation)))
+		}
+		value, err = fn()
+		backoff = min(backoff*2, maxRetryBackoff)
+	}
+	return value, err
+}
+
+func (r *retryAdapter) CreateDir(ctx context.Context, data *dirsRepositoryAdapterPort.CreateDirData) (*dirsRepositoryAdapterPort.CreateDirResult, error) {
+	return r.next.CreateDir(ctx, data)
+}
+
+func (r *retryAdapter) DeleteDir(ctx context.Context, data *dirsRepositoryAdapterPort.DeleteDirData) error {
+	return r.next.DeleteDir(ctx, data)
+}
+
+func (r *retryAdapter) RenameDir(ctx context.Context, data *dirsRepositoryAdapterPort.RenameDirData) error {
+	return r.next.RenameDir(ctx, data)
+}
+
+func (r *retryAdapter) GetDirTree(ctx context.Context, data *dirsRepositoryAdapterPort.GetDirTreeData) (*dirsRepositoryAdapterPort.DirTreeResult, error) {
+	return withRetry(ctx, &r.policy, "GetDirTree", func() (*dirsRepositoryAdapterPort.DirTreeResult, error) {
+		return r.next.GetDirTree(ctx, data)
+	})
+}
+
+func (r *retryAdapter) SnapshotDir(ctx context.Context, data *dirsRepositoryAdapterPort.SnapshotDirData) (*dirsRepositoryAdapterPort.SnapshotDirResult, error) {
+	return r.next.SnapshotDir(ctx, data)
+}
+
+func (r *retryAdapter) PruneDirs(ctx context.Context, data *dirsRepositoryAdapterPort.PruneDirsData) (*dirsRepositoryAdapterPort.PruneDirsResult, error) {
+	return r.next.PruneDirs(ctx, data)
+}
+
+func (r *retryAdapter) DigestDir(ctx context.Context, data *dirsRepositoryAdapterPort.DigestDirData) (*dirsRepositoryAdapterPort.DigestDirResult, error) {
+	return withRetry(ctx, &r.policy, "DigestDir", func() (*dirsRepositoryAdapterPort.DigestDirResult, error) {
+		return r.next.DigestDir(ctx, data)
+	})
+}
+
+func (r *retryAdapter) FlattenDir(ctx context.Context, data *dirsRepositoryAdapterPort.FlattenDirData) (*dirsRepositoryAdapterPort.FlattenDirResult, error) {
+	return r.next.FlattenDir(ctx, data)
+}
+
+func (r *retryAdapter) EmptyDir(ctx context.Context, data *dirsRepositoryAdapterPort.EmptyDirData) (*dirsRepositoryAdapterPort.EmptyDirResult, error) {
+	return r.next.EmptyDir(ctx, data)
+}
+
+func (r *retryAdapter) GetDirSize(ctx context.Context, data *dirsRepositoryAdapterPort.GetDirSizeData) (*dirsRepositoryAdapterPort.DirSizeResult, error) {
+	return withRetry(ctx, &r.policy, "GetDirSize", func() (*dirsRepositoryAdapterPort.DirSizeResult, error) {
+		return r.next.GetDirSize(ctx, data)
+	})
+}
+
+func (r *retryAdapter) RefreshDirSizes(ctx context.Context, data *dirsRepositoryAdapterPort.RefreshDirSizesData) (*dirsRepositoryAdapterPort.DirSizeResult, error) {
+	return r.next.RefreshDirSizes(ctx, data)
+}
+
+func (r *retryAdapter) InvalidateDirSizes(ctx context.Context, data *dirsRepositoryAdapterPort.InvalidateDirSizesData) error {
+	return r.next.InvalidateDirSizes(ctx, data)
+}
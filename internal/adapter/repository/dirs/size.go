@@ -0,0 +1,313 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	dirsRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/dirs"
+	"github.com/flash-go/files-service/internal/storefs"
+)
+
+// Aggregate size of a directory subtree, with the cached sizes of its
+// subdirectories. A node stays in the tree after it is invalidated so its
+// children can still be reused, but its own totals are computed again.
+type sizeNode struct {
+	valid      bool
+	size       int64
+	files      int
+	dirs       int
+	computedAt time.Time
+	children   map[string]*sizeNode
+}
+
+// Cache of directory sizes, a tree of nodes keyed by path element
+type sizeCache struct {
+	mu   sync.Mutex
+	root *sizeNode
+	// Number of walks running, and the paths invalidated since the oldest
+	// of them started, replayed on their results once they are stored
+	walks       int
+	invalidated []string
+}
+
+func newSizeCache() *sizeCache {
+	return &sizeCache{root: &sizeNode{children: make(map[string]*sizeNode)}}
+}
+
+/*
+GetDirSize returns the aggregate size of a directory inside the adapter's
+base path: the total size and number of the regular files in its subtree and
+the number of its subdirectories.
+
+ 1. Rejects paths that traverse outside the base directory, resolves the
+    absolute path and ensures it is inside storeLocalRootPath ("" is the base
+    directory itself).
+ 2. Walks through parent directories to reject symlinked path components and
+    confirms the target exists and is a directory.
+ 3. Returns the cached size if it is still valid. Otherwise computes it,
+    reading only the directories whose size was invalidated since (see
+    InvalidateDirSizes) and reusing the cached sizes of the others, and
+    caches the result.
+
+Symlinks are not followed and names matching hiddenNames are skipped along
+with their contents. Sizes changed by other processes are only noticed by
+RefreshDirSizes.
+*/
+func (a *adapter) GetDirSize(ctx context.Context, data *dirsRepositoryAdapterPort.GetDirSizeData) (*dirsRepositoryAdapterPort.DirSizeResult, error) {
+	return a.dirSize(ctx, data.Path, true)
+}
+
+/*
+RefreshDirSizes computes the size of a directory like GetDirSize, but walks
+its whole subtree without using cached sizes and replaces them with the
+result. Run it on the base directory ("") to make later size queries of any
+directory instant.
+
+Paths invalidated while the walk runs stay invalid once its result is stored.
+*/
+func (a *adapter) RefreshDirSizes(ctx context.Context, data *dirsRepositoryAdapterPort.RefreshDirSizesData) (*dirsRepositoryAdapterPort.DirSizeResult, error) {
+	return a.dirSize(ctx, data.Path, false)
+}
+
+/*
+InvalidateDirSizes drops the cached sizes affected by a change of the given
+slash-separated paths relative to the base directory: the sizes of the path
+itself and everything below it, and the totals of each of its ancestors.
+Paths need not exist (e.g. a deleted file).
+*/
+func (a *adapter) InvalidateDirSizes(ctx context.Context, data *dirsRepositoryAdapterPort.InvalidateDirSizesData) error {
+	a.sizes.mu.Lock()
+	defer a.sizes.mu.Unlock()
+	for _, p := range data.Paths {
+		p = sizePath(p)
+		a.sizes.invalidate(p)
+		if a.sizes.walks > 0 {
+			a.sizes.invalidated = append(a.sizes.invalidated, p)
+		}
+	}
+	return nil
+}
+
+// Return the size of a directory, from the cache if reuse is set and it is
+// valid, else by walking it and caching the result
+func (a *adapter) dirSize(ctx context.Context, dirPath string, reuse bool) (*dirsRepositoryAdapterPort.DirSizeResult, error) {
+	// Validate input path
+	cleanPath := filepath.Clean(dirPath)
+	if strings.HasPrefix(cleanPath, "..") {
+		return nil, dirsRepositoryAdapterPort.ErrPathEscape
+	}
+
+	// Resolve absolute paths
+	baseAbs, err := filepath.Abs(a.storeLocalRootPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve base path: %w", err)
+	}
+	targetAbs, err := filepath.Abs(filepath.Join(baseAbs, cleanPath))
+	if err != nil {
+		return nil, dirsRepositoryAdapterPort.ErrInvalidPath
+	}
+
+	// Ensure targetAbs is inside baseAbs
+	rel, err := filepath.Rel(baseAbs, targetAbs)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return nil, dirsRepositoryAdapterPort.ErrPathEscape
+	}
+
+	// Check parent directories for symlinks
+	if err := checkParents(baseAbs, targetAbs); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, dirsRepositoryAdapterPort.ErrDirNotFound
+		}
+		return nil, err
+	}
+
+	// Check that the target exists and is a directory
+	info, err := os.Stat(targetAbs)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, dirsRepositoryAdapterPort.ErrDirNotFound
+		}
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, dirsRepositoryAdapterPort.ErrInvalidPath
+	}
+
+	key := sizePath(filepath.ToSlash(rel))
+	c := a.sizes
+
+	// Use the cached size
+	if reuse {
+		if node := c.lookup(key); node != nil {
+			return sizeResult(key, node), nil
+		}
+	}
+
+	// Walk the directory, then store the result and replay the paths
+	// invalidated meanwhile
+	c.mu.Lock()
+	c.walks++
+	start := len(c.invalidated)
+	c.mu.Unlock()
+
+	node, err := a.walkSize(ctx, targetAbs, key, reuse)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err == nil {
+		c.store(key, node)
+		for _, p := range c.invalidated[start:] {
+			c.invalidate(p)
+		}
+	}
+	c.walks--
+	if c.walks == 0 {
+		c.invalidated = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return sizeResult(key, node), nil
+}
+
+// Compute the size of a directory bottom-up. With reuse, valid cached sizes
+// of subdirectories are used instead of walking them. Subdirectories removed
+// during the walk are skipped.
+func (a *adapter) walkSize(ctx context.Context, dirAbs, key string, reuse bool) (*sizeNode, error) {
+	if reuse {
+		if node := a.sizes.lookup(key); node != nil {
+			return node, nil
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dirAbs)
+	if err != nil {
+		return nil, err
+	}
+	node := &sizeNode{
+		valid:    true,
+		children: make(map[string]*sizeNode),
+	}
+	for _, entry := range entries {
+		if storefs.Hidden(a.hiddenNames, entry.Name()) {
+			continue
+		}
+		switch {
+		case entry.Type().IsRegular():
+			info, err := entry.Info()
+			if err != nil {
+				// Removed since it was listed
+				continue
+			}
+			node.size += info.Size()
+			node.files++
+		case entry.IsDir():
+			child, err := a.walkSize(ctx, filepath.Join(dirAbs, entry.Name()), path.Join(key, entry.Name()), reuse)
+			if err != nil {
+				if os.IsNotExist(err) {
+					continue
+				}
+				return nil, err
+			}
+			node.children[entry.Name()] = child
+			node.size += child.size
+			node.files += child.files
+			node.dirs += child.dirs + 1
+		}
+	}
+	node.computedAt = time.Now()
+	return node, nil
+}
+
+// Return a copy of the cached node of a path if its size is valid
+func (c *sizeCache) lookup(key string) *sizeNode {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	node := c.root
+	for _, name := range sizeElems(key) {
+		if node = node.children[name]; node == nil {
+			return nil
+		}
+	}
+	if !node.valid {
+		return nil
+	}
+	copied := *node
+	return &copied
+}
+
+// Store the node of a path, invalidating its ancestors. The caller must hold
+// mu.
+func (c *sizeCache) store(key string, node *sizeNode) {
+	elems := sizeElems(key)
+	if len(elems) == 0 {
+		c.root = node
+		return
+	}
+	parent := c.root
+	for _, name := range elems[:len(elems)-1] {
+		parent.valid = false
+		child := parent.children[name]
+		if child == nil {
+			child = &sizeNode{children: make(map[string]*sizeNode)}
+			parent.children[name] = child
+		}
+		parent = child
+	}
+	parent.valid = false
+	parent.children[elems[len(elems)-1]] = node
+}
+
+// Drop the cached sizes of a path and its subtree and invalidate its
+// ancestors. The caller must hold mu.
+func (c *sizeCache) invalidate(key string) {
+	elems := sizeElems(key)
+	if len(elems) == 0 {
+		c.root = &sizeNode{children: make(map[string]*sizeNode)}
+		return
+	}
+	node := c.root
+	for _, name := range elems[:len(elems)-1] {
+		node.valid = false
+		if node = node.children[name]; node == nil {
+			return
+		}
+	}
+	node.valid = false
+	delete(node.children, elems[len(elems)-1])
+}
+
+// Normalize a slash-separated path into a cache key ("" is the base
+// directory)
+func sizePath(p string) string {
+	return strings.Trim(path.Clean("/"+p), "/")
+}
+
+// Split a cache key into path elements
+func sizeElems(key string) []string {
+	if key == "" {
+		return nil
+	}
+	return strings.Split(key, "/")
+}
+
+// Build the result of a cached node
+func sizeResult(key string, node *sizeNode) *dirsRepositoryAdapterPort.DirSizeResult {
+	return &dirsRepositoryAdapterPort.DirSizeResult{
+		Path:       key,
+		Size:       node.size,
+		Files:      node.files,
+		Dirs:       node.dirs,
+		ComputedAt: node.computedAt,
+	}
+}
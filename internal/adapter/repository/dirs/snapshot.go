@@ -0,0 +1,212 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	dirsRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/dirs"
+	"github.com/flash-go/files-service/internal/storefs"
+)
+
+// Maximum number of entries copied by a single snapshot
+const maxSnapshotEntries = 100000
+
+/*
+SnapshotDir creates a copy of a directory inside the adapter's base path,
+cloning file contents with reflinks where the filesystem supports it.
+
+This function performs the same path checks as RenameDir:
+
+ 1. Validates that both paths are non-empty and do not traverse outside the base directory.
+ 2. Resolves absolute paths and ensures both are inside storeLocalRootPath.
+ 3. Confirms the source exists and is a directory, and that the target does not exist.
+ 4. Checks parent directories of both paths for symlinks (symlink race prevention).
+ 5. Rejects the snapshot if the target's parent already holds dirMaxEntries entries.
+ 6. Copies the tree into a hidden temp directory next to the target and renames
+    it into place, so a failed snapshot never leaves a partial copy behind.
+    Symlinks are skipped and at most maxSnapshotEntries entries are copied
+    (ErrDirTooLarge otherwise).
+
+File contents are cloned with FICLONE while the filesystem supports it; on the
+first failure the remaining files are copied regularly. Strategy reports
+SnapshotStrategyReflink if every file was cloned and SnapshotStrategyCopy
+otherwise.
+
+| Old Path   | New Path         | Reason                               |
+|------------|------------------|--------------------------------------|
+| "projects" | "projects-2025"  | Inside base, target does not exist   |
+| "projects" | "projects/inner" | Rejected, target inside the source   |
+*/
+func (a *adapter) SnapshotDir(ctx context.Context, data *dirsRepositoryAdapterPort.SnapshotDirData) (*dirsRepositoryAdapterPort.SnapshotDirResult, error) {
+	// Validate input paths
+	if data.OldPath == "" || data.NewPath == "" {
+		return nil, dirsRepositoryAdapterPort.ErrInvalidPath
+	}
+	oldClean := filepath.Clean(data.OldPath)
+	newClean := filepath.Clean(data.NewPath)
+	if oldClean == "." || strings.HasPrefix(oldClean, "..") ||
+		newClean == "." || strings.HasPrefix(newClean, "..") {
+		return nil, dirsRepositoryAdapterPort.ErrInvalidPath
+	}
+
+	// Resolve absolute paths
+	baseAbs, err := filepath.Abs(a.storeLocalRootPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve base path: %w", err)
+	}
+	oldAbs, err := filepath.Abs(filepath.Join(baseAbs, oldClean))
+	if err != nil {
+		return nil, dirsRepositoryAdapterPort.ErrInvalidPath
+	}
+	newAbs, err := filepath.Abs(filepath.Join(baseAbs, newClean))
+	if err != nil {
+		return nil, dirsRepositoryAdapterPort.ErrInvalidPath
+	}
+
+	// Ensure old and new paths are inside base, and new is not inside old
+	relOld, err := filepath.Rel(baseAbs, oldAbs)
+	if err != nil || strings.HasPrefix(relOld, "..") {
+		return nil, dirsRepositoryAdapterPort.ErrPathEscape
+	}
+	relNew, err := filepath.Rel(baseAbs, newAbs)
+	if err != nil || strings.HasPrefix(relNew, "..") {
+		return nil, dirsRepositoryAdapterPort.ErrPathEscape
+	}
+	if rel, err := filepath.Rel(oldAbs, newAbs); err != nil || !strings.HasPrefix(rel, "..") {
+		return nil, dirsRepositoryAdapterPort.ErrPathEscape
+	}
+
+	// Check old directory exists
+	info, err := os.Lstat(oldAbs)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, dirsRepositoryAdapterPort.ErrDirOldNotFound
+		}
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, dirsRepositoryAdapterPort.ErrInvalidPath
+	}
+
+	// Check new directory does not exist
+	if _, err := os.Lstat(newAbs); err == nil {
+		return nil, dirsRepositoryAdapterPort.ErrDirNewExist
+	}
+
+	// Check for symlinks in parent directories of old and new
+	for _, path := range []string{oldAbs, newAbs} {
+		if err := checkParents(baseAbs, filepath.Dir(path)); err != nil {
+			return nil, parentsError(err)
+		}
+	}
+
+	// Check parent directory capacity
+	if full, err := storefs.DirFull(filepath.Dir(newAbs), a.dirMaxEntries); err != nil {
+		return nil, fmt.Errorf("failed to count entries: %w", err)
+	} else if full {
+		return nil, dirsRepositoryAdapterPort.ErrDirFull
+	}
+
+	// Copy into a temp directory next to the target
+	tempAbs, err := os.MkdirTemp(filepath.Dir(newAbs), "."+filepath.Base(newAbs)+".snapshot-*")
+	if err != nil {
+		return nil, err
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			os.RemoveAll(tempAbs)
+		}
+	}()
+
+	snapshot := snapshotCopier{reflink: true}
+	if err := snapshot.copyDir(oldAbs, tempAbs); err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(tempAbs, info.Mode().Perm()); err != nil {
+		return nil, err
+	}
+
+	// Move snapshot into place
+	if err := os.Rename(tempAbs, newAbs); err != nil {
+		return nil, err
+	}
+	committed = true
+
+	strategy := dirsRepositoryAdapterPort.SnapshotStrategyCopy
+	if snapshot.reflink && snapshot.cloned > 0 {
+		strategy = dirsRepositoryAdapterPort.SnapshotStrategyReflink
+	}
+	return &dirsRepositoryAdapterPort.SnapshotDirResult{
+		Strategy: strategy,
+	}, nil
+}
+
+// Recursive directory copy, cloning files until the filesystem refuses
+type snapshotCopier struct {
+	reflink bool
+	cloned  int
+	entries int
+}
+
+func (c *snapshotCopier) copyDir(srcAbs, dstAbs string) error {
+	return filepath.WalkDir(srcAbs, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(srcAbs, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		if c.entries++; c.entries > maxSnapshotEntries {
+			return dirsRepositoryAdapterPort.ErrDirTooLarge
+		}
+		target := filepath.Join(dstAbs, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return os.Mkdir(target, info.Mode().Perm())
+		case info.Mode().IsRegular():
+			return c.copyFile(path, target, info.Mode().Perm())
+		}
+		// Skip symlinks and special files
+		return nil
+	})
+}
+
+func (c *snapshotCopier) copyFile(srcAbs, dstAbs string, perm fs.FileMode) error {
+	src, err := os.Open(srcAbs)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(dstAbs, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	if c.reflink {
+		if err := cloneFile(dst, src); err == nil {
+			c.cloned++
+			return dst.Close()
+		}
+		c.reflink = false
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		return err
+	}
+	return dst.Close()
+}
@@ -0,0 +1,82 @@
+package adapter
+
+import (
+	"context"
+	"time"
+
+	"github.com/flash-go/files-service/internal/deadline"
+	dirsRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/dirs"
+)
+
+// Repository decorator bounding the calls that only read the store (stat,
+// list, open, hash) by operationTimeout. The call runs in its own goroutine
+// selected against the derived context, so a blocking syscall on a hung
+// network mount is abandoned (left to finish in the background) instead of
+// wedging the request. Writes pass straight through: an abandoned write
+// would keep changing the store after the request failed and released its
+// path lock, and uploads and copies are bounded by their own deadlines.
+type timeoutAdapter struct {
+	next             dirsRepositoryAdapterPort.Interface
+	operationTimeout time.Duration
+}
+
+// Run fn with a context bounded by timeout, see deadline.Call
+func withTimeout[T any](ctx context.Context, timeout time.Duration, fn func(ctx context.Context) (T, error)) (T, error) {
+	return deadline.Call(ctx, timeout, dirsRepositoryAdapterPort.ErrOperationTimeout, fn)
+}
+
+func (t *timeoutAdapter) CreateDir(ctx context.Context, data *dirsRepositoryAdapterPort.CreateDirData) (*dirsRepositoryAdapterPort.CreateDirResult, error) {
+	return t.next.CreateDir(ctx, data)
+}
+
+func (t *timeoutAdapter) DeleteDir(ctx context.Context, data *dirsRepositoryAdapterPort.DeleteDirData) error {
+	return t.next.DeleteDir(ctx, data)
+}
+
+func (t *timeoutAdapter) RenameDir(ctx context.Context, data *dirsRepositoryAdapterPort.RenameDirData) error {
+	return t.next.RenameDir(ctx, data)
+}
+
+func (t *timeoutAdapter) GetDirTree(ctx context.Context, data *dirsRepositoryAdapterPort.GetDirTreeData) (*dirsRepositoryAdapterPort.DirTreeResult, error) {
+	return withTimeout(ctx, t.operationTimeout, func(ctx context.Context) (*dirsRepositoryAdapterPort.DirTreeResult, error) {
+		return t.next.GetDirTree(ctx, data)
+	})
+}
+
+func (t *timeoutAdapter) SnapshotDir(ctx context.Context, data *dirsRepositoryAdapterPort.SnapshotDirData) (*dirsRepositoryAdapterPort.SnapshotDirResult, error) {
+	return t.next.SnapshotDir(ctx, data)
+}
+
+func (t *timeoutAdapter) PruneDirs(ctx context.Context, data *dirsRepositoryAdapterPort.PruneDirsData) (*dirsRepositoryAdapterPort.PruneDirsResult, error) {
+	return t.next.PruneDirs(ctx, data)
+}
+
+func (t *timeoutAdapter) DigestDir(ctx context.Context, data *dirsRepositoryAdapterPort.DigestDirData) (*dirsRepositoryAdapterPort.DigestDirResult, error) {
+	return withTimeout(ctx, t.operationTimeout, func(ctx context.Context) (*dirsRepositoryAdapterPort.DigestDirResult, error) {
+		return t.next.DigestDir(ctx, data)
+	})
+}
+
+func (t *timeoutAdapter) FlattenDir(ctx context.Context, data *dirsRepositoryAdapterPort.FlattenDirData) (*dirsRepositoryAdapterPort.FlattenDirResult, error) {
+	return t.next.FlattenDir(ctx, data)
+}
+
+func (t *timeoutAdapter) EmptyDir(ctx context.Context, data *dirsRepositoryAdapterPort.EmptyDirData) (*dirsRepositoryAdapterPort.EmptyDirResult, error) {
+	return t.next.EmptyDir(ctx, data)
+}
+
+func (t *timeoutAdapter) GetDirSize(ctx context.Context, data *dirsRepositoryAdapterPort.GetDirSizeData) (*dirsRepositoryAdapterPort.DirSizeResult, error) {
+	return withTimeout(ctx, t.operationTimeout, func(ctx context.Context) (*dirsRepositoryAdapterPort.DirSizeResult, error) {
+		return t.next.GetDirSize(ctx, data)
+	})
+}
+
+// The refresh walks a whole subtree as a maintenance task, so it is bounded
+// by the caller's context only
+func (t *timeoutAdapter) RefreshDirSizes(ctx context.Context, data *dirsRepositoryAdapterPort.RefreshDirSizesData) (*dirsRepositoryAdapterPort.DirSizeResult, error) {
+	return t.next.RefreshDirSizes(ctx, data)
+}
+
+func (t *timeoutAdapter) InvalidateDirSizes(ctx context.Context, data *dirsRepositoryAdapterPort.InvalidateDirSizesData) error {
+	return t.next.InvalidateDirSizes(ctx, data)
+}
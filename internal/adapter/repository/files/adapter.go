@@ -1,44 +1,236 @@
 package adapter
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"mime"
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	filesRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/files"
+	"github.com/flash-go/files-service/internal/storefs"
+	"go.opentelemetry.io/otel/metric"
 )
 
 type Config struct {
-	StoreLocalRootPath string
+	StoreLocalRootPath     string
+	StoreLocalTempPath     string
+	StoreLocalTrashPath    string
+	StoreLocalBackupPath   string
+	StoreLocalVersionsPath string
+	StoreLocalExpiryPath   string
+	VersionsRetention      int
+	DirMaxEntries          int
+	FileMaxSize            int64
+	ListMaxEntries         int
+	ListInlineMaxSize      int64
+	PreviewMaxBytes        int64
+	RequireExtension       bool
+	FollowSymlinks         bool
+	CaseInsensitiveNames   bool
+	OperationTimeout       time.Duration
+	RetryAttempts          int
+	RetryBackoff           time.Duration
+	RetryCounter           metric.Int64Counter
+	ListDefaultPath        string
+	HiddenNames            []string
+	RenameSamePathNoop     bool
+	MimeDetection          string
+	MimeRoutes             []MimeRoute
+	TopDirs                []string
+	CheckFreeSpace         bool
+	FreeSpaceMargin        int64
+	ListOrder              string
+	UploadDedup            string
+	UploadOrientation      bool
+	UploadStripExif        bool
+	ImageMaxPixels         int64
+	NamespaceRoots         []string
 }
 
+// MIME detection strategies
+const (
+	MimeDetectionContent   = "content"   // Sniff the content (default)
+	MimeDetectionExtension = "extension" // Look up the extension only
+	MimeDetectionHybrid    = "hybrid"    // Look up the extension, else sniff
+)
+
 func New(config *Config) filesRepositoryAdapterPort.Interface {
-	return &adapter{
-		storeLocalRootPath: config.StoreLocalRootPath,
+	a := &adapter{
+		storeLocalRootPath:     config.StoreLocalRootPath,
+		storeLocalTempPath:     config.StoreLocalTempPath,
+		storeLocalTrashPath:    config.StoreLocalTrashPath,
+		storeLocalBackupPath:   config.StoreLocalBackupPath,
+		storeLocalVersionsPath: config.StoreLocalVersionsPath,
+		storeLocalExpiryPath:   config.StoreLocalExpiryPath,
+		versionsRetention:      config.VersionsRetention,
+		dirMaxEntries:          config.DirMaxEntries,
+		fileMaxSize:            config.FileMaxSize,
+		listMaxEntries:         config.ListMaxEntries,
+		listInlineMaxSize:      config.ListInlineMaxSize,
+		previewMaxBytes:        config.PreviewMaxBytes,
+		requireExtension:       config.RequireExtension,
+		followSymlinks:         config.FollowSymlinks,
+		caseInsensitiveNames:   config.CaseInsensitiveNames,
+		listDefaultPath:        config.ListDefaultPath,
+		hiddenNames:            config.HiddenNames,
+		renameSamePathNoop:     config.RenameSamePathNoop,
+		mimeDetection:          config.MimeDetection,
+		mimeRoutes:             config.MimeRoutes,
+		topDirs:                config.TopDirs,
+		checkFreeSpace:         config.CheckFreeSpace,
+		freeSpaceMargin:        config.FreeSpaceMargin,
+		listOrder:              config.ListOrder,
+		uploadDedup:            config.UploadDedup,
+		uploadOrientation:      config.UploadOrientation,
+		uploadStripExif:        config.UploadStripExif,
+		imageMaxPixels:         config.ImageMaxPixels,
+		namespaceRoots:         absRoots(config.NamespaceRoots),
+		hashCache:              make(map[string]*hashEntry),
+	}
+	var repository filesRepositoryAdapterPort.Interface = a
+	if config.RetryAttempts > 0 {
+		repository = &retryAdapter{
+			next: repository,
+			policy: retryPolicy{
+				attempts: config.RetryAttempts,
+				backoff:  config.RetryBackoff,
+				counter:  config.RetryCounter,
+			},
+		}
 	}
+	if config.OperationTimeout > 0 {
+		return &timeoutAdapter{
+			next:             repository,
+			operationTimeout: config.OperationTimeout,
+		}
+	}
+	return repository
 }
 
 type adapter struct {
-	storeLocalRootPath string
+	storeLocalRootPath     string
+	storeLocalTempPath     string
+	storeLocalTrashPath    string
+	storeLocalBackupPath   string
+	storeLocalVersionsPath string
+	storeLocalExpiryPath   string
+	versionsRetention      int
+	dirMaxEntries          int
+	fileMaxSize            int64
+	listMaxEntries         int
+	listInlineMaxSize      int64
+	previewMaxBytes        int64
+	requireExtension       bool
+	followSymlinks         bool
+	caseInsensitiveNames   bool
+	listDefaultPath        string
+	hiddenNames            []string
+	renameSamePathNoop     bool
+	mimeDetection          string
+	mimeRoutes             []MimeRoute
+	topDirs                []string
+	checkFreeSpace         bool
+	freeSpaceMargin        int64
+	listOrder              string
+	uploadDedup            string
+	uploadOrientation      bool
+	uploadStripExif        bool
+	imageMaxPixels         int64
+	namespaceRoots         []string
+	hashMu                 sync.Mutex
+	hashCache              map[string]*hashEntry
 }
 
+// Preview size used when PreviewMaxBytes is not configured
+const defaultPreviewMaxBytes = 64 * 1024
+
 /*
 CreateFile securely saves an uploaded file within the adapter's base path.
 
 This function performs several safety checks before writing the file:
 
-1. Validates that the target path and filename are non-empty.
-2. Cleans the path to remove "." and ".." elements.
-3. Resolves the absolute path and ensures it is inside the base directory.
-4. Checks that all parent directories exist.
-5. Walks through parent directories to prevent symlink attacks.
-6. Protects against overwriting existing files.
-7. Opens the uploaded file safely and writes it atomically to the target path.
+ 1. Validates that the target path and filename are non-empty.
+    Rejects uploads whose declared size (DeclaredSize) or actual size exceeds
+    fileMaxSize before anything is written. If requireExtension is set,
+    rejects filenames without an extension (ErrMissingExtension).
+ 2. Cleans the path to remove "." and ".." elements and redundant separators,
+    so equivalent spellings ("a//b/", "a/./b", "a/b") share one location. The
+    stored name is the last element of the uploaded filename; names that do
+    not denote a file ("", ".", "..", "/") are rejected with ErrInvalidFile.
+ 3. Resolves the absolute path and ensures it is inside the base directory.
+ 4. Checks that all parent directories exist. If CreateDir is set, missing
+    directories (at most maxCreateDepth levels) are created first with mode
+    0700, after the traversal checks above, and removed again if the upload
+    fails. A new top-level directory must be listed in topDirs if it is set
+    (ErrTopDirNotAllowed).
+ 5. Walks through parent directories to prevent symlink attacks.
+ 6. Applies the create mode: CreateModeCreate (default) protects against
+    overwriting existing files (ErrFileExist), CreateModeReplace only replaces
+    an existing file (ErrFileNotFound when absent) and CreateModeUpsert does
+    either. Rejects new files in a directory that already holds dirMaxEntries
+    entries. If caseInsensitiveNames is set, a file whose name differs only in
+    case (e.g. "Photo.PNG" next to "photo.png") is rejected with ErrFileExist
+    in every mode, so uploads behave the same on case-sensitive and
+    case-insensitive filesystems.
+ 7. Opens the uploaded file safely and writes it atomically to the target path.
+    The content comes from File or, if it is nil, from Stream, which is read
+    once as it arrives (its size is only known once it ends, so only
+    DeclaredSize is checked up front). The content is written to a temp file
+    (in storeLocalTempPath when it is on the same device as the target
+    directory, otherwise in the target directory itself), synced to disk and
+    then moved into place, so readers never see a partial file. In
+    CreateModeCreate the move does not overwrite a file created at the
+    target since the existence check, which fails with ErrFileExist (see
+    moveIntoPlace).
+ 8. Removes the temp file on every failure path, so no partial upload is left
+    behind. The copy is capped at fileMaxSize, so a stream exceeding it is
+    rejected with ErrFileTooLarge even if its reported size was lower. A full
+    disk or exhausted quota is reported as ErrStorageFull. If checkFreeSpace
+    is set, an upload is rejected with ErrStorageFull before the copy when
+    the target filesystem has less than its size (the larger of DeclaredSize
+    and the actual size) plus freeSpaceMargin available; a full disk during
+    the copy is still caught. If ctx has a deadline, the copy is aborted with
+    the context cause once it passes.
+ 9. If Backup is set and an existing file is replaced, hard-links it into the
+    backup path (else the trash path, ErrBackupUnavailable if neither is
+    configured) as <unix nanos>/<path> just before the rename, and returns
+    that location in BackupPath. If storeLocalVersionsPath is set, the old
+    file is kept there as a version instead (see backupFile). The backup root
+    must be on the same filesystem as the base path. If mimeRoutes is set,
+    the upload is stored in the subdirectory of the first route matching its
+    MIME type (detected per mimeDetection) below the requested path, which is
+    created as with CreateDir. The result holds the final slash-separated path.
+ 10. If ExpiresAt is set, records it for the stored file in
+    storeLocalExpiryPath (ErrExpiryUnavailable if not configured) before the
+    rename, so the file is deleted by DeleteExpiredFile once it passes. A
+    replaced file's expiry does not carry over to the new content.
+ 11. Unless the duplicate content policy (Dedup, else uploadDedup) is
+    DedupAllow, hashes the content (SHA-256) while copying and compares it
+    with the regular files of the target directory of the same size (see
+    findDuplicate). On a match, DedupReject fails with ErrDuplicateContent
+    and DedupLink stores nothing and returns the path of the existing file
+    with Duplicate set. The hash of a stored upload is kept in the hash
+    cache, so later checks do not read it again.
+ 12. If uploadOrientation or uploadStripExif is set, rewrites JPEG uploads
+    with their pixels stored upright and without their metadata segments
+    respectively, before the duplicate check (see processImage). Images that
+    cannot be decoded fail the upload.
 
 Allowed paths examples (assuming base is /var/data):
 
@@ -54,10 +246,37 @@ Rejected paths examples:
 | "uploads/../.."     | "hack.txt"     | Resolves above base directory              |
 | "uploads/symlink"   | "file.txt"     | Parent directory is a symlink outside base |
 | "uploads"           | ""             | Empty filename                             |
+| "uploads"           | ".."           | Filename does not denote a file            |
 */
-func (a *adapter) CreateFile(ctx context.Context, data *filesRepositoryAdapterPort.CreateFileData) error {
-	if data.File == nil || data.File.Filename == "" {
-		return filesRepositoryAdapterPort.ErrInvalidFile
+func (a *adapter) CreateFile(ctx context.Context, data *filesRepositoryAdapterPort.CreateFileData) (*filesRepositoryAdapterPort.CreateFileResult, error) {
+	content := newUpload(data)
+	if content == nil || content.filename == "" {
+		return nil, filesRepositoryAdapterPort.ErrInvalidFile
+	}
+	if data.DeclaredSize != nil && *data.DeclaredSize < 0 {
+		return nil, filesRepositoryAdapterPort.ErrInvalidFile
+	}
+	if data.Backup && a.backupRoot() == "" {
+		return nil, filesRepositoryAdapterPort.ErrBackupUnavailable
+	}
+	if data.ExpiresAt != nil && a.storeLocalExpiryPath == "" {
+		return nil, filesRepositoryAdapterPort.ErrExpiryUnavailable
+	}
+
+	// Resolve the stored file name
+	name, err := storedFilename(content.filename)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check size limit against declared and actual size
+	if a.fileMaxSize > 0 {
+		if data.DeclaredSize != nil && *data.DeclaredSize > a.fileMaxSize {
+			return nil, filesRepositoryAdapterPort.ErrFileTooLarge
+		}
+		if content.size > a.fileMaxSize {
+			return nil, filesRepositoryAdapterPort.ErrFileTooLarge
+		}
 	}
 
 	// Clean and build path
@@ -66,82 +285,418 @@ func (a *adapter) CreateFile(ctx context.Context, data *filesRepositoryAdapterPo
 		cleanPath = ""
 	}
 	if strings.HasPrefix(cleanPath, "..") {
-		return filesRepositoryAdapterPort.ErrInvalidPath
+		return nil, filesRepositoryAdapterPort.ErrPathEscape
 	}
 
 	baseAbs, err := filepath.Abs(a.storeLocalRootPath)
 	if err != nil {
-		return fmt.Errorf("failed to resolve base path: %w", err)
+		return nil, fmt.Errorf("failed to resolve base path: %w", err)
 	}
 
 	targetDir := filepath.Join(baseAbs, cleanPath)
 	targetDirAbs, err := filepath.Abs(targetDir)
 	if err != nil {
-		return filesRepositoryAdapterPort.ErrInvalidPath
+		return nil, filesRepositoryAdapterPort.ErrInvalidPath
 	}
 
 	// Ensure directory is inside base
 	relToBase, err := filepath.Rel(baseAbs, targetDirAbs)
 	if err != nil || strings.HasPrefix(relToBase, "..") {
-		return filesRepositoryAdapterPort.ErrInvalidPath
+		return nil, filesRepositoryAdapterPort.ErrPathEscape
 	}
 
-	// Check parent directories for symlinks (symlink race prevention)
-	current := targetDirAbs
-	for {
-		if current == baseAbs || current == string(filepath.Separator) {
-			break
+	// Route the upload into the subdirectory mapped to its MIME type
+	routed := false
+	if len(a.mimeRoutes) > 0 {
+		mimeType, err := a.detectUploadMimeType(name, content)
+		if err != nil {
+			return nil, err
+		}
+		if dir := a.routeDir(mimeType); dir != "" {
+			if !data.CreateDir {
+				if _, err := os.Stat(targetDirAbs); os.IsNotExist(err) {
+					return nil, filesRepositoryAdapterPort.ErrDirNotFound
+				}
+			}
+			targetDirAbs = filepath.Join(targetDirAbs, dir)
+			routed = true
 		}
-		info, err := os.Lstat(current)
+	}
+
+	// Create missing target directories when requested or routed
+	committed := false
+	if data.CreateDir || routed {
+		created, err := a.createMissingDirs(baseAbs, targetDirAbs)
 		if err != nil {
-			return fmt.Errorf("failed to stat %q: %w", current, err)
+			return nil, err
 		}
-		if info.Mode()&os.ModeSymlink != 0 {
-			return filesRepositoryAdapterPort.ErrInvalidPath
+
+		// Remove the created directories unless the file was moved into place
+		defer func() {
+			if !committed {
+				removeCreatedDirs(targetDirAbs, created)
+			}
+		}()
+	}
+
+	// Check parent directories for symlinks (symlink race prevention)
+	if err := checkParents(baseAbs, targetDirAbs); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, filesRepositoryAdapterPort.ErrDirNotFound
 		}
-		current = filepath.Dir(current)
+		return nil, err
 	}
 
 	// Check directory exists
 	info, err := os.Stat(targetDirAbs)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return filesRepositoryAdapterPort.ErrDirNotFound
+			return nil, filesRepositoryAdapterPort.ErrDirNotFound
 		}
-		return err
+		return nil, err
 	}
 	if !info.IsDir() {
-		return filesRepositoryAdapterPort.ErrInvalidPath
+		return nil, filesRepositoryAdapterPort.ErrInvalidPath
 	}
 
 	// Build full file path
-	filename := filepath.Join(targetDirAbs, filepath.Base(data.File.Filename))
+	filename := filepath.Join(targetDirAbs, name)
+
+	// Check extension policy
+	// (a leading dot marks a hidden file, not an extension)
+	if a.requireExtension && strings.TrimPrefix(filepath.Ext(strings.TrimLeft(filepath.Base(filename), ".")), ".") == "" {
+		return nil, filesRepositoryAdapterPort.ErrMissingExtension
+	}
+
+	// Check file existence against the create mode
+	existing, err := os.Lstat(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	exists := err == nil
+	if exists && !existing.Mode().IsRegular() {
+		return nil, filesRepositoryAdapterPort.ErrInvalidPath
+	}
+	if a.caseInsensitiveNames {
+		if variant, err := caseVariantExists(targetDirAbs, filepath.Base(filename)); err != nil {
+			return nil, err
+		} else if variant {
+			return nil, filesRepositoryAdapterPort.ErrFileExist
+		}
+	}
+	switch data.Mode {
+	case filesRepositoryAdapterPort.CreateModeUpsert:
+	case filesRepositoryAdapterPort.CreateModeReplace:
+		if !exists {
+			return nil, filesRepositoryAdapterPort.ErrFileNotFound
+		}
+	default:
+		if exists {
+			return nil, filesRepositoryAdapterPort.ErrFileExist
+		}
+	}
+
+	// Check directory capacity
+	if !exists {
+		if full, err := storefs.DirFull(targetDirAbs, a.dirMaxEntries); err != nil {
+			return nil, fmt.Errorf("failed to count entries: %w", err)
+		} else if full {
+			return nil, filesRepositoryAdapterPort.ErrDirFull
+		}
+	}
 
-	// Check file existence
-	if _, err := os.Stat(filename); err == nil {
-		return filesRepositoryAdapterPort.ErrFileExist
+	// Check free space for the upload before copying anything
+	if a.checkFreeSpace {
+		size := content.size
+		if data.DeclaredSize != nil && *data.DeclaredSize > size {
+			size = *data.DeclaredSize
+		}
+		if free, ok := freeSpace(targetDirAbs); ok && free < size+a.freeSpaceMargin {
+			return nil, filesRepositoryAdapterPort.ErrStorageFull
+		}
 	}
 
 	// Open source file
-	src, err := data.File.Open()
+	src, err := content.open()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer src.Close()
 
-	// Create destination file
-	dst, err := os.Create(filename)
+	// Create temp file
+	dst, err := os.CreateTemp(a.tempDir(targetDirAbs), "."+filepath.Base(filename)+".tmp-*")
 	if err != nil {
-		return err
+		return nil, storageError(err)
 	}
-	defer dst.Close()
 
-	// Copy content
-	if _, err := io.Copy(dst, src); err != nil {
-		return err
+	// Remove the temp file unless it was moved into place
+	defer func() {
+		dst.Close()
+		if !committed {
+			os.Remove(dst.Name())
+		}
+	}()
+
+	// Copy content, reading at most one byte past fileMaxSize so a source
+	// larger than its reported size is still caught. A context deadline
+	// aborts the copy with the context cause. The content is hashed while
+	// copying unless duplicates are allowed.
+	var reader io.Reader = src
+	if _, ok := ctx.Deadline(); ok {
+		reader = contextReader{ctx, reader}
+	}
+	if a.fileMaxSize > 0 {
+		reader = io.LimitReader(reader, a.fileMaxSize+1)
+	}
+	dedup := a.dedup(data)
+	var writer io.Writer = dst
+	sha256Hash := sha256.New()
+	if dedup != filesRepositoryAdapterPort.DedupAllow {
+		writer = io.MultiWriter(dst, sha256Hash)
+	}
+	written, err := io.Copy(writer, reader)
+	if err != nil {
+		return nil, storageError(err)
+	}
+	if a.fileMaxSize > 0 && written > a.fileMaxSize {
+		return nil, filesRepositoryAdapterPort.ErrFileTooLarge
+	}
+	if err := dst.Sync(); err != nil {
+		return nil, storageError(err)
+	}
+	if err := dst.Close(); err != nil {
+		return nil, storageError(err)
 	}
 
-	return nil
+	// Normalize the orientation and strip the metadata of images
+	processed, err := a.processImage(name, dst.Name())
+	if err != nil {
+		return nil, err
+	}
+	if processed != nil {
+		written = int64(len(processed))
+		if a.fileMaxSize > 0 && written > a.fileMaxSize {
+			return nil, filesRepositoryAdapterPort.ErrFileTooLarge
+		}
+		sha256Hash.Reset()
+		sha256Hash.Write(processed)
+	}
+
+	// Look for a file with the same content in the target directory
+	var sum string
+	if dedup != filesRepositoryAdapterPort.DedupAllow {
+		sum = hex.EncodeToString(sha256Hash.Sum(nil))
+		duplicateAbs, err := a.findDuplicate(ctx, targetDirAbs, dst.Name(), written, sum)
+		if err != nil {
+			return nil, err
+		}
+		if duplicateAbs != "" {
+			if dedup == filesRepositoryAdapterPort.DedupReject {
+				return nil, filesRepositoryAdapterPort.ErrDuplicateContent
+			}
+			duplicatePath, _ := filepath.Rel(baseAbs, duplicateAbs)
+			return &filesRepositoryAdapterPort.CreateFileResult{
+				Path:      filepath.ToSlash(duplicatePath),
+				Duplicate: true,
+			}, nil
+		}
+	}
+
+	// Keep the replaced file as a backup when requested
+	relPath, _ := filepath.Rel(baseAbs, filename)
+	result := filesRepositoryAdapterPort.CreateFileResult{
+		Path: filepath.ToSlash(relPath),
+	}
+	if data.Backup && exists {
+		backupAbs, backupPath, err := a.backupFile(filename, relPath)
+		if err != nil {
+			return nil, err
+		}
+
+		// Remove the backup unless the file was moved into place
+		defer func() {
+			if !committed {
+				os.Remove(backupAbs)
+			}
+		}()
+		result.BackupPath = &backupPath
+	}
+
+	// Record the expiry of the new content
+	if data.ExpiresAt != nil {
+		info, err := os.Stat(dst.Name())
+		if err != nil {
+			return nil, err
+		}
+		if err := a.setExpiry(relPath, info, *data.ExpiresAt); err != nil {
+			return nil, storageError(err)
+		}
+	}
+
+	// Move temp file into place, without overwriting a file created since
+	// the existence check unless the create mode allows replacing
+	replace := data.Mode == filesRepositoryAdapterPort.CreateModeReplace || data.Mode == filesRepositoryAdapterPort.CreateModeUpsert
+	if err := moveIntoPlace(dst.Name(), filename, replace); err != nil {
+		return nil, err
+	}
+	committed = true
+
+	// Index the hash of the new content for later duplicate checks
+	if sum != "" {
+		if info, err := os.Lstat(filename); err == nil {
+			a.storeHash(filename, &hashEntry{info: info, sha256: sum})
+		}
+	}
+
+	// Drop versions beyond the retention count
+	if result.BackupPath != nil && a.storeLocalVersionsPath != "" {
+		a.pruneVersions(relPath)
+	}
+	return &result, nil
+}
+
+// Maximum number of directory levels created by an upload with CreateDir
+const maxCreateDepth = 5
+
+// Create the missing directories of dirAbs with mode 0700, returning the
+// topmost one created ("" when none were missing). The deepest existing
+// ancestor must pass the symlink walk and the capacity check, and a created
+// top-level directory must be listed in topDirs if that is set.
+func (a *adapter) createMissingDirs(baseAbs, dirAbs string) (string, error) {
+	// Find the deepest existing ancestor
+	existingAbs := dirAbs
+	created := ""
+	for depth := 0; ; depth++ {
+		_, err := os.Lstat(existingAbs)
+		if err == nil {
+			break
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+		if existingAbs == baseAbs || depth >= maxCreateDepth {
+			return "", filesRepositoryAdapterPort.ErrInvalidPath
+		}
+		created = existingAbs
+		existingAbs = filepath.Dir(existingAbs)
+	}
+	if created == "" {
+		return "", nil
+	}
+
+	// Check a created top-level directory against the allowlist
+	if len(a.topDirs) > 0 && existingAbs == baseAbs && !slices.Contains(a.topDirs, filepath.Base(created)) {
+		return "", filesRepositoryAdapterPort.ErrTopDirNotAllowed
+	}
+
+	// Check the existing ancestor
+	if err := checkParents(baseAbs, existingAbs); err != nil {
+		return "", err
+	}
+	info, err := os.Stat(existingAbs)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return "", filesRepositoryAdapterPort.ErrInvalidPath
+	}
+	if full, err := storefs.DirFull(existingAbs, a.dirMaxEntries); err != nil {
+		return "", fmt.Errorf("failed to count entries: %w", err)
+	} else if full {
+		return "", filesRepositoryAdapterPort.ErrDirFull
+	}
+
+	// Create directories
+	if err := os.MkdirAll(dirAbs, 0700); err != nil {
+		removeCreatedDirs(dirAbs, created)
+		return "", storageError(err)
+	}
+	return created, nil
+}
+
+// Remove the directories from dirAbs up to and including created. Only empty
+// directories are removed, so content added concurrently is never lost.
+func removeCreatedDirs(dirAbs, created string) {
+	if created == "" {
+		return
+	}
+	for current := dirAbs; ; current = filepath.Dir(current) {
+		if err := os.Remove(current); err != nil && !os.IsNotExist(err) {
+			return
+		}
+		if current == created {
+			return
+		}
+	}
+}
+
+// Report a full disk or exhausted quota as ErrStorageFull, so clients can tell
+// transient capacity errors from their own mistakes
+func storageError(err error) error {
+	if isStorageFull(err) {
+		return filesRepositoryAdapterPort.ErrStorageFull
+	}
+	return err
+}
+
+// Reader failing with the context cause once ctx is done
+type contextReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if r.ctx.Err() != nil {
+		return 0, context.Cause(r.ctx)
+	}
+	return r.reader.Read(p)
+}
+
+// Return the name a file is stored under: the last element of the client
+// supplied filename, rejected if it does not denote a file or contains NUL or
+// other control characters
+func storedFilename(filename string) (string, error) {
+	name := filepath.Base(filepath.Clean(filename))
+	if name == "." || name == ".." || name == string(filepath.Separator) {
+		return "", filesRepositoryAdapterPort.ErrInvalidFile
+	}
+	if strings.IndexFunc(name, unicode.IsControl) >= 0 {
+		return "", filesRepositoryAdapterPort.ErrInvalidCharacters
+	}
+	return name, nil
+}
+
+// Report whether dirAbs holds an entry whose name equals name ignoring case
+// but is not spelled exactly the same
+func caseVariantExists(dirAbs, name string) (bool, error) {
+	entries, err := os.ReadDir(dirAbs)
+	if err != nil {
+		return false, err
+	}
+	for _, entry := range entries {
+		if entry.Name() != name && strings.EqualFold(entry.Name(), name) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Resolve the directory for upload temp files. The configured temp path is
+// used only when it shares a device with the target directory, otherwise the
+// final rename would not be atomic, so the target directory is used instead.
+func (a *adapter) tempDir(targetDirAbs string) string {
+	if a.storeLocalTempPath == "" {
+		return targetDirAbs
+	}
+	tempInfo, err := os.Stat(a.storeLocalTempPath)
+	if err != nil || !tempInfo.IsDir() {
+		return targetDirAbs
+	}
+	targetInfo, err := os.Stat(targetDirAbs)
+	if err != nil || !sameDevice(tempInfo, targetInfo) {
+		return targetDirAbs
+	}
+	return a.storeLocalTempPath
 }
 
 /*
@@ -149,20 +704,71 @@ GetFiles securely lists files and directories within a specified path relative t
 
 This function performs multiple safety checks:
 
-1. Validates that the requested path is non-empty and does not traverse outside the base directory using ".." or absolute paths.
-2. Resolves the absolute path for the requested directory.
-3. Ensures the path is inside the adapter's storeLocalRootPath.
-4. Checks parent directories for symlinks to prevent symlink race attacks.
-5. Reads the directory contents, safely obtains file info, size, and MIME type.
-6. Returns a sorted list with directories first, then files, both alphabetically.
+ 1. Validates that the requested path is non-empty and does not traverse outside the base directory using ".." or absolute paths.
+    An empty path lists listDefaultPath (the base directory when unset).
+ 2. Resolves the absolute path for the requested directory.
+ 3. Ensures the path is inside the adapter's storeLocalRootPath.
+ 4. Checks parent directories for symlinks to prevent symlink race attacks.
+    If followSymlinks is set, symlinks are resolved instead and the path is
+    rejected only when the resolved location escapes the base directory.
+ 5. If the path does not exist below an existing directory (see
+    missingDir), fails with ErrDirNotFound or, if EmptyIfMissing is set,
+    returns an empty list. Paths that are invalid or escape the base fail
+    either way.
+    If the path points at a file, lists its parent directory instead and marks
+    that file's entry as Selected ("reveal in folder").
+ 6. If WithPath is set, fills each entry's Path with its slash-separated path
+    relative to the base directory.
+ 7. Reads the directory contents, safely obtains file info, size, and MIME type
+    (see detectMimeType).
+    Rejects directories holding more than listMaxEntries entries with
+    ErrTooManyEntries, reading at most one entry past the cap.
+    If SkipErrors is set, entries that cannot be read (e.g. permission denied)
+    are kept with their Error set instead of failing the whole listing.
+    If WithEncoding is set, the character encoding of text files is detected
+    from the same prefix read used for MIME detection.
+    AccessTime is set where the platform tracks it and the directory is not
+    on a noatime mount.
+    Symlink entries are marked with IsSymlink. If followSymlinks is set, links
+    resolving inside the base report their target's IsDir, size and MIME type;
+    other links are left unresolved.
+    If WithContent is set, the base64 content of files of at most
+    listInlineMaxSize bytes is inlined as Content; larger files return only
+    metadata.
+    If WithDirStats is set, directory entries get their ChildCount from one
+    shallow ReadDir each; WithDirSize additionally sums the sizes of their
+    direct child files into ChildrenSize.
+ 8. Leaves out entries whose name matches one of the hiddenNames glob
+    patterns (e.g. ".trash" or upload temp files).
+ 9. Returns a list sorted per Order (listOrder if empty): by default
+    directories first, then files, both alphabetically, see listOrderLess.
+    If Limit is set, returns only one page of that list: at most Limit
+    entries following the entry given by AfterDir and AfterName (from the
+    start if AfterName is empty), see pageEntries. Only the page's entries
+    are inspected, and listMaxEntries does not apply since the page bounds
+    the result.
+ 10. If MimeCategory is set, lists only files whose detected MIME type has
+    that top-level type (e.g. "image"), leaving out directories and
+    unresolved symlinks. The filter applies before paging, so pages and
+    cursors walk the filtered listing.
+ 11. If IncludeSelf is set, puts an entry for the listed directory itself
+    (the parent when the path is a file) at the head of the first page,
+    marked with IsSelf and named ".", see selfEntry. It is not counted by
+    Limit and is left out of listings of missing paths.
+ 12. Returns the change token of the listed directory, computed by
+    listToken from the same read as the entries, as ListToken would. If
+    Since is set and equals that token, returns only the token with
+    Unchanged set instead of the listing. Listings of missing paths have no
+    token.
 
 Allowed paths examples (assuming base is /var/data):
 
-| Input Path    | Resulting Absolute Path | Reason              |
-|---------------|------------------------|----------------------|
-| ""            | /var/data              | Base directory, safe |
-| "uploads"     | /var/data/uploads      | Inside base, safe    |
-| "tmp/session" | /var/data/tmp/session  | Inside base, safe    |
+| Input Path      | Resulting Absolute Path | Reason               |
+|-----------------|-------------------------|----------------------|
+| ""              | /var/data               | Base directory, safe |
+| "uploads"       | /var/data/uploads       | Inside base, safe    |
+| "tmp/session"   | /var/data/tmp/session   | Inside base, safe    |
+| "uploads/a.txt" | /var/data/uploads       | File, parent listed  |
 
 Rejected paths examples:
 
@@ -173,11 +779,15 @@ Rejected paths examples:
 | "uploads/../.."  | Resolves above base directory                 |
 | "symlink_folder" | Parent directory is a symlink outside base    |
 */
-func (a *adapter) GetFiles(ctx context.Context, data *filesRepositoryAdapterPort.GetFilesData) (*[]filesRepositoryAdapterPort.FileResult, error) {
-	cleanPath := filepath.Clean(data.Path)
+func (a *adapter) GetFiles(ctx context.Context, data *filesRepositoryAdapterPort.GetFilesData) (*filesRepositoryAdapterPort.GetFilesResult, error) {
+	requestPath := data.Path
+	if requestPath == "" {
+		requestPath = a.listDefaultPath
+	}
+	cleanPath := filepath.Clean(requestPath)
 
-	if cleanPath == ".." || strings.HasPrefix(cleanPath, "..") {
-		return nil, filesRepositoryAdapterPort.ErrInvalidPath
+	if strings.HasPrefix(cleanPath, "..") {
+		return nil, filesRepositoryAdapterPort.ErrPathEscape
 	}
 
 	baseAbs, err := filepath.Abs(a.storeLocalRootPath)
@@ -193,67 +803,155 @@ func (a *adapter) GetFiles(ctx context.Context, data *filesRepositoryAdapterPort
 
 	// Ensure target is inside base
 	if rel, _ := filepath.Rel(baseAbs, targetAbs); strings.HasPrefix(rel, "..") {
-		return nil, filesRepositoryAdapterPort.ErrInvalidPath
+		return nil, filesRepositoryAdapterPort.ErrPathEscape
 	}
 
-	// Check parent directories for symlinks
-	current := targetAbs
-	for {
-		if current == baseAbs || current == string(filepath.Separator) {
-			break
-		}
-		info, err := os.Lstat(current)
-		if err != nil {
-			return nil, filesRepositoryAdapterPort.ErrInvalidPath
-		}
-		if info.Mode()&os.ModeSymlink != 0 {
-			return nil, filesRepositoryAdapterPort.ErrInvalidPath
+	// Check parent directories for symlinks, or resolve them inside base
+	readAbs, err := a.listPath(baseAbs, targetAbs)
+	if err != nil {
+		if a.missingDir(baseAbs, targetAbs) {
+			return missingListing(data)
 		}
-		current = filepath.Dir(current)
+		return nil, err
 	}
 
 	// Check directory existence
-	info, err := os.Stat(targetAbs)
+	info, err := os.Stat(readAbs)
 	if err != nil {
 		if os.IsNotExist(err) {
+			if a.missingDir(baseAbs, targetAbs) {
+				return missingListing(data)
+			}
 			return nil, filesRepositoryAdapterPort.ErrDirNotFound
 		}
 		return nil, err
 	}
+
+	// List the parent of a file and select the file's entry
+	selected := ""
 	if !info.IsDir() {
-		return nil, filesRepositoryAdapterPort.ErrInvalidPath
+		if !info.Mode().IsRegular() || targetAbs == baseAbs {
+			return nil, filesRepositoryAdapterPort.ErrInvalidPath
+		}
+		selected = filepath.Base(targetAbs)
+		targetAbs = filepath.Dir(targetAbs)
+		if readAbs, err = a.listPath(baseAbs, targetAbs); err != nil {
+			return nil, err
+		}
+		if info, err = os.Stat(readAbs); err != nil {
+			return nil, err
+		}
 	}
 
 	// Read dir
-	files, err := os.ReadDir(targetAbs)
+	var files []os.DirEntry
+	if data.Limit > 0 {
+		files, err = os.ReadDir(readAbs)
+	} else {
+		files, err = a.readDir(readAbs)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Leave out hidden entries
+	files = slices.DeleteFunc(files, func(file os.DirEntry) bool {
+		return storefs.Hidden(a.hiddenNames, file.Name())
+	})
+
+	// Hash the entries just read into the change token, and skip the
+	// listing if it matches Since
+	token, err := listToken(ctx, info, files)
 	if err != nil {
 		return nil, err
 	}
+	if data.Since != "" && token == data.Since {
+		return &filesRepositoryAdapterPort.GetFilesResult{Token: token, Unchanged: true}, nil
+	}
+
+	// Keep only files of the requested MIME category
+	if data.MimeCategory != "" {
+		files = slices.DeleteFunc(files, func(file os.DirEntry) bool {
+			return !a.inMimeCategory(baseAbs, filepath.Join(readAbs, file.Name()), data.MimeCategory)
+		})
+	}
+
+	// Select the requested page
+	if data.Limit > 0 {
+		files = a.pageEntries(baseAbs, readAbs, files, data)
+	}
+
+	// Check whether access times can be trusted
+	withAccessTime := atimeReliable(readAbs)
+
+	// Resolve listed directory relative to base
+	relDir, err := filepath.Rel(baseAbs, targetAbs)
+	if err != nil {
+		return nil, filesRepositoryAdapterPort.ErrInvalidPath
+	}
 
 	// Build response
 	response := make([]filesRepositoryAdapterPort.FileResult, len(files))
 	for i, file := range files {
+		fileInfo := filesRepositoryAdapterPort.FileResult{
+			Name:     file.Name(),
+			IsDir:    file.IsDir(),
+			Selected: file.Name() == selected,
+		}
+
+		if data.WithPath {
+			p := filepath.ToSlash(filepath.Join(relDir, file.Name()))
+			fileInfo.Path = &p
+		}
+
 		info, err := file.Info()
 		if err != nil {
-			return nil, err
+			if !data.SkipErrors {
+				return nil, err
+			}
+			e := entryError(err)
+			fileInfo.Error = &e
+			response[i] = fileInfo
+			continue
 		}
 
-		fileInfo := filesRepositoryAdapterPort.FileResult{
-			Name:  file.Name(),
-			IsDir: file.IsDir(),
+		// Follow symlinks resolving inside base, leave others unresolved
+		entryAbs := filepath.Join(readAbs, file.Name())
+		if info.Mode()&os.ModeSymlink != 0 {
+			fileInfo.IsSymlink = true
+			resolved, resolvedInfo, ok := a.followSymlink(baseAbs, entryAbs)
+			if !ok {
+				response[i] = fileInfo
+				continue
+			}
+			entryAbs, info = resolved, resolvedInfo
+			fileInfo.IsDir = info.IsDir()
 		}
 
-		if !file.IsDir() {
+		if withAccessTime {
+			fileInfo.AccessTime = accessTime(info)
+		}
+
+		if fileInfo.IsDir && data.WithDirStats {
+			fileInfo.ChildCount, fileInfo.ChildrenSize = dirStats(entryAbs, data.WithDirSize)
+		}
+
+		if !fileInfo.IsDir {
 			s := info.Size()
 			fileInfo.Size = &s
 
-			f, err := os.Open(filepath.Join(targetAbs, file.Name()))
+			mt, head, err := a.detectMimeType(entryAbs, data.WithEncoding)
 			if err == nil {
-				defer f.Close()
-				buf := make([]byte, 512)
-				n, _ := f.Read(buf)
-				mt := http.DetectContentType(buf[:n])
 				fileInfo.MimeType = &mt
+				if data.WithEncoding {
+					fileInfo.Encoding = detectEncoding(mt, head)
+				}
+				if data.WithContent && s <= a.listInlineMaxSize {
+					fileInfo.Content = a.inlineContent(entryAbs)
+				}
+			} else if data.SkipErrors {
+				e := entryError(err)
+				fileInfo.Error = &e
 			}
 		}
 
@@ -261,14 +959,21 @@ func (a *adapter) GetFiles(ctx context.Context, data *filesRepositoryAdapterPort
 	}
 
 	// Sorting
+	order := a.order(data)
 	sort.Slice(response, func(i, j int) bool {
-		if response[i].IsDir != response[j].IsDir {
-			return response[i].IsDir
-		}
-		return response[i].Name < response[j].Name
+		return listOrderLess(order, response[i].IsDir, response[i].Name, response[j].IsDir, response[j].Name)
 	})
 
-	return &response, nil
+	// Put the listed directory itself at the head of the first page
+	if data.IncludeSelf && data.AfterName == "" {
+		self, err := a.selfEntry(readAbs, relDir, withAccessTime, data)
+		if err != nil {
+			return nil, err
+		}
+		response = append([]filesRepositoryAdapterPort.FileResult{*self}, response...)
+	}
+
+	return &filesRepositoryAdapterPort.GetFilesResult{Files: response, Token: token}, nil
 }
 
 /*
@@ -276,12 +981,14 @@ DeleteFile securely deletes a file within the adapter's base path.
 
 This function performs several safety checks before removing the file:
 
-1. Validates that the file path is non-empty and does not traverse outside the base directory.
-2. Resolves the absolute path for the file relative to the base.
-3. Ensures the file path is inside the adapter's storeLocalRootPath.
-4. Checks that all parent directories do not contain symlinks (symlink race prevention).
-5. Confirms the file exists before attempting deletion.
-6. Removes the file safely using os.Remove.
+ 1. Validates that the file path is non-empty and does not traverse outside the base directory.
+ 2. Resolves the absolute path for the file relative to the base.
+ 3. Ensures the file path is inside the adapter's storeLocalRootPath.
+ 4. Checks that all parent directories do not contain symlinks (symlink race prevention).
+ 5. Confirms the file exists before attempting deletion.
+ 6. If UnmodifiedSince is set, refuses to delete a file modified after that time.
+ 7. Removes the file safely using os.Remove, or, if storeLocalTrashPath is set,
+    moves it to the trash (see moveToTrash) to be purged later by PurgeTrash.
 
 Allowed paths examples (assuming base is /var/data):
 
@@ -305,9 +1012,12 @@ func (a *adapter) DeleteFile(ctx context.Context, data *filesRepositoryAdapterPo
 	}
 
 	cleanPath := filepath.Clean(data.Path)
-	if cleanPath == "." || strings.HasPrefix(cleanPath, "..") {
+	if cleanPath == "." {
 		return filesRepositoryAdapterPort.ErrInvalidPath
 	}
+	if strings.HasPrefix(cleanPath, "..") {
+		return filesRepositoryAdapterPort.ErrPathEscape
+	}
 
 	baseAbs, err := filepath.Abs(a.storeLocalRootPath)
 	if err != nil {
@@ -323,23 +1033,12 @@ func (a *adapter) DeleteFile(ctx context.Context, data *filesRepositoryAdapterPo
 	// Ensure file is inside base
 	relToBase, err := filepath.Rel(baseAbs, targetFileAbs)
 	if err != nil || strings.HasPrefix(relToBase, "..") {
-		return filesRepositoryAdapterPort.ErrInvalidPath
+		return filesRepositoryAdapterPort.ErrPathEscape
 	}
 
 	// Check parent directories for symlinks (symlink race prevention)
-	current := filepath.Dir(targetFileAbs)
-	for {
-		if current == baseAbs || current == string(filepath.Separator) {
-			break
-		}
-		info, err := os.Lstat(current)
-		if err != nil {
-			return fmt.Errorf("failed to stat %q: %w", current, err)
-		}
-		if info.Mode()&os.ModeSymlink != 0 {
-			return filesRepositoryAdapterPort.ErrInvalidPath
-		}
-		current = filepath.Dir(current)
+	if err := checkParents(baseAbs, filepath.Dir(targetFileAbs)); err != nil {
+		return err
 	}
 
 	// Check file exists
@@ -354,6 +1053,16 @@ func (a *adapter) DeleteFile(ctx context.Context, data *filesRepositoryAdapterPo
 		return filesRepositoryAdapterPort.ErrInvalidPath
 	}
 
+	// Check precondition (HTTP dates have second precision)
+	if data.UnmodifiedSince != nil && info.ModTime().Truncate(time.Second).After(*data.UnmodifiedSince) {
+		return filesRepositoryAdapterPort.ErrFileModified
+	}
+
+	// Move file to trash
+	if a.storeLocalTrashPath != "" {
+		return a.moveToTrash(ctx, targetFileAbs, relToBase, info)
+	}
+
 	// Delete file
 	return os.Remove(targetFileAbs)
 }
@@ -363,13 +1072,22 @@ RenameFile securely renames a file within the adapter's base path.
 
 This function performs multiple safety checks before renaming the file:
 
-1. Validates that both old and new paths are non-empty and do not traverse outside
-   the base directory using ".." or absolute paths.
-2. Resolves absolute paths for old and new files relative to the base.
-3. Ensures both paths are inside the adapter's storeLocalRootPath.
-4. Checks that all parent directories do not contain symlinks (symlink race prevention).
-5. Checks that the old file exists and the new file does not exist.
-6. Ensures the target paths are files and not directories.
+ 1. Validates that both old and new paths are non-empty and do not traverse outside
+    the base directory using ".." or absolute paths.
+ 2. Resolves absolute paths for old and new files relative to the base.
+ 3. Ensures both paths are inside the adapter's storeLocalRootPath.
+ 4. Checks that all parent directories do not contain symlinks (symlink race prevention).
+ 5. Checks that the old file exists and the new file does not exist.
+ 6. Ensures the target paths are files and not directories.
+ 7. If UnmodifiedSince is set, refuses to rename a file modified after that time.
+ 8. Renaming a path onto itself returns ErrSamePath, or succeeds without
+    touching the file if renameSamePathNoop is set. A new path differing only
+    in case that resolves to the old file (case-insensitive filesystem) is
+    renamed in place instead of reported as existing.
+ 9. Moves the file's recorded expiry (see setExpiry) along with it.
+ 10. Rejects moves between the roots of different store namespaces
+    (namespaceRoots) nested inside one another with ErrCrossNamespace, as
+    they have their own policies and may live on other devices.
 
 Allowed paths examples (assuming base is /var/data):
 
@@ -377,6 +1095,7 @@ Allowed paths examples (assuming base is /var/data):
 |-------------------------|---------------------------------|----------------------------|
 | old: "uploads/img.png"  | /var/data/uploads/img.png       | Inside base, exists        |
 | new: "uploads/img2.png" | /var/data/uploads/img2.png      | Inside base, no collisions |
+| new: "uploads/IMG.png"  | /var/data/uploads/IMG.png       | Case change of old file    |
 
 Rejected paths examples:
 
@@ -396,12 +1115,18 @@ func (a *adapter) RenameFile(ctx context.Context, data *filesRepositoryAdapterPo
 	cleanOld := filepath.Clean(data.OldPath)
 	cleanNew := filepath.Clean(data.NewPath)
 
-	if cleanOld == "." || strings.HasPrefix(cleanOld, "..") {
+	if cleanOld == "." {
 		return filesRepositoryAdapterPort.ErrInvalidPath
 	}
-	if cleanNew == "." || strings.HasPrefix(cleanNew, "..") {
+	if strings.HasPrefix(cleanOld, "..") {
+		return filesRepositoryAdapterPort.ErrPathEscape
+	}
+	if cleanNew == "." {
 		return filesRepositoryAdapterPort.ErrInvalidPath
 	}
+	if strings.HasPrefix(cleanNew, "..") {
+		return filesRepositoryAdapterPort.ErrPathEscape
+	}
 
 	baseAbs, err := filepath.Abs(a.storeLocalRootPath)
 	if err != nil {
@@ -421,32 +1146,25 @@ func (a *adapter) RenameFile(ctx context.Context, data *filesRepositoryAdapterPo
 
 	// Ensure both paths are inside base
 	if rel, _ := filepath.Rel(baseAbs, oldAbs); strings.HasPrefix(rel, "..") {
-		return filesRepositoryAdapterPort.ErrInvalidPath
+		return filesRepositoryAdapterPort.ErrPathEscape
 	}
 	if rel, _ := filepath.Rel(baseAbs, newAbs); strings.HasPrefix(rel, "..") {
-		return filesRepositoryAdapterPort.ErrInvalidPath
+		return filesRepositoryAdapterPort.ErrPathEscape
+	}
+
+	// Ensure both paths belong to the same store namespace
+	if a.crossesNamespace(oldAbs, newAbs) {
+		return filesRepositoryAdapterPort.ErrCrossNamespace
 	}
 
 	// Check parent directories for symlinks (symlink race prevention)
 	for _, path := range []string{oldAbs, newAbs} {
-		current := filepath.Dir(path)
-		for {
-			if current == baseAbs || current == string(filepath.Separator) {
-				break
-			}
-			info, err := os.Lstat(current)
-			if err != nil {
-				return filesRepositoryAdapterPort.ErrInvalidPath
-			}
-			if info.Mode()&os.ModeSymlink != 0 {
-				return filesRepositoryAdapterPort.ErrInvalidPath
-			}
-			current = filepath.Dir(current)
+		if err := checkParents(baseAbs, filepath.Dir(path)); err != nil {
+			return parentsError(err)
 		}
 	}
 
 	// Check existence and type
-	fmt.Println(oldAbs)
 	oldInfo, err := os.Stat(oldAbs)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -457,15 +1175,885 @@ func (a *adapter) RenameFile(ctx context.Context, data *filesRepositoryAdapterPo
 	if oldInfo.IsDir() {
 		return filesRepositoryAdapterPort.ErrInvalidPath
 	}
+	if data.UnmodifiedSince != nil && oldInfo.ModTime().Truncate(time.Second).After(*data.UnmodifiedSince) {
+		return filesRepositoryAdapterPort.ErrFileModified
+	}
+	if cleanOld == cleanNew {
+		if a.renameSamePathNoop {
+			return nil
+		}
+		return filesRepositoryAdapterPort.ErrSamePath
+	}
 
 	if newInfo, err := os.Stat(newAbs); err == nil {
 		if newInfo.IsDir() {
 			return filesRepositoryAdapterPort.ErrInvalidPath
 		}
-		return filesRepositoryAdapterPort.ErrFileNewExist
+		// A case change on a case-insensitive filesystem finds the old file
+		if !strings.EqualFold(cleanOld, cleanNew) || !os.SameFile(oldInfo, newInfo) {
+			return filesRepositoryAdapterPort.ErrFileNewExist
+		}
 	} else if !os.IsNotExist(err) {
 		return err
 	}
 
-	return os.Rename(oldAbs, newAbs)
+	if err := os.Rename(oldAbs, newAbs); err != nil {
+		return err
+	}
+	oldRel, _ := filepath.Rel(baseAbs, oldAbs)
+	newRel, _ := filepath.Rel(baseAbs, newAbs)
+	a.moveExpiry(oldRel, newRel)
+	return nil
+}
+
+/*
+WriteFileAt securely writes uploaded bytes at a given offset within an existing
+file inside the adapter's base path.
+
+This function performs several safety checks before writing:
+
+ 1. Validates that the file path is non-empty, the offset is not negative and
+    the path does not traverse outside the base directory.
+ 2. Resolves the absolute path and ensures it is inside storeLocalRootPath.
+ 3. Checks that all parent directories do not contain symlinks (symlink race prevention).
+ 4. Confirms the target exists and is a regular file (symlinks are rejected).
+ 5. Rejects writes whose end (offset + size) would exceed fileMaxSize with
+    ErrFileTooLarge. Without fileMaxSize, rejects offsets past the end of the
+    file with ErrInvalidRange, so a write cannot grow a sparse file at will.
+ 6. Opens the target without following a symlink swapped in since the check
+    (see openInBase) and writes the content in place with WriteAt, leaving
+    the rest of the file untouched. Offsets past the end of the file extend
+    it, leaving a hole. A full disk or exhausted quota is reported as
+    ErrStorageFull.
+
+Allowed paths examples (assuming base is /var/data):
+
+| Input Path               | Offset | Reason                           |
+|--------------------------|--------|----------------------------------|
+| "uploads/images/pic.png" | 0      | Inside base, overwrites the head |
+| "uploads/disk.img"       | 4096   | Inside base, patches a block     |
+
+Rejected paths examples:
+
+| Input Path                 | Offset | Reason for rejection                       |
+|----------------------------|--------|--------------------------------------------|
+| "../../etc/passwd"         | 0      | Path traversal outside base                |
+| "uploads/symlink/file.txt" | 0      | Parent directory is a symlink outside base |
+| "uploads/missing.txt"      | 0      | File does not exist                        |
+| "uploads/file.txt"         | -1     | Negative offset                            |
+| "uploads/file.txt"         | 1<<62  | Past the end, fileMaxSize unset            |
+*/
+func (a *adapter) WriteFileAt(ctx context.Context, data *filesRepositoryAdapterPort.WriteFileAtData) error {
+	if data.File == nil {
+		return filesRepositoryAdapterPort.ErrInvalidFile
+	}
+	if data.Path == "" || data.Offset < 0 {
+		return filesRepositoryAdapterPort.ErrInvalidPath
+	}
+
+	cleanPath := filepath.Clean(data.Path)
+	if cleanPath == "." {
+		return filesRepositoryAdapterPort.ErrInvalidPath
+	}
+	if strings.HasPrefix(cleanPath, "..") {
+		return filesRepositoryAdapterPort.ErrPathEscape
+	}
+
+	baseAbs, err := filepath.Abs(a.storeLocalRootPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve base path: %w", err)
+	}
+
+	targetFileAbs, err := filepath.Abs(filepath.Join(baseAbs, cleanPath))
+	if err != nil {
+		return filesRepositoryAdapterPort.ErrInvalidPath
+	}
+
+	// Ensure file is inside base
+	relToBase, err := filepath.Rel(baseAbs, targetFileAbs)
+	if err != nil || strings.HasPrefix(relToBase, "..") {
+		return filesRepositoryAdapterPort.ErrPathEscape
+	}
+
+	// Check parent directories for symlinks (symlink race prevention)
+	if err := checkParents(baseAbs, filepath.Dir(targetFileAbs)); err != nil {
+		return err
+	}
+
+	// Check file exists and is a regular file
+	info, err := os.Lstat(targetFileAbs)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return filesRepositoryAdapterPort.ErrFileNotFound
+		}
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return filesRepositoryAdapterPort.ErrInvalidPath
+	}
+
+	// Check size limit, or without one keep the write from leaving a hole
+	if a.fileMaxSize > 0 {
+		if data.File.Size > a.fileMaxSize || data.Offset > a.fileMaxSize-data.File.Size {
+			return filesRepositoryAdapterPort.ErrFileTooLarge
+		}
+	} else if data.Offset > info.Size() {
+		return filesRepositoryAdapterPort.ErrInvalidRange
+	}
+
+	// Open source file
+	src, err := data.File.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	// Open target file for writing, without following a symlink
+	dst, err := openInBase(baseAbs, relToBase, os.O_WRONLY)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return filesRepositoryAdapterPort.ErrFileNotFound
+		}
+		return err
+	}
+	defer dst.Close()
+
+	// Write content at offset, no more than its declared size
+	if _, err := io.Copy(io.NewOffsetWriter(dst, data.Offset), io.LimitReader(src, data.File.Size)); err != nil {
+		return storageError(err)
+	}
+	return storageError(dst.Close())
+}
+
+/*
+WriteFileRange securely writes one chunk of a Content-Range upload into a file
+inside the adapter's base path.
+
+This function performs the same path checks as WriteFileAt:
+
+ 1. Validates that the file path is non-empty and does not traverse outside
+    the base directory, and that the chunk lies within Total
+    (ErrInvalidRange otherwise).
+ 2. Resolves the absolute path and ensures it is inside storeLocalRootPath.
+ 3. Checks that all parent directories do not contain symlinks (symlink race prevention).
+ 4. Rejects uploads whose Total exceeds fileMaxSize.
+ 5. A chunk starting at 0 applies the create mode like CreateFile:
+    CreateModeCreate (default) rejects an existing file with ErrFileExist,
+    CreateModeReplace a missing one with ErrFileNotFound and CreateModeUpsert
+    accepts both. New files must fit in dirMaxEntries.
+ 6. Writes the chunk with WriteAt into a temp file of the upload (see
+    rangePartName, in storeLocalTempPath when it is on the same device as
+    the target directory, see tempDir). A chunk starting at 0 creates it
+    pre-allocated to Total; later chunks require it to exist
+    (ErrFileNotFound) with exactly Total bytes. A full disk or exhausted
+    quota is reported as ErrStorageFull.
+ 7. Once Complete is set, syncs the temp file and moves it into place (see
+    moveIntoPlace), failing with ErrFileExist if a file was created meanwhile
+    in CreateModeCreate, or with ErrFileNotFound if it was removed meanwhile
+    in CreateModeReplace; the temp file is then removed. The target is never
+    modified before, so readers see either the previous file or the
+    complete upload.
+
+Temp files of abandoned uploads are removed by a later first chunk once they
+are rangePartTtl old. Tracking which ranges were received is left to the
+caller.
+*/
+func (a *adapter) WriteFileRange(ctx context.Context, data *filesRepositoryAdapterPort.WriteFileRangeData) error {
+	if data.Path == "" {
+		return filesRepositoryAdapterPort.ErrInvalidPath
+	}
+	if data.Start < 0 || data.Total <= 0 || data.Start+int64(len(data.Content)) > data.Total {
+		return filesRepositoryAdapterPort.ErrInvalidRange
+	}
+
+	cleanPath := filepath.Clean(data.Path)
+	if cleanPath == "." {
+		return filesRepositoryAdapterPort.ErrInvalidPath
+	}
+	if strings.HasPrefix(cleanPath, "..") {
+		return filesRepositoryAdapterPort.ErrPathEscape
+	}
+
+	baseAbs, err := filepath.Abs(a.storeLocalRootPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve base path: %w", err)
+	}
+
+	targetFileAbs, err := filepath.Abs(filepath.Join(baseAbs, cleanPath))
+	if err != nil {
+		return filesRepositoryAdapterPort.ErrInvalidPath
+	}
+
+	// Ensure file is inside base
+	relToBase, err := filepath.Rel(baseAbs, targetFileAbs)
+	if err != nil || strings.HasPrefix(relToBase, "..") {
+		return filesRepositoryAdapterPort.ErrPathEscape
+	}
+
+	// Check parent directories for symlinks (symlink race prevention)
+	if err := checkParents(baseAbs, filepath.Dir(targetFileAbs)); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return filesRepositoryAdapterPort.ErrDirNotFound
+		}
+		return err
+	}
+
+	// Check size limit
+	if a.fileMaxSize > 0 && data.Total > a.fileMaxSize {
+		return filesRepositoryAdapterPort.ErrFileTooLarge
+	}
+
+	// Check file existence against the create mode
+	targetDirAbs := filepath.Dir(targetFileAbs)
+	info, err := os.Lstat(targetFileAbs)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	exists := err == nil
+	if exists && !info.Mode().IsRegular() {
+		return filesRepositoryAdapterPort.ErrInvalidPath
+	}
+	if data.Start == 0 {
+		if a.caseInsensitiveNames {
+			if variant, err := caseVariantExists(targetDirAbs, filepath.Base(targetFileAbs)); err != nil {
+				return err
+			} else if variant {
+				return filesRepositoryAdapterPort.ErrFileExist
+			}
+		}
+		switch data.Mode {
+		case filesRepositoryAdapterPort.CreateModeUpsert:
+		case filesRepositoryAdapterPort.CreateModeReplace:
+			if !exists {
+				return filesRepositoryAdapterPort.ErrFileNotFound
+			}
+		default:
+			if exists {
+				return filesRepositoryAdapterPort.ErrFileExist
+			}
+		}
+		if !exists {
+			if full, err := storefs.DirFull(targetDirAbs, a.dirMaxEntries); err != nil {
+				return fmt.Errorf("failed to count entries: %w", err)
+			} else if full {
+				return filesRepositoryAdapterPort.ErrDirFull
+			}
+		}
+	}
+
+	// Open the temp file of the upload, creating and pre-allocating it for
+	// the first chunk
+	tempDirAbs := a.tempDir(targetDirAbs)
+	partAbs := filepath.Join(tempDirAbs, rangePartName(targetFileAbs, data.UploadId))
+	var dst *os.File
+	if data.Start == 0 {
+		removeStaleRangeParts(tempDirAbs)
+		if dst, err = os.OpenFile(partAbs, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600); err != nil {
+			return storageError(err)
+		}
+		defer dst.Close()
+		if err := dst.Truncate(data.Total); err != nil {
+			os.Remove(partAbs)
+			return storageError(err)
+		}
+	} else {
+		if dst, err = os.OpenFile(partAbs, os.O_WRONLY, 0); err != nil {
+			if os.IsNotExist(err) {
+				return filesRepositoryAdapterPort.ErrFileNotFound
+			}
+			return err
+		}
+		defer dst.Close()
+		if info, err := dst.Stat(); err != nil {
+			return err
+		} else if info.Size() != data.Total {
+			return filesRepositoryAdapterPort.ErrInvalidRange
+		}
+	}
+
+	// Write chunk at its offset
+	if _, err := dst.WriteAt(data.Content, data.Start); err != nil {
+		return storageError(err)
+	}
+	if !data.Complete {
+		return storageError(dst.Close())
+	}
+
+	// Move the completed file into place, without overwriting a file
+	// created since the existence check unless the create mode allows
+	// replacing
+	if err := dst.Sync(); err != nil {
+		return storageError(err)
+	}
+	if err := dst.Close(); err != nil {
+		return storageError(err)
+	}
+	if data.Mode == filesRepositoryAdapterPort.CreateModeReplace {
+		if _, err := os.Lstat(targetFileAbs); err != nil {
+			os.Remove(partAbs)
+			if os.IsNotExist(err) {
+				return filesRepositoryAdapterPort.ErrFileNotFound
+			}
+			return err
+		}
+	}
+	replace := data.Mode == filesRepositoryAdapterPort.CreateModeReplace || data.Mode == filesRepositoryAdapterPort.CreateModeUpsert
+	if err := moveIntoPlace(partAbs, targetFileAbs, replace); err != nil {
+		os.Remove(partAbs)
+		return err
+	}
+	return nil
+}
+
+// Time after which the temp file of an abandoned range upload is removed
+const rangePartTtl = 24 * time.Hour
+
+// Return the name of the temp file staging the range upload uploadId of the
+// file targetAbs. It is derived from both, so every instance sharing the
+// store finds it, and the uploads of equally named files in different
+// directories sharing storeLocalTempPath do not collide.
+func rangePartName(targetAbs, uploadId string) string {
+	sum := sha256.Sum256([]byte(targetAbs + "\x00" + uploadId))
+	return "." + filepath.Base(targetAbs) + ".tmp-range-" + hex.EncodeToString(sum[:16])
+}
+
+// Remove the temp files of range uploads in dirAbs left untouched for
+// rangePartTtl, best effort.
+func removeStaleRangeParts(dirAbs string) {
+	entries, err := os.ReadDir(dirAbs)
+	if err != nil {
+		return
+	}
+	cutoff := time.Now().Add(-rangePartTtl)
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.Type().IsRegular() || !strings.HasPrefix(name, ".") || !strings.Contains(name, ".tmp-range-") {
+			continue
+		}
+		if info, err := entry.Info(); err == nil && info.ModTime().Before(cutoff) {
+			os.Remove(filepath.Join(dirAbs, name))
+		}
+	}
+}
+
+/*
+StatFile securely returns information about a file or directory within the
+adapter's base path.
+
+This function performs the same path checks as DeleteFile:
+
+ 1. Validates that the path is non-empty and does not traverse outside the base directory.
+ 2. Resolves the absolute path and ensures it is inside storeLocalRootPath.
+ 3. Checks that all parent directories do not contain symlinks (symlink race prevention).
+ 4. Returns ErrFileNotFound when nothing exists at the path, and rejects a
+    symlink at the path with ErrPathEscape instead of following it.
+
+Size and MimeType are set for files only, Encoding only for text files when
+WithEncoding is set. AccessTime is set where the platform reliably tracks it.
+*/
+func (a *adapter) StatFile(ctx context.Context, data *filesRepositoryAdapterPort.StatFileData) (*filesRepositoryAdapterPort.FileResult, error) {
+	if data.Path == "" {
+		return nil, filesRepositoryAdapterPort.ErrInvalidPath
+	}
+
+	cleanPath := filepath.Clean(data.Path)
+	if cleanPath == "." {
+		return nil, filesRepositoryAdapterPort.ErrInvalidPath
+	}
+	if strings.HasPrefix(cleanPath, "..") {
+		return nil, filesRepositoryAdapterPort.ErrPathEscape
+	}
+
+	baseAbs, err := filepath.Abs(a.storeLocalRootPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve base path: %w", err)
+	}
+
+	targetAbs, err := filepath.Abs(filepath.Join(baseAbs, cleanPath))
+	if err != nil {
+		return nil, filesRepositoryAdapterPort.ErrInvalidPath
+	}
+
+	// Ensure target is inside base
+	relToBase, err := filepath.Rel(baseAbs, targetAbs)
+	if err != nil || strings.HasPrefix(relToBase, "..") {
+		return nil, filesRepositoryAdapterPort.ErrPathEscape
+	}
+
+	// Check parent directories for symlinks (symlink race prevention)
+	if err := checkParents(baseAbs, filepath.Dir(targetAbs)); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, filesRepositoryAdapterPort.ErrFileNotFound
+		}
+		return nil, err
+	}
+
+	// Stat target, without following a symlink
+	info, err := os.Lstat(targetAbs)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, filesRepositoryAdapterPort.ErrFileNotFound
+		}
+		return nil, err
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		return nil, filesRepositoryAdapterPort.ErrPathEscape
+	}
+
+	// Build result
+	result := filesRepositoryAdapterPort.FileResult{
+		Name:  info.Name(),
+		IsDir: info.IsDir(),
+	}
+	if atimeReliable(filepath.Dir(targetAbs)) {
+		result.AccessTime = accessTime(info)
+	}
+	if !info.IsDir() {
+		s := info.Size()
+		result.Size = &s
+		if mt, head, err := a.detectMimeType(targetAbs, data.WithEncoding); err == nil {
+			result.MimeType = &mt
+			if data.WithEncoding {
+				result.Encoding = detectEncoding(mt, head)
+			}
+		}
+		if expiresAt := a.readExpiry(relToBase, info); expiresAt != nil {
+			expiresIn := max(int64(time.Until(*expiresAt).Seconds()), 0)
+			result.ExpiresAt = expiresAt
+			result.ExpiresIn = &expiresIn
+		}
+	}
+	return &result, nil
+}
+
+/*
+PreviewFile returns the head of a text file for preview purposes.
+
+ 1. Reuses StatFile for the path, traversal and symlink guards, and opens
+    the file without following a symlink swapped in since (see openInBase).
+ 2. Rejects directories with ErrInvalidPath and files whose detected MIME type
+    is not textual with ErrFileNotText.
+ 3. Reads only the requested prefix: at most Bytes bytes, capped by
+    previewMaxBytes (which is also the default when Bytes is 0), and, if Lines
+    is set, at most that many lines.
+ 4. Drops a trailing incomplete UTF-8 sequence cut by the byte limit.
+
+Truncated reports whether the file holds more content than returned.
+*/
+func (a *adapter) PreviewFile(ctx context.Context, data *filesRepositoryAdapterPort.PreviewFileData) (*filesRepositoryAdapterPort.PreviewResult, error) {
+	if data.Bytes < 0 || data.Lines < 0 {
+		return nil, filesRepositoryAdapterPort.ErrInvalidPath
+	}
+
+	// Check path and type
+	stat, err := a.StatFile(ctx, &filesRepositoryAdapterPort.StatFileData{Path: data.Path})
+	if err != nil {
+		return nil, err
+	}
+	if stat.IsDir {
+		return nil, filesRepositoryAdapterPort.ErrInvalidPath
+	}
+	if stat.MimeType == nil || !isTextMimeType(*stat.MimeType) {
+		return nil, filesRepositoryAdapterPort.ErrFileNotText
+	}
+
+	// Clamp size
+	maxBytes := a.previewMaxBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultPreviewMaxBytes
+	}
+	limit := data.Bytes
+	if limit == 0 || limit > maxBytes {
+		limit = maxBytes
+	}
+
+	baseAbs, err := filepath.Abs(a.storeLocalRootPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve base path: %w", err)
+	}
+
+	// Read prefix
+	f, err := openInBase(baseAbs, filepath.Clean(data.Path), os.O_RDONLY)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, filesRepositoryAdapterPort.ErrFileNotFound
+		}
+		return nil, err
+	}
+	defer f.Close()
+	buf, err := io.ReadAll(io.LimitReader(f, limit))
+	if err != nil {
+		return nil, err
+	}
+
+	// Cut to line limit
+	if data.Lines > 0 {
+		lines := 0
+		for i, c := range buf {
+			if c == '\n' {
+				lines++
+				if lines == data.Lines {
+					buf = buf[:i+1]
+					break
+				}
+			}
+		}
+	}
+	truncated := int64(len(buf)) < *stat.Size
+
+	// Drop an incomplete trailing rune
+	if truncated {
+		for i := 0; i < utf8.UTFMax-1 && len(buf) > 0; i++ {
+			if r, size := utf8.DecodeLastRune(buf); r != utf8.RuneError || size > 1 {
+				break
+			}
+			buf = buf[:len(buf)-1]
+		}
+	}
+
+	return &filesRepositoryAdapterPort.PreviewResult{
+		Content:   string(buf),
+		MimeType:  *stat.MimeType,
+		Truncated: truncated,
+	}, nil
+}
+
+// Report whether the MIME type denotes textual content
+func isTextMimeType(mimeType string) bool {
+	mediaType, _, _ := strings.Cut(mimeType, ";")
+	switch mediaType {
+	case "application/json", "application/xml", "application/javascript":
+		return true
+	}
+	return strings.HasPrefix(mediaType, "text/")
+}
+
+/*
+detectMimeType determines the MIME type of a file according to mimeDetection.
+
+| Strategy    | MIME type from                                           |
+|-------------|----------------------------------------------------------|
+| "content"   | Sniffing the first 512 bytes (default)                   |
+| "extension" | The extension only, application/octet-stream if unknown  |
+| "hybrid"    | The extension, sniffing files with an unknown one        |
+
+The extension strategies do not open the file unless withHead is set and the
+type is textual. The head read for sniffing (or for withHead) is returned
+along with the type for further inspection, nil if the file was not read.
+*/
+func (a *adapter) detectMimeType(fileAbs string, withHead bool) (string, []byte, error) {
+	if mt := a.extensionMimeType(fileAbs); mt != "" {
+		if !withHead || !isTextMimeType(mt) {
+			return mt, nil, nil
+		}
+		head, err := readHead(fileAbs)
+		return mt, head, err
+	}
+	head, err := readHead(fileAbs)
+	if err != nil {
+		return "", nil, err
+	}
+	return http.DetectContentType(head), head, nil
+}
+
+// Report whether an entry is a file, or a symlink followed to one, whose
+// detected MIME type has the given top-level type
+func (a *adapter) inMimeCategory(baseAbs, entryAbs, category string) bool {
+	info, err := os.Lstat(entryAbs)
+	if err != nil {
+		return false
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		var ok bool
+		if entryAbs, info, ok = a.followSymlink(baseAbs, entryAbs); !ok {
+			return false
+		}
+	}
+	if info.IsDir() {
+		return false
+	}
+	mt, _, err := a.detectMimeType(entryAbs, false)
+	return err == nil && strings.HasPrefix(mt, category+"/")
+}
+
+// Return the MIME type of a file name by its extension under the extension
+// strategies ("" if the content must be sniffed)
+func (a *adapter) extensionMimeType(name string) string {
+	if a.mimeDetection != MimeDetectionExtension && a.mimeDetection != MimeDetectionHybrid {
+		return ""
+	}
+	mt := mime.TypeByExtension(filepath.Ext(name))
+	if mt == "" && a.mimeDetection == MimeDetectionExtension {
+		mt = "application/octet-stream"
+	}
+	return mt
+}
+
+// Read the first 512 bytes of a file
+func readHead(fileAbs string) ([]byte, error) {
+	f, err := os.Open(fileAbs)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	buf := make([]byte, 512)
+	n, _ := f.Read(buf)
+	return buf[:n], nil
+}
+
+/*
+detectEncoding guesses the character encoding of a text file from its head.
+
+ 1. A byte order mark identifies "utf-8", "utf-16le" or "utf-16be".
+ 2. A head that is valid UTF-8 (ignoring a rune cut at the end) is "utf-8".
+ 3. A head free of C0/C1 control characters (other than tab, newline and
+    carriage return) is "iso-8859-1".
+
+Returns nil for non-text MIME types and when detection is inconclusive.
+*/
+func detectEncoding(mimeType string, head []byte) *string {
+	if !isTextMimeType(mimeType) {
+		return nil
+	}
+
+	encoding := ""
+	switch {
+	case bytes.HasPrefix(head, []byte{0xEF, 0xBB, 0xBF}):
+		encoding = "utf-8"
+	case bytes.HasPrefix(head, []byte{0xFF, 0xFE}):
+		encoding = "utf-16le"
+	case bytes.HasPrefix(head, []byte{0xFE, 0xFF}):
+		encoding = "utf-16be"
+	case validUTF8Prefix(head):
+		encoding = "utf-8"
+	case latin1(head):
+		encoding = "iso-8859-1"
+	default:
+		return nil
+	}
+	return &encoding
+}
+
+// Report whether b is valid UTF-8, allowing a rune cut at the end
+func validUTF8Prefix(b []byte) bool {
+	for i := 0; i < utf8.UTFMax-1 && len(b) > 0 && !utf8.Valid(b); i++ {
+		b = b[:len(b)-1]
+	}
+	return utf8.Valid(b)
+}
+
+// Report whether b looks like ISO-8859-1 text
+func latin1(b []byte) bool {
+	for _, c := range b {
+		if (c < 0x20 && c != '\t' && c != '\n' && c != '\r') || (c >= 0x7F && c < 0xA0) {
+			return false
+		}
+	}
+	return true
+}
+
+// Describe why a listed entry could not be read
+func entryError(err error) string {
+	switch {
+	case os.IsPermission(err):
+		return "permission_denied"
+	case os.IsNotExist(err):
+		return "not_found"
+	default:
+		return "unreadable"
+	}
+}
+
+// Read the directory entries, failing with ErrTooManyEntries when there are
+// more than listMaxEntries. Reads at most one entry past the cap.
+func (a *adapter) readDir(dirAbs string) ([]os.DirEntry, error) {
+	if a.listMaxEntries <= 0 {
+		return os.ReadDir(dirAbs)
+	}
+	dir, err := os.Open(dirAbs)
+	if err != nil {
+		return nil, err
+	}
+	defer dir.Close()
+	entries, err := dir.ReadDir(a.listMaxEntries + 1)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if len(entries) > a.listMaxEntries {
+		return nil, filesRepositoryAdapterPort.ErrTooManyEntries
+	}
+	return entries, nil
+}
+
+// Report whether targetAbs does not exist while its deepest existing ancestor
+// is a directory passing the listing's symlink checks, so an empty listing
+// can stand in for it. Paths through a symlink or a file never qualify.
+func (a *adapter) missingDir(baseAbs, targetAbs string) bool {
+	existingAbs := targetAbs
+	for {
+		if _, err := os.Lstat(existingAbs); err == nil {
+			break
+		} else if !os.IsNotExist(err) || existingAbs == baseAbs {
+			return false
+		}
+		existingAbs = filepath.Dir(existingAbs)
+	}
+	if existingAbs == targetAbs {
+		return false
+	}
+	readAbs, err := a.listPath(baseAbs, existingAbs)
+	if err != nil {
+		return false
+	}
+	info, err := os.Stat(readAbs)
+	return err == nil && info.IsDir()
+}
+
+// Return the listing of a missing path: empty if EmptyIfMissing is set,
+// ErrDirNotFound otherwise
+func missingListing(data *filesRepositoryAdapterPort.GetFilesData) (*filesRepositoryAdapterPort.GetFilesResult, error) {
+	if data.EmptyIfMissing {
+		return &filesRepositoryAdapterPort.GetFilesResult{Files: []filesRepositoryAdapterPort.FileResult{}}, nil
+	}
+	return nil, filesRepositoryAdapterPort.ErrDirNotFound
+}
+
+// Resolve the directory to read for a listing. Without followSymlinks the
+// parents are checked for symlinks, otherwise they are resolved and the
+// result must stay inside the base directory.
+func (a *adapter) listPath(baseAbs, targetAbs string) (string, error) {
+	if !a.followSymlinks {
+		if err := checkParents(baseAbs, targetAbs); err != nil {
+			return "", parentsError(err)
+		}
+		return targetAbs, nil
+	}
+	resolved, err := resolveInBase(baseAbs, targetAbs)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", filesRepositoryAdapterPort.ErrDirNotFound
+		}
+		return "", filesRepositoryAdapterPort.ErrInvalidPath
+	}
+	return resolved, nil
+}
+
+// Resolve a listed symlink entry if followSymlinks is set and the link
+// points at a directory or regular file inside the base directory
+func (a *adapter) followSymlink(baseAbs, linkAbs string) (string, os.FileInfo, bool) {
+	if !a.followSymlinks {
+		return "", nil, false
+	}
+	resolved, err := resolveInBase(baseAbs, linkAbs)
+	if err != nil {
+		return "", nil, false
+	}
+	info, err := os.Stat(resolved)
+	if err != nil || !(info.IsDir() || info.Mode().IsRegular()) {
+		return "", nil, false
+	}
+	return resolved, info, true
+}
+
+// Resolve all symlinks in path and ensure the result is inside the base
+// directory, itself resolved so a symlinked root still matches
+func resolveInBase(baseAbs, path string) (string, error) {
+	baseReal, err := filepath.EvalSymlinks(baseAbs)
+	if err != nil {
+		return "", err
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(baseReal, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", filesRepositoryAdapterPort.ErrPathEscape
+	}
+	return resolved, nil
+}
+
+// Count the direct children of a directory and, if withSize is set, sum the
+// sizes of its direct child files. Returns nils if the directory is unreadable.
+func dirStats(dirAbs string, withSize bool) (*int, *int64) {
+	entries, err := os.ReadDir(dirAbs)
+	if err != nil {
+		return nil, nil
+	}
+	count := len(entries)
+	if !withSize {
+		return &count, nil
+	}
+	var size int64
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		if info, err := entry.Info(); err == nil {
+			size += info.Size()
+		}
+	}
+	return &count, &size
+}
+
+// Build the entry of a listed directory itself: its ModTime, its AccessTime
+// if trusted and its ChildCount and ChildrenSize as for the directory entries
+// of the listing.
+func (a *adapter) selfEntry(readAbs, relDir string, withAccessTime bool, data *filesRepositoryAdapterPort.GetFilesData) (*filesRepositoryAdapterPort.FileResult, error) {
+	info, err := os.Stat(readAbs)
+	if err != nil {
+		return nil, err
+	}
+	modTime := info.ModTime()
+	self := filesRepositoryAdapterPort.FileResult{
+		Name:    ".",
+		IsDir:   true,
+		ModTime: &modTime,
+		IsSelf:  true,
+	}
+	if data.WithPath {
+		p := ""
+		if relDir != "." {
+			p = filepath.ToSlash(relDir)
+		}
+		self.Path = &p
+	}
+	if withAccessTime {
+		self.AccessTime = accessTime(info)
+	}
+	if data.WithDirStats {
+		self.ChildCount, self.ChildrenSize = dirStats(readAbs, data.WithDirSize)
+	}
+	return &self, nil
+}
+
+// Read a small file as base64 for inlining into a listing. Returns nil if the
+// file cannot be read or has grown past listInlineMaxSize.
+func (a *adapter) inlineContent(path string) *string {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	content, err := io.ReadAll(io.LimitReader(f, a.listInlineMaxSize+1))
+	if err != nil || int64(len(content)) > a.listInlineMaxSize {
+		return nil
+	}
+	encoded := base64.StdEncoding.EncodeToString(content)
+	return &encoded
+}
+
+// Report a failed parent walk as ErrPathEscape if it found a symlink or left
+// the base, else (e.g. a missing parent) as ErrInvalidPath
+func parentsError(err error) error {
+	if errors.Is(err, filesRepositoryAdapterPort.ErrPathEscape) {
+		return err
+	}
+	return filesRepositoryAdapterPort.ErrInvalidPath
+}
+
+// Walk from start up to the base directory, rejecting symlinked components
+// with ErrPathEscape, see storefs.CheckParents
+func checkParents(baseAbs, start string) error {
+	return storefs.CheckParents(baseAbs, start, filesRepositoryAdapterPort.ErrPathEscape)
 }
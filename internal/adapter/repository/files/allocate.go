@@ -0,0 +1,124 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	filesRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/files"
+	"github.com/flash-go/files-service/internal/storefs"
+)
+
+/*
+AllocateFile creates a new file of a given size inside the adapter's base path
+without writing real data, reserving space for later WriteFileAt or range
+writes.
+
+This function performs the same path checks as WriteFileRange:
+
+ 1. Validates that the file path is non-empty, the size is not negative and
+    the path does not traverse outside the base directory.
+ 2. Resolves the absolute path and ensures it is inside storeLocalRootPath.
+ 3. Checks that all parent directories do not contain symlinks (symlink race prevention).
+ 4. Rejects sizes above fileMaxSize, existing targets (ErrFileExist) and
+    directories already holding dirMaxEntries entries.
+ 5. Sparse files are only truncated to size, so no blocks are reserved.
+    Otherwise the blocks are allocated up front (fallocate on Linux, zeros
+    written elsewhere or where unsupported), so a full disk or exhausted quota
+    is reported now as ErrStorageFull rather than midway through an upload.
+    A failed allocation removes the file again.
+*/
+func (a *adapter) AllocateFile(ctx context.Context, data *filesRepositoryAdapterPort.AllocateFileData) error {
+	if data.Path == "" || data.Size < 0 {
+		return filesRepositoryAdapterPort.ErrInvalidPath
+	}
+
+	cleanPath := filepath.Clean(data.Path)
+	if cleanPath == "." {
+		return filesRepositoryAdapterPort.ErrInvalidPath
+	}
+	if strings.HasPrefix(cleanPath, "..") {
+		return filesRepositoryAdapterPort.ErrPathEscape
+	}
+
+	baseAbs, err := filepath.Abs(a.storeLocalRootPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve base path: %w", err)
+	}
+
+	targetFileAbs, err := filepath.Abs(filepath.Join(baseAbs, cleanPath))
+	if err != nil {
+		return filesRepositoryAdapterPort.ErrInvalidPath
+	}
+
+	// Ensure file is inside base
+	relToBase, err := filepath.Rel(baseAbs, targetFileAbs)
+	if err != nil || strings.HasPrefix(relToBase, "..") {
+		return filesRepositoryAdapterPort.ErrPathEscape
+	}
+
+	// Check parent directories for symlinks (symlink race prevention)
+	if err := checkParents(baseAbs, filepath.Dir(targetFileAbs)); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return filesRepositoryAdapterPort.ErrDirNotFound
+		}
+		return err
+	}
+
+	// Check size limit
+	if a.fileMaxSize > 0 && data.Size > a.fileMaxSize {
+		return filesRepositoryAdapterPort.ErrFileTooLarge
+	}
+
+	// Check entries limit
+	if full, err := storefs.DirFull(filepath.Dir(targetFileAbs), a.dirMaxEntries); err != nil {
+		return fmt.Errorf("failed to count entries: %w", err)
+	} else if full {
+		return filesRepositoryAdapterPort.ErrDirFull
+	}
+
+	// Create file
+	dst, err := os.OpenFile(targetFileAbs, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		if os.IsExist(err) {
+			return filesRepositoryAdapterPort.ErrFileExist
+		}
+		return storageError(err)
+	}
+	defer dst.Close()
+
+	// Allocate blocks
+	if data.Sparse {
+		err = dst.Truncate(data.Size)
+	} else {
+		err = allocate(dst, data.Size)
+	}
+	if err == nil {
+		err = dst.Close()
+	}
+	if err != nil {
+		os.Remove(targetFileAbs)
+		return storageError(err)
+	}
+	return nil
+}
+
+// Reserve blocks by writing size zero bytes, for filesystems without
+// fallocate support
+func writeZeros(f *os.File, size int64) error {
+	_, err := io.CopyN(f, zeroReader{}, size)
+	return err
+}
+
+// Reader of endless zero bytes
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
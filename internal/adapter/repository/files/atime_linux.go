@@ -0,0 +1,31 @@
+//go:build linux
+
+package adapter
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// Mount flag set on noatime mounts (statvfs ST_NOATIME)
+const stNoAtime = 0x400
+
+// Report whether access times are updated on the filesystem holding the path
+func atimeReliable(path string) bool {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return false
+	}
+	return stat.Flags&stNoAtime == 0
+}
+
+// Return the last access time of the entry
+func accessTime(info os.FileInfo) *time.Time {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	t := time.Unix(int64(stat.Atim.Sec), int64(stat.Atim.Nsec))
+	return &t
+}
@@ -0,0 +1,18 @@
+//go:build !linux
+
+package adapter
+
+import (
+	"os"
+	"time"
+)
+
+// Access times are not exposed on this platform
+func atimeReliable(path string) bool {
+	return false
+}
+
+// Access times are not exposed on this platform
+func accessTime(info os.FileInfo) *time.Time {
+	return nil
+}
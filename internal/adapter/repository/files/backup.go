@@ -0,0 +1,68 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	filesRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/files"
+)
+
+// Return the directory receiving backups of replaced files: the versions
+// path, else the backup path, else the trash path ("" when none is
+// configured)
+func (a *adapter) backupRoot() string {
+	if a.storeLocalVersionsPath != "" {
+		return a.storeLocalVersionsPath
+	}
+	if a.storeLocalBackupPath != "" {
+		return a.storeLocalBackupPath
+	}
+	return a.storeLocalTrashPath
+}
+
+// Hard-link a file about to be replaced as <backup>/<unix nanos>/<relPath>,
+// or as the version <versions>/<relPath>/<unix nanos> if the versions path is
+// set, so the link keeps the old content once the new file is renamed over
+// it. Returns the absolute and the slash-separated backup path relative to
+// the backup root.
+func (a *adapter) backupFile(fileAbs, relPath string) (string, string, error) {
+	if a.storeLocalVersionsPath != "" {
+		return a.versionFile(fileAbs, relPath)
+	}
+	root := a.backupRoot()
+	if root == "" {
+		return "", "", filesRepositoryAdapterPort.ErrBackupUnavailable
+	}
+	rel := filepath.Join(strconv.FormatInt(time.Now().UnixNano(), 10), relPath)
+	backupAbs := filepath.Join(root, rel)
+	if err := os.MkdirAll(filepath.Dir(backupAbs), 0700); err != nil {
+		return "", "", fmt.Errorf("failed to create backup dir: %w", err)
+	}
+	if err := os.Link(fileAbs, backupAbs); err != nil {
+		return "", "", fmt.Errorf("failed to back up file: %w", err)
+	}
+	return backupAbs, filepath.ToSlash(rel), nil
+}
+
+/*
+PurgeBackups permanently deletes backups of replaced files made before
+CreatedBefore.
+
+Backups are named like trash entries (see backupFile), so only the separate
+backup path is purged here; backups kept in the trash follow the trash
+retention. Returns the number of purged entries; purging stops at the first
+error.
+*/
+func (a *adapter) PurgeBackups(ctx context.Context, data *filesRepositoryAdapterPort.PurgeBackupsData) (*filesRepositoryAdapterPort.PurgeBackupsResult, error) {
+	result := filesRepositoryAdapterPort.PurgeBackupsResult{}
+	if a.storeLocalBackupPath == "" {
+		return &result, nil
+	}
+	purged, err := purgeBefore(a.storeLocalBackupPath, data.CreatedBefore)
+	result.Purged = purged
+	return &result, err
+}
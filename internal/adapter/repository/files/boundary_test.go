@@ -0,0 +1,64 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	filesRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/files"
+)
+
+func TestRenameAndMoveRejectCrossNamespace(t *testing.T) {
+	// The root store holds the root of the "media" namespace
+	dir := t.TempDir()
+	media := filepath.Join(dir, "media")
+	for _, d := range []string{media, filepath.Join(dir, "docs"), filepath.Join(media, "docs")} {
+		if err := os.MkdirAll(d, 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, f := range []string{"notes.txt", "media/photo.png"} {
+		if err := os.WriteFile(filepath.Join(dir, f), []byte("content"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a := &adapter{storeLocalRootPath: dir, namespaceRoots: absRoots([]string{dir, media})}
+
+	tests := []struct {
+		oldPath string
+		newPath string
+	}{
+		{"notes.txt", "media/notes.txt"},
+		{"notes.txt", "media/docs/notes.txt"},
+		{"media/photo.png", "docs/photo.png"},
+	}
+	for _, tt := range tests {
+		err := a.RenameFile(context.Background(), &filesRepositoryAdapterPort.RenameFileData{
+			OldPath: tt.oldPath,
+			NewPath: tt.newPath,
+		})
+		if !errors.Is(err, filesRepositoryAdapterPort.ErrCrossNamespace) {
+			t.Errorf("RenameFile(%q, %q) = %v, want ErrCrossNamespace", tt.oldPath, tt.newPath, err)
+		}
+		_, err = a.MoveFile(context.Background(), &filesRepositoryAdapterPort.MoveFileData{
+			SourcePath: tt.oldPath,
+			DestPath:   filepath.Dir(tt.newPath),
+		})
+		if !errors.Is(err, filesRepositoryAdapterPort.ErrCrossNamespace) {
+			t.Errorf("MoveFile(%q, %q) = %v, want ErrCrossNamespace", tt.oldPath, filepath.Dir(tt.newPath), err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, tt.oldPath)); err != nil {
+			t.Errorf("%s after rejected move: %v", tt.oldPath, err)
+		}
+	}
+
+	// Moves within one namespace are still allowed
+	if err := a.RenameFile(context.Background(), &filesRepositoryAdapterPort.RenameFileData{
+		OldPath: "media/photo.png",
+		NewPath: "media/docs/photo.png",
+	}); err != nil {
+		t.Errorf("RenameFile within media: %v", err)
+	}
+}
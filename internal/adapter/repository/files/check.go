@@ -0,0 +1,142 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	filesRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/files"
+	"github.com/flash-go/files-service/internal/storefs"
+)
+
+/*
+CheckPath reports which of the path safety rules applied by the operations of
+this adapter accept a path for the given operation, and which one rejects it
+with which error, without changing anything. The rules run in this order and
+stop at the first rejection:
+
+ 1. PathRuleCharacters: rejects NUL and other control characters with
+    ErrInvalidCharacters.
+ 2. PathRuleEmpty: rejects the base directory itself ("", "." or "/") with
+    ErrInvalidPath, except for PathOpList and PathOpUpload, which take a
+    directory.
+ 3. PathRuleAbsolute: always passes, as a leading separator is dropped and
+    the path anchored at the base directory rather than the filesystem root.
+ 4. PathRuleTraversal: rejects paths that resolve outside the base directory
+    after cleaning with ErrPathEscape.
+ 5. PathRuleDepth: rejects paths nested deeper than the parent walk allows
+    (maxParentWalk) with ErrPathEscape, and for PathOpUpload paths with more
+    than maxCreateDepth missing directories with ErrInvalidPath.
+ 6. PathRuleSymlink: walks the parent directories of the deepest existing
+    component with Lstat, rejecting symlinked ones with ErrPathEscape. For
+    PathOpList and PathOpUpload the target directory counts as a parent.
+
+Path is slash-separated and relative to the base directory once the traversal
+rule passed, the input path otherwise. Whether the entry exists or has the
+right type is not checked (see ResolvePath).
+*/
+func (a *adapter) CheckPath(ctx context.Context, data *filesRepositoryAdapterPort.CheckPathData) (*filesRepositoryAdapterPort.CheckPathResult, error) {
+	baseAbs, err := filepath.Abs(a.storeLocalRootPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve base path: %w", err)
+	}
+
+	result := filesRepositoryAdapterPort.CheckPathResult{
+		Path:      data.Path,
+		Operation: data.Operation,
+		Checks:    []filesRepositoryAdapterPort.PathCheck{},
+	}
+
+	// Record the outcome of a rule, reporting whether it passed
+	check := func(rule string, err error) bool {
+		result.Checks = append(result.Checks, filesRepositoryAdapterPort.PathCheck{
+			Rule:   rule,
+			Passed: err == nil,
+		})
+		if err != nil {
+			code := err.Error()
+			result.Rule = &rule
+			result.Error = &code
+		}
+		return err == nil
+	}
+	dirOp := data.Operation == filesRepositoryAdapterPort.PathOpList || data.Operation == filesRepositoryAdapterPort.PathOpUpload
+
+	// Check characters
+	var rejected error
+	if strings.IndexFunc(data.Path, unicode.IsControl) >= 0 {
+		rejected = filesRepositoryAdapterPort.ErrInvalidCharacters
+	}
+	if !check(filesRepositoryAdapterPort.PathRuleCharacters, rejected) {
+		return &result, nil
+	}
+
+	// Check for the base directory itself
+	cleanPath := filepath.Clean(data.Path)
+	if !dirOp && (cleanPath == "." || cleanPath == string(filepath.Separator)) {
+		rejected = filesRepositoryAdapterPort.ErrInvalidPath
+	}
+	if !check(filesRepositoryAdapterPort.PathRuleEmpty, rejected) {
+		return &result, nil
+	}
+
+	// Absolute paths are anchored at the base directory
+	check(filesRepositoryAdapterPort.PathRuleAbsolute, nil)
+
+	// Check traversal
+	targetAbs := filepath.Join(baseAbs, cleanPath)
+	rel, err := filepath.Rel(baseAbs, targetAbs)
+	if strings.HasPrefix(cleanPath, "..") || err != nil || strings.HasPrefix(rel, "..") {
+		rejected = filesRepositoryAdapterPort.ErrPathEscape
+	}
+	if !check(filesRepositoryAdapterPort.PathRuleTraversal, rejected) {
+		return &result, nil
+	}
+	if rel == "." {
+		rel = ""
+	}
+	result.Path = filepath.ToSlash(rel)
+
+	// Find the deepest existing component
+	existingAbs := targetAbs
+	missing := 0
+	for existingAbs != baseAbs {
+		if _, err := os.Lstat(existingAbs); err == nil {
+			break
+		} else if !os.IsNotExist(err) {
+			return nil, err
+		}
+		existingAbs = filepath.Dir(existingAbs)
+		missing++
+	}
+
+	// Check depth
+	if rel != "" && strings.Count(rel, string(filepath.Separator))+1 > storefs.MaxParentWalk {
+		rejected = filesRepositoryAdapterPort.ErrPathEscape
+	} else if data.Operation == filesRepositoryAdapterPort.PathOpUpload && missing > maxCreateDepth {
+		rejected = filesRepositoryAdapterPort.ErrInvalidPath
+	}
+	if !check(filesRepositoryAdapterPort.PathRuleDepth, rejected) {
+		return &result, nil
+	}
+
+	// Check parent directories for symlinks
+	parentAbs := existingAbs
+	if existingAbs == targetAbs && !dirOp {
+		parentAbs = filepath.Dir(existingAbs)
+	}
+	if existingAbs != baseAbs {
+		if err := checkParents(baseAbs, parentAbs); err != nil {
+			rejected = parentsError(err)
+		}
+	}
+	if !check(filesRepositoryAdapterPort.PathRuleSymlink, rejected) {
+		return &result, nil
+	}
+
+	result.Allowed = true
+	return &result, nil
+}
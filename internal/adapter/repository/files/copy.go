@@ -0,0 +1,90 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	filesRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/files"
+	"github.com/flash-go/files-service/internal/storefs"
+)
+
+/*
+CopyFile securely copies a file to another path within the adapter's base
+path, without the content leaving the store.
+
+This function applies the checks of MoveFile to both paths (see
+resolveTransfer), then:
+
+ 1. Rejects an existing destination with ErrFileExist, including the source
+    itself, and an existing directory with ErrIsDirectory.
+ 2. Rejects new files in a directory that already holds dirMaxEntries
+    entries. If checkFreeSpace is set, rejects the copy with ErrStorageFull
+    when the destination filesystem has less than the file size plus
+    freeSpaceMargin available.
+ 3. Streams the content into a temp file (in storeLocalTempPath when it is on
+    the same device as the destination directory, see tempDir), syncs it,
+    gives it the permissions and modification time of the source and moves
+    it into place without overwriting a file created meanwhile (see
+    copyInto). The temp file is removed on every failure path.
+
+The copy is a new file: the source's recorded expiry, versions and backups
+do not carry over.
+
+Allowed paths examples (assuming base is /var/data):
+
+| Input Path                      | Resulting Absolute Path              | Reason                  |
+|---------------------------------|--------------------------------------|-------------------------|
+| source: "templates/report.docx" | /var/data/templates/report.docx      | Inside base, exists     |
+| dest: "reports/q3.docx"         | /var/data/reports/q3.docx            | Inside base, dir exists |
+
+Rejected paths examples:
+
+| Input Path                 | Reason for rejection                       |
+|----------------------------|--------------------------------------------|
+| "../../etc/passwd"         | Path traversal outside base                |
+| "uploads/symlink/file.txt" | Parent directory is a symlink              |
+| "missing/file.txt"         | Destination directory does not exist       |
+| ""                         | Empty file path                            |
+*/
+func (a *adapter) CopyFile(ctx context.Context, data *filesRepositoryAdapterPort.CopyFileData) (*filesRepositoryAdapterPort.CopyFileResult, error) {
+	t, err := a.resolveTransfer(data.SourcePath, data.DestPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check destination existence
+	if destInfo, err := os.Lstat(t.destAbs); err == nil {
+		if destInfo.IsDir() {
+			return nil, filesRepositoryAdapterPort.ErrIsDirectory
+		}
+		return nil, filesRepositoryAdapterPort.ErrFileExist
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	// Check directory capacity
+	destDirAbs := filepath.Dir(t.destAbs)
+	if full, err := storefs.DirFull(destDirAbs, a.dirMaxEntries); err != nil {
+		return nil, fmt.Errorf("failed to count entries: %w", err)
+	} else if full {
+		return nil, filesRepositoryAdapterPort.ErrDirFull
+	}
+
+	// Check free space for the copy
+	if a.checkFreeSpace {
+		if free, ok := freeSpace(destDirAbs); ok && free < t.sourceInfo.Size()+a.freeSpaceMargin {
+			return nil, filesRepositoryAdapterPort.ErrStorageFull
+		}
+	}
+
+	// Copy the file into place
+	if err := copyInto(ctx, t.sourceAbs, a.tempDir(destDirAbs), t.destAbs, t.sourceInfo, false); err != nil {
+		return nil, err
+	}
+	return &filesRepositoryAdapterPort.CopyFileResult{
+		Path: filepath.ToSlash(t.destRel),
+		Size: t.sourceInfo.Size(),
+	}, nil
+}
@@ -0,0 +1,46 @@
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	filesRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/files"
+)
+
+func TestCreateFileRejectsStreamLargerThanDeclared(t *testing.T) {
+	for _, separateTemp := range []bool{false, true} {
+		dir := t.TempDir()
+		a := &adapter{storeLocalRootPath: dir, fileMaxSize: 16}
+		dirs := []string{dir}
+		if separateTemp {
+			a.storeLocalTempPath = t.TempDir()
+			dirs = append(dirs, a.storeLocalTempPath)
+		}
+
+		declared := int64(8)
+		_, err := a.CreateFile(context.Background(), &filesRepositoryAdapterPort.CreateFileData{
+			Stream: &filesRepositoryAdapterPort.FileStream{
+				Filename: "upload.bin",
+				Reader:   bytes.NewReader(make([]byte, 32)),
+			},
+			DeclaredSize: &declared,
+		})
+		if !errors.Is(err, filesRepositoryAdapterPort.ErrFileTooLarge) {
+			t.Fatalf("CreateFile = %v, want ErrFileTooLarge", err)
+		}
+
+		// Neither the file nor its temp file is left behind
+		for _, d := range dirs {
+			entries, err := os.ReadDir(d)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, entry := range entries {
+				t.Errorf("left behind %q in %s", entry.Name(), d)
+			}
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package adapter
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	filesRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/files"
+	"github.com/flash-go/files-service/internal/storefs"
+)
+
+// Return the duplicate content policy of an upload (uploadDedup if the
+// request does not set one, DedupAllow if neither does)
+func (a *adapter) dedup(data *filesRepositoryAdapterPort.CreateFileData) string {
+	if data.Dedup != "" {
+		return data.Dedup
+	}
+	if a.uploadDedup != "" {
+		return a.uploadDedup
+	}
+	return filesRepositoryAdapterPort.DedupAllow
+}
+
+/*
+findDuplicate looks for a regular file in dirAbs with the same content as the
+uploaded temp file, given its size and SHA-256, and returns its absolute path
+("" if there is none).
+
+Only direct entries of the directory with the same size are hashed. Hidden
+entries, symlinks, temp files of other uploads and the temp file itself are
+skipped. Hashes come from the hash cache shared with HashFile when the file
+did not change since, and are stored there once computed, so repeated uploads
+to a directory only read each candidate once. Stops with the context error
+once ctx is done.
+*/
+func (a *adapter) findDuplicate(ctx context.Context, dirAbs, tempAbs string, size int64, sum string) (string, error) {
+	entries, err := os.ReadDir(dirAbs)
+	if err != nil {
+		return "", err
+	}
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		name := entry.Name()
+		if !entry.Type().IsRegular() || storefs.Hidden(a.hiddenNames, name) || (strings.HasPrefix(name, ".") && strings.Contains(name, ".tmp-")) {
+			continue
+		}
+		fileAbs := filepath.Join(dirAbs, name)
+		if fileAbs == tempAbs {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.Size() != size {
+			// Removed since it was listed, or different content
+			continue
+		}
+		hash, err := a.fileHash(ctx, fileAbs, info)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return "", err
+		}
+		if hash == sum {
+			return fileAbs, nil
+		}
+	}
+	return "", nil
+}
+
+// Return the SHA-256 of a file, from the hash cache if it did not change
+func (a *adapter) fileHash(ctx context.Context, fileAbs string, info os.FileInfo) (string, error) {
+	if entry := a.cachedHash(fileAbs, info); entry != nil {
+		return entry.sha256, nil
+	}
+	f, err := os.Open(fileAbs)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	opened, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	if !os.SameFile(info, opened) {
+		// Replaced since it was listed
+		return "", os.ErrNotExist
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, contextReader{ctx, f}); err != nil {
+		return "", err
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+	a.storeHash(fileAbs, &hashEntry{info: opened, sha256: sum})
+	return sum, nil
+}
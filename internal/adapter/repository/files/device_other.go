@@ -0,0 +1,24 @@
+//go:build !unix
+
+package adapter
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// Device identity is unavailable, so never report a shared device
+func sameDevice(a, b os.FileInfo) bool {
+	return false
+}
+
+// Report whether the error is caused by a full disk
+func isStorageFull(err error) bool {
+	return errors.Is(err, syscall.ENOSPC)
+}
+
+// File identity is unavailable
+func fileID(info os.FileInfo) (uint64, bool) {
+	return 0, false
+}
@@ -0,0 +1,36 @@
+//go:build unix
+
+package adapter
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// Report whether both entries reside on the same device
+func sameDevice(a, b os.FileInfo) bool {
+	aStat, ok := a.Sys().(*syscall.Stat_t)
+	if !ok {
+		return false
+	}
+	bStat, ok := b.Sys().(*syscall.Stat_t)
+	if !ok {
+		return false
+	}
+	return aStat.Dev == bStat.Dev
+}
+
+// Report whether the error is caused by a full disk or exhausted quota
+func isStorageFull(err error) bool {
+	return errors.Is(err, syscall.ENOSPC) || errors.Is(err, syscall.EDQUOT)
+}
+
+// Return the inode number identifying a file on its device
+func fileID(info os.FileInfo) (uint64, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return uint64(stat.Ino), true
+}
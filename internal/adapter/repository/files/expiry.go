@@ -0,0 +1,271 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	filesRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/files"
+)
+
+// Maximum number of expired files returned by a single ListExpiredFiles
+const maxExpiredFiles = 10000
+
+// Record the expiry of a file in its sidecar <expiry>/<relPath>, holding the
+// expiry in unix nanos and the file's inode. The inode ties the expiry to the
+// content it was set for: a file replaced at the same path (a new inode)
+// does not inherit it, while in-place writes keep it.
+func (a *adapter) setExpiry(relPath string, info os.FileInfo, expiresAt time.Time) error {
+	sidecarAbs, err := a.expirySidecar(relPath)
+	if err != nil {
+		return err
+	}
+	id, _ := fileID(info)
+	content := strconv.FormatInt(expiresAt.UnixNano(), 10) + " " + strconv.FormatUint(id, 10) + "\n"
+	return os.WriteFile(sidecarAbs, []byte(content), 0600)
+}
+
+// Return the recorded expiry of a file, nil if it has none or the sidecar
+// was recorded for other content
+func (a *adapter) readExpiry(relPath string, info os.FileInfo) *time.Time {
+	if a.storeLocalExpiryPath == "" {
+		return nil
+	}
+	expiresAt, id, ok := readSidecar(filepath.Join(a.storeLocalExpiryPath, relPath))
+	if !ok {
+		return nil
+	}
+	if current, ok := fileID(info); ok && id != 0 && id != current {
+		return nil
+	}
+	return &expiresAt
+}
+
+// Move the recorded expiry of a renamed file to its new path, best effort
+func (a *adapter) moveExpiry(oldRel, newRel string) {
+	if a.storeLocalExpiryPath == "" {
+		return
+	}
+	oldAbs := filepath.Join(a.storeLocalExpiryPath, oldRel)
+	if info, err := os.Lstat(oldAbs); err != nil || !info.Mode().IsRegular() {
+		return
+	}
+	newAbs, err := a.expirySidecar(newRel)
+	if err != nil {
+		return
+	}
+	os.Rename(oldAbs, newAbs)
+}
+
+// Return the sidecar path of a file, creating its parent directories.
+// Sidecars left behind by entries since replaced with an entry of the other
+// kind (a file where a dir was, or the reverse) are removed on the way.
+func (a *adapter) expirySidecar(relPath string) (string, error) {
+	dirAbs := a.storeLocalExpiryPath
+	if parent := filepath.Dir(relPath); parent != "." {
+		for _, name := range strings.Split(parent, string(filepath.Separator)) {
+			dirAbs = filepath.Join(dirAbs, name)
+			if info, err := os.Lstat(dirAbs); err == nil && !info.IsDir() {
+				os.Remove(dirAbs)
+			}
+		}
+	}
+	if err := os.MkdirAll(dirAbs, 0700); err != nil {
+		return "", fmt.Errorf("failed to create expiry dir: %w", err)
+	}
+	sidecarAbs := filepath.Join(a.storeLocalExpiryPath, relPath)
+	if info, err := os.Lstat(sidecarAbs); err == nil && info.IsDir() {
+		os.RemoveAll(sidecarAbs)
+	}
+	return sidecarAbs, nil
+}
+
+// Parse a sidecar into the expiry and the inode it was recorded for
+func readSidecar(sidecarAbs string) (time.Time, uint64, bool) {
+	content, err := os.ReadFile(sidecarAbs)
+	if err != nil {
+		return time.Time{}, 0, false
+	}
+	fields := strings.Fields(string(content))
+	if len(fields) != 2 {
+		return time.Time{}, 0, false
+	}
+	nanos, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return time.Time{}, 0, false
+	}
+	id, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return time.Time{}, 0, false
+	}
+	return time.Unix(0, nanos), id, true
+}
+
+/*
+ListExpiredFiles returns the slash-separated paths of files whose recorded
+expiry is not after Now, without deleting them (see DeleteExpiredFile).
+
+ 1. Walks the sidecars in storeLocalExpiryPath (nothing if not configured)
+    and stops with the context error once ctx is done.
+ 2. Removes sidecars that are unreadable or no longer apply: the file is
+    gone, is not a regular file, has been replaced by other content, or sits
+    below a symlinked directory. Emptied sidecar directories are removed.
+ 3. Returns at most maxExpiredFiles paths; the rest are found by the next
+    call once these are deleted.
+*/
+func (a *adapter) ListExpiredFiles(ctx context.Context, data *filesRepositoryAdapterPort.ListExpiredFilesData) (*[]string, error) {
+	paths := []string{}
+	if a.storeLocalExpiryPath == "" {
+		return &paths, nil
+	}
+
+	baseAbs, err := filepath.Abs(a.storeLocalRootPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve base path: %w", err)
+	}
+
+	var dirs []string
+	err = filepath.WalkDir(a.storeLocalExpiryPath, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && p == a.storeLocalExpiryPath {
+				return filepath.SkipAll
+			}
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != a.storeLocalExpiryPath {
+				dirs = append(dirs, p)
+			}
+			return nil
+		}
+		if len(paths) >= maxExpiredFiles {
+			return filepath.SkipAll
+		}
+		rel, err := filepath.Rel(a.storeLocalExpiryPath, p)
+		if err != nil {
+			return err
+		}
+		expiresAt, ok := a.sidecarExpiry(baseAbs, rel, p)
+		if !ok {
+			os.Remove(p)
+			return nil
+		}
+		if !expiresAt.After(data.Now) {
+			paths = append(paths, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Remove emptied sidecar directories, deepest first
+	for i := len(dirs) - 1; i >= 0; i-- {
+		os.Remove(dirs[i])
+	}
+
+	return &paths, nil
+}
+
+// Return the expiry a sidecar records for the file at relPath, false if the
+// sidecar no longer applies to any file
+func (a *adapter) sidecarExpiry(baseAbs, relPath, sidecarAbs string) (time.Time, bool) {
+	if !filepath.IsLocal(relPath) {
+		return time.Time{}, false
+	}
+	fileAbs := filepath.Join(baseAbs, relPath)
+	if err := checkParents(baseAbs, filepath.Dir(fileAbs)); err != nil {
+		return time.Time{}, false
+	}
+	info, err := os.Lstat(fileAbs)
+	if err != nil || !info.Mode().IsRegular() {
+		return time.Time{}, false
+	}
+	expiresAt, id, ok := readSidecar(sidecarAbs)
+	if !ok {
+		return time.Time{}, false
+	}
+	if current, ok := fileID(info); ok && id != 0 && id != current {
+		return time.Time{}, false
+	}
+	return expiresAt, true
+}
+
+/*
+DeleteExpiredFile deletes a file inside the adapter's base path if its
+recorded expiry is not after Now, and reports whether it did.
+
+ 1. Rejects paths that traverse outside the base directory and symlinked
+    parent directories, as DeleteFile does.
+ 2. Re-reads the expiry for the current file, so a file replaced or given a
+    later expiry since it was listed is kept.
+ 3. Moves the file to the trash if storeLocalTrashPath is set, else removes
+    it, and removes its sidecar.
+*/
+func (a *adapter) DeleteExpiredFile(ctx context.Context, data *filesRepositoryAdapterPort.DeleteExpiredFileData) (*filesRepositoryAdapterPort.DeleteExpiredFileResult, error) {
+	result := filesRepositoryAdapterPort.DeleteExpiredFileResult{}
+	if a.storeLocalExpiryPath == "" {
+		return &result, nil
+	}
+
+	cleanPath := filepath.Clean(data.Path)
+	if cleanPath == "." {
+		return nil, filesRepositoryAdapterPort.ErrInvalidPath
+	}
+	if strings.HasPrefix(cleanPath, "..") {
+		return nil, filesRepositoryAdapterPort.ErrPathEscape
+	}
+
+	baseAbs, err := filepath.Abs(a.storeLocalRootPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve base path: %w", err)
+	}
+	fileAbs := filepath.Join(baseAbs, cleanPath)
+	relPath, err := filepath.Rel(baseAbs, fileAbs)
+	if err != nil || strings.HasPrefix(relPath, "..") {
+		return nil, filesRepositoryAdapterPort.ErrPathEscape
+	}
+
+	// Check parent directories for symlinks (symlink race prevention)
+	if err := checkParents(baseAbs, filepath.Dir(fileAbs)); err != nil {
+		return nil, parentsError(err)
+	}
+
+	// Check the current file is still expired
+	info, err := os.Lstat(fileAbs)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &result, nil
+		}
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return &result, nil
+	}
+	expiresAt := a.readExpiry(relPath, info)
+	if expiresAt == nil || expiresAt.After(data.Now) {
+		return &result, nil
+	}
+
+	// Delete file, honoring the trash
+	if a.storeLocalTrashPath != "" {
+		err = a.moveToTrash(ctx, fileAbs, relPath, info)
+	} else {
+		err = os.Remove(fileAbs)
+	}
+	if err != nil {
+		return nil, err
+	}
+	os.Remove(filepath.Join(a.storeLocalExpiryPath, relPath))
+	result.Deleted = true
+
+	return &result, nil
+}
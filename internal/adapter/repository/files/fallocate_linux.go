@@ -0,0 +1,22 @@
+//go:build linux
+
+package adapter
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// Allocate size bytes of blocks for the file with fallocate, writing zeros
+// if the filesystem does not support it
+func allocate(f *os.File, size int64) error {
+	if size == 0 {
+		return nil
+	}
+	err := syscall.Fallocate(int(f.Fd()), 0, 0, size)
+	if errors.Is(err, syscall.EOPNOTSUPP) {
+		return writeZeros(f, size)
+	}
+	return err
+}
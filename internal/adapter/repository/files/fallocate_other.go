@@ -0,0 +1,10 @@
+//go:build !linux
+
+package adapter
+
+import "os"
+
+// Allocate size bytes of blocks for the file by writing zeros
+func allocate(f *os.File, size int64) error {
+	return writeZeros(f, size)
+}
@@ -0,0 +1,158 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	filesRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/files"
+	"github.com/flash-go/files-service/internal/storefs"
+)
+
+// Maximum (and default) depth searched by FindFiles
+const maxFindDepth = 32
+
+/*
+FindFiles recursively searches a subtree within the adapter's base path for
+entries whose name matches Pattern.
+
+This function performs the same path checks as GetFiles:
+
+ 1. Validates that the path does not traverse outside the base directory.
+ 2. Resolves the absolute path and ensures it is inside storeLocalRootPath.
+ 3. Checks parent directories for symlinks to prevent symlink race attacks.
+ 4. Confirms the search root exists and is a directory.
+
+Patterns containing glob metacharacters ("*", "?", "[") are matched against
+entry names with path.Match; other patterns match names containing them,
+ignoring case. Entries matching hiddenNames are skipped along with their
+contents. Symlinks are reported but never followed, the walk stops
+Depth levels below the root (at most and by default maxFindDepth), and the
+search is aborted when the context is done. Each match is passed to Found as
+soon as it is seen (an error it returns aborts the search), with a
+slash-separated path relative to the search root. Entries below the root that
+cannot be read are skipped rather than failing the search.
+
+| Path      | Pattern  | Matches                            |
+|-----------|----------|------------------------------------|
+| ""        | "*.pdf"  | docs/a.pdf, docs/2025/b.pdf        |
+| "uploads" | "report" | Report.txt, q1/monthly-report.csv  |
+*/
+func (a *adapter) FindFiles(ctx context.Context, data *filesRepositoryAdapterPort.FindFilesData) error {
+	if data.Pattern == "" {
+		return filesRepositoryAdapterPort.ErrInvalidPattern
+	}
+	glob := strings.ContainsAny(data.Pattern, "*?[")
+	if glob {
+		if _, err := path.Match(data.Pattern, ""); err != nil {
+			return filesRepositoryAdapterPort.ErrInvalidPattern
+		}
+	}
+	substring := strings.ToLower(data.Pattern)
+
+	depth := data.Depth
+	if depth <= 0 || depth > maxFindDepth {
+		depth = maxFindDepth
+	}
+
+	cleanPath := filepath.Clean(data.Path)
+	if strings.HasPrefix(cleanPath, "..") {
+		return filesRepositoryAdapterPort.ErrPathEscape
+	}
+
+	baseAbs, err := filepath.Abs(a.storeLocalRootPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve base path: %w", err)
+	}
+
+	rootAbs, err := filepath.Abs(filepath.Join(baseAbs, cleanPath))
+	if err != nil {
+		return filesRepositoryAdapterPort.ErrInvalidPath
+	}
+
+	// Ensure search root is inside base
+	if rel, _ := filepath.Rel(baseAbs, rootAbs); strings.HasPrefix(rel, "..") {
+		return filesRepositoryAdapterPort.ErrPathEscape
+	}
+
+	// Check parent directories for symlinks
+	if err := checkParents(baseAbs, rootAbs); err != nil {
+		return parentsError(err)
+	}
+
+	// Check search root is a directory
+	info, err := os.Stat(rootAbs)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return filesRepositoryAdapterPort.ErrDirNotFound
+		}
+		return err
+	}
+	if !info.IsDir() {
+		return filesRepositoryAdapterPort.ErrInvalidPath
+	}
+
+	// Walk the subtree
+	return filepath.WalkDir(rootAbs, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Skip unreadable entries below the root
+			if p != rootAbs {
+				return nil
+			}
+			return err
+		}
+		if ctx.Err() != nil {
+			return context.Cause(ctx)
+		}
+		rel, err := filepath.Rel(rootAbs, p)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+
+		// Skip hidden entries and everything below them
+		name := d.Name()
+		if storefs.Hidden(a.hiddenNames, name) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		var matched bool
+		if glob {
+			matched, _ = path.Match(data.Pattern, name)
+		} else {
+			matched = strings.Contains(strings.ToLower(name), substring)
+		}
+		if matched {
+			result := filesRepositoryAdapterPort.FindResult{
+				Path:      rel,
+				IsDir:     d.IsDir(),
+				IsSymlink: d.Type()&fs.ModeSymlink != 0,
+			}
+			if d.Type().IsRegular() {
+				if info, err := d.Info(); err == nil {
+					s := info.Size()
+					result.Size = &s
+				}
+			}
+			if err := data.Found(result); err != nil {
+				return err
+			}
+		}
+
+		// Stop descending at the depth limit
+		if d.IsDir() && strings.Count(rel, "/")+1 >= depth {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+}
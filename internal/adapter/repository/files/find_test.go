@@ -0,0 +1,68 @@
+package adapter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	filesRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/files"
+)
+
+func TestFindFilesSkipsUnreadableDirs(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a/report.txt", "b/report.txt", "c/report.txt"} {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("report"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a := &adapter{storeLocalRootPath: dir}
+
+	// Remove b once the root is listed, so reading it fails during the walk
+	var found []string
+	err := a.FindFiles(context.Background(), &filesRepositoryAdapterPort.FindFilesData{
+		Pattern: "report*",
+		Found: func(result filesRepositoryAdapterPort.FindResult) error {
+			found = append(found, result.Path)
+			if result.Path == "a/report.txt" {
+				return os.RemoveAll(filepath.Join(dir, "b"))
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("FindFiles: %v", err)
+	}
+	if want := []string{"a/report.txt", "c/report.txt"}; !slices.Equal(found, want) {
+		t.Errorf("found %q, want %q", found, want)
+	}
+}
+
+func TestFindFilesStopsOnFoundError(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a := &adapter{storeLocalRootPath: dir}
+
+	calls := 0
+	err := a.FindFiles(context.Background(), &filesRepositoryAdapterPort.FindFilesData{
+		Pattern: "*.txt",
+		Found: func(result filesRepositoryAdapterPort.FindResult) error {
+			calls++
+			return context.Canceled
+		},
+	})
+	if err != context.Canceled {
+		t.Fatalf("FindFiles = %v, want the error returned by Found", err)
+	}
+	if calls != 1 {
+		t.Errorf("Found called %d times after failing, want 1", calls)
+	}
+}
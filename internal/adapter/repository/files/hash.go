@@ -0,0 +1,147 @@
+package adapter
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"hash/crc32"
+	"io"
+	"os"
+
+	filesRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/files"
+)
+
+// Maximum number of file hashes kept in the cache
+const maxHashCacheEntries = 10000
+
+// Cached hashes of a file, valid while the file keeps its identity, size and
+// modification time
+type hashEntry struct {
+	info   os.FileInfo
+	sha256 string
+	md5    *string
+	crc32  *string
+}
+
+/*
+HashFile computes the SHA-256 of a regular file inside the adapter's base path
+(and its MD5 and CRC-32 if WithMD5 and WithCRC32 are set) in a single pass,
+without returning the content.
+
+ 1. Opens the file through OpenFile, so the same path, symlink and type checks
+    apply. Directories are rejected with ErrIsDirectory.
+ 2. Looks the file up in the hash cache. An entry is used only if the file is
+    still the same inode with the same size and modification time, so any
+    write (through the service or out of band) invalidates it.
+ 3. Otherwise reads the file once, feeding every requested hash, and stops
+    with the context error once ctx is done.
+ 4. Stores the result, evicting an arbitrary entry once the cache holds
+    maxHashCacheEntries files.
+
+Hashes are lowercase hex; CRC-32 uses the IEEE polynomial.
+*/
+func (a *adapter) HashFile(ctx context.Context, data *filesRepositoryAdapterPort.HashFileData) (*filesRepositoryAdapterPort.HashFileResult, error) {
+	opened, err := a.OpenFile(ctx, &filesRepositoryAdapterPort.OpenFileData{Path: data.Path})
+	if err != nil {
+		return nil, err
+	}
+	f := opened.File
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	// Use cached hashes of an unchanged file
+	if entry := a.cachedHash(f.Name(), info); entry != nil &&
+		(!data.WithMD5 || entry.md5 != nil) && (!data.WithCRC32 || entry.crc32 != nil) {
+		return hashResult(entry, info, data), nil
+	}
+
+	// Hash the content in a single pass
+	sha256Hash := sha256.New()
+	writers := []io.Writer{sha256Hash}
+	var md5Hash, crc32Hash hash.Hash
+	if data.WithMD5 {
+		md5Hash = md5.New()
+		writers = append(writers, md5Hash)
+	}
+	if data.WithCRC32 {
+		crc32Hash = crc32.NewIEEE()
+		writers = append(writers, crc32Hash)
+	}
+	if _, err := io.Copy(io.MultiWriter(writers...), contextReader{ctx, f}); err != nil {
+		return nil, err
+	}
+
+	entry := &hashEntry{
+		info:   info,
+		sha256: hex.EncodeToString(sha256Hash.Sum(nil)),
+	}
+	if md5Hash != nil {
+		s := hex.EncodeToString(md5Hash.Sum(nil))
+		entry.md5 = &s
+	}
+	if crc32Hash != nil {
+		s := hex.EncodeToString(crc32Hash.Sum(nil))
+		entry.crc32 = &s
+	}
+	a.storeHash(f.Name(), entry)
+
+	return hashResult(entry, info, data), nil
+}
+
+// Return the cached hashes of a file if it did not change since
+func (a *adapter) cachedHash(fileAbs string, info os.FileInfo) *hashEntry {
+	a.hashMu.Lock()
+	defer a.hashMu.Unlock()
+	entry, ok := a.hashCache[fileAbs]
+	if !ok {
+		return nil
+	}
+	if !os.SameFile(entry.info, info) || entry.info.Size() != info.Size() || !entry.info.ModTime().Equal(info.ModTime()) {
+		delete(a.hashCache, fileAbs)
+		return nil
+	}
+	return entry
+}
+
+// Cache the hashes of a file, keeping hashes of an unchanged file that this
+// computation did not request
+func (a *adapter) storeHash(fileAbs string, entry *hashEntry) {
+	a.hashMu.Lock()
+	defer a.hashMu.Unlock()
+	if old, ok := a.hashCache[fileAbs]; ok && os.SameFile(old.info, entry.info) &&
+		old.info.Size() == entry.info.Size() && old.info.ModTime().Equal(entry.info.ModTime()) {
+		if entry.md5 == nil {
+			entry.md5 = old.md5
+		}
+		if entry.crc32 == nil {
+			entry.crc32 = old.crc32
+		}
+	} else if !ok && len(a.hashCache) >= maxHashCacheEntries {
+		for key := range a.hashCache {
+			delete(a.hashCache, key)
+			break
+		}
+	}
+	a.hashCache[fileAbs] = entry
+}
+
+// Build the result holding the requested hashes of an entry
+func hashResult(entry *hashEntry, info os.FileInfo, data *filesRepositoryAdapterPort.HashFileData) *filesRepositoryAdapterPort.HashFileResult {
+	result := &filesRepositoryAdapterPort.HashFileResult{
+		SHA256: entry.sha256,
+		Size:   info.Size(),
+	}
+	if data.WithMD5 {
+		result.MD5 = entry.md5
+	}
+	if data.WithCRC32 {
+		result.CRC32 = entry.crc32
+	}
+	return result
+}
@@ -0,0 +1,325 @@
+package adapter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	filesRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/files"
+)
+
+// JPEG quality of uploads re-encoded to normalize their orientation
+const orientJpegQuality = 92
+
+// Brands of the HEIF container (ISO/IEC 23008-12) in the ftyp box
+var heifBrands = []string{"heic", "heix", "heim", "heis", "hevc", "hevx", "mif1", "msf1"}
+
+// Segment of a JPEG file before its first scan, from its 0xFF marker prefix
+// to the end of its payload
+type jpegSegment struct {
+	marker     byte
+	start, end int
+}
+
+/*
+processImage applies the upload image options to the temp file of an upload
+named name, returning its new content (nil if it was left untouched).
+
+Only JPEG uploads are processed, detected per mimeDetection like mimeRoutes.
+If uploadOrientation is set, an image whose EXIF orientation is not the
+default is decoded, rotated and flipped so its pixels are stored upright,
+and re-encoded at orientJpegQuality; its EXIF data is kept with the
+orientation reset to 1. If uploadStripExif is set, APP1 (EXIF, XMP) and APP13
+(IPTC) segments are removed, without re-encoding unless the image is also
+rotated. Images that cannot be parsed or decoded are rejected with
+ErrInvalidImage and images with more than imageMaxPixels pixels (unless zero)
+with ErrImageTooLarge before they are decoded. HEIC/HEIF images cannot be
+decoded with the standard library, so they are rejected with
+ErrImageUnsupported while either option is set.
+*/
+func (a *adapter) processImage(name, tempAbs string) ([]byte, error) {
+	if !a.uploadOrientation && !a.uploadStripExif {
+		return nil, nil
+	}
+
+	// Detect the image type
+	mimeType := a.extensionMimeType(name)
+	if mimeType == "" {
+		head, err := readHead(tempAbs)
+		if err != nil {
+			return nil, err
+		}
+		mimeType = http.DetectContentType(head)
+		if isHeif(head) {
+			mimeType = "image/heif"
+		}
+	}
+	ext := strings.ToLower(filepath.Ext(name))
+	if ext == ".heic" || ext == ".heif" || mimeType == "image/heic" || mimeType == "image/heif" {
+		return nil, filesRepositoryAdapterPort.ErrImageUnsupported
+	}
+	if mimeType != "image/jpeg" {
+		return nil, nil
+	}
+
+	// Parse the metadata segments
+	content, err := os.ReadFile(tempAbs)
+	if err != nil {
+		return nil, err
+	}
+	segments, scan, err := jpegSegments(content)
+	if err != nil {
+		return nil, err
+	}
+	orientation, orientationAt, order := jpegOrientation(content, segments)
+
+	// Check the image dimensions before decoding
+	rotate := a.uploadOrientation && orientation > 1 && orientation <= 8
+	if a.uploadOrientation {
+		config, err := jpeg.DecodeConfig(bytes.NewReader(content))
+		if err != nil {
+			return nil, filesRepositoryAdapterPort.ErrInvalidImage
+		}
+		if rotate && a.imageMaxPixels > 0 && int64(config.Width)*int64(config.Height) > a.imageMaxPixels {
+			return nil, filesRepositoryAdapterPort.ErrImageTooLarge
+		}
+	}
+	if !rotate && !a.uploadStripExif {
+		return nil, nil
+	}
+
+	// Rebuild the file from the kept segments, followed by the original
+	// scan or by the re-encoded image without its SOI marker
+	var out bytes.Buffer
+	out.Write(content[:2])
+	stripped := false
+	for _, segment := range segments {
+		if a.uploadStripExif && (segment.marker == 0xE1 || segment.marker == 0xED) {
+			stripped = true
+			continue
+		}
+		if rotate && !metadataSegment(content, segment) {
+			continue
+		}
+		if rotate && segment.start <= orientationAt && orientationAt < segment.end {
+			// Reset the orientation in a copy of the segment
+			patched := bytes.Clone(content[segment.start:segment.end])
+			order.PutUint16(patched[orientationAt-segment.start:], 1)
+			out.Write(patched)
+			continue
+		}
+		out.Write(content[segment.start:segment.end])
+	}
+	if !rotate {
+		if !stripped {
+			return nil, nil
+		}
+		out.Write(content[scan:])
+	} else {
+		img, err := jpeg.Decode(bytes.NewReader(content))
+		if err != nil {
+			return nil, filesRepositoryAdapterPort.ErrInvalidImage
+		}
+		var encoded bytes.Buffer
+		if err := jpeg.Encode(&encoded, orient(img, orientation), &jpeg.Options{Quality: orientJpegQuality}); err != nil {
+			return nil, err
+		}
+		out.Write(encoded.Bytes()[2:])
+	}
+
+	// Replace the content of the temp file
+	if err := writeSynced(tempAbs, out.Bytes()); err != nil {
+		return nil, storageError(err)
+	}
+	return out.Bytes(), nil
+}
+
+// Overwrite the content of an existing file and sync it to disk
+func writeSynced(fileAbs string, content []byte) error {
+	f, err := os.OpenFile(fileAbs, os.O_WRONLY|os.O_TRUNC, 0)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Report whether the head of a file is an ISO BMFF ftyp box with a HEIF brand
+func isHeif(head []byte) bool {
+	if len(head) < 12 || string(head[4:8]) != "ftyp" {
+		return false
+	}
+	brand := string(head[8:12])
+	for _, b := range heifBrands {
+		if brand == b {
+			return true
+		}
+	}
+	return false
+}
+
+// Split a JPEG file into the segments preceding its first scan, returning
+// them with the offset of the SOS marker. Fails with ErrInvalidImage if the
+// file is not a well-formed JPEG up to the scan.
+func jpegSegments(content []byte) ([]jpegSegment, int, error) {
+	if len(content) < 4 || content[0] != 0xFF || content[1] != 0xD8 {
+		return nil, 0, filesRepositoryAdapterPort.ErrInvalidImage
+	}
+	var segments []jpegSegment
+	i := 2
+	for i+1 < len(content) {
+		if content[i] != 0xFF {
+			return nil, 0, filesRepositoryAdapterPort.ErrInvalidImage
+		}
+		marker := content[i+1]
+		switch {
+		case marker == 0xFF:
+			// Fill byte
+			i++
+			continue
+		case marker == 0xDA:
+			return segments, i, nil
+		case marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7):
+			// Standalone marker
+			segments = append(segments, jpegSegment{marker: marker, start: i, end: i + 2})
+			i += 2
+			continue
+		case marker == 0xD8 || marker == 0xD9:
+			return nil, 0, filesRepositoryAdapterPort.ErrInvalidImage
+		}
+		if i+4 > len(content) {
+			break
+		}
+		end := i + 2 + int(binary.BigEndian.Uint16(content[i+2:]))
+		if end < i+4 || end > len(content) {
+			break
+		}
+		segments = append(segments, jpegSegment{marker: marker, start: i, end: end})
+		i = end
+	}
+	return nil, 0, filesRepositoryAdapterPort.ErrInvalidImage
+}
+
+// Report whether a segment holds metadata that stays valid once the image is
+// re-encoded: APPn segments other than Adobe colour transforms (APP14) and
+// multi-picture indexes (APP2 "MPF"), which describe the original scan, and
+// comments
+func metadataSegment(content []byte, segment jpegSegment) bool {
+	switch {
+	case segment.marker == 0xFE:
+		return true
+	case segment.marker == 0xEE:
+		return false
+	case segment.marker == 0xE2:
+		return !bytes.HasPrefix(content[segment.start+4:segment.end], []byte("MPF\x00"))
+	}
+	return segment.marker >= 0xE0 && segment.marker <= 0xEF
+}
+
+// Return the EXIF segment of a JPEG file and the offset of its TIFF header,
+// or -1 if it has none
+func exifSegment(content []byte, segments []jpegSegment) (jpegSegment, int) {
+	for _, segment := range segments {
+		if segment.marker != 0xE1 {
+			continue
+		}
+		payload := content[segment.start+4 : segment.end]
+		if len(payload) >= 14 && bytes.HasPrefix(payload, []byte("Exif\x00\x00")) {
+			return segment, segment.start + 10
+		}
+	}
+	return jpegSegment{}, -1
+}
+
+// Return the EXIF orientation of a JPEG file with the offset and byte order
+// of its value, or 1 and -1 if it has none. Only the first image file
+// directory is read.
+func jpegOrientation(content []byte, segments []jpegSegment) (int, int, binary.ByteOrder) {
+	segment, tiff := exifSegment(content, segments)
+	if tiff < 0 {
+		return 1, -1, nil
+	}
+	var order binary.ByteOrder
+	switch string(content[tiff : tiff+4]) {
+	case "II*\x00":
+		order = binary.LittleEndian
+	case "MM\x00*":
+		order = binary.BigEndian
+	default:
+		return 1, -1, nil
+	}
+	ifd := tiff + int(order.Uint32(content[tiff+4:]))
+	if ifd < tiff || ifd+2 > segment.end {
+		return 1, -1, nil
+	}
+	count := int(order.Uint16(content[ifd:]))
+	for n := 0; n < count; n++ {
+		entry := ifd + 2 + n*12
+		if entry+12 > segment.end {
+			break
+		}
+		// Orientation tag, a single SHORT stored in the entry itself
+		if order.Uint16(content[entry:]) == 0x0112 && order.Uint16(content[entry+2:]) == 3 && order.Uint32(content[entry+4:]) == 1 {
+			return int(order.Uint16(content[entry+8:])), entry + 8, order
+		}
+	}
+	return 1, -1, nil
+}
+
+/*
+orient returns a copy of an image transformed so it displays upright given
+its EXIF orientation (2-8): mirrored horizontally (2), rotated by 180 degrees
+(3), mirrored vertically (4), transposed (5), rotated clockwise by 90 degrees
+(6), transversed (7) or rotated counterclockwise by 90 degrees (8).
+Orientations 5 to 8 swap the width and height.
+*/
+func orient(img image.Image, orientation int) image.Image {
+	bounds := img.Bounds()
+	src := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Src)
+
+	w, h := bounds.Dx(), bounds.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = w-1-x, y
+			case 3:
+				dx, dy = w-1-x, h-1-y
+			case 4:
+				dx, dy = x, h-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = h-1-y, x
+			case 7:
+				dx, dy = h-1-y, w-1-x
+			case 8:
+				dx, dy = y, w-1-x
+			default:
+				dx, dy = x, y
+			}
+			copy(dst.Pix[dst.PixOffset(dx, dy):dst.PixOffset(dx, dy)+4], src.Pix[src.PixOffset(x, y):src.PixOffset(x, y)+4])
+		}
+	}
+	return dst
+}
@@ -0,0 +1,67 @@
+//go:build linux
+
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	filesRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/files"
+)
+
+// Count the file descriptors open in this process
+func openFds(t *testing.T) int {
+	t.Helper()
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		t.Skipf("open fds not countable: %v", err)
+	}
+	return len(entries)
+}
+
+func TestGetFilesKeepsOpenFdsBounded(t *testing.T) {
+	const files = 5000
+	dir := t.TempDir()
+	for i := range files {
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("file-%04d.txt", i)), []byte("content\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a := &adapter{storeLocalRootPath: dir}
+
+	// Lower the fd limit well below the number of files for the duration of
+	// the listing, so holding a descriptor per entry fails it with EMFILE
+	before := openFds(t)
+	var limit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &limit); err != nil {
+		t.Fatal(err)
+	}
+	lowered := limit
+	lowered.Cur = uint64(before + 64)
+	if err := syscall.Setrlimit(syscall.RLIMIT_NOFILE, &lowered); err != nil {
+		t.Fatal(err)
+	}
+	results, err := a.GetFiles(context.Background(), &filesRepositoryAdapterPort.GetFilesData{
+		WithEncoding: true,
+	})
+	if err := syscall.Setrlimit(syscall.RLIMIT_NOFILE, &limit); err != nil {
+		t.Fatal(err)
+	}
+
+	if err != nil {
+		t.Fatalf("GetFiles: %v", err)
+	}
+	if len(results.Files) != files {
+		t.Fatalf("GetFiles listed %d entries, want %d", len(results.Files), files)
+	}
+	if results.Files[0].MimeType == nil {
+		t.Errorf("GetFiles did not detect the MIME type")
+	}
+	if after := openFds(t); after > before {
+		t.Errorf("open fds grew from %d to %d", before, after)
+	}
+}
@@ -0,0 +1,281 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	filesRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/files"
+	"github.com/flash-go/files-service/internal/storefs"
+)
+
+/*
+MoveFile securely moves a file to another path, possibly in another directory
+tree, within the adapter's base path.
+
+This function performs multiple safety checks before moving the file:
+
+ 1. Validates that both source and destination paths are non-empty and do
+    not traverse outside the base directory using ".." or absolute paths.
+ 2. Ensures both paths are inside the adapter's storeLocalRootPath and belong
+    to the same store namespace (ErrCrossNamespace, see RenameFile).
+ 3. Checks that all parent directories do not contain symlinks (symlink race
+    prevention). The destination directory must exist (ErrDirNotFound);
+    unlike uploads, no directories are created.
+ 4. Checks that the source exists (ErrFileNotFound) and is a regular file
+    (ErrIsDirectory for a directory, ErrInvalidPath for other entries).
+ 5. If UnmodifiedSince is set, refuses to move a file modified after that
+    time.
+ 6. Moving a file onto its own path is a no-op, reported with Moved unset.
+ 7. Rejects an existing destination with ErrFileExist unless Overwrite is set,
+    and an existing directory with ErrIsDirectory. A destination differing
+    only in case that resolves to the source (case-insensitive filesystem) is
+    renamed in place. Rejects moves into a directory that already holds
+    dirMaxEntries entries.
+ 8. Moves the file with a rename, or without Overwrite with a hard link
+    (see moveIntoPlace), so a file created at the destination meanwhile is
+    not overwritten. Across filesystems (EXDEV) the file is copied next to
+    the destination, synced and moved into place, and the source removed,
+    keeping its permissions and modification time; the result has Copied
+    set.
+ 9. Moves the file's recorded expiry (see setExpiry) along with it.
+
+Allowed paths examples (assuming base is /var/data):
+
+| Input Path                   | Resulting Absolute Path          | Reason                    |
+|------------------------------|----------------------------------|---------------------------|
+| source: "inbox/img.png"      | /var/data/inbox/img.png          | Inside base, exists       |
+| dest: "archive/2024/img.png" | /var/data/archive/2024/img.png   | Inside base, dir exists   |
+
+Rejected paths examples:
+
+| Input Path                 | Reason for rejection                       |
+|----------------------------|--------------------------------------------|
+| "../../etc/passwd"         | Path traversal outside base                |
+| "uploads/../../secret.txt" | Resolves above base directory              |
+| "uploads/symlink/file.txt" | Parent directory is a symlink              |
+| "missing/file.txt"         | Destination directory does not exist       |
+| ""                         | Empty file path                            |
+*/
+func (a *adapter) MoveFile(ctx context.Context, data *filesRepositoryAdapterPort.MoveFileData) (*filesRepositoryAdapterPort.MoveFileResult, error) {
+	t, err := a.resolveTransfer(data.SourcePath, data.DestPath)
+	if err != nil {
+		return nil, err
+	}
+	if data.UnmodifiedSince != nil && t.sourceInfo.ModTime().Truncate(time.Second).After(*data.UnmodifiedSince) {
+		return nil, filesRepositoryAdapterPort.ErrFileModified
+	}
+
+	result := filesRepositoryAdapterPort.MoveFileResult{
+		Path: filepath.ToSlash(t.destRel),
+	}
+	if t.sourceAbs == t.destAbs {
+		return &result, nil
+	}
+
+	// Check destination existence and type
+	replace := data.Overwrite
+	if destInfo, err := os.Lstat(t.destAbs); err == nil {
+		switch {
+		case destInfo.IsDir():
+			return nil, filesRepositoryAdapterPort.ErrIsDirectory
+		case strings.EqualFold(t.sourceRel, t.destRel) && os.SameFile(t.sourceInfo, destInfo):
+			// A case change on a case-insensitive filesystem finds the source
+			replace = true
+		case !destInfo.Mode().IsRegular():
+			return nil, filesRepositoryAdapterPort.ErrInvalidPath
+		case !data.Overwrite:
+			return nil, filesRepositoryAdapterPort.ErrFileExist
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	} else if filepath.Dir(t.destAbs) != filepath.Dir(t.sourceAbs) {
+		// Check directory capacity
+		if full, err := storefs.DirFull(filepath.Dir(t.destAbs), a.dirMaxEntries); err != nil {
+			return nil, fmt.Errorf("failed to count entries: %w", err)
+		} else if full {
+			return nil, filesRepositoryAdapterPort.ErrDirFull
+		}
+	}
+
+	// Move the file into place, copying it across filesystems
+	expiresAt := a.readExpiry(t.sourceRel, t.sourceInfo)
+	if err := moveIntoPlace(t.sourceAbs, t.destAbs, replace); err != nil {
+		if !errors.Is(err, syscall.EXDEV) {
+			return nil, err
+		}
+		if err := copyInto(ctx, t.sourceAbs, filepath.Dir(t.destAbs), t.destAbs, t.sourceInfo, replace); err != nil {
+			return nil, err
+		}
+		if err := os.Remove(t.sourceAbs); err != nil {
+			return nil, err
+		}
+		result.Copied = true
+	}
+	result.Moved = true
+
+	// Move the recorded expiry, tying it to the copy's new inode
+	a.moveExpiry(t.sourceRel, t.destRel)
+	if result.Copied && expiresAt != nil {
+		if info, err := os.Stat(t.destAbs); err == nil {
+			a.setExpiry(t.destRel, info, *expiresAt)
+		}
+	}
+	return &result, nil
+}
+
+// Operands of a move or copy, resolved and checked
+type transfer struct {
+	sourceAbs  string
+	destAbs    string
+	sourceRel  string
+	destRel    string
+	sourceInfo os.FileInfo
+}
+
+// Resolve the source and destination of a move or copy, applying the checks
+// shared by MoveFile and CopyFile: both paths inside base and in the same
+// store namespace, parents free of symlinks, an existing destination
+// directory and a regular source file.
+func (a *adapter) resolveTransfer(sourcePath, destPath string) (*transfer, error) {
+	if sourcePath == "" || destPath == "" {
+		return nil, filesRepositoryAdapterPort.ErrInvalidPath
+	}
+
+	cleanSource := filepath.Clean(sourcePath)
+	cleanDest := filepath.Clean(destPath)
+	for _, cleanPath := range []string{cleanSource, cleanDest} {
+		if cleanPath == "." {
+			return nil, filesRepositoryAdapterPort.ErrInvalidPath
+		}
+		if strings.HasPrefix(cleanPath, "..") {
+			return nil, filesRepositoryAdapterPort.ErrPathEscape
+		}
+	}
+
+	baseAbs, err := filepath.Abs(a.storeLocalRootPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve base path: %w", err)
+	}
+
+	var t transfer
+	if t.sourceAbs, err = filepath.Abs(filepath.Join(baseAbs, cleanSource)); err != nil {
+		return nil, filesRepositoryAdapterPort.ErrInvalidPath
+	}
+	if t.destAbs, err = filepath.Abs(filepath.Join(baseAbs, cleanDest)); err != nil {
+		return nil, filesRepositoryAdapterPort.ErrInvalidPath
+	}
+
+	// Ensure both paths are inside base
+	t.sourceRel, err = filepath.Rel(baseAbs, t.sourceAbs)
+	if err != nil || strings.HasPrefix(t.sourceRel, "..") {
+		return nil, filesRepositoryAdapterPort.ErrPathEscape
+	}
+	t.destRel, err = filepath.Rel(baseAbs, t.destAbs)
+	if err != nil || strings.HasPrefix(t.destRel, "..") {
+		return nil, filesRepositoryAdapterPort.ErrPathEscape
+	}
+
+	// Ensure both paths belong to the same store namespace
+	if a.crossesNamespace(t.sourceAbs, t.destAbs) {
+		return nil, filesRepositoryAdapterPort.ErrCrossNamespace
+	}
+
+	// Check parent directories for symlinks (symlink race prevention)
+	if err := checkParents(baseAbs, filepath.Dir(t.sourceAbs)); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, filesRepositoryAdapterPort.ErrFileNotFound
+		}
+		return nil, parentsError(err)
+	}
+	destDirAbs := filepath.Dir(t.destAbs)
+	if err := checkParents(baseAbs, destDirAbs); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, filesRepositoryAdapterPort.ErrDirNotFound
+		}
+		return nil, parentsError(err)
+	}
+	if info, err := os.Stat(destDirAbs); err != nil {
+		return nil, err
+	} else if !info.IsDir() {
+		return nil, filesRepositoryAdapterPort.ErrInvalidPath
+	}
+
+	// Check source existence and type
+	t.sourceInfo, err = os.Lstat(t.sourceAbs)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, filesRepositoryAdapterPort.ErrFileNotFound
+		}
+		return nil, err
+	}
+	if t.sourceInfo.IsDir() {
+		return nil, filesRepositoryAdapterPort.ErrIsDirectory
+	}
+	if !t.sourceInfo.Mode().IsRegular() {
+		return nil, filesRepositoryAdapterPort.ErrInvalidPath
+	}
+	return &t, nil
+}
+
+// Copy a file to destAbs through a temp file in tempDirAbs, which must be on
+// the filesystem of destAbs. The temp file is synced and moved into place
+// like an upload (see moveIntoPlace), with the permissions and modification
+// time of the source. If ctx has a deadline, the copy is aborted with the
+// context cause once it passes.
+func copyInto(ctx context.Context, sourceAbs, tempDirAbs, destAbs string, sourceInfo os.FileInfo, replace bool) error {
+	src, err := os.Open(sourceAbs)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	// Create temp file
+	dst, err := os.CreateTemp(tempDirAbs, "."+filepath.Base(destAbs)+".tmp-*")
+	if err != nil {
+		return storageError(err)
+	}
+
+	// Remove the temp file unless it was moved into place
+	committed := false
+	defer func() {
+		dst.Close()
+		if !committed {
+			os.Remove(dst.Name())
+		}
+	}()
+
+	// Copy content
+	var reader io.Reader = src
+	if _, ok := ctx.Deadline(); ok {
+		reader = contextReader{ctx, reader}
+	}
+	if _, err := io.Copy(dst, reader); err != nil {
+		return storageError(err)
+	}
+	if err := dst.Chmod(sourceInfo.Mode().Perm()); err != nil {
+		return err
+	}
+	if err := dst.Sync(); err != nil {
+		return storageError(err)
+	}
+	if err := dst.Close(); err != nil {
+		return storageError(err)
+	}
+	if err := os.Chtimes(dst.Name(), time.Time{}, sourceInfo.ModTime()); err != nil {
+		return err
+	}
+
+	// Move temp file into place
+	if err := moveIntoPlace(dst.Name(), destAbs, replace); err != nil {
+		return err
+	}
+	committed = true
+	return nil
+}
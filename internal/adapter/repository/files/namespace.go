@@ -0,0 +1,243 @@
+package adapter
+
+import (
+	"context"
+
+	"github.com/flash-go/files-service/internal/namespace"
+	filesRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/files"
+)
+
+// Repository decorator routing every call to the repository of the store
+// namespace selected in the context (see namespace.WithName), or to the
+// default repository if none is selected.
+type namespaceAdapter struct {
+	fallback   filesRepositoryAdapterPort.Interface
+	namespaces map[string]filesRepositoryAdapterPort.Interface
+}
+
+func NewNamespaced(fallback filesRepositoryAdapterPort.Interface, namespaces map[string]filesRepositoryAdapterPort.Interface) filesRepositoryAdapterPort.Interface {
+	return &namespaceAdapter{
+		fallback:   fallback,
+		namespaces: namespaces,
+	}
+}
+
+// Return the repository of the namespace selected in ctx
+func (n *namespaceAdapter) repository(ctx context.Context) (filesRepositoryAdapterPort.Interface, error) {
+	name := namespace.Name(ctx)
+	if name == "" {
+		return n.fallback, nil
+	}
+	if repository, ok := n.namespaces[name]; ok {
+		return repository, nil
+	}
+	return nil, namespace.ErrUnknownNamespace
+}
+
+func (n *namespaceAdapter) CreateFile(ctx context.Context, data *filesRepositoryAdapterPort.CreateFileData) (*filesRepositoryAdapterPort.CreateFileResult, error) {
+	repository, err := n.repository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repository.CreateFile(ctx, data)
+}
+
+func (n *namespaceAdapter) GetFiles(ctx context.Context, data *filesRepositoryAdapterPort.GetFilesData) (*filesRepositoryAdapterPort.GetFilesResult, error) {
+	repository, err := n.repository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repository.GetFiles(ctx, data)
+}
+
+func (n *namespaceAdapter) FindFiles(ctx context.Context, data *filesRepositoryAdapterPort.FindFilesData) error {
+	repository, err := n.repository(ctx)
+	if err != nil {
+		return err
+	}
+	return repository.FindFiles(ctx, data)
+}
+
+func (n *namespaceAdapter) DeleteFile(ctx context.Context, data *filesRepositoryAdapterPort.DeleteFileData) error {
+	repository, err := n.repository(ctx)
+	if err != nil {
+		return err
+	}
+	return repository.DeleteFile(ctx, data)
+}
+
+func (n *namespaceAdapter) RenameFile(ctx context.Context, data *filesRepositoryAdapterPort.RenameFileData) error {
+	repository, err := n.repository(ctx)
+	if err != nil {
+		return err
+	}
+	return repository.RenameFile(ctx, data)
+}
+
+func (n *namespaceAdapter) MoveFile(ctx context.Context, data *filesRepositoryAdapterPort.MoveFileData) (*filesRepositoryAdapterPort.MoveFileResult, error) {
+	repository, err := n.repository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repository.MoveFile(ctx, data)
+}
+
+func (n *namespaceAdapter) CopyFile(ctx context.Context, data *filesRepositoryAdapterPort.CopyFileData) (*filesRepositoryAdapterPort.CopyFileResult, error) {
+	repository, err := n.repository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repository.CopyFile(ctx, data)
+}
+
+func (n *namespaceAdapter) WriteFileAt(ctx context.Context, data *filesRepositoryAdapterPort.WriteFileAtData) error {
+	repository, err := n.repository(ctx)
+	if err != nil {
+		return err
+	}
+	return repository.WriteFileAt(ctx, data)
+}
+
+func (n *namespaceAdapter) WriteFileRange(ctx context.Context, data *filesRepositoryAdapterPort.WriteFileRangeData) error {
+	repository, err := n.repository(ctx)
+	if err != nil {
+		return err
+	}
+	return repository.WriteFileRange(ctx, data)
+}
+
+func (n *namespaceAdapter) PreviewFile(ctx context.Context, data *filesRepositoryAdapterPort.PreviewFileData) (*filesRepositoryAdapterPort.PreviewResult, error) {
+	repository, err := n.repository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repository.PreviewFile(ctx, data)
+}
+
+func (n *namespaceAdapter) StatFile(ctx context.Context, data *filesRepositoryAdapterPort.StatFileData) (*filesRepositoryAdapterPort.FileResult, error) {
+	repository, err := n.repository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repository.StatFile(ctx, data)
+}
+
+func (n *namespaceAdapter) PurgeTrash(ctx context.Context, data *filesRepositoryAdapterPort.PurgeTrashData) (*filesRepositoryAdapterPort.PurgeTrashResult, error) {
+	repository, err := n.repository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repository.PurgeTrash(ctx, data)
+}
+
+func (n *namespaceAdapter) PurgeBackups(ctx context.Context, data *filesRepositoryAdapterPort.PurgeBackupsData) (*filesRepositoryAdapterPort.PurgeBackupsResult, error) {
+	repository, err := n.repository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repository.PurgeBackups(ctx, data)
+}
+
+func (n *namespaceAdapter) ListToken(ctx context.Context, data *filesRepositoryAdapterPort.ListTokenData) (*filesRepositoryAdapterPort.ListTokenResult, error) {
+	repository, err := n.repository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repository.ListToken(ctx, data)
+}
+
+func (n *namespaceAdapter) OpenFile(ctx context.Context, data *filesRepositoryAdapterPort.OpenFileData) (*filesRepositoryAdapterPort.OpenFileResult, error) {
+	repository, err := n.repository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repository.OpenFile(ctx, data)
+}
+
+func (n *namespaceAdapter) AllocateFile(ctx context.Context, data *filesRepositoryAdapterPort.AllocateFileData) error {
+	repository, err := n.repository(ctx)
+	if err != nil {
+		return err
+	}
+	return repository.AllocateFile(ctx, data)
+}
+
+func (n *namespaceAdapter) HashFile(ctx context.Context, data *filesRepositoryAdapterPort.HashFileData) (*filesRepositoryAdapterPort.HashFileResult, error) {
+	repository, err := n.repository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repository.HashFile(ctx, data)
+}
+
+func (n *namespaceAdapter) ListFileVersions(ctx context.Context, data *filesRepositoryAdapterPort.ListFileVersionsData) (*[]filesRepositoryAdapterPort.FileVersionResult, error) {
+	repository, err := n.repository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repository.ListFileVersions(ctx, data)
+}
+
+func (n *namespaceAdapter) OpenFileVersion(ctx context.Context, data *filesRepositoryAdapterPort.OpenFileVersionData) (*filesRepositoryAdapterPort.OpenFileResult, error) {
+	repository, err := n.repository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repository.OpenFileVersion(ctx, data)
+}
+
+func (n *namespaceAdapter) RestoreFileVersion(ctx context.Context, data *filesRepositoryAdapterPort.RestoreFileVersionData) (*filesRepositoryAdapterPort.RestoreFileVersionResult, error) {
+	repository, err := n.repository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repository.RestoreFileVersion(ctx, data)
+}
+
+func (n *namespaceAdapter) SplitFile(ctx context.Context, data *filesRepositoryAdapterPort.SplitFileData) (*filesRepositoryAdapterPort.SplitFileResult, error) {
+	repository, err := n.repository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repository.SplitFile(ctx, data)
+}
+
+func (n *namespaceAdapter) ResolvePath(ctx context.Context, data *filesRepositoryAdapterPort.ResolvePathData) (*filesRepositoryAdapterPort.ResolvePathResult, error) {
+	repository, err := n.repository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repository.ResolvePath(ctx, data)
+}
+
+func (n *namespaceAdapter) CheckPath(ctx context.Context, data *filesRepositoryAdapterPort.CheckPathData) (*filesRepositoryAdapterPort.CheckPathResult, error) {
+	repository, err := n.repository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repository.CheckPath(ctx, data)
+}
+
+func (n *namespaceAdapter) ListExpiredFiles(ctx context.Context, data *filesRepositoryAdapterPort.ListExpiredFilesData) (*[]string, error) {
+	repository, err := n.repository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repository.ListExpiredFiles(ctx, data)
+}
+
+func (n *namespaceAdapter) DeleteExpiredFile(ctx context.Context, data *filesRepositoryAdapterPort.DeleteExpiredFileData) (*filesRepositoryAdapterPort.DeleteExpiredFileResult, error) {
+	repository, err := n.repository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repository.DeleteExpiredFile(ctx, data)
+}
+
+func (n *namespaceAdapter) RecentFiles(ctx context.Context, data *filesRepositoryAdapterPort.RecentFilesData) (*[]filesRepositoryAdapterPort.RecentFileResult, error) {
+	repository, err := n.repository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repository.RecentFiles(ctx, data)
+}
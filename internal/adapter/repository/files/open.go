@@ -0,0 +1,174 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	filesRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/files"
+)
+
+/*
+OpenFile opens a regular file inside the adapter's base path for reading. The
+caller owns the returned file and must close it.
+
+ 1. Rejects paths that traverse outside the base directory (an empty path is
+    the store root), resolves the absolute path and ensures it is inside
+    storeLocalRootPath.
+ 2. Checks parent directories for symlinks, or resolves them inside the base
+    directory if followSymlinks is set (same rules as GetFiles).
+ 3. If the path is a directory and IndexName is set, opens the regular file
+    IndexName inside it instead. A directory without one (or with IndexName
+    empty) yields ErrIsDirectory, so callers can fall back to a listing.
+ 4. Rejects symlinked targets with ErrPathEscape and other non-regular targets
+    with ErrInvalidPath.
+
+MimeType is taken from the file extension, falling back to sniffing the first
+512 bytes, so static assets (css, js, svg) get their proper types.
+
+| Path        | IndexName    | Opens                     |
+|-------------|--------------|---------------------------|
+| "a/b.txt"   | any          | a/b.txt                   |
+| "site"      | "index.html" | site/index.html if exists |
+| "site"      | ""           | ErrIsDirectory            |
+*/
+func (a *adapter) OpenFile(ctx context.Context, data *filesRepositoryAdapterPort.OpenFileData) (*filesRepositoryAdapterPort.OpenFileResult, error) {
+	cleanPath := filepath.Clean(data.Path)
+	if strings.HasPrefix(cleanPath, "..") {
+		return nil, filesRepositoryAdapterPort.ErrPathEscape
+	}
+
+	baseAbs, err := filepath.Abs(a.storeLocalRootPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve base path: %w", err)
+	}
+
+	targetAbs, err := filepath.Abs(filepath.Join(baseAbs, cleanPath))
+	if err != nil {
+		return nil, filesRepositoryAdapterPort.ErrInvalidPath
+	}
+
+	// Ensure target is inside base
+	if rel, err := filepath.Rel(baseAbs, targetAbs); err != nil || strings.HasPrefix(rel, "..") {
+		return nil, filesRepositoryAdapterPort.ErrPathEscape
+	}
+
+	// Check existence
+	if _, err := os.Lstat(targetAbs); os.IsNotExist(err) {
+		return nil, filesRepositoryAdapterPort.ErrFileNotFound
+	}
+
+	// Check parent directories for symlinks, or resolve them inside base
+	readAbs, err := a.listPath(baseAbs, targetAbs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Stat target
+	info, err := os.Lstat(readAbs)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, filesRepositoryAdapterPort.ErrFileNotFound
+		}
+		return nil, err
+	}
+
+	// Serve the index file of a directory
+	if info.IsDir() {
+		if data.IndexName == "" || strings.ContainsAny(data.IndexName, `/\`) {
+			return nil, filesRepositoryAdapterPort.ErrIsDirectory
+		}
+		readAbs = filepath.Join(readAbs, data.IndexName)
+		if info, err = os.Lstat(readAbs); err != nil {
+			if os.IsNotExist(err) {
+				return nil, filesRepositoryAdapterPort.ErrIsDirectory
+			}
+			return nil, err
+		}
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		return nil, filesRepositoryAdapterPort.ErrPathEscape
+	}
+	if !info.Mode().IsRegular() {
+		return nil, filesRepositoryAdapterPort.ErrInvalidPath
+	}
+
+	// Open file
+	f, err := os.Open(readAbs)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, filesRepositoryAdapterPort.ErrFileNotFound
+		}
+		return nil, err
+	}
+
+	// Detect MIME type
+	mimeType := mime.TypeByExtension(filepath.Ext(readAbs))
+	if mimeType == "" {
+		head := make([]byte, 512)
+		n, _ := io.ReadFull(f, head)
+		mimeType = http.DetectContentType(head[:n])
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+
+	return &filesRepositoryAdapterPort.OpenFileResult{
+		File:     f,
+		Name:     info.Name(),
+		Size:     info.Size(),
+		MimeType: mimeType,
+		ModTime:  info.ModTime(),
+	}, nil
+}
+
+/*
+openInBase opens the file at relPath below baseAbs without following a
+symlink in its place. The file is opened through an os.Root handle on
+baseAbs, so no component can lead outside the base even if swapped for a
+symlink after the caller's checks, and must then be the very entry at
+relPath: a symlink there (including one swapped in meanwhile) fails with
+ErrPathEscape. flag must not create or truncate, as it applies before the
+entry is checked.
+*/
+func openInBase(baseAbs, relPath string, flag int) (*os.File, error) {
+	root, err := os.OpenRoot(baseAbs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open base path: %w", err)
+	}
+	defer root.Close()
+
+	// Open file, refusing components that escape base
+	f, err := root.OpenFile(relPath, flag, 0)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		return nil, filesRepositoryAdapterPort.ErrPathEscape
+	}
+
+	// Ensure the opened file is the entry itself, not a symlink target
+	entryInfo, err := root.Lstat(relPath)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	fileInfo, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if entryInfo.Mode()&os.ModeSymlink != 0 || !os.SameFile(entryInfo, fileInfo) {
+		f.Close()
+		return nil, filesRepositoryAdapterPort.ErrPathEscape
+	}
+	return f, nil
+}
@@ -0,0 +1,74 @@
+package adapter
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	filesRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/files"
+)
+
+// Select the entries of a listing page: the first limit entries, in listing
+// order (see listOrderLess), that come strictly after the position given by
+// AfterDir and AfterName. Positioning by the last seen entry rather than an
+// offset keeps pages from skipping or repeating entries when the directory
+// changes between requests.
+func (a *adapter) pageEntries(baseAbs, readAbs string, entries []os.DirEntry, data *filesRepositoryAdapterPort.GetFilesData) []os.DirEntry {
+	type keyed struct {
+		entry os.DirEntry
+		isDir bool
+	}
+	order := a.order(data)
+	page := make([]keyed, 0, len(entries))
+	for _, entry := range entries {
+		isDir := entry.IsDir()
+		if entry.Type()&os.ModeSymlink != 0 {
+			if _, info, ok := a.followSymlink(baseAbs, filepath.Join(readAbs, entry.Name())); ok {
+				isDir = info.IsDir()
+			}
+		}
+
+		// Skip entries up to and including the last seen one
+		if data.AfterName != "" && !listOrderLess(order, data.AfterDir, data.AfterName, isDir, entry.Name()) {
+			continue
+		}
+		page = append(page, keyed{entry, isDir})
+	}
+
+	sort.Slice(page, func(i, j int) bool {
+		return listOrderLess(order, page[i].isDir, page[i].entry.Name(), page[j].isDir, page[j].entry.Name())
+	})
+
+	if len(page) > data.Limit {
+		page = page[:data.Limit]
+	}
+
+	result := make([]os.DirEntry, len(page))
+	for i := range page {
+		result[i] = page[i].entry
+	}
+	return result
+}
+
+// Return the listing order requested by data, else the configured one
+func (a *adapter) order(data *filesRepositoryAdapterPort.GetFilesData) string {
+	if data.Order != "" {
+		return data.Order
+	}
+	return a.listOrder
+}
+
+// Report whether an entry sorts before another in a listing order (dirs
+// first for an empty or unknown order). Names are compared bytewise.
+func listOrderLess(order string, aDir bool, aName string, bDir bool, bName string) bool {
+	if aDir != bDir {
+		switch order {
+		case filesRepositoryAdapterPort.ListOrderFilesFirst:
+			return bDir
+		case filesRepositoryAdapterPort.ListOrderMixed:
+		default:
+			return aDir
+		}
+	}
+	return aName < bName
+}
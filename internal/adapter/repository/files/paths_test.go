@@ -0,0 +1,104 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	filesRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/files"
+)
+
+func TestStoredFilename(t *testing.T) {
+	tests := []struct {
+		filename string
+		name     string
+		err      error
+	}{
+		{"pic.png", "pic.png", nil},
+		{"photos/pic.png", "pic.png", nil},
+		{"./pic.png", "pic.png", nil},
+		{"photos/../pic.png", "pic.png", nil},
+		{"photos//pic.png/", "pic.png", nil},
+		{"", "", filesRepositoryAdapterPort.ErrInvalidFile},
+		{".", "", filesRepositoryAdapterPort.ErrInvalidFile},
+		{"..", "", filesRepositoryAdapterPort.ErrInvalidFile},
+		{"photos/..", "", filesRepositoryAdapterPort.ErrInvalidFile},
+		{"/", "", filesRepositoryAdapterPort.ErrInvalidFile},
+		{"pic\x00.png", "", filesRepositoryAdapterPort.ErrInvalidCharacters},
+	}
+	for _, tt := range tests {
+		name, err := storedFilename(tt.filename)
+		if name != tt.name || !errors.Is(err, tt.err) {
+			t.Errorf("storedFilename(%q) = %q, %v, want %q, %v", tt.filename, name, err, tt.name, tt.err)
+		}
+	}
+}
+
+func TestEquivalentPathSpellingsShareOneLocation(t *testing.T) {
+	spellings := []string{"a/b", "a//b", "a/./b/", "./a/b", "a/c/../b"}
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "a", "b"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "a", "c"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	a := &adapter{storeLocalRootPath: dir}
+
+	for i, spelling := range spellings {
+		t.Run(spelling, func(t *testing.T) {
+			// Create through this spelling
+			result, err := a.CreateFile(context.Background(), &filesRepositoryAdapterPort.CreateFileData{
+				Path: spelling,
+				File: multipartFile(t, "notes.txt", []byte("notes")),
+			})
+			if err != nil {
+				t.Fatalf("CreateFile: %v", err)
+			}
+			if result.Path != "a/b/notes.txt" {
+				t.Errorf("created at %q, want a/b/notes.txt", result.Path)
+			}
+
+			// Every other spelling sees the same file
+			for _, other := range spellings {
+				_, err := a.CreateFile(context.Background(), &filesRepositoryAdapterPort.CreateFileData{
+					Path: other,
+					File: multipartFile(t, "notes.txt", []byte("notes")),
+				})
+				if !errors.Is(err, filesRepositoryAdapterPort.ErrFileExist) {
+					t.Errorf("CreateFile through %q = %v, want ErrFileExist", other, err)
+				}
+				results, err := a.GetFiles(context.Background(), &filesRepositoryAdapterPort.GetFilesData{Path: other})
+				if err != nil {
+					t.Fatalf("GetFiles through %q: %v", other, err)
+				}
+				if len(results.Files) != 1 || results.Files[0].Name != "notes.txt" {
+					t.Errorf("GetFiles through %q = %+v, want notes.txt only", other, results.Files)
+				}
+			}
+
+			// Delete through the next spelling
+			next := spellings[(i+1)%len(spellings)]
+			if err := a.DeleteFile(context.Background(), &filesRepositoryAdapterPort.DeleteFileData{
+				Path: strings.TrimSuffix(next, "/") + "//notes.txt",
+			}); err != nil {
+				t.Fatalf("DeleteFile through %q: %v", next, err)
+			}
+			if _, err := os.Stat(filepath.Join(dir, "a", "b", "notes.txt")); !os.IsNotExist(err) {
+				t.Errorf("file still present after delete: %v", err)
+			}
+		})
+	}
+
+	// No directory was created for any of the spellings
+	entries, err := os.ReadDir(filepath.Join(dir, "a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("a holds %d entries, want b and c only", len(entries))
+	}
+}
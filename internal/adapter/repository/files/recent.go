@@ -0,0 +1,183 @@
+package adapter
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	filesRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/files"
+	"github.com/flash-go/files-service/internal/storefs"
+)
+
+const (
+	// Number of files returned by RecentFiles when no limit is given
+	defaultRecentLimit = 50
+
+	// Maximum number of files returned by RecentFiles
+	maxRecentLimit = 1000
+)
+
+/*
+RecentFiles returns the most recently modified regular files of a subtree
+within the adapter's base path, newest first.
+
+This function performs the same path checks as FindFiles:
+
+ 1. Validates that the path does not traverse outside the base directory.
+ 2. Resolves the absolute path and ensures it is inside storeLocalRootPath.
+ 3. Checks parent directories for symlinks to prevent symlink race attacks.
+ 4. Confirms the root exists and is a directory.
+
+The walk keeps only the Limit newest files seen so far (defaultRecentLimit if
+zero, at most maxRecentLimit) in a min-heap, so memory stays bounded by the
+limit whatever the size of the subtree. Entries matching hiddenNames are
+skipped along with their contents, symlinks are never followed nor reported,
+the walk stops Depth levels below the root (at most and by default
+maxFindDepth), and it is aborted when the context is done. Files with the
+same modification time are ordered by path. Paths are slash-separated and
+relative to the root.
+*/
+func (a *adapter) RecentFiles(ctx context.Context, data *filesRepositoryAdapterPort.RecentFilesData) (*[]filesRepositoryAdapterPort.RecentFileResult, error) {
+	limit := data.Limit
+	if limit <= 0 {
+		limit = defaultRecentLimit
+	}
+	if limit > maxRecentLimit {
+		limit = maxRecentLimit
+	}
+
+	depth := data.Depth
+	if depth <= 0 || depth > maxFindDepth {
+		depth = maxFindDepth
+	}
+
+	cleanPath := filepath.Clean(data.Path)
+	if strings.HasPrefix(cleanPath, "..") {
+		return nil, filesRepositoryAdapterPort.ErrPathEscape
+	}
+
+	baseAbs, err := filepath.Abs(a.storeLocalRootPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve base path: %w", err)
+	}
+
+	rootAbs, err := filepath.Abs(filepath.Join(baseAbs, cleanPath))
+	if err != nil {
+		return nil, filesRepositoryAdapterPort.ErrInvalidPath
+	}
+
+	// Ensure root is inside base
+	if rel, _ := filepath.Rel(baseAbs, rootAbs); strings.HasPrefix(rel, "..") {
+		return nil, filesRepositoryAdapterPort.ErrPathEscape
+	}
+
+	// Check parent directories for symlinks
+	if err := checkParents(baseAbs, rootAbs); err != nil {
+		return nil, parentsError(err)
+	}
+
+	// Check root is a directory
+	info, err := os.Stat(rootAbs)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, filesRepositoryAdapterPort.ErrDirNotFound
+		}
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, filesRepositoryAdapterPort.ErrInvalidPath
+	}
+
+	// Walk the subtree, keeping the newest files
+	recent := make(recentHeap, 0, limit)
+	err = filepath.WalkDir(rootAbs, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(rootAbs, p)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+
+		// Skip hidden entries and everything below them
+		if storefs.Hidden(a.hiddenNames, d.Name()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				// Removed since it was listed
+				return nil
+			}
+			file := filesRepositoryAdapterPort.RecentFileResult{
+				Path:    rel,
+				Size:    info.Size(),
+				ModTime: info.ModTime(),
+			}
+			if len(recent) < limit {
+				heap.Push(&recent, file)
+			} else if recentBefore(recent[0], file) {
+				recent[0] = file
+				heap.Fix(&recent, 0)
+			}
+		}
+
+		// Stop descending at the depth limit
+		if d.IsDir() && strings.Count(rel, "/")+1 >= depth {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := []filesRepositoryAdapterPort.RecentFileResult(recent)
+	sort.Slice(results, func(i, j int) bool {
+		return recentBefore(results[j], results[i])
+	})
+	return &results, nil
+}
+
+// Report whether file a is older than file b (for equal times, whether it
+// sorts after b by path, so newest-first results are ordered by path)
+func recentBefore(a, b filesRepositoryAdapterPort.RecentFileResult) bool {
+	if !a.ModTime.Equal(b.ModTime) {
+		return a.ModTime.Before(b.ModTime)
+	}
+	return a.Path > b.Path
+}
+
+// Min-heap of files with the oldest at the root
+type recentHeap []filesRepositoryAdapterPort.RecentFileResult
+
+func (h recentHeap) Len() int           { return len(h) }
+func (h recentHeap) Less(i, j int) bool { return recentBefore(h[i], h[j]) }
+func (h recentHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *recentHeap) Push(x any) {
+	*h = append(*h, x.(filesRepositoryAdapterPort.RecentFileResult))
+}
+
+func (h *recentHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
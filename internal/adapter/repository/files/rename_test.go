@@ -0,0 +1,106 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	filesRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/files"
+)
+
+func TestRenameFileWritesNothingToStdout(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "old.txt"), []byte("content"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	a := &adapter{storeLocalRootPath: dir}
+
+	// Redirect stdout through a pipe for the duration of the rename
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = writer
+	renameErr := a.RenameFile(context.Background(), &filesRepositoryAdapterPort.RenameFileData{
+		OldPath: "old.txt",
+		NewPath: "new.txt",
+	})
+	os.Stdout = stdout
+	writer.Close()
+	output, err := io.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if renameErr != nil {
+		t.Fatalf("RenameFile: %v", renameErr)
+	}
+	if len(output) > 0 {
+		t.Errorf("RenameFile wrote to stdout: %q", output)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "new.txt")); err != nil {
+		t.Errorf("renamed file: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "old.txt")); !os.IsNotExist(err) {
+		t.Errorf("old file still present: %v", err)
+	}
+}
+
+func TestRenameFileOntoItself(t *testing.T) {
+	tests := []struct {
+		name    string
+		newPath string
+		noop    bool
+		link    string
+		want    error
+		exist   []string
+	}{
+		{"identical path", "notes.txt", false, "", filesRepositoryAdapterPort.ErrSamePath, []string{"notes.txt"}},
+		{"identical path as no-op", "notes.txt", true, "", nil, []string{"notes.txt"}},
+		{"equivalent spelling", "./docs/../notes.txt", false, "", filesRepositoryAdapterPort.ErrSamePath, []string{"notes.txt"}},
+		{"equivalent spelling as no-op", "./docs/../notes.txt", true, "", nil, []string{"notes.txt"}},
+		{"case variant", "Notes.txt", false, "", nil, []string{"Notes.txt"}},
+		{"case variant of another file", "Notes.txt", false, "other.txt", filesRepositoryAdapterPort.ErrFileNewExist, []string{"notes.txt", "Notes.txt"}},
+		// A hard link stands in for the same file seen through a
+		// case-insensitive filesystem
+		{"case variant resolving to the file", "Notes.txt", false, "notes.txt", nil, []string{"Notes.txt"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.Mkdir(filepath.Join(dir, "docs"), 0700); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("notes"), 0600); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "other.txt"), []byte("other"), 0600); err != nil {
+				t.Fatal(err)
+			}
+			if tt.link != "" {
+				if err := os.Link(filepath.Join(dir, tt.link), filepath.Join(dir, "Notes.txt")); err != nil {
+					t.Fatal(err)
+				}
+			}
+			a := &adapter{storeLocalRootPath: dir, renameSamePathNoop: tt.noop}
+
+			err := a.RenameFile(context.Background(), &filesRepositoryAdapterPort.RenameFileData{
+				OldPath: "notes.txt",
+				NewPath: tt.newPath,
+			})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("RenameFile = %v, want %v", err, tt.want)
+			}
+			for _, name := range tt.exist {
+				if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+					t.Errorf("%s after rename: %v", name, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	filesRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/files"
+)
+
+/*
+ResolvePath returns the canonical form of a path as the other operations
+resolve it, and whether and as what it exists, without listing or reading
+anything.
+
+ 1. Cleans the path and rejects traversal outside the base directory with
+    ErrPathEscape, the same way every operation does.
+ 2. Walks the parent directories of the deepest existing component,
+    rejecting symlinked components with ErrPathEscape.
+ 3. Stats the entry itself without following a symlink.
+
+Path is slash-separated and relative to the base directory ("" for the base
+itself), so "/uploads/", "./uploads" and "uploads/x/.." all resolve to
+"uploads". Type is one of the EntryType constants, and nil if the entry does
+not exist.
+*/
+func (a *adapter) ResolvePath(ctx context.Context, data *filesRepositoryAdapterPort.ResolvePathData) (*filesRepositoryAdapterPort.ResolvePathResult, error) {
+	cleanPath := filepath.Clean(data.Path)
+	if strings.HasPrefix(cleanPath, "..") {
+		return nil, filesRepositoryAdapterPort.ErrPathEscape
+	}
+
+	baseAbs, err := filepath.Abs(a.storeLocalRootPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve base path: %w", err)
+	}
+	targetAbs := filepath.Join(baseAbs, cleanPath)
+	rel, err := filepath.Rel(baseAbs, targetAbs)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return nil, filesRepositoryAdapterPort.ErrPathEscape
+	}
+	if rel == "." {
+		rel = ""
+	}
+	result := filesRepositoryAdapterPort.ResolvePathResult{
+		Path: filepath.ToSlash(rel),
+	}
+
+	// Find the deepest existing component
+	existingAbs := targetAbs
+	for {
+		if _, err := os.Lstat(existingAbs); err == nil {
+			break
+		} else if !os.IsNotExist(err) || existingAbs == baseAbs {
+			return nil, filesRepositoryAdapterPort.ErrInvalidPath
+		}
+		existingAbs = filepath.Dir(existingAbs)
+	}
+
+	// Check parent directories for symlinks
+	if existingAbs != baseAbs {
+		if err := checkParents(baseAbs, filepath.Dir(existingAbs)); err != nil {
+			return nil, parentsError(err)
+		}
+	}
+	if existingAbs != targetAbs {
+		// Only a directory can hold the missing rest of the path
+		if info, err := os.Lstat(existingAbs); err != nil || !info.IsDir() {
+			return nil, filesRepositoryAdapterPort.ErrInvalidPath
+		}
+		return &result, nil
+	}
+
+	// Stat entry
+	info, err := os.Lstat(targetAbs)
+	if err != nil {
+		return nil, err
+	}
+	entryType := filesRepositoryAdapterPort.EntryTypeOther
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		entryType = filesRepositoryAdapterPort.EntryTypeSymlink
+	case info.IsDir():
+		entryType = filesRepositoryAdapterPort.EntryTypeDir
+	case info.Mode().IsRegular():
+		entryType = filesRepositoryAdapterPort.EntryTypeFile
+	}
+	result.Exists = true
+	result.Type = &entryType
+
+	return &result, nil
+}
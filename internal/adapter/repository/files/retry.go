@@ -0,0 +1,208 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"syscall"
+	"time"
+
+	filesRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/files"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Longest wait between two attempts of a call
+const maxRetryBackoff = 5 * time.Second
+
+// Errors of a storage backend that is momentarily unavailable (e.g. an NFS
+// server restarting or a throttled FUSE mount), worth trying again
+var transientErrors = []error{
+	syscall.EAGAIN,
+	syscall.EINTR,
+	syscall.EBUSY,
+	syscall.ETIMEDOUT,
+	syscall.ESTALE,
+}
+
+// Repository decorator retrying the calls that only read the store when they
+// fail with a transient error. Calls that change the store are passed through
+// unchanged, as retrying a partly applied change is not safe.
+type retryAdapter struct {
+	next   filesRepositoryAdapterPort.Interface
+	policy retryPolicy
+}
+
+// Number of retries of a failed call, the wait before the first one (doubled
+// before each next one, up to maxRetryBackoff) and the counter of retries
+type retryPolicy struct {
+	attempts int
+	backoff  time.Duration
+	counter  metric.Int64Counter
+}
+
+// Report whether an error is transient. Not found, exists, permission and
+// validation errors never are.
+func transient(err error) bool {
+	for _, target := range transientErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Run fn, running it again up to policy.attempts times while it fails with a
+// transient error. The waits are jittered between half and all of the
+// backoff, and cut short when ctx is done, returning the last error. Every
+// retry is counted with the operation name as attribute.
+func withRetry[T any](ctx context.Context, policy *retryPolicy, operation string, fn func() (T, error)) (T, error) {
+	value, err := fn()
+	backoff := policy.backoff
+	for attempt := 0; attempt < policy.attempts && err != nil && transient(err); attempt++ {
+		wait := backoff/2 + rand.N(backoff/2+1)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return value, err
+		case <-timer.C:
+		}
+		if policy.counter != nil {
+			policy.counter.Add(ctx, 1, metric.WithAttributes(attribute.String("operation", operation)))
+		}
+		value, err = fn()
+		backoff = min(backoff*2, maxRetryBackoff)
+	}
+	return value, err
+}
+
+func (r *retryAdapter) CreateFile(ctx context.Context, data *filesRepositoryAdapterPort.CreateFileData) (*filesRepositoryAdapterPort.CreateFileResult, error) {
+	return r.next.CreateFile(ctx, data)
+}
+
+func (r *retryAdapter) GetFiles(ctx context.Context, data *filesRepositoryAdapterPort.GetFilesData) (*filesRepositoryAdapterPort.GetFilesResult, error) {
+	return withRetry(ctx, &r.policy, "GetFiles", func() (*filesRepositoryAdapterPort.GetFilesResult, error) {
+		return r.next.GetFiles(ctx, data)
+	})
+}
+
+// Not retried, as a new attempt would hand the matches already found to
+// data.Found again
+func (r *retryAdapter) FindFiles(ctx context.Context, data *filesRepositoryAdapterPort.FindFilesData) error {
+	return r.next.FindFiles(ctx, data)
+}
+
+func (r *retryAdapter) RecentFiles(ctx context.Context, data *filesRepositoryAdapterPort.RecentFilesData) (*[]filesRepositoryAdapterPort.RecentFileResult, error) {
+	return withRetry(ctx, &r.policy, "RecentFiles", func() (*[]filesRepositoryAdapterPort.RecentFileResult, error) {
+		return r.next.RecentFiles(ctx, data)
+	})
+}
+
+func (r *retryAdapter) DeleteFile(ctx context.Context, data *filesRepositoryAdapterPort.DeleteFileData) error {
+	return r.next.DeleteFile(ctx, data)
+}
+
+func (r *retryAdapter) RenameFile(ctx context.Context, data *filesRepositoryAdapterPort.RenameFileData) error {
+	return r.next.RenameFile(ctx, data)
+}
+
+func (r *retryAdapter) MoveFile(ctx context.Context, data *filesRepositoryAdapterPort.MoveFileData) (*filesRepositoryAdapterPort.MoveFileResult, error) {
+	return r.next.MoveFile(ctx, data)
+}
+
+func (r *retryAdapter) CopyFile(ctx context.Context, data *filesRepositoryAdapterPort.CopyFileData) (*filesRepositoryAdapterPort.CopyFileResult, error) {
+	return r.next.CopyFile(ctx, data)
+}
+
+func (r *retryAdapter) WriteFileAt(ctx context.Context, data *filesRepositoryAdapterPort.WriteFileAtData) error {
+	return r.next.WriteFileAt(ctx, data)
+}
+
+func (r *retryAdapter) AllocateFile(ctx context.Context, data *filesRepositoryAdapterPort.AllocateFileData) error {
+	return r.next.AllocateFile(ctx, data)
+}
+
+func (r *retryAdapter) HashFile(ctx context.Context, data *filesRepositoryAdapterPort.HashFileData) (*filesRepositoryAdapterPort.HashFileResult, error) {
+	return withRetry(ctx, &r.policy, "HashFile", func() (*filesRepositoryAdapterPort.HashFileResult, error) {
+		return r.next.HashFile(ctx, data)
+	})
+}
+
+func (r *retryAdapter) WriteFileRange(ctx context.Context, data *filesRepositoryAdapterPort.WriteFileRangeData) error {
+	return r.next.WriteFileRange(ctx, data)
+}
+
+func (r *retryAdapter) PreviewFile(ctx context.Context, data *filesRepositoryAdapterPort.PreviewFileData) (*filesRepositoryAdapterPort.PreviewResult, error) {
+	return withRetry(ctx, &r.policy, "PreviewFile", func() (*filesRepositoryAdapterPort.PreviewResult, error) {
+		return r.next.PreviewFile(ctx, data)
+	})
+}
+
+func (r *retryAdapter) StatFile(ctx context.Context, data *filesRepositoryAdapterPort.StatFileData) (*filesRepositoryAdapterPort.FileResult, error) {
+	return withRetry(ctx, &r.policy, "StatFile", func() (*filesRepositoryAdapterPort.FileResult, error) {
+		return r.next.StatFile(ctx, data)
+	})
+}
+
+func (r *retryAdapter) PurgeTrash(ctx context.Context, data *filesRepositoryAdapterPort.PurgeTrashData) (*filesRepositoryAdapterPort.PurgeTrashResult, error) {
+	return r.next.PurgeTrash(ctx, data)
+}
+
+func (r *retryAdapter) PurgeBackups(ctx context.Context, data *filesRepositoryAdapterPort.PurgeBackupsData) (*filesRepositoryAdapterPort.PurgeBackupsResult, error) {
+	return r.next.PurgeBackups(ctx, data)
+}
+
+func (r *retryAdapter) ListToken(ctx context.Context, data *filesRepositoryAdapterPort.ListTokenData) (*filesRepositoryAdapterPort.ListTokenResult, error) {
+	return withRetry(ctx, &r.policy, "ListToken", func() (*filesRepositoryAdapterPort.ListTokenResult, error) {
+		return r.next.ListToken(ctx, data)
+	})
+}
+
+func (r *retryAdapter) OpenFile(ctx context.Context, data *filesRepositoryAdapterPort.OpenFileData) (*filesRepositoryAdapterPort.OpenFileResult, error) {
+	return withRetry(ctx, &r.policy, "OpenFile", func() (*filesRepositoryAdapterPort.OpenFileResult, error) {
+		return r.next.OpenFile(ctx, data)
+	})
+}
+
+func (r *retryAdapter) ListFileVersions(ctx context.Context, data *filesRepositoryAdapterPort.ListFileVersionsData) (*[]filesRepositoryAdapterPort.FileVersionResult, error) {
+	return withRetry(ctx, &r.policy, "ListFileVersions", func() (*[]filesRepositoryAdapterPort.FileVersionResult, error) {
+		return r.next.ListFileVersions(ctx, data)
+	})
+}
+
+func (r *retryAdapter) OpenFileVersion(ctx context.Context, data *filesRepositoryAdapterPort.OpenFileVersionData) (*filesRepositoryAdapterPort.OpenFileResult, error) {
+	return withRetry(ctx, &r.policy, "OpenFileVersion", func() (*filesRepositoryAdapterPort.OpenFileResult, error) {
+		return r.next.OpenFileVersion(ctx, data)
+	})
+}
+
+func (r *retryAdapter) RestoreFileVersion(ctx context.Context, data *filesRepositoryAdapterPort.RestoreFileVersionData) (*filesRepositoryAdapterPort.RestoreFileVersionResult, error) {
+	return r.next.RestoreFileVersion(ctx, data)
+}
+
+func (r *retryAdapter) SplitFile(ctx context.Context, data *filesRepositoryAdapterPort.SplitFileData) (*filesRepositoryAdapterPort.SplitFileResult, error) {
+	return r.next.SplitFile(ctx, data)
+}
+
+func (r *retryAdapter) ResolvePath(ctx context.Context, data *filesRepositoryAdapterPort.ResolvePathData) (*filesRepositoryAdapterPort.ResolvePathResult, error) {
+	return withRetry(ctx, &r.policy, "ResolvePath", func() (*filesRepositoryAdapterPort.ResolvePathResult, error) {
+		return r.next.ResolvePath(ctx, data)
+	})
+}
+
+func (r *retryAdapter) CheckPath(ctx context.Context, data *filesRepositoryAdapterPort.CheckPathData) (*filesRepositoryAdapterPort.CheckPathResult, error) {
+	return withRetry(ctx, &r.policy, "CheckPath", func() (*filesRepositoryAdapterPort.CheckPathResult, error) {
+		return r.next.CheckPath(ctx, data)
+	})
+}
+
+func (r *retryAdapter) ListExpiredFiles(ctx context.Context, data *filesRepositoryAdapterPort.ListExpiredFilesData) (*[]string, error) {
+	return withRetry(ctx, &r.policy, "ListExpiredFiles", func() (*[]string, error) {
+		return r.next.ListExpiredFiles(ctx, data)
+	})
+}
+
+func (r *retryAdapter) DeleteExpiredFile(ctx context.Context, data *filesRepositoryAdapterPort.DeleteExpiredFileData) (*filesRepositoryAdapterPort.DeleteExpiredFileResult, error) {
+	return r.next.DeleteExpiredFile(ctx, data)
+}
@@ -0,0 +1,41 @@
+package adapter
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Subdirectory receiving uploads whose MIME type matches Pattern: a media
+// type ("application/pdf"), a top-level type wildcard ("image/*") or "*"
+type MimeRoute struct {
+	Pattern string
+	Dir     string
+}
+
+// Return the subdirectory of the first route matching mimeType ("" if none
+// matches)
+func (a *adapter) routeDir(mimeType string) string {
+	mediaType, _, _ := strings.Cut(mimeType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	topType, _, _ := strings.Cut(mediaType, "/")
+	for _, route := range a.mimeRoutes {
+		switch route.Pattern {
+		case "*", mediaType, topType + "/*":
+			return route.Dir
+		}
+	}
+	return ""
+}
+
+// Determine the MIME type of an upload named name according to
+// mimeDetection, like detectMimeType does for stored files
+func (a *adapter) detectUploadMimeType(name string, content *upload) (string, error) {
+	if mt := a.extensionMimeType(name); mt != "" {
+		return mt, nil
+	}
+	head, err := content.head()
+	if err != nil {
+		return "", err
+	}
+	return http.DetectContentType(head), nil
+}
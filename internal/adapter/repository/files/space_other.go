@@ -0,0 +1,8 @@
+//go:build !linux
+
+package adapter
+
+// Free space is not queried on this platform
+func freeSpace(path string) (int64, bool) {
+	return 0, false
+}
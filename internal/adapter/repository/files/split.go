@@ -0,0 +1,205 @@
+package adapter
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	filesRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/files"
+)
+
+// Maximum number of parts written by a single split (part names carry four
+// digits)
+const maxSplitParts = 9999
+
+/*
+SplitFile writes the content of a regular file inside the adapter's base path
+into sequential part files of ChunkSize bytes (the last one may be shorter),
+named "<name>.part0001", "<name>.part0002", ... in a target directory (the
+file's own directory if TargetPath is empty). It is the inverse of
+concatenating the parts in order.
+
+ 1. Opens the file through OpenFile, so the same path, symlink and type
+    checks apply. Directories are rejected with ErrIsDirectory.
+ 2. Rejects a ChunkSize below 1 with ErrInvalidChunkSize, and splits into
+    more than maxSplitParts parts with ErrTooManyParts.
+ 3. Resolves the target inside the base directory, rejecting traversal and
+    symlinked parents; it must be an existing directory (ErrDirNotFound).
+ 4. Fails with ErrFileExist if any part name is taken, with ErrDirFull if the
+    parts would take the target past dirMaxEntries and, if checkFreeSpace is
+    set, with ErrStorageFull if the target filesystem lacks the file's size
+    plus freeSpaceMargin.
+ 5. Writes every part to a temp file, hashing it on the way, and links it
+    into place, so a part created meanwhile is never replaced. Stops with
+    the context error once ctx is done.
+ 6. Removes the parts already written on every failure, so a split either
+    yields all parts or none.
+
+An empty file yields a single empty part. Paths in the result are
+slash-separated and relative to the base directory; hashes are lowercase hex
+SHA-256.
+*/
+func (a *adapter) SplitFile(ctx context.Context, data *filesRepositoryAdapterPort.SplitFileData) (*filesRepositoryAdapterPort.SplitFileResult, error) {
+	if data.ChunkSize < 1 {
+		return nil, filesRepositoryAdapterPort.ErrInvalidChunkSize
+	}
+
+	// Open source file
+	opened, err := a.OpenFile(ctx, &filesRepositoryAdapterPort.OpenFileData{Path: data.Path})
+	if err != nil {
+		return nil, err
+	}
+	src := opened.File
+	defer src.Close()
+
+	// Count parts
+	parts := max((opened.Size+data.ChunkSize-1)/data.ChunkSize, 1)
+	if parts > maxSplitParts {
+		return nil, filesRepositoryAdapterPort.ErrTooManyParts
+	}
+
+	// Resolve the target directory
+	baseAbs, err := filepath.Abs(a.storeLocalRootPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve base path: %w", err)
+	}
+	targetPath := data.TargetPath
+	if targetPath == "" {
+		targetPath = filepath.Dir(filepath.Clean(data.Path))
+	}
+	targetClean := filepath.Clean(targetPath)
+	if strings.HasPrefix(targetClean, "..") {
+		return nil, filesRepositoryAdapterPort.ErrPathEscape
+	}
+	targetAbs := filepath.Join(baseAbs, targetClean)
+	if rel, err := filepath.Rel(baseAbs, targetAbs); err != nil || strings.HasPrefix(rel, "..") {
+		return nil, filesRepositoryAdapterPort.ErrPathEscape
+	}
+
+	// Check parent directories for symlinks (symlink race prevention)
+	if err := checkParents(baseAbs, targetAbs); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, filesRepositoryAdapterPort.ErrDirNotFound
+		}
+		return nil, err
+	}
+	if info, err := os.Stat(targetAbs); err != nil {
+		if os.IsNotExist(err) {
+			return nil, filesRepositoryAdapterPort.ErrDirNotFound
+		}
+		return nil, err
+	} else if !info.IsDir() {
+		return nil, filesRepositoryAdapterPort.ErrInvalidPath
+	}
+
+	// Check part names, capacity and free space before writing anything
+	names := make([]string, parts)
+	for i := range names {
+		names[i] = fmt.Sprintf("%s.part%04d", opened.Name, i+1)
+		if _, err := os.Lstat(filepath.Join(targetAbs, names[i])); err == nil {
+			return nil, filesRepositoryAdapterPort.ErrFileExist
+		} else if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	if room, err := a.dirRoom(targetAbs, len(names)); err != nil {
+		return nil, fmt.Errorf("failed to count entries: %w", err)
+	} else if !room {
+		return nil, filesRepositoryAdapterPort.ErrDirFull
+	}
+	if a.checkFreeSpace {
+		if free, ok := freeSpace(targetAbs); ok && free < opened.Size+a.freeSpaceMargin {
+			return nil, filesRepositoryAdapterPort.ErrStorageFull
+		}
+	}
+
+	// Write parts, removing them all unless every part was written
+	written := []string{}
+	committed := false
+	defer func() {
+		if !committed {
+			for _, partAbs := range written {
+				os.Remove(partAbs)
+			}
+		}
+	}()
+	result := filesRepositoryAdapterPort.SplitFileResult{
+		Parts: make([]filesRepositoryAdapterPort.FilePart, 0, len(names)),
+	}
+	reader := contextReader{ctx, io.LimitReader(src, opened.Size)}
+	for _, name := range names {
+		partAbs := filepath.Join(targetAbs, name)
+		size, sum, err := a.writePart(reader, targetAbs, partAbs, data.ChunkSize)
+		if err != nil {
+			return nil, err
+		}
+		written = append(written, partAbs)
+		rel, _ := filepath.Rel(baseAbs, partAbs)
+		result.Parts = append(result.Parts, filesRepositoryAdapterPort.FilePart{
+			Path:   filepath.ToSlash(rel),
+			Size:   size,
+			SHA256: sum,
+		})
+	}
+	committed = true
+
+	return &result, nil
+}
+
+// Write the next chunkSize bytes of reader to partAbs through a temp file,
+// returning the part's size and SHA-256. The part must not exist yet.
+func (a *adapter) writePart(reader io.Reader, dirAbs, partAbs string, chunkSize int64) (int64, string, error) {
+	dst, err := os.CreateTemp(a.tempDir(dirAbs), "."+filepath.Base(partAbs)+".tmp-*")
+	if err != nil {
+		return 0, "", storageError(err)
+	}
+	defer func() {
+		dst.Close()
+		os.Remove(dst.Name())
+	}()
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(dst, hash), io.LimitReader(reader, chunkSize))
+	if err != nil {
+		return 0, "", storageError(err)
+	}
+	if err := dst.Close(); err != nil {
+		return 0, "", storageError(err)
+	}
+
+	// Move the part into place
+	if err := os.Link(dst.Name(), partAbs); err != nil {
+		if os.IsExist(err) {
+			return 0, "", filesRepositoryAdapterPort.ErrFileExist
+		}
+		return 0, "", storageError(err)
+	}
+	return size, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Report whether dirAbs can take n more entries under dirMaxEntries
+func (a *adapter) dirRoom(dirAbs string, n int) (bool, error) {
+	if a.dirMaxEntries <= 0 {
+		return true, nil
+	}
+	if n > a.dirMaxEntries {
+		return false, nil
+	}
+	dir, err := os.Open(dirAbs)
+	if err != nil {
+		return false, err
+	}
+	defer dir.Close()
+	names, err := dir.Readdirnames(a.dirMaxEntries)
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+	return len(names)+n <= a.dirMaxEntries, nil
+}
@@ -0,0 +1,48 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	filesRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/files"
+)
+
+func TestStatAndPreviewRejectSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	outside := filepath.Join(t.TempDir(), "secret.txt")
+	if err := os.WriteFile(outside, []byte("secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("notes\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(dir, "outside.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("notes.txt", filepath.Join(dir, "inside.txt")); err != nil {
+		t.Fatal(err)
+	}
+	a := &adapter{storeLocalRootPath: dir}
+
+	for _, path := range []string{"outside.txt", "inside.txt"} {
+		_, err := a.StatFile(context.Background(), &filesRepositoryAdapterPort.StatFileData{Path: path})
+		if !errors.Is(err, filesRepositoryAdapterPort.ErrPathEscape) {
+			t.Errorf("StatFile(%q) = %v, want ErrPathEscape", path, err)
+		}
+		_, err = a.PreviewFile(context.Background(), &filesRepositoryAdapterPort.PreviewFileData{Path: path})
+		if !errors.Is(err, filesRepositoryAdapterPort.ErrPathEscape) {
+			t.Errorf("PreviewFile(%q) = %v, want ErrPathEscape", path, err)
+		}
+	}
+
+	result, err := a.PreviewFile(context.Background(), &filesRepositoryAdapterPort.PreviewFileData{Path: "notes.txt"})
+	if err != nil {
+		t.Fatalf("PreviewFile: %v", err)
+	}
+	if result.Content != "notes\n" {
+		t.Errorf("PreviewFile content = %q, want %q", result.Content, "notes\n")
+	}
+}
@@ -0,0 +1,168 @@
+package adapter
+
+import (
+	"context"
+	"time"
+
+	"github.com/flash-go/files-service/internal/deadline"
+	filesRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/files"
+)
+
+// Repository decorator bounding the calls that only read the store (stat,
+// list, open, hash) by operationTimeout. The call runs in its own goroutine
+// selected against the derived context, so a blocking syscall on a hung
+// network mount is abandoned (left to finish in the background) instead of
+// wedging the request. Writes pass straight through: an abandoned write
+// would keep changing the store after the request failed and released its
+// path lock, and uploads and copies are bounded by their own deadlines.
+type timeoutAdapter struct {
+	next             filesRepositoryAdapterPort.Interface
+	operationTimeout time.Duration
+}
+
+// Run fn with a context bounded by timeout, see deadline.Call
+func withTimeout[T any](ctx context.Context, timeout time.Duration, fn func(ctx context.Context) (T, error)) (T, error) {
+	return deadline.Call(ctx, timeout, filesRepositoryAdapterPort.ErrOperationTimeout, fn)
+}
+
+// Like withTimeout, but releases the value of an abandoned call that later
+// succeeds, see deadline.CallRelease
+func withTimeoutRelease[T any](ctx context.Context, timeout time.Duration, fn func(ctx context.Context) (T, error), release func(T)) (T, error) {
+	return deadline.CallRelease(ctx, timeout, filesRepositoryAdapterPort.ErrOperationTimeout, fn, release)
+}
+
+func (t *timeoutAdapter) CreateFile(ctx context.Context, data *filesRepositoryAdapterPort.CreateFileData) (*filesRepositoryAdapterPort.CreateFileResult, error) {
+	return t.next.CreateFile(ctx, data)
+}
+
+func (t *timeoutAdapter) GetFiles(ctx context.Context, data *filesRepositoryAdapterPort.GetFilesData) (*filesRepositoryAdapterPort.GetFilesResult, error) {
+	return withTimeout(ctx, t.operationTimeout, func(ctx context.Context) (*filesRepositoryAdapterPort.GetFilesResult, error) {
+		return t.next.GetFiles(ctx, data)
+	})
+}
+
+// A search hands its matches to data.Found while it runs, so it cannot be
+// abandoned; it is bounded by a context the walk checks at every entry instead
+func (t *timeoutAdapter) FindFiles(ctx context.Context, data *filesRepositoryAdapterPort.FindFilesData) error {
+	ctx, cancel := context.WithTimeoutCause(ctx, t.operationTimeout, filesRepositoryAdapterPort.ErrOperationTimeout)
+	defer cancel()
+	return t.next.FindFiles(ctx, data)
+}
+
+func (t *timeoutAdapter) DeleteFile(ctx context.Context, data *filesRepositoryAdapterPort.DeleteFileData) error {
+	return t.next.DeleteFile(ctx, data)
+}
+
+func (t *timeoutAdapter) RenameFile(ctx context.Context, data *filesRepositoryAdapterPort.RenameFileData) error {
+	return t.next.RenameFile(ctx, data)
+}
+
+func (t *timeoutAdapter) MoveFile(ctx context.Context, data *filesRepositoryAdapterPort.MoveFileData) (*filesRepositoryAdapterPort.MoveFileResult, error) {
+	return t.next.MoveFile(ctx, data)
+}
+
+func (t *timeoutAdapter) CopyFile(ctx context.Context, data *filesRepositoryAdapterPort.CopyFileData) (*filesRepositoryAdapterPort.CopyFileResult, error) {
+	return t.next.CopyFile(ctx, data)
+}
+
+func (t *timeoutAdapter) WriteFileAt(ctx context.Context, data *filesRepositoryAdapterPort.WriteFileAtData) error {
+	return t.next.WriteFileAt(ctx, data)
+}
+
+func (t *timeoutAdapter) WriteFileRange(ctx context.Context, data *filesRepositoryAdapterPort.WriteFileRangeData) error {
+	return t.next.WriteFileRange(ctx, data)
+}
+
+func (t *timeoutAdapter) PreviewFile(ctx context.Context, data *filesRepositoryAdapterPort.PreviewFileData) (*filesRepositoryAdapterPort.PreviewResult, error) {
+	return withTimeout(ctx, t.operationTimeout, func(ctx context.Context) (*filesRepositoryAdapterPort.PreviewResult, error) {
+		return t.next.PreviewFile(ctx, data)
+	})
+}
+
+func (t *timeoutAdapter) StatFile(ctx context.Context, data *filesRepositoryAdapterPort.StatFileData) (*filesRepositoryAdapterPort.FileResult, error) {
+	return withTimeout(ctx, t.operationTimeout, func(ctx context.Context) (*filesRepositoryAdapterPort.FileResult, error) {
+		return t.next.StatFile(ctx, data)
+	})
+}
+
+func (t *timeoutAdapter) PurgeTrash(ctx context.Context, data *filesRepositoryAdapterPort.PurgeTrashData) (*filesRepositoryAdapterPort.PurgeTrashResult, error) {
+	return t.next.PurgeTrash(ctx, data)
+}
+
+func (t *timeoutAdapter) PurgeBackups(ctx context.Context, data *filesRepositoryAdapterPort.PurgeBackupsData) (*filesRepositoryAdapterPort.PurgeBackupsResult, error) {
+	return t.next.PurgeBackups(ctx, data)
+}
+
+func (t *timeoutAdapter) ListToken(ctx context.Context, data *filesRepositoryAdapterPort.ListTokenData) (*filesRepositoryAdapterPort.ListTokenResult, error) {
+	return withTimeout(ctx, t.operationTimeout, func(ctx context.Context) (*filesRepositoryAdapterPort.ListTokenResult, error) {
+		return t.next.ListToken(ctx, data)
+	})
+}
+
+func (t *timeoutAdapter) OpenFile(ctx context.Context, data *filesRepositoryAdapterPort.OpenFileData) (*filesRepositoryAdapterPort.OpenFileResult, error) {
+	return withTimeoutRelease(ctx, t.operationTimeout, func(ctx context.Context) (*filesRepositoryAdapterPort.OpenFileResult, error) {
+		return t.next.OpenFile(ctx, data)
+	}, func(result *filesRepositoryAdapterPort.OpenFileResult) {
+		result.File.Close()
+	})
+}
+
+func (t *timeoutAdapter) AllocateFile(ctx context.Context, data *filesRepositoryAdapterPort.AllocateFileData) error {
+	return t.next.AllocateFile(ctx, data)
+}
+
+func (t *timeoutAdapter) HashFile(ctx context.Context, data *filesRepositoryAdapterPort.HashFileData) (*filesRepositoryAdapterPort.HashFileResult, error) {
+	return withTimeout(ctx, t.operationTimeout, func(ctx context.Context) (*filesRepositoryAdapterPort.HashFileResult, error) {
+		return t.next.HashFile(ctx, data)
+	})
+}
+
+func (t *timeoutAdapter) ListFileVersions(ctx context.Context, data *filesRepositoryAdapterPort.ListFileVersionsData) (*[]filesRepositoryAdapterPort.FileVersionResult, error) {
+	return withTimeout(ctx, t.operationTimeout, func(ctx context.Context) (*[]filesRepositoryAdapterPort.FileVersionResult, error) {
+		return t.next.ListFileVersions(ctx, data)
+	})
+}
+
+func (t *timeoutAdapter) OpenFileVersion(ctx context.Context, data *filesRepositoryAdapterPort.OpenFileVersionData) (*filesRepositoryAdapterPort.OpenFileResult, error) {
+	return withTimeoutRelease(ctx, t.operationTimeout, func(ctx context.Context) (*filesRepositoryAdapterPort.OpenFileResult, error) {
+		return t.next.OpenFileVersion(ctx, data)
+	}, func(result *filesRepositoryAdapterPort.OpenFileResult) {
+		result.File.Close()
+	})
+}
+
+func (t *timeoutAdapter) RestoreFileVersion(ctx context.Context, data *filesRepositoryAdapterPort.RestoreFileVersionData) (*filesRepositoryAdapterPort.RestoreFileVersionResult, error) {
+	return t.next.RestoreFileVersion(ctx, data)
+}
+
+func (t *timeoutAdapter) SplitFile(ctx context.Context, data *filesRepositoryAdapterPort.SplitFileData) (*filesRepositoryAdapterPort.SplitFileResult, error) {
+	return t.next.SplitFile(ctx, data)
+}
+
+func (t *timeoutAdapter) ResolvePath(ctx context.Context, data *filesRepositoryAdapterPort.ResolvePathData) (*filesRepositoryAdapterPort.ResolvePathResult, error) {
+	return withTimeout(ctx, t.operationTimeout, func(ctx context.Context) (*filesRepositoryAdapterPort.ResolvePathResult, error) {
+		return t.next.ResolvePath(ctx, data)
+	})
+}
+
+func (t *timeoutAdapter) CheckPath(ctx context.Context, data *filesRepositoryAdapterPort.CheckPathData) (*filesRepositoryAdapterPort.CheckPathResult, error) {
+	return withTimeout(ctx, t.operationTimeout, func(ctx context.Context) (*filesRepositoryAdapterPort.CheckPathResult, error) {
+		return t.next.CheckPath(ctx, data)
+	})
+}
+
+func (t *timeoutAdapter) ListExpiredFiles(ctx context.Context, data *filesRepositoryAdapterPort.ListExpiredFilesData) (*[]string, error) {
+	return withTimeout(ctx, t.operationTimeout, func(ctx context.Context) (*[]string, error) {
+		return t.next.ListExpiredFiles(ctx, data)
+	})
+}
+
+func (t *timeoutAdapter) DeleteExpiredFile(ctx context.Context, data *filesRepositoryAdapterPort.DeleteExpiredFileData) (*filesRepositoryAdapterPort.DeleteExpiredFileResult, error) {
+	return t.next.DeleteExpiredFile(ctx, data)
+}
+
+func (t *timeoutAdapter) RecentFiles(ctx context.Context, data *filesRepositoryAdapterPort.RecentFilesData) (*[]filesRepositoryAdapterPort.RecentFileResult, error) {
+	return withTimeout(ctx, t.operationTimeout, func(ctx context.Context) (*[]filesRepositoryAdapterPort.RecentFileResult, error) {
+		return t.next.RecentFiles(ctx, data)
+	})
+}
@@ -0,0 +1,132 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	filesRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/files"
+	"github.com/flash-go/files-service/internal/storefs"
+)
+
+/*
+ListToken returns a cheap change token for the directory GetFiles would list
+for the same path, so polling clients can skip unchanged listings.
+
+Path resolution matches GetFiles: an empty path lists listDefaultPath, a file
+path lists its parent directory, and the same traversal and symlink checks
+apply.
+
+The token is the hex FNV-64a hash of the directory's own mtime followed by,
+for every non-hidden entry in name order, its name, type bits, size and
+mtime (see listToken). GetFiles returns the same token with the listing it
+was computed from. Adding, removing, renaming or rewriting a direct entry changes the
+token; changes deeper in the tree do not. Only the directory is read and each
+entry is stat'ed once; contents are never opened.
+*/
+func (a *adapter) ListToken(ctx context.Context, data *filesRepositoryAdapterPort.ListTokenData) (*filesRepositoryAdapterPort.ListTokenResult, error) {
+	requestPath := data.Path
+	if requestPath == "" {
+		requestPath = a.listDefaultPath
+	}
+	cleanPath := filepath.Clean(requestPath)
+
+	if strings.HasPrefix(cleanPath, "..") {
+		return nil, filesRepositoryAdapterPort.ErrPathEscape
+	}
+
+	baseAbs, err := filepath.Abs(a.storeLocalRootPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve base path: %w", err)
+	}
+
+	targetAbs, err := filepath.Abs(filepath.Join(baseAbs, cleanPath))
+	if err != nil {
+		return nil, filesRepositoryAdapterPort.ErrInvalidPath
+	}
+
+	// Ensure target is inside base
+	if rel, _ := filepath.Rel(baseAbs, targetAbs); strings.HasPrefix(rel, "..") {
+		return nil, filesRepositoryAdapterPort.ErrPathEscape
+	}
+
+	// Check parent directories for symlinks, or resolve them inside base
+	readAbs, err := a.listPath(baseAbs, targetAbs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check directory existence
+	info, err := os.Stat(readAbs)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, filesRepositoryAdapterPort.ErrDirNotFound
+		}
+		return nil, err
+	}
+
+	// Use the parent of a file
+	if !info.IsDir() {
+		if !info.Mode().IsRegular() || targetAbs == baseAbs {
+			return nil, filesRepositoryAdapterPort.ErrInvalidPath
+		}
+		if readAbs, err = a.listPath(baseAbs, filepath.Dir(targetAbs)); err != nil {
+			return nil, err
+		}
+		if info, err = os.Stat(readAbs); err != nil {
+			return nil, err
+		}
+	}
+
+	// Read dir
+	entries, err := a.readDir(readAbs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Leave out hidden entries
+	entries = slices.DeleteFunc(entries, func(entry os.DirEntry) bool {
+		return storefs.Hidden(a.hiddenNames, entry.Name())
+	})
+
+	// Hash dir and entry metadata
+	token, err := listToken(ctx, info, entries)
+	if err != nil {
+		return nil, err
+	}
+
+	return &filesRepositoryAdapterPort.ListTokenResult{
+		Token: token,
+	}, nil
+}
+
+// Hash the listed directory's mtime and the metadata of its entries into a
+// change token. Entries are hashed by name order, so the token does not
+// depend on how the directory was read; the caller leaves out hidden ones.
+func listToken(ctx context.Context, dirInfo os.FileInfo, entries []os.DirEntry) (string, error) {
+	sorted := slices.SortedFunc(slices.Values(entries), func(x, y os.DirEntry) int {
+		return strings.Compare(x.Name(), y.Name())
+	})
+	h := fnv.New64a()
+	h.Write(strconv.AppendInt(nil, dirInfo.ModTime().UnixNano(), 10))
+	for _, entry := range sorted {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			// Entry vanished while listing, which is a change in itself
+			if os.IsNotExist(err) {
+				continue
+			}
+			return "", err
+		}
+		fmt.Fprintf(h, "\n%s\x00%d\x00%d\x00%d", entry.Name(), info.Mode().Type(), info.Size(), info.ModTime().UnixNano())
+	}
+	return strconv.FormatUint(h.Sum64(), 16), nil
+}
@@ -0,0 +1,64 @@
+package adapter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	filesRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/files"
+)
+
+func TestGetFilesReturnsListToken(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "docs"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"b.txt", "a.txt", ".cache"} {
+		if err := os.WriteFile(filepath.Join(dir, "docs", name), []byte(name), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a := &adapter{storeLocalRootPath: dir, listMaxEntries: 10, hiddenNames: []string{".cache"}}
+
+	token, err := a.ListToken(context.Background(), &filesRepositoryAdapterPort.ListTokenData{Path: "docs"})
+	if err != nil {
+		t.Fatalf("ListToken: %v", err)
+	}
+
+	// Plain, paged and file listings of the dir return the same token
+	for _, data := range []filesRepositoryAdapterPort.GetFilesData{
+		{Path: "docs"},
+		{Path: "docs", Limit: 1},
+		{Path: "docs/a.txt"},
+	} {
+		result, err := a.GetFiles(context.Background(), &data)
+		if err != nil {
+			t.Fatalf("GetFiles(%+v): %v", data, err)
+		}
+		if result.Token != token.Token {
+			t.Errorf("GetFiles(%+v) token = %q, want %q", data, result.Token, token.Token)
+		}
+	}
+
+	// An unchanged dir is skipped
+	result, err := a.GetFiles(context.Background(), &filesRepositoryAdapterPort.GetFilesData{Path: "docs", Since: token.Token})
+	if err != nil {
+		t.Fatalf("GetFiles since token: %v", err)
+	}
+	if !result.Unchanged || len(result.Files) != 0 {
+		t.Errorf("GetFiles since token = %+v, want unchanged", result)
+	}
+
+	// A new entry changes the token and the listing is returned again
+	if err := os.WriteFile(filepath.Join(dir, "docs", "c.txt"), []byte("c"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	result, err = a.GetFiles(context.Background(), &filesRepositoryAdapterPort.GetFilesData{Path: "docs", Since: token.Token})
+	if err != nil {
+		t.Fatalf("GetFiles after write: %v", err)
+	}
+	if result.Unchanged || result.Token == token.Token || len(result.Files) != 3 {
+		t.Errorf("GetFiles after write = %+v, want a new token and 3 entries", result)
+	}
+}
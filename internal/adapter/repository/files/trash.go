@@ -0,0 +1,82 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+
+	filesRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/files"
+)
+
+// Move a file into the trash as <trash>/<deletion unix nanos>/<relPath>, so
+// the deletion time can be recovered from the path alone. A trash on another
+// filesystem (EXDEV) gets a copy of the file (see copyInto), after which the
+// file is removed.
+func (a *adapter) moveToTrash(ctx context.Context, fileAbs, relPath string, info os.FileInfo) error {
+	trashAbs := filepath.Join(
+		a.storeLocalTrashPath,
+		strconv.FormatInt(time.Now().UnixNano(), 10),
+		relPath,
+	)
+	if err := os.MkdirAll(filepath.Dir(trashAbs), 0700); err != nil {
+		return fmt.Errorf("failed to create trash dir: %w", err)
+	}
+	if err := os.Rename(fileAbs, trashAbs); err != nil {
+		if !errors.Is(err, syscall.EXDEV) {
+			return fmt.Errorf("failed to move file to trash: %w", err)
+		}
+		if err := copyInto(ctx, fileAbs, filepath.Dir(trashAbs), trashAbs, info, false); err != nil {
+			return fmt.Errorf("failed to copy file to trash: %w", err)
+		}
+		return os.Remove(fileAbs)
+	}
+	return nil
+}
+
+/*
+PurgeTrash permanently deletes trashed items deleted before DeletedBefore.
+
+Every top-level trash entry is named after its deletion time in unix
+nanoseconds (see moveToTrash). Entries with other names are left untouched.
+Returns the number of purged entries; purging stops at the first error.
+*/
+func (a *adapter) PurgeTrash(ctx context.Context, data *filesRepositoryAdapterPort.PurgeTrashData) (*filesRepositoryAdapterPort.PurgeTrashResult, error) {
+	result := filesRepositoryAdapterPort.PurgeTrashResult{}
+	if a.storeLocalTrashPath == "" {
+		return &result, nil
+	}
+	purged, err := purgeBefore(a.storeLocalTrashPath, data.DeletedBefore)
+	result.Purged = purged
+	return &result, err
+}
+
+// Remove the top-level entries of root named after a unix nanoseconds time
+// before the given time, returning how many were removed
+func purgeBefore(root string, before time.Time) (int, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	purged := 0
+	for _, entry := range entries {
+		nanos, err := strconv.ParseInt(entry.Name(), 10, 64)
+		if err != nil || !time.Unix(0, nanos).Before(before) {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(root, entry.Name())); err != nil {
+			return purged, err
+		}
+		purged++
+	}
+
+	return purged, nil
+}
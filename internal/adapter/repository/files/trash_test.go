@@ -0,0 +1,53 @@
+package adapter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	filesRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/files"
+)
+
+func TestDeleteFileCopiesToTrashOnOtherFilesystem(t *testing.T) {
+	dir := t.TempDir()
+	trash, err := os.MkdirTemp("/dev/shm", "trash-")
+	if err != nil {
+		t.Skipf("no second filesystem: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(trash) })
+	dirInfo, err := os.Stat(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trashInfo, err := os.Stat(trash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sameDevice(dirInfo, trashInfo) {
+		t.Skip("no second filesystem")
+	}
+	if err := os.Mkdir(filepath.Join(dir, "docs"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "docs", "notes.txt"), []byte("notes"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	a := &adapter{storeLocalRootPath: dir, storeLocalTrashPath: trash}
+
+	if err := a.DeleteFile(context.Background(), &filesRepositoryAdapterPort.DeleteFileData{
+		Path: "docs/notes.txt",
+	}); err != nil {
+		t.Fatalf("DeleteFile: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "docs", "notes.txt")); !os.IsNotExist(err) {
+		t.Errorf("file still present after delete: %v", err)
+	}
+	trashed, err := filepath.Glob(filepath.Join(trash, "*", "docs", "notes.txt"))
+	if err != nil || len(trashed) != 1 {
+		t.Fatalf("trashed files = %q, %v, want one", trashed, err)
+	}
+	if content, err := os.ReadFile(trashed[0]); err != nil || string(content) != "notes" {
+		t.Errorf("trashed content = %q, %v, want %q", content, err, "notes")
+	}
+}
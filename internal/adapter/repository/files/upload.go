@@ -0,0 +1,99 @@
+package adapter
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"io/fs"
+	"mime/multipart"
+	"os"
+
+	filesRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/files"
+)
+
+// Number of leading bytes of an upload used to detect its MIME type
+const sniffSize = 512
+
+// Content of an upload: a buffered form file, or a request stream that can
+// be read only once
+type upload struct {
+	filename string
+	size     int64
+	file     *multipart.FileHeader
+	stream   *bufio.Reader
+}
+
+// Return the upload of data (File, else Stream), or nil if it has none
+func newUpload(data *filesRepositoryAdapterPort.CreateFileData) *upload {
+	switch {
+	case data.File != nil:
+		return &upload{
+			filename: data.File.Filename,
+			size:     data.File.Size,
+			file:     data.File,
+		}
+	case data.Stream != nil && data.Stream.Reader != nil:
+		return &upload{
+			filename: data.Stream.Filename,
+			stream:   bufio.NewReaderSize(data.Stream.Reader, sniffSize),
+		}
+	}
+	return nil
+}
+
+// Return the first sniffSize bytes of the content (fewer if it is shorter)
+// without consuming a stream
+func (u *upload) head() ([]byte, error) {
+	if u.stream != nil {
+		head, err := u.stream.Peek(sniffSize)
+		if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+			return nil, err
+		}
+		return head, nil
+	}
+	src, err := u.file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+	buf := make([]byte, sniffSize)
+	n, _ := io.ReadFull(src, buf)
+	return buf[:n], nil
+}
+
+// Open the content for copying. A stream is returned as is, so it must be
+// opened once.
+func (u *upload) open() (io.ReadCloser, error) {
+	if u.stream != nil {
+		return io.NopCloser(u.stream), nil
+	}
+	return u.file.Open()
+}
+
+// Move a file (the temp file of a finished upload or a moved file) to
+// fileAbs. Unless replace is set, it is hard-linked into place and then
+// removed, so a file created at fileAbs since the existence check is not
+// overwritten (ErrFileExist). Filesystems without hard links fall back to a
+// rename after a last existence check.
+func moveIntoPlace(srcAbs, fileAbs string, replace bool) error {
+	if !replace {
+		err := os.Link(srcAbs, fileAbs)
+		if err == nil {
+			os.Remove(srcAbs)
+			return nil
+		}
+		if os.IsExist(err) {
+			return filesRepositoryAdapterPort.ErrFileExist
+		}
+		if !errors.Is(err, errors.ErrUnsupported) && !errors.Is(err, fs.ErrPermission) {
+			return storageError(err)
+		}
+		if _, err := os.Lstat(fileAbs); err == nil {
+			return filesRepositoryAdapterPort.ErrFileExist
+		}
+	}
+	if err := os.Rename(srcAbs, fileAbs); err != nil {
+		return storageError(err)
+	}
+	return nil
+}
@@ -0,0 +1,332 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	filesRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/files"
+	"github.com/flash-go/files-service/internal/storefs"
+)
+
+// Hard-link a file about to be replaced as <versions>/<relPath>/<unix nanos>,
+// so all versions of a path live in one directory named after it. Returns
+// the absolute and the slash-separated version path relative to the versions
+// root.
+func (a *adapter) versionFile(fileAbs, relPath string) (string, string, error) {
+	rel := filepath.Join(relPath, strconv.FormatInt(time.Now().UnixNano(), 10))
+	versionAbs := filepath.Join(a.storeLocalVersionsPath, rel)
+	if err := os.MkdirAll(filepath.Dir(versionAbs), 0700); err != nil {
+		return "", "", fmt.Errorf("failed to create versions dir: %w", err)
+	}
+	if err := os.Link(fileAbs, versionAbs); err != nil {
+		return "", "", fmt.Errorf("failed to keep version: %w", err)
+	}
+	return versionAbs, filepath.ToSlash(rel), nil
+}
+
+// Remove the oldest versions of a path beyond versionsRetention (if set).
+// Best effort: a version that cannot be removed is left for the next upload.
+func (a *adapter) pruneVersions(relPath string) {
+	if a.versionsRetention <= 0 {
+		return
+	}
+	dirAbs := filepath.Join(a.storeLocalVersionsPath, relPath)
+	versions, err := readVersions(dirAbs)
+	if err != nil {
+		return
+	}
+	for _, version := range versions[min(a.versionsRetention, len(versions)):] {
+		os.Remove(filepath.Join(dirAbs, version.VersionId))
+	}
+}
+
+// Return the versions kept in a versions directory, newest first. Entries
+// other than regular files named after a unix nanoseconds time are ignored.
+func readVersions(dirAbs string) ([]filesRepositoryAdapterPort.FileVersionResult, error) {
+	entries, err := os.ReadDir(dirAbs)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []filesRepositoryAdapterPort.FileVersionResult{}, nil
+		}
+		return nil, err
+	}
+	versions := []filesRepositoryAdapterPort.FileVersionResult{}
+	for _, entry := range entries {
+		nanos, err := strconv.ParseInt(entry.Name(), 10, 64)
+		if err != nil || !entry.Type().IsRegular() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		versions = append(versions, filesRepositoryAdapterPort.FileVersionResult{
+			VersionId: entry.Name(),
+			Size:      info.Size(),
+			CreatedAt: time.Unix(0, nanos),
+		})
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].CreatedAt.After(versions[j].CreatedAt)
+	})
+	return versions, nil
+}
+
+// Resolve the versions directory of a path, rejecting the store root and
+// paths that traverse outside the versions root
+func (a *adapter) versionsDir(path string) (string, error) {
+	if a.storeLocalVersionsPath == "" {
+		return "", filesRepositoryAdapterPort.ErrVersionsDisabled
+	}
+	cleanPath := filepath.Clean(path)
+	if cleanPath == "." || cleanPath == "/" {
+		return "", filesRepositoryAdapterPort.ErrInvalidPath
+	}
+	if strings.HasPrefix(cleanPath, "..") {
+		return "", filesRepositoryAdapterPort.ErrPathEscape
+	}
+	rootAbs, err := filepath.Abs(a.storeLocalVersionsPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve versions path: %w", err)
+	}
+	dirAbs := filepath.Join(rootAbs, cleanPath)
+	if rel, err := filepath.Rel(rootAbs, dirAbs); err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", filesRepositoryAdapterPort.ErrPathEscape
+	}
+	return dirAbs, nil
+}
+
+/*
+ListFileVersions returns the versions kept for a file path, newest first.
+
+Versions are the previous contents of the file, kept by uploads with Backup
+set while storeLocalVersionsPath is configured (ErrVersionsDisabled
+otherwise), at most versionsRetention per path. The path does not need to
+exist in the store anymore; a path without versions yields an empty list.
+*/
+func (a *adapter) ListFileVersions(ctx context.Context, data *filesRepositoryAdapterPort.ListFileVersionsData) (*[]filesRepositoryAdapterPort.FileVersionResult, error) {
+	dirAbs, err := a.versionsDir(data.Path)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := readVersions(dirAbs)
+	if err != nil {
+		return nil, err
+	}
+	return &versions, nil
+}
+
+/*
+OpenFileVersion opens a version of a file path (see ListFileVersions) for
+reading. The caller owns the returned file and must close it.
+
+Unknown or malformed version ids yield ErrVersionNotFound. MimeType is taken
+from the extension of the path, falling back to sniffing the first 512 bytes.
+*/
+func (a *adapter) OpenFileVersion(ctx context.Context, data *filesRepositoryAdapterPort.OpenFileVersionData) (*filesRepositoryAdapterPort.OpenFileResult, error) {
+	dirAbs, err := a.versionsDir(data.Path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := strconv.ParseUint(data.VersionId, 10, 63); err != nil {
+		return nil, filesRepositoryAdapterPort.ErrVersionNotFound
+	}
+	versionAbs := filepath.Join(dirAbs, data.VersionId)
+
+	// Stat version
+	info, err := os.Lstat(versionAbs)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, filesRepositoryAdapterPort.ErrVersionNotFound
+		}
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, filesRepositoryAdapterPort.ErrVersionNotFound
+	}
+
+	// Open version
+	f, err := os.Open(versionAbs)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, filesRepositoryAdapterPort.ErrVersionNotFound
+		}
+		return nil, err
+	}
+
+	// Detect MIME type
+	mimeType := mime.TypeByExtension(filepath.Ext(dirAbs))
+	if mimeType == "" {
+		head := make([]byte, 512)
+		n, _ := io.ReadFull(f, head)
+		mimeType = http.DetectContentType(head[:n])
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+
+	return &filesRepositoryAdapterPort.OpenFileResult{
+		File:     f,
+		Name:     filepath.Base(dirAbs),
+		Size:     info.Size(),
+		MimeType: mimeType,
+		ModTime:  info.ModTime(),
+	}, nil
+}
+
+/*
+RestoreFileVersion makes a version of a file path (see ListFileVersions) the
+current file again.
+
+ 1. Validates the path and version id as OpenFileVersion does, and resolves
+    the file inside the adapter's base path, rejecting traversal and
+    symlinked parent directories (ErrDirNotFound if its directory is gone).
+ 2. If the file exists (it must be a regular file, ErrInvalidPath otherwise)
+    it is kept as a new version first, so nothing is lost. A missing file is
+    recreated, subject to dirMaxEntries.
+ 3. Copies the version to a temp file and renames it into place, the same
+    way uploads are written. The version is copied rather than linked so
+    later writes to the file cannot alter the history. On failure the
+    archived version is removed again and the file is left untouched.
+ 4. Drops versions beyond versionsRetention, which may include the restored
+    version itself.
+
+The result holds the slash-separated path, the restored size and the id of
+the archived version (nil if the file did not exist).
+*/
+func (a *adapter) RestoreFileVersion(ctx context.Context, data *filesRepositoryAdapterPort.RestoreFileVersionData) (*filesRepositoryAdapterPort.RestoreFileVersionResult, error) {
+	dirAbs, err := a.versionsDir(data.Path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := strconv.ParseUint(data.VersionId, 10, 63); err != nil {
+		return nil, filesRepositoryAdapterPort.ErrVersionNotFound
+	}
+
+	// Open version
+	src, err := os.Open(filepath.Join(dirAbs, data.VersionId))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, filesRepositoryAdapterPort.ErrVersionNotFound
+		}
+		return nil, err
+	}
+	defer src.Close()
+	if info, err := src.Stat(); err != nil {
+		return nil, err
+	} else if !info.Mode().IsRegular() {
+		return nil, filesRepositoryAdapterPort.ErrVersionNotFound
+	}
+
+	// Resolve the file path
+	baseAbs, err := filepath.Abs(a.storeLocalRootPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve base path: %w", err)
+	}
+	fileAbs := filepath.Join(baseAbs, filepath.Clean(data.Path))
+	relPath, err := filepath.Rel(baseAbs, fileAbs)
+	if err != nil || relPath == "." || strings.HasPrefix(relPath, "..") {
+		return nil, filesRepositoryAdapterPort.ErrPathEscape
+	}
+	targetDirAbs := filepath.Dir(fileAbs)
+
+	// Check parent directories for symlinks (symlink race prevention)
+	if err := checkParents(baseAbs, targetDirAbs); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, filesRepositoryAdapterPort.ErrDirNotFound
+		}
+		return nil, err
+	}
+	if info, err := os.Stat(targetDirAbs); err != nil {
+		if os.IsNotExist(err) {
+			return nil, filesRepositoryAdapterPort.ErrDirNotFound
+		}
+		return nil, err
+	} else if !info.IsDir() {
+		return nil, filesRepositoryAdapterPort.ErrInvalidPath
+	}
+
+	// Check the current file
+	existing, err := os.Lstat(fileAbs)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	exists := err == nil
+	if exists && !existing.Mode().IsRegular() {
+		return nil, filesRepositoryAdapterPort.ErrInvalidPath
+	}
+	if !exists {
+		if full, err := storefs.DirFull(targetDirAbs, a.dirMaxEntries); err != nil {
+			return nil, fmt.Errorf("failed to count entries: %w", err)
+		} else if full {
+			return nil, filesRepositoryAdapterPort.ErrDirFull
+		}
+	}
+
+	// Create temp file
+	dst, err := os.CreateTemp(a.tempDir(targetDirAbs), "."+filepath.Base(fileAbs)+".tmp-*")
+	if err != nil {
+		return nil, storageError(err)
+	}
+
+	// Remove the temp file unless it was moved into place
+	committed := false
+	defer func() {
+		dst.Close()
+		if !committed {
+			os.Remove(dst.Name())
+		}
+	}()
+
+	// Copy version content
+	written, err := io.Copy(dst, contextReader{ctx, src})
+	if err != nil {
+		return nil, storageError(err)
+	}
+	if err := dst.Close(); err != nil {
+		return nil, storageError(err)
+	}
+
+	// Keep the current file as a version
+	result := filesRepositoryAdapterPort.RestoreFileVersionResult{
+		Path: filepath.ToSlash(relPath),
+		Size: written,
+	}
+	if exists {
+		archivedAbs, _, err := a.versionFile(fileAbs, relPath)
+		if err != nil {
+			return nil, err
+		}
+
+		// Remove the archived version unless the file was moved into place
+		defer func() {
+			if !committed {
+				os.Remove(archivedAbs)
+			}
+		}()
+		archivedId := filepath.Base(archivedAbs)
+		result.ArchivedVersionId = &archivedId
+	}
+
+	// Move temp file into place
+	if err := os.Rename(dst.Name(), fileAbs); err != nil {
+		return nil, storageError(err)
+	}
+	committed = true
+
+	// Drop versions beyond the retention count
+	a.pruneVersions(relPath)
+	return &result, nil
+}
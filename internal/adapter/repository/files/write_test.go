@@ -0,0 +1,98 @@
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"testing"
+
+	filesRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/files"
+)
+
+// Return a multipart file header holding content, as a parsed upload would
+func multipartFile(t *testing.T, name string, content []byte) *multipart.FileHeader {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(content)
+	writer.Close()
+	form, err := multipart.NewReader(&body, writer.Boundary()).ReadForm(1 << 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { form.RemoveAll() })
+	return form.File["file"][0]
+}
+
+func TestWriteFileAtBounds(t *testing.T) {
+	tests := []struct {
+		name        string
+		fileMaxSize int64
+		offset      int64
+		want        error
+		content     string
+	}{
+		{"patch head", 0, 0, nil, "xyzdef"},
+		{"append at end", 0, 6, nil, "abcdefxyz"},
+		{"past end without limit", 0, 7, filesRepositoryAdapterPort.ErrInvalidRange, "abcdef"},
+		{"huge offset without limit", 0, math.MaxInt64 - 1, filesRepositoryAdapterPort.ErrInvalidRange, "abcdef"},
+		{"hole within limit", 16, 8, nil, "abcdef\x00\x00xyz"},
+		{"end over limit", 16, 14, filesRepositoryAdapterPort.ErrFileTooLarge, "abcdef"},
+		{"offset overflowing with size", 16, math.MaxInt64 - 1, filesRepositoryAdapterPort.ErrFileTooLarge, "abcdef"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			target := filepath.Join(dir, "file.bin")
+			if err := os.WriteFile(target, []byte("abcdef"), 0600); err != nil {
+				t.Fatal(err)
+			}
+			a := &adapter{storeLocalRootPath: dir, fileMaxSize: tt.fileMaxSize}
+			err := a.WriteFileAt(context.Background(), &filesRepositoryAdapterPort.WriteFileAtData{
+				Path:   "file.bin",
+				Offset: tt.offset,
+				File:   multipartFile(t, "patch", []byte("xyz")),
+			})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("WriteFileAt = %v, want %v", err, tt.want)
+			}
+			content, err := os.ReadFile(target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(content) != tt.content {
+				t.Errorf("content = %q, want %q", content, tt.content)
+			}
+		})
+	}
+}
+
+func TestWriteFileAtRejectsSymlink(t *testing.T) {
+	dir := t.TempDir()
+	outside := filepath.Join(t.TempDir(), "outside.txt")
+	if err := os.WriteFile(outside, []byte("abcdef"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(dir, "link.txt")); err != nil {
+		t.Fatal(err)
+	}
+	a := &adapter{storeLocalRootPath: dir}
+	err := a.WriteFileAt(context.Background(), &filesRepositoryAdapterPort.WriteFileAtData{
+		Path: "link.txt",
+		File: multipartFile(t, "patch", []byte("xyz")),
+	})
+	if err == nil {
+		t.Fatal("WriteFileAt through a symlink succeeded")
+	}
+	if content, _ := os.ReadFile(outside); string(content) != "abcdef" {
+		t.Errorf("symlink target changed to %q", content)
+	}
+}
@@ -0,0 +1,36 @@
+package adapter
+
+import (
+	"context"
+	"os"
+
+	storeRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/store"
+)
+
+type Config struct {
+	StoreLocalRootPath string
+}
+
+func New(config *Config) storeRepositoryAdapterPort.Interface {
+	return &adapter{
+		storeLocalRootPath: config.StoreLocalRootPath,
+	}
+}
+
+type adapter struct {
+	storeLocalRootPath string
+}
+
+// GetStatus reports the basic status of the local store root. The root is
+// available when it exists and is a directory, and read-only when its mount
+// currently is (e.g. after the kernel remounted it on an I/O error). Only a
+// stat and a statfs are made, so the check stays cheap enough for
+// unauthenticated probes.
+func (a *adapter) GetStatus(ctx context.Context) (*storeRepositoryAdapterPort.StatusResult, error) {
+	info, err := os.Stat(a.storeLocalRootPath)
+	available := err == nil && info.IsDir()
+	return &storeRepositoryAdapterPort.StatusResult{
+		Available: available,
+		ReadOnly:  available && readOnly(a.storeLocalRootPath),
+	}, nil
+}
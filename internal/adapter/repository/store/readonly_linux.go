@@ -0,0 +1,19 @@
+//go:build linux
+
+package adapter
+
+import (
+	"syscall"
+)
+
+// Mount flag set on read-only mounts (statvfs ST_RDONLY)
+const stReadOnly = 0x1
+
+// Report whether the filesystem holding the path is mounted read-only
+func readOnly(path string) bool {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return false
+	}
+	return stat.Flags&stReadOnly != 0
+}
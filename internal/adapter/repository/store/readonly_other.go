@@ -0,0 +1,8 @@
+//go:build !linux
+
+package adapter
+
+// Mount flags are only inspected on linux
+func readOnly(path string) bool {
+	return false
+}
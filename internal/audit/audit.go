@@ -0,0 +1,180 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flash-go/files-service/internal/httpctx"
+	"github.com/flash-go/files-service/internal/namespace"
+	"github.com/flash-go/flash/http/server"
+	"github.com/flash-go/flash/logger"
+)
+
+// Error codes of requests rejected for their paths (traversal outside the
+// store root, symlinked components, control characters)
+var rejectedCodes = map[string]bool{
+	"invalid_path":       true,
+	"invalid_old_path":   true,
+	"invalid_new_path":   true,
+	"invalid_characters": true,
+	"path_escape":        true,
+}
+
+const (
+	// Maximum number of rejections logged per actor and window
+	maxLogsPerWindow = 10
+	// Length of a rate limiting window
+	logWindow = time.Minute
+	// Maximum number of actors counted separately in a window; the rest
+	// share one count
+	maxTrackedActors = 10000
+	// Maximum number of bytes logged per input path
+	maxLoggedPathLen = 256
+)
+
+/*
+Middleware logs requests rejected for their paths (see rejectedCodes) at
+warn level, so traversal and symlink probing shows up in the logs:
+
+| Field     | Value                                                  |
+|-----------|--------------------------------------------------------|
+| user      | Authenticated user ("user" user value, "-" if missing) |
+| method    | Request method                                         |
+| path      | Request path                                           |
+| code      | Error code of the response                             |
+| namespace | X-Store-Namespace header, if set                       |
+| inputs    | Raw input paths (see inputPaths)                       |
+
+Only request input and error codes are logged, never resolved server paths.
+At most maxLogsPerWindow rejections are logged per user and logWindow; the
+number of suppressed ones is logged with the first rejection of a later
+window. Routes must add it after the users middleware.
+*/
+func Middleware(log logger.Logger) func(handler server.ReqHandler) server.ReqHandler {
+	l := &limiter{counts: make(map[string]int)}
+	return func(handler server.ReqHandler) server.ReqHandler {
+		return func(ctx server.ReqCtx) {
+			handler(ctx)
+
+			code := errorCode(ctx)
+			if !rejectedCodes[code] {
+				return
+			}
+			user := "-"
+			if v := ctx.UserValue("user"); v != nil {
+				user = fmt.Sprint(v)
+			}
+			allowed, suppressed := l.allow(user, time.Now())
+			if suppressed > 0 {
+				log.Log().Warn().
+					Int("suppressed", suppressed).
+					Msg("path rejection logs suppressed")
+			}
+			if !allowed {
+				return
+			}
+			event := log.Log().Warn().
+				Str("user", user).
+				Str("method", string(ctx.Request().Header.Method())).
+				Str("path", string(ctx.Request().URI().Path())).
+				Str("code", code)
+			if name := ctx.GetHeader(namespace.Header); name != "" {
+				event = event.Str("namespace", name)
+			}
+			event.Strs("inputs", inputPaths(ctx)).Msg("rejected request path")
+		}
+	}
+}
+
+// Return the error code of the response written by the handler ("" if it
+// did not fail with an ErrorResponse body)
+func errorCode(ctx server.ReqCtx) string {
+	response := ctx.Response()
+	if response.StatusCode() < 400 {
+		return ""
+	}
+	var body httpctx.ErrorResponse
+	if err := json.Unmarshal(response.Body(), &body); err != nil {
+		return ""
+	}
+	return body.Code
+}
+
+// Collect the raw paths given in a request: the "path" query argument, the
+// string fields named "path" or "*_path" and the strings of a "paths" array
+// in the JSON body (or multipart "meta") and the uploaded filename, each cut
+// to maxLoggedPathLen bytes
+func inputPaths(ctx server.ReqCtx) []string {
+	inputs := []string{}
+	if path := ctx.Request().URI().QueryArgs().Peek("path"); path != nil {
+		inputs = append(inputs, truncate(string(path)))
+	}
+	body := ctx.Body()
+	if meta := ctx.FormValue("meta"); len(meta) > 0 {
+		body = meta
+		if file, err := ctx.FormFile("file"); err == nil {
+			inputs = append(inputs, truncate(file.Filename))
+		}
+	}
+	var fields map[string]any
+	if json.Unmarshal(body, &fields) == nil {
+		for key, value := range fields {
+			if s, ok := value.(string); ok && (key == "path" || strings.HasSuffix(key, "_path")) {
+				inputs = append(inputs, truncate(s))
+			}
+			if values, ok := value.([]any); ok && key == "paths" {
+				for _, value := range values {
+					if s, ok := value.(string); ok {
+						inputs = append(inputs, truncate(s))
+					}
+				}
+			}
+		}
+	}
+	return inputs
+}
+
+// Cut s to at most maxLoggedPathLen bytes
+func truncate(s string) string {
+	if len(s) > maxLoggedPathLen {
+		return s[:maxLoggedPathLen]
+	}
+	return s
+}
+
+// Per-user count of logged rejections in the current window
+type limiter struct {
+	mu          sync.Mutex
+	windowStart time.Time
+	counts      map[string]int
+	suppressed  int
+}
+
+// Count a rejection by user at now, reporting whether to log it and the
+// number of rejections suppressed in the window that just ended (0 if the
+// window did not end)
+func (l *limiter) allow(user string, now time.Time) (bool, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	suppressed := 0
+	if now.Sub(l.windowStart) >= logWindow {
+		suppressed = l.suppressed
+		l.windowStart = now
+		l.suppressed = 0
+		clear(l.counts)
+	}
+
+	if _, ok := l.counts[user]; !ok && len(l.counts) >= maxTrackedActors {
+		user = ""
+	}
+	if l.counts[user] >= maxLogsPerWindow {
+		l.suppressed++
+		return false, suppressed
+	}
+	l.counts[user]++
+	return true, suppressed
+}
@@ -0,0 +1,29 @@
+// Package clientpath normalizes and checks the store paths clients send.
+package clientpath
+
+import (
+	"path"
+	"strings"
+	"unicode"
+)
+
+// Return the canonical form of a client path: relative, slash-separated,
+// without "." segments, leading "./" or trailing slashes ("" for the root),
+// so "/uploads/", "uploads" and "./uploads" all become "uploads". Parent
+// segments are kept for the repositories to reject.
+func Canonical(p string) string {
+	if p == "" {
+		return ""
+	}
+	p = strings.TrimLeft(path.Clean(p), "/")
+	if p == "." {
+		return ""
+	}
+	return p
+}
+
+// Report whether a client path contains NUL or other control characters,
+// which the filesystem would truncate at or store verbatim in a name
+func HasControlChars(p string) bool {
+	return strings.IndexFunc(p, unicode.IsControl) >= 0
+}
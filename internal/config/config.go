@@ -1,7 +1,62 @@
 package config
 
 const (
-	UsersServiceNameOptKey   = "/users/serviceName"
-	UsersAdminRoleOptKey     = "/users/adminRole"
-	StoreLocalRootPathOptKey = "/store/local/rootPath"
+	UsersServiceNameOptKey       = "/users/serviceName"
+	UsersAdminRoleOptKey         = "/users/adminRole"
+	StoreLocalRootPathOptKey     = "/store/local/rootPath"
+	StoreLocalTempPathOptKey     = "/store/local/tempPath"
+	StoreLocalTrashPathOptKey    = "/store/local/trashPath"
+	StoreDirMaxEntriesOptKey     = "/store/dirMaxEntries"
+	StoreFileMaxSizeOptKey       = "/store/fileMaxSize"
+	StoreListMaxEntriesOptKey    = "/store/listMaxEntries"
+	StoreListInlineMaxSizeOptKey = "/store/listInlineMaxSize"
+	UploadIdempotencyTtlOptKey   = "/upload/idempotencyTtl"
+	PreviewMaxBytesOptKey        = "/preview/maxBytes"
+	StoreRequireExtensionOptKey  = "/store/requireExtension"
+	StoreFollowSymlinksOptKey    = "/store/followSymlinks"
+	StoreOperationTimeoutOptKey  = "/store/operationTimeout"
+	StoreCaseInsensitiveOptKey   = "/store/caseInsensitive"
+	TrashTtlOptKey               = "/trash/ttl"
+	TrashSweepIntervalOptKey     = "/trash/sweepInterval"
+	StoreListDefaultPathOptKey   = "/store/listDefaultPath"
+	StoreHiddenNamesOptKey       = "/store/hiddenNames"
+	HttpCanonicalPathsOptKey     = "/http/canonicalPaths"
+	DownloadIndexFileOptKey      = "/download/indexFile"
+	StoreNamespacesOptKey        = "/store/namespaces"
+	DownloadDirListingOptKey     = "/download/dirListing"
+	RenameSamePathNoopOptKey     = "/rename/samePathNoop"
+	UploadMaxDurationOptKey      = "/upload/maxDuration"
+	StoreMimeDetectionOptKey     = "/store/mimeDetection"
+	StoreLocalBackupPathOptKey   = "/store/local/backupPath"
+	BackupTtlOptKey              = "/backup/ttl"
+	DownloadConcatMaxSizeOptKey  = "/download/concatMaxSize"
+	StoreMimeRoutesOptKey        = "/store/mimeRoutes"
+	StoreTopDirsOptKey           = "/store/topDirs"
+	DownloadCacheControlOptKey   = "/download/cacheControl"
+	DeleteBatchThresholdOptKey   = "/delete/batchThreshold"
+	StoreCheckFreeSpaceOptKey    = "/store/checkFreeSpace"
+	StoreFreeSpaceMarginOptKey   = "/store/freeSpaceMargin"
+	StoreLocalVersionsPathOptKey = "/store/local/versionsPath"
+	VersionsRetentionOptKey      = "/versions/retention"
+	StoreListOrderOptKey         = "/store/listOrder"
+	DownloadMaxConcurrentOptKey  = "/download/maxConcurrent"
+	ImportAllowedHostsOptKey     = "/import/allowedHosts"
+	ImportDeniedHostsOptKey      = "/import/deniedHosts"
+	ImportMaxSizeOptKey          = "/import/maxSize"
+	ImportMimeTypesOptKey        = "/import/mimeTypes"
+	StoreLocalExpiryPathOptKey   = "/store/local/expiryPath"
+	ExpirySweepIntervalOptKey    = "/expiry/sweepInterval"
+	DownloadImageMaxPixelsOptKey = "/download/imageMaxPixels"
+	DownloadImageCacheSizeOptKey = "/download/imageCacheSize"
+	UploadStreamThresholdOptKey  = "/upload/streamThreshold"
+	UploadDedupOptKey            = "/upload/dedup"
+	DirSizeRefreshIntervalOptKey = "/dirSize/refreshInterval"
+	UploadSessionSecretOptKey    = "/upload/sessionSecret"
+	UploadSessionTokenTtlOptKey  = "/upload/sessionTokenTtl"
+	UploadFixOrientationOptKey   = "/upload/fixOrientation"
+	UploadStripExifOptKey        = "/upload/stripExif"
+	DownloadAutoIndexOptKey      = "/download/autoIndex"
+	DownloadAutoIndexLimitOptKey = "/download/autoIndexLimit"
+	StoreRetryAttemptsOptKey     = "/store/retryAttempts"
+	StoreRetryBackoffOptKey      = "/store/retryBackoff"
 )
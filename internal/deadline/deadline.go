@@ -0,0 +1,51 @@
+// Package deadline bounds repository calls that may block on a hung store.
+package deadline
+
+import (
+	"context"
+	"time"
+)
+
+type callResult[T any] struct {
+	value T
+	err   error
+}
+
+// Run fn with a context bounded by timeout, returning errTimeout if it does
+// not finish in time and the parent context error if that is done. fn runs
+// in its own goroutine, which is abandoned (left to finish in the
+// background) on timeout.
+func Call[T any](ctx context.Context, timeout time.Duration, errTimeout error, fn func(ctx context.Context) (T, error)) (T, error) {
+	return CallRelease(ctx, timeout, errTimeout, fn, nil)
+}
+
+// Like Call, but passes the value of an abandoned call that later succeeds
+// to release (if set), so acquired resources are not leaked
+func CallRelease[T any](ctx context.Context, timeout time.Duration, errTimeout error, fn func(ctx context.Context) (T, error), release func(T)) (T, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan callResult[T], 1)
+	go func() {
+		value, err := fn(ctx)
+		done <- callResult[T]{value, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-ctx.Done():
+		if release != nil {
+			go func() {
+				if r := <-done; r.err == nil {
+					release(r.value)
+				}
+			}()
+		}
+		var zero T
+		if err := context.Cause(ctx); err != context.DeadlineExceeded {
+			return zero, err
+		}
+		return zero, errTimeout
+	}
+}
@@ -5,7 +5,14 @@ import (
 )
 
 var (
-	ErrDirInvalidPath    = errors.New(errors.ErrBadRequest, "invalid_path")
-	ErrDirInvalidOldPath = errors.New(errors.ErrBadRequest, "invalid_old_path")
-	ErrDirInvalidNewPath = errors.New(errors.ErrBadRequest, "invalid_new_path")
+	ErrDirInvalidPath       = errors.New(errors.ErrBadRequest, "invalid_path")
+	ErrDirInvalidOldPath    = errors.New(errors.ErrBadRequest, "invalid_old_path")
+	ErrDirInvalidNewPath    = errors.New(errors.ErrBadRequest, "invalid_new_path")
+	ErrDirInvalidDepth      = errors.New(errors.ErrBadRequest, "invalid_depth")
+	ErrDirInvalidAge        = errors.New(errors.ErrBadRequest, "invalid_age")
+	ErrDirInvalidName       = errors.New(errors.ErrBadRequest, "invalid_name")
+	ErrDirInvalidTree       = errors.New(errors.ErrBadRequest, "invalid_tree")
+	ErrDirLayoutTooLarge    = errors.New(errors.ErrBadRequest, "layout_too_large")
+	ErrDirInvalidCharacters = errors.New(errors.ErrBadRequest, "invalid_characters")
+	ErrDirInvalidPolicy     = errors.New(errors.ErrBadRequest, "invalid_policy")
 )
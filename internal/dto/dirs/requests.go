@@ -1,7 +1,20 @@
 package dto
 
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/flash-go/files-service/internal/clientpath"
+)
+
 type AdminCreateDirRequest struct {
-	Path string `json:"path"`
+	Path        string `json:"path"`
+	GetOrCreate bool   `json:"get_or_create"`
+}
+
+func (r *AdminCreateDirRequest) Canonicalize() {
+	r.Path = clientpath.Canonical(r.Path)
 }
 
 func (r *AdminCreateDirRequest) Validate() error {
@@ -15,6 +28,9 @@ func (r *AdminCreateDirRequest) ValidatePath() error {
 	if r.Path == "" {
 		return ErrDirInvalidPath
 	}
+	if clientpath.HasControlChars(r.Path) {
+		return ErrDirInvalidCharacters
+	}
 	return nil
 }
 
@@ -22,6 +38,10 @@ type AdminDeleteDirRequest struct {
 	Path string `json:"path"`
 }
 
+func (r *AdminDeleteDirRequest) Canonicalize() {
+	r.Path = clientpath.Canonical(r.Path)
+}
+
 func (r *AdminDeleteDirRequest) Validate() error {
 	if err := r.ValidatePath(); err != nil {
 		return err
@@ -33,6 +53,9 @@ func (r *AdminDeleteDirRequest) ValidatePath() error {
 	if r.Path == "" {
 		return ErrDirInvalidPath
 	}
+	if clientpath.HasControlChars(r.Path) {
+		return ErrDirInvalidCharacters
+	}
 	return nil
 }
 
@@ -41,6 +64,11 @@ type AdminRenameDirRequest struct {
 	NewPath string `json:"new_path"`
 }
 
+func (r *AdminRenameDirRequest) Canonicalize() {
+	r.OldPath = clientpath.Canonical(r.OldPath)
+	r.NewPath = clientpath.Canonical(r.NewPath)
+}
+
 func (r *AdminRenameDirRequest) Validate() error {
 	if err := r.ValidateOldPath(); err != nil {
 		return err
@@ -55,6 +83,9 @@ func (r *AdminRenameDirRequest) ValidateOldPath() error {
 	if r.OldPath == "" {
 		return ErrDirInvalidOldPath
 	}
+	if clientpath.HasControlChars(r.OldPath) {
+		return ErrDirInvalidCharacters
+	}
 	return nil
 }
 
@@ -62,5 +93,316 @@ func (r *AdminRenameDirRequest) ValidateNewPath() error {
 	if r.NewPath == "" {
 		return ErrDirInvalidNewPath
 	}
+	if clientpath.HasControlChars(r.NewPath) {
+		return ErrDirInvalidCharacters
+	}
+	return nil
+}
+
+type AdminSnapshotDirRequest struct {
+	OldPath string `json:"old_path"`
+	NewPath string `json:"new_path"`
+}
+
+func (r *AdminSnapshotDirRequest) Canonicalize() {
+	r.OldPath = clientpath.Canonical(r.OldPath)
+	r.NewPath = clientpath.Canonical(r.NewPath)
+}
+
+func (r *AdminSnapshotDirRequest) Validate() error {
+	if err := r.ValidateOldPath(); err != nil {
+		return err
+	}
+	if err := r.ValidateNewPath(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *AdminSnapshotDirRequest) ValidateOldPath() error {
+	if r.OldPath == "" {
+		return ErrDirInvalidOldPath
+	}
+	if clientpath.HasControlChars(r.OldPath) {
+		return ErrDirInvalidCharacters
+	}
+	return nil
+}
+
+func (r *AdminSnapshotDirRequest) ValidateNewPath() error {
+	if r.NewPath == "" {
+		return ErrDirInvalidNewPath
+	}
+	if clientpath.HasControlChars(r.NewPath) {
+		return ErrDirInvalidCharacters
+	}
+	return nil
+}
+
+type AdminPruneDirsRequest struct {
+	Path         string `json:"path"`
+	OlderThan    int64  `json:"older_than"`
+	WithContents bool   `json:"with_contents"`
+	DryRun       bool   `json:"dry_run"`
+}
+
+func (r *AdminPruneDirsRequest) Canonicalize() {
+	r.Path = clientpath.Canonical(r.Path)
+}
+
+func (r *AdminPruneDirsRequest) Validate() error {
+	if err := r.ValidatePath(); err != nil {
+		return err
+	}
+	if err := r.ValidateOlderThan(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *AdminPruneDirsRequest) ValidatePath() error {
+	if clientpath.HasControlChars(r.Path) {
+		return ErrDirInvalidCharacters
+	}
+	return nil
+}
+
+func (r *AdminPruneDirsRequest) ValidateOlderThan() error {
+	if r.OlderThan <= 0 || r.OlderThan > math.MaxInt64/int64(time.Second) {
+		return ErrDirInvalidAge
+	}
+	return nil
+}
+
+type AdminDigestDirRequest struct {
+	Path string `json:"path"`
+}
+
+func (r *AdminDigestDirRequest) Canonicalize() {
+	r.Path = clientpath.Canonical(r.Path)
+}
+
+func (r *AdminDigestDirRequest) Validate() error {
+	if err := r.ValidatePath(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *AdminDigestDirRequest) ValidatePath() error {
+	if clientpath.HasControlChars(r.Path) {
+		return ErrDirInvalidCharacters
+	}
+	return nil
+}
+
+type AdminFlattenDirRequest struct {
+	Path        string `json:"path"`
+	TargetPath  string `json:"target_path"`
+	OnConflict  string `json:"on_conflict"`
+	PrefixNames bool   `json:"prefix_names"`
+}
+
+func (r *AdminFlattenDirRequest) Canonicalize() {
+	r.Path = clientpath.Canonical(r.Path)
+	r.TargetPath = clientpath.Canonical(r.TargetPath)
+}
+
+func (r *AdminFlattenDirRequest) Validate() error {
+	if err := r.ValidatePath(); err != nil {
+		return err
+	}
+	if err := r.ValidateTargetPath(); err != nil {
+		return err
+	}
+	if err := r.ValidateOnConflict(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *AdminFlattenDirRequest) ValidatePath() error {
+	if clientpath.HasControlChars(r.Path) {
+		return ErrDirInvalidCharacters
+	}
+	return nil
+}
+
+func (r *AdminFlattenDirRequest) ValidateTargetPath() error {
+	if clientpath.HasControlChars(r.TargetPath) {
+		return ErrDirInvalidCharacters
+	}
+	return nil
+}
+
+func (r *AdminFlattenDirRequest) ValidateOnConflict() error {
+	switch r.OnConflict {
+	case "", "rename", "skip", "overwrite":
+		return nil
+	}
+	return ErrDirInvalidPolicy
+}
+
+type AdminEmptyDirRequest struct {
+	Path       string `json:"path"`
+	BestEffort bool   `json:"best_effort"`
+}
+
+func (r *AdminEmptyDirRequest) Canonicalize() {
+	r.Path = clientpath.Canonical(r.Path)
+}
+
+func (r *AdminEmptyDirRequest) Validate() error {
+	if err := r.ValidatePath(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *AdminEmptyDirRequest) ValidatePath() error {
+	if r.Path == "" {
+		return ErrDirInvalidPath
+	}
+	if clientpath.HasControlChars(r.Path) {
+		return ErrDirInvalidCharacters
+	}
+	return nil
+}
+
+type AdminDirSizeRequest struct {
+	Path string `json:"path"`
+}
+
+func (r *AdminDirSizeRequest) Canonicalize() {
+	r.Path = clientpath.Canonical(r.Path)
+}
+
+func (r *AdminDirSizeRequest) Validate() error {
+	if err := r.ValidatePath(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *AdminDirSizeRequest) ValidatePath() error {
+	if clientpath.HasControlChars(r.Path) {
+		return ErrDirInvalidCharacters
+	}
+	return nil
+}
+
+type AdminRefreshDirSizesRequest struct {
+	Path string `json:"path"`
+}
+
+func (r *AdminRefreshDirSizesRequest) Canonicalize() {
+	r.Path = clientpath.Canonical(r.Path)
+}
+
+func (r *AdminRefreshDirSizesRequest) Validate() error {
+	if err := r.ValidatePath(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *AdminRefreshDirSizesRequest) ValidatePath() error {
+	if clientpath.HasControlChars(r.Path) {
+		return ErrDirInvalidCharacters
+	}
+	return nil
+}
+
+type AdminDirTreeRequest struct {
+	Path      string `json:"path"`
+	Depth     int    `json:"depth"`
+	WithFiles bool   `json:"with_files"`
+}
+
+func (r *AdminDirTreeRequest) Canonicalize() {
+	r.Path = clientpath.Canonical(r.Path)
+}
+
+func (r *AdminDirTreeRequest) Validate() error {
+	if err := r.ValidatePath(); err != nil {
+		return err
+	}
+	if err := r.ValidateDepth(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *AdminDirTreeRequest) ValidatePath() error {
+	if clientpath.HasControlChars(r.Path) {
+		return ErrDirInvalidCharacters
+	}
+	return nil
+}
+
+func (r *AdminDirTreeRequest) ValidateDepth() error {
+	if r.Depth < 0 {
+		return ErrDirInvalidDepth
+	}
+	return nil
+}
+
+// Maximum number of directories in a layout request
+const dirLayoutMaxNodes = 1000
+
+type AdminEnsureDirLayoutRequest struct {
+	Path string                 `json:"path"`
+	Tree []DirLayoutNodeRequest `json:"tree"`
+}
+
+func (r *AdminEnsureDirLayoutRequest) Canonicalize() {
+	r.Path = clientpath.Canonical(r.Path)
+}
+
+type DirLayoutNodeRequest struct {
+	Name     string                 `json:"name"`
+	Children []DirLayoutNodeRequest `json:"children"`
+}
+
+func (r *AdminEnsureDirLayoutRequest) Validate() error {
+	if err := r.ValidatePath(); err != nil {
+		return err
+	}
+	if err := r.ValidateTree(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *AdminEnsureDirLayoutRequest) ValidatePath() error {
+	if clientpath.HasControlChars(r.Path) {
+		return ErrDirInvalidCharacters
+	}
 	return nil
 }
+
+func (r *AdminEnsureDirLayoutRequest) ValidateTree() error {
+	count := 0
+	var validate func(nodes []DirLayoutNodeRequest) error
+	validate = func(nodes []DirLayoutNodeRequest) error {
+		for _, node := range nodes {
+			if node.Name == "" || node.Name == "." || node.Name == ".." || strings.ContainsAny(node.Name, "/\\") {
+				return ErrDirInvalidName
+			}
+			if clientpath.HasControlChars(node.Name) {
+				return ErrDirInvalidCharacters
+			}
+			if count++; count > dirLayoutMaxNodes {
+				return ErrDirLayoutTooLarge
+			}
+			if err := validate(node.Children); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if len(r.Tree) == 0 {
+		return ErrDirInvalidTree
+	}
+	return validate(r.Tree)
+}
@@ -0,0 +1,28 @@
+package dto
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestAdminPruneDirsRequestValidateOlderThan(t *testing.T) {
+	maxOlderThan := int64(math.MaxInt64 / int64(time.Second))
+	tests := []struct {
+		olderThan int64
+		valid     bool
+	}{
+		{-1, false},
+		{0, false},
+		{1, true},
+		{maxOlderThan, true},
+		{maxOlderThan + 1, false},
+		{math.MaxInt64, false},
+	}
+	for _, tt := range tests {
+		r := AdminPruneDirsRequest{OlderThan: tt.olderThan}
+		if err := r.ValidateOlderThan(); (err == nil) != tt.valid {
+			t.Errorf("ValidateOlderThan(%d) = %v, want valid %t", tt.olderThan, err, tt.valid)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package dto
+
+import "time"
+
+type CreateDirResponse struct {
+	Path    string `json:"path"`
+	Created bool   `json:"created"`
+}
+
+type DirTreeResponse struct {
+	Name     string            `json:"name"`
+	IsDir    bool              `json:"is_dir"`
+	Children []DirTreeResponse `json:"children"`
+}
+
+type EnsureDirLayoutResponse struct {
+	Created []string `json:"created"`
+}
+
+type SnapshotDirResponse struct {
+	Strategy string `json:"strategy"`
+}
+
+type PruneDirsResponse struct {
+	Deleted []string `json:"deleted"`
+	Skipped []string `json:"skipped"`
+}
+
+type DigestDirResponse struct {
+	Digest string `json:"digest"`
+	Files  int    `json:"files"`
+	Dirs   int    `json:"dirs"`
+}
+
+type FlattenDirResponse struct {
+	Files []FlattenedFileResponse `json:"files"`
+}
+
+type FlattenedFileResponse struct {
+	OldPath string  `json:"old_path"`
+	NewPath *string `json:"new_path,omitempty"`
+	Status  string  `json:"status"`
+	Error   *string `json:"error,omitempty"`
+}
+
+type EmptyDirResponse struct {
+	Files  int                       `json:"files"`
+	Dirs   int                       `json:"dirs"`
+	Failed []EmptyDirFailureResponse `json:"failed"`
+}
+
+type EmptyDirFailureResponse struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+type DirSizeResponse struct {
+	Path       string    `json:"path"`
+	Size       int64     `json:"size"`
+	Files      int       `json:"files"`
+	Dirs       int       `json:"dirs"`
+	ComputedAt time.Time `json:"computed_at"`
+}
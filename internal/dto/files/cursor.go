@@ -0,0 +1,35 @@
+package dto
+
+import (
+	"encoding/base64"
+	"strings"
+
+	"github.com/flash-go/files-service/internal/clientpath"
+)
+
+// Maximum number of entries per listing page
+const MaxListLimit = 1000
+
+// Encode the position of a listed entry as an opaque page cursor. The cursor
+// names the entry itself (its kind and name), not an offset, so it stays
+// valid when entries are added or removed before it.
+func EncodeListCursor(isDir bool, name string) string {
+	kind := "f"
+	if isDir {
+		kind = "d"
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(kind + name))
+}
+
+// Decode a page cursor into the kind and name of the last listed entry
+func DecodeListCursor(cursor string) (isDir bool, name string, ok bool) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(raw) < 2 {
+		return false, "", false
+	}
+	kind, name := raw[0], string(raw[1:])
+	if (kind != 'd' && kind != 'f') || strings.Contains(name, "/") || clientpath.HasControlChars(name) {
+		return false, "", false
+	}
+	return kind == 'd', name, true
+}
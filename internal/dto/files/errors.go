@@ -1,11 +1,39 @@
 package dto
 
 import (
+	internalErrors "github.com/flash-go/files-service/internal/errors"
 	"github.com/flash-go/sdk/errors"
 )
 
 var (
-	ErrDirInvalidPath    = errors.New(errors.ErrBadRequest, "invalid_path")
-	ErrDirInvalidOldPath = errors.New(errors.ErrBadRequest, "invalid_old_path")
-	ErrDirInvalidNewPath = errors.New(errors.ErrBadRequest, "invalid_new_path")
+	ErrDirInvalidPath          = errors.New(errors.ErrBadRequest, "invalid_path")
+	ErrDirInvalidOldPath       = errors.New(errors.ErrBadRequest, "invalid_old_path")
+	ErrDirInvalidNewPath       = errors.New(errors.ErrBadRequest, "invalid_new_path")
+	ErrFileInvalidOffset       = errors.New(errors.ErrBadRequest, "invalid_offset")
+	ErrFileInvalidLimit        = errors.New(errors.ErrBadRequest, "invalid_limit")
+	ErrFileInvalidMode         = errors.New(errors.ErrBadRequest, "invalid_mode")
+	ErrFileInvalidPattern      = errors.New(errors.ErrBadRequest, "invalid_pattern")
+	ErrFileInvalidDepth        = errors.New(errors.ErrBadRequest, "invalid_depth")
+	ErrFileInvalidRange        = errors.New(errors.ErrBadRequest, "invalid_range")
+	ErrFileInvalidCharacters   = errors.New(errors.ErrBadRequest, "invalid_characters")
+	ErrFileInvalidSize         = errors.New(errors.ErrBadRequest, "invalid_size")
+	ErrFileInvalidCursor       = errors.New(errors.ErrBadRequest, "invalid_cursor")
+	ErrFileInvalidPaths        = errors.New(errors.ErrBadRequest, "invalid_paths")
+	ErrFileInvalidHash         = errors.New(errors.ErrBadRequest, "invalid_hash")
+	ErrFileInvalidVersion      = errors.New(errors.ErrBadRequest, "invalid_version")
+	ErrFileInvalidOrder        = errors.New(errors.ErrBadRequest, "invalid_order")
+	ErrFileRangeUnsatisfied    = errors.New(internalErrors.ErrRangeNotSatisfiable, "range_not_satisfiable")
+	ErrFileTooLarge            = errors.New(internalErrors.ErrPayloadTooLarge, "file_too_large")
+	ErrFileTooManyDownloads    = errors.New(errors.ErrServiceUnavailable, "too_many_downloads")
+	ErrFileInvalidChunkSize    = errors.New(errors.ErrBadRequest, "invalid_chunk_size")
+	ErrFileInvalidUrl          = errors.New(errors.ErrBadRequest, "invalid_url")
+	ErrFileInvalidName         = errors.New(errors.ErrBadRequest, "invalid_name")
+	ErrFileInvalidTtl          = errors.New(errors.ErrBadRequest, "invalid_ttl")
+	ErrFileInvalidMimeCategory = errors.New(errors.ErrBadRequest, "invalid_mime_category")
+	ErrFileInvalidFormat       = errors.New(errors.ErrBadRequest, "invalid_format")
+	ErrFileInvalidQuality      = errors.New(errors.ErrBadRequest, "invalid_quality")
+	ErrFileInvalidDedup        = errors.New(errors.ErrBadRequest, "invalid_dedup")
+	ErrFileInvalidOperation    = errors.New(errors.ErrBadRequest, "invalid_operation")
+	ErrFileInvalidSourcePath   = errors.New(errors.ErrBadRequest, "invalid_source_path")
+	ErrFileInvalidDestPath     = errors.New(errors.ErrBadRequest, "invalid_dest_path")
 )
@@ -1,17 +1,273 @@
 package dto
 
+import (
+	"strings"
+
+	"github.com/flash-go/files-service/internal/clientpath"
+)
+
 type AdminCreateFileRequest struct {
-	Path string `json:"path"`
+	Path      string `json:"path"`
+	Size      *int64 `json:"size"`
+	Mode      string `json:"mode"`
+	CreateDir bool   `json:"create_dir"`
+	Backup    bool   `json:"backup"`
+	Ttl       *int64 `json:"ttl"`
+	Dedup     string `json:"dedup"`
+}
+
+func (r *AdminCreateFileRequest) Canonicalize() {
+	r.Path = clientpath.Canonical(r.Path)
+}
+
+func (r *AdminCreateFileRequest) Validate() error {
+	if err := r.ValidatePath(); err != nil {
+		return err
+	}
+	if err := r.ValidateMode(); err != nil {
+		return err
+	}
+	if err := r.ValidateTtl(); err != nil {
+		return err
+	}
+	if err := r.ValidateDedup(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *AdminCreateFileRequest) ValidatePath() error {
+	if clientpath.HasControlChars(r.Path) {
+		return ErrFileInvalidCharacters
+	}
+	return nil
+}
+
+func (r *AdminCreateFileRequest) ValidateMode() error {
+	switch r.Mode {
+	case "", "create", "replace", "upsert":
+		return nil
+	}
+	return ErrFileInvalidMode
+}
+
+func (r *AdminCreateFileRequest) ValidateTtl() error {
+	if r.Ttl != nil && *r.Ttl < 1 {
+		return ErrFileInvalidTtl
+	}
+	return nil
+}
+
+func (r *AdminCreateFileRequest) ValidateDedup() error {
+	switch r.Dedup {
+	case "", "allow", "reject", "link":
+		return nil
+	}
+	return ErrFileInvalidDedup
 }
 
 type AdminListFilesRequest struct {
+	Path           string `json:"path"`
+	WithPath       bool   `json:"with_path"`
+	SkipErrors     bool   `json:"skip_errors"`
+	WithEncoding   bool   `json:"with_encoding"`
+	WithContent    bool   `json:"with_content"`
+	WithDirStats   bool   `json:"with_dir_stats"`
+	WithDirSize    bool   `json:"with_dir_size"`
+	Since          string `json:"since"`
+	GroupByType    bool   `json:"group_by_type"`
+	Limit          int    `json:"limit"`
+	Cursor         string `json:"cursor"`
+	Order          string `json:"order"`
+	ErrorOnMissing *bool  `json:"error_on_missing"`
+	MimeCategory   string `json:"mime_category"`
+	IncludeSelf    bool   `json:"include_self"`
+}
+
+func (r *AdminListFilesRequest) Canonicalize() {
+	r.Path = clientpath.Canonical(r.Path)
+}
+
+func (r *AdminListFilesRequest) Validate() error {
+	if err := r.ValidatePath(); err != nil {
+		return err
+	}
+	if err := r.ValidateLimit(); err != nil {
+		return err
+	}
+	if err := r.ValidateCursor(); err != nil {
+		return err
+	}
+	if err := r.ValidateOrder(); err != nil {
+		return err
+	}
+	if err := r.ValidateMimeCategory(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *AdminListFilesRequest) ValidatePath() error {
+	if clientpath.HasControlChars(r.Path) {
+		return ErrFileInvalidCharacters
+	}
+	return nil
+}
+
+func (r *AdminListFilesRequest) ValidateLimit() error {
+	if r.Limit < 0 || r.Limit > MaxListLimit {
+		return ErrFileInvalidLimit
+	}
+	return nil
+}
+
+func (r *AdminListFilesRequest) ValidateCursor() error {
+	if r.Cursor == "" {
+		return nil
+	}
+	if r.Limit == 0 {
+		return ErrFileInvalidCursor
+	}
+	if _, _, ok := DecodeListCursor(r.Cursor); !ok {
+		return ErrFileInvalidCursor
+	}
+	return nil
+}
+
+func (r *AdminListFilesRequest) ValidateOrder() error {
+	switch r.Order {
+	case "", "dirs_first", "files_first", "mixed":
+		return nil
+	}
+	return ErrFileInvalidOrder
+}
+
+func (r *AdminListFilesRequest) ValidateMimeCategory() error {
+	switch r.MimeCategory {
+	case "", "application", "audio", "font", "image", "model", "text", "video":
+		return nil
+	}
+	return ErrFileInvalidMimeCategory
+}
+
+type AdminListTokenRequest struct {
 	Path string `json:"path"`
 }
 
+func (r *AdminListTokenRequest) Canonicalize() {
+	r.Path = clientpath.Canonical(r.Path)
+}
+
+func (r *AdminListTokenRequest) Validate() error {
+	if err := r.ValidatePath(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *AdminListTokenRequest) ValidatePath() error {
+	if clientpath.HasControlChars(r.Path) {
+		return ErrFileInvalidCharacters
+	}
+	return nil
+}
+
+type AdminFindRequest struct {
+	Path    string `json:"path"`
+	Pattern string `json:"pattern"`
+	Depth   int    `json:"depth"`
+}
+
+func (r *AdminFindRequest) Canonicalize() {
+	r.Path = clientpath.Canonical(r.Path)
+}
+
+func (r *AdminFindRequest) Validate() error {
+	if err := r.ValidatePath(); err != nil {
+		return err
+	}
+	if err := r.ValidatePattern(); err != nil {
+		return err
+	}
+	if err := r.ValidateDepth(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *AdminFindRequest) ValidatePath() error {
+	if clientpath.HasControlChars(r.Path) {
+		return ErrFileInvalidCharacters
+	}
+	return nil
+}
+
+func (r *AdminFindRequest) ValidatePattern() error {
+	if r.Pattern == "" {
+		return ErrFileInvalidPattern
+	}
+	return nil
+}
+
+func (r *AdminFindRequest) ValidateDepth() error {
+	if r.Depth < 0 {
+		return ErrFileInvalidDepth
+	}
+	return nil
+}
+
+type AdminRecentFilesRequest struct {
+	Path  string `json:"path"`
+	Limit int    `json:"limit"`
+	Depth int    `json:"depth"`
+}
+
+func (r *AdminRecentFilesRequest) Canonicalize() {
+	r.Path = clientpath.Canonical(r.Path)
+}
+
+func (r *AdminRecentFilesRequest) Validate() error {
+	if err := r.ValidatePath(); err != nil {
+		return err
+	}
+	if err := r.ValidateLimit(); err != nil {
+		return err
+	}
+	if err := r.ValidateDepth(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *AdminRecentFilesRequest) ValidatePath() error {
+	if clientpath.HasControlChars(r.Path) {
+		return ErrFileInvalidCharacters
+	}
+	return nil
+}
+
+func (r *AdminRecentFilesRequest) ValidateLimit() error {
+	if r.Limit < 0 || r.Limit > MaxListLimit {
+		return ErrFileInvalidLimit
+	}
+	return nil
+}
+
+func (r *AdminRecentFilesRequest) ValidateDepth() error {
+	if r.Depth < 0 {
+		return ErrFileInvalidDepth
+	}
+	return nil
+}
+
 type AdminDeleteFileRequest struct {
 	Path string `json:"path"`
 }
 
+func (r *AdminDeleteFileRequest) Canonicalize() {
+	r.Path = clientpath.Canonical(r.Path)
+}
+
 func (r *AdminDeleteFileRequest) Validate() error {
 	if err := r.ValidatePath(); err != nil {
 		return err
@@ -23,6 +279,9 @@ func (r *AdminDeleteFileRequest) ValidatePath() error {
 	if r.Path == "" {
 		return ErrDirInvalidPath
 	}
+	if clientpath.HasControlChars(r.Path) {
+		return ErrFileInvalidCharacters
+	}
 	return nil
 }
 
@@ -31,6 +290,11 @@ type AdminRenameFileRequest struct {
 	NewPath string `json:"new_path"`
 }
 
+func (r *AdminRenameFileRequest) Canonicalize() {
+	r.OldPath = clientpath.Canonical(r.OldPath)
+	r.NewPath = clientpath.Canonical(r.NewPath)
+}
+
 func (r *AdminRenameFileRequest) Validate() error {
 	if err := r.ValidateOldPath(); err != nil {
 		return err
@@ -45,6 +309,9 @@ func (r *AdminRenameFileRequest) ValidateOldPath() error {
 	if r.OldPath == "" {
 		return ErrDirInvalidOldPath
 	}
+	if clientpath.HasControlChars(r.OldPath) {
+		return ErrFileInvalidCharacters
+	}
 	return nil
 }
 
@@ -52,5 +319,587 @@ func (r *AdminRenameFileRequest) ValidateNewPath() error {
 	if r.NewPath == "" {
 		return ErrDirInvalidNewPath
 	}
+	if clientpath.HasControlChars(r.NewPath) {
+		return ErrFileInvalidCharacters
+	}
+	return nil
+}
+
+type AdminMoveFileRequest struct {
+	SourcePath string `json:"source_path"`
+	DestPath   string `json:"dest_path"`
+	Overwrite  bool   `json:"overwrite"`
+}
+
+func (r *AdminMoveFileRequest) Canonicalize() {
+	r.SourcePath = clientpath.Canonical(r.SourcePath)
+	r.DestPath = clientpath.Canonical(r.DestPath)
+}
+
+func (r *AdminMoveFileRequest) Validate() error {
+	if err := r.ValidateSourcePath(); err != nil {
+		return err
+	}
+	if err := r.ValidateDestPath(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *AdminMoveFileRequest) ValidateSourcePath() error {
+	if r.SourcePath == "" {
+		return ErrFileInvalidSourcePath
+	}
+	if clientpath.HasControlChars(r.SourcePath) {
+		return ErrFileInvalidCharacters
+	}
+	return nil
+}
+
+func (r *AdminMoveFileRequest) ValidateDestPath() error {
+	if r.DestPath == "" {
+		return ErrFileInvalidDestPath
+	}
+	if clientpath.HasControlChars(r.DestPath) {
+		return ErrFileInvalidCharacters
+	}
+	return nil
+}
+
+type AdminCopyFileRequest struct {
+	SourcePath string `json:"source_path"`
+	DestPath   string `json:"dest_path"`
+}
+
+func (r *AdminCopyFileRequest) Canonicalize() {
+	r.SourcePath = clientpath.Canonical(r.SourcePath)
+	r.DestPath = clientpath.Canonical(r.DestPath)
+}
+
+func (r *AdminCopyFileRequest) Validate() error {
+	if err := r.ValidateSourcePath(); err != nil {
+		return err
+	}
+	if err := r.ValidateDestPath(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *AdminCopyFileRequest) ValidateSourcePath() error {
+	if r.SourcePath == "" {
+		return ErrFileInvalidSourcePath
+	}
+	if clientpath.HasControlChars(r.SourcePath) {
+		return ErrFileInvalidCharacters
+	}
+	return nil
+}
+
+func (r *AdminCopyFileRequest) ValidateDestPath() error {
+	if r.DestPath == "" {
+		return ErrFileInvalidDestPath
+	}
+	if clientpath.HasControlChars(r.DestPath) {
+		return ErrFileInvalidCharacters
+	}
+	return nil
+}
+
+type AdminWriteAtRequest struct {
+	Path   string `json:"path"`
+	Offset int64  `json:"offset"`
+}
+
+func (r *AdminWriteAtRequest) Canonicalize() {
+	r.Path = clientpath.Canonical(r.Path)
+}
+
+func (r *AdminWriteAtRequest) Validate() error {
+	if err := r.ValidatePath(); err != nil {
+		return err
+	}
+	if err := r.ValidateOffset(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *AdminWriteAtRequest) ValidatePath() error {
+	if r.Path == "" {
+		return ErrDirInvalidPath
+	}
+	if clientpath.HasControlChars(r.Path) {
+		return ErrFileInvalidCharacters
+	}
+	return nil
+}
+
+func (r *AdminWriteAtRequest) ValidateOffset() error {
+	if r.Offset < 0 {
+		return ErrFileInvalidOffset
+	}
+	return nil
+}
+
+type AdminAllocateFileRequest struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	Sparse bool   `json:"sparse"`
+}
+
+func (r *AdminAllocateFileRequest) Canonicalize() {
+	r.Path = clientpath.Canonical(r.Path)
+}
+
+func (r *AdminAllocateFileRequest) Validate() error {
+	if err := r.ValidatePath(); err != nil {
+		return err
+	}
+	if err := r.ValidateSize(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *AdminAllocateFileRequest) ValidatePath() error {
+	if r.Path == "" {
+		return ErrDirInvalidPath
+	}
+	if clientpath.HasControlChars(r.Path) {
+		return ErrFileInvalidCharacters
+	}
+	return nil
+}
+
+func (r *AdminAllocateFileRequest) ValidateSize() error {
+	if r.Size < 0 {
+		return ErrFileInvalidSize
+	}
+	return nil
+}
+
+type AdminFileHashRequest struct {
+	Path      string `json:"path"`
+	WithMD5   bool   `json:"with_md5"`
+	WithCRC32 bool   `json:"with_crc32"`
+}
+
+func (r *AdminFileHashRequest) Canonicalize() {
+	r.Path = clientpath.Canonical(r.Path)
+}
+
+func (r *AdminFileHashRequest) Validate() error {
+	if err := r.ValidatePath(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *AdminFileHashRequest) ValidatePath() error {
+	if r.Path == "" {
+		return ErrDirInvalidPath
+	}
+	if clientpath.HasControlChars(r.Path) {
+		return ErrFileInvalidCharacters
+	}
+	return nil
+}
+
+type AdminMoveRequest struct {
+	OldPath string `json:"old_path"`
+	NewPath string `json:"new_path"`
+}
+
+func (r *AdminMoveRequest) Canonicalize() {
+	r.OldPath = clientpath.Canonical(r.OldPath)
+	r.NewPath = clientpath.Canonical(r.NewPath)
+}
+
+func (r *AdminMoveRequest) Validate() error {
+	if err := r.ValidateOldPath(); err != nil {
+		return err
+	}
+	if err := r.ValidateNewPath(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *AdminMoveRequest) ValidateOldPath() error {
+	if r.OldPath == "" {
+		return ErrDirInvalidOldPath
+	}
+	if clientpath.HasControlChars(r.OldPath) {
+		return ErrFileInvalidCharacters
+	}
+	return nil
+}
+
+func (r *AdminMoveRequest) ValidateNewPath() error {
+	if r.NewPath == "" {
+		return ErrDirInvalidNewPath
+	}
+	if clientpath.HasControlChars(r.NewPath) {
+		return ErrFileInvalidCharacters
+	}
+	return nil
+}
+
+type AdminPreviewFileRequest struct {
+	Path  string `json:"path"`
+	Bytes int64  `json:"bytes"`
+	Lines int    `json:"lines"`
+}
+
+func (r *AdminPreviewFileRequest) Canonicalize() {
+	r.Path = clientpath.Canonical(r.Path)
+}
+
+func (r *AdminPreviewFileRequest) Validate() error {
+	if err := r.ValidatePath(); err != nil {
+		return err
+	}
+	if err := r.ValidateLimits(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *AdminPreviewFileRequest) ValidatePath() error {
+	if r.Path == "" {
+		return ErrDirInvalidPath
+	}
+	if clientpath.HasControlChars(r.Path) {
+		return ErrFileInvalidCharacters
+	}
+	return nil
+}
+
+func (r *AdminPreviewFileRequest) ValidateLimits() error {
+	if r.Bytes < 0 || r.Lines < 0 {
+		return ErrFileInvalidLimit
+	}
+	return nil
+}
+
+// Maximum number of files concatenated by a single request
+const MaxConcatFiles = 100
+
+type AdminConcatFilesRequest struct {
+	Paths []string `json:"paths"`
+}
+
+func (r *AdminConcatFilesRequest) Canonicalize() {
+	for i := range r.Paths {
+		r.Paths[i] = clientpath.Canonical(r.Paths[i])
+	}
+}
+
+func (r *AdminConcatFilesRequest) Validate() error {
+	if err := r.ValidatePaths(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *AdminConcatFilesRequest) ValidatePaths() error {
+	if len(r.Paths) == 0 || len(r.Paths) > MaxConcatFiles {
+		return ErrFileInvalidPaths
+	}
+	for _, p := range r.Paths {
+		if p == "" {
+			return ErrDirInvalidPath
+		}
+		if clientpath.HasControlChars(p) {
+			return ErrFileInvalidCharacters
+		}
+	}
+	return nil
+}
+
+const MaxVerifyFiles = 1000
+
+type AdminVerifyFilesRequest struct {
+	Files []VerifyFileRequest `json:"files"`
+}
+
+type VerifyFileRequest struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+}
+
+func (r *AdminVerifyFilesRequest) Canonicalize() {
+	for i := range r.Files {
+		r.Files[i].Path = clientpath.Canonical(r.Files[i].Path)
+	}
+}
+
+func (r *AdminVerifyFilesRequest) Validate() error {
+	if err := r.ValidateFiles(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *AdminVerifyFilesRequest) ValidateFiles() error {
+	if len(r.Files) == 0 || len(r.Files) > MaxVerifyFiles {
+		return ErrFileInvalidPaths
+	}
+	for _, f := range r.Files {
+		if f.Path == "" {
+			return ErrDirInvalidPath
+		}
+		if clientpath.HasControlChars(f.Path) {
+			return ErrFileInvalidCharacters
+		}
+		if len(f.SHA256) != 64 || strings.Trim(strings.ToLower(f.SHA256), "0123456789abcdef") != "" {
+			return ErrFileInvalidHash
+		}
+	}
+	return nil
+}
+
+type AdminListFileVersionsRequest struct {
+	Path string `json:"path"`
+}
+
+func (r *AdminListFileVersionsRequest) Canonicalize() {
+	r.Path = clientpath.Canonical(r.Path)
+}
+
+func (r *AdminListFileVersionsRequest) Validate() error {
+	if err := r.ValidatePath(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *AdminListFileVersionsRequest) ValidatePath() error {
+	if r.Path == "" {
+		return ErrDirInvalidPath
+	}
+	if clientpath.HasControlChars(r.Path) {
+		return ErrFileInvalidCharacters
+	}
+	return nil
+}
+
+type AdminRestoreFileVersionRequest struct {
+	Path      string `json:"path"`
+	VersionId string `json:"version_id"`
+}
+
+func (r *AdminRestoreFileVersionRequest) Canonicalize() {
+	r.Path = clientpath.Canonical(r.Path)
+}
+
+func (r *AdminRestoreFileVersionRequest) Validate() error {
+	if err := r.ValidatePath(); err != nil {
+		return err
+	}
+	if err := r.ValidateVersionId(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *AdminRestoreFileVersionRequest) ValidatePath() error {
+	if r.Path == "" {
+		return ErrDirInvalidPath
+	}
+	if clientpath.HasControlChars(r.Path) {
+		return ErrFileInvalidCharacters
+	}
+	return nil
+}
+
+func (r *AdminRestoreFileVersionRequest) ValidateVersionId() error {
+	if r.VersionId == "" || strings.Trim(r.VersionId, "0123456789") != "" {
+		return ErrFileInvalidVersion
+	}
+	return nil
+}
+
+type AdminSplitFileRequest struct {
+	Path       string `json:"path"`
+	TargetPath string `json:"target_path"`
+	ChunkSize  int64  `json:"chunk_size"`
+}
+
+func (r *AdminSplitFileRequest) Canonicalize() {
+	r.Path = clientpath.Canonical(r.Path)
+	if r.TargetPath != "" {
+		r.TargetPath = clientpath.Canonical(r.TargetPath)
+	}
+}
+
+func (r *AdminSplitFileRequest) Validate() error {
+	if err := r.ValidatePath(); err != nil {
+		return err
+	}
+	if err := r.ValidateTargetPath(); err != nil {
+		return err
+	}
+	if err := r.ValidateChunkSize(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *AdminSplitFileRequest) ValidatePath() error {
+	if r.Path == "" {
+		return ErrDirInvalidPath
+	}
+	if clientpath.HasControlChars(r.Path) {
+		return ErrFileInvalidCharacters
+	}
+	return nil
+}
+
+func (r *AdminSplitFileRequest) ValidateTargetPath() error {
+	if clientpath.HasControlChars(r.TargetPath) {
+		return ErrFileInvalidCharacters
+	}
+	return nil
+}
+
+func (r *AdminSplitFileRequest) ValidateChunkSize() error {
+	if r.ChunkSize < 1 {
+		return ErrFileInvalidChunkSize
+	}
+	return nil
+}
+
+type AdminResolvePathRequest struct {
+	Path string `json:"path"`
+}
+
+func (r *AdminResolvePathRequest) Canonicalize() {
+	r.Path = clientpath.Canonical(r.Path)
+}
+
+func (r *AdminResolvePathRequest) Validate() error {
+	if err := r.ValidatePath(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *AdminResolvePathRequest) ValidatePath() error {
+	if clientpath.HasControlChars(r.Path) {
+		return ErrFileInvalidCharacters
+	}
+	return nil
+}
+
+type AdminCheckPathRequest struct {
+	Path      string `json:"path"`
+	Operation string `json:"operation"`
+}
+
+func (r *AdminCheckPathRequest) Canonicalize() {
+	r.Path = clientpath.Canonical(r.Path)
+}
+
+func (r *AdminCheckPathRequest) Validate() error {
+	if err := r.ValidateOperation(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *AdminCheckPathRequest) ValidateOperation() error {
+	switch r.Operation {
+	case "read", "delete", "list", "upload", "create_dir":
+		return nil
+	}
+	return ErrFileInvalidOperation
+}
+
+type AdminImportFileRequest struct {
+	Url       string `json:"url"`
+	Path      string `json:"path"`
+	Name      string `json:"name"`
+	Mode      string `json:"mode"`
+	CreateDir bool   `json:"create_dir"`
+	Backup    bool   `json:"backup"`
+}
+
+func (r *AdminImportFileRequest) Canonicalize() {
+	r.Path = clientpath.Canonical(r.Path)
+}
+
+func (r *AdminImportFileRequest) Validate() error {
+	if err := r.ValidateUrl(); err != nil {
+		return err
+	}
+	if err := r.ValidatePath(); err != nil {
+		return err
+	}
+	if err := r.ValidateName(); err != nil {
+		return err
+	}
+	if err := r.ValidateMode(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *AdminImportFileRequest) ValidateUrl() error {
+	if r.Url == "" || clientpath.HasControlChars(r.Url) {
+		return ErrFileInvalidUrl
+	}
+	return nil
+}
+
+func (r *AdminImportFileRequest) ValidatePath() error {
+	if clientpath.HasControlChars(r.Path) {
+		return ErrFileInvalidCharacters
+	}
+	return nil
+}
+
+func (r *AdminImportFileRequest) ValidateName() error {
+	if clientpath.HasControlChars(r.Name) {
+		return ErrFileInvalidCharacters
+	}
+	if strings.ContainsAny(r.Name, `/\`) || r.Name == "." || r.Name == ".." {
+		return ErrFileInvalidName
+	}
+	return nil
+}
+
+func (r *AdminImportFileRequest) ValidateMode() error {
+	switch r.Mode {
+	case "", "create", "replace", "upsert":
+		return nil
+	}
+	return ErrFileInvalidMode
+}
+
+type AdminStatFileRequest struct {
+	Path         string `json:"path"`
+	WithEncoding bool   `json:"with_encoding"`
+}
+
+func (r *AdminStatFileRequest) Canonicalize() {
+	r.Path = clientpath.Canonical(r.Path)
+}
+
+func (r *AdminStatFileRequest) Validate() error {
+	if err := r.ValidatePath(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *AdminStatFileRequest) ValidatePath() error {
+	if r.Path == "" {
+		return ErrDirInvalidPath
+	}
+	if clientpath.HasControlChars(r.Path) {
+		return ErrFileInvalidCharacters
+	}
 	return nil
 }
@@ -1,8 +1,146 @@
 package dto
 
+import "time"
+
+type CreateFileResponse struct {
+	Path       string  `json:"path"`
+	BackupPath *string `json:"backup_path"`
+	Duplicate  bool    `json:"duplicate,omitempty"`
+}
+
+type MoveFileResponse struct {
+	Path   string `json:"path"`
+	Moved  bool   `json:"moved"`
+	Copied bool   `json:"copied"`
+}
+
+type CopyFileResponse struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
 type FileResponse struct {
-	Name     string  `json:"name"`
-	IsDir    bool    `json:"is_dir"`
-	Size     *int64  `json:"size"`
-	MimeType *string `json:"mime_type"`
+	Name         string     `json:"name"`
+	IsDir        bool       `json:"is_dir"`
+	Size         *int64     `json:"size"`
+	MimeType     *string    `json:"mime_type"`
+	Selected     bool       `json:"selected"`
+	Path         *string    `json:"path,omitempty"`
+	Error        *string    `json:"error,omitempty"`
+	Encoding     *string    `json:"encoding,omitempty"`
+	AccessTime   *time.Time `json:"access_time,omitempty"`
+	IsSymlink    bool       `json:"is_symlink,omitempty"`
+	Content      *string    `json:"content,omitempty"`
+	ChildCount   *int       `json:"child_count,omitempty"`
+	ChildrenSize *int64     `json:"children_size,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	ExpiresIn    *int64     `json:"expires_in,omitempty"`
+	ModTime      *time.Time `json:"mod_time,omitempty"`
+	IsSelf       bool       `json:"is_self,omitempty"`
+}
+
+type GroupedFilesResponse struct {
+	Dirs  []FileResponse `json:"dirs"`
+	Files []FileResponse `json:"files"`
+}
+
+type FilesPageResponse struct {
+	Entries    []FileResponse `json:"entries"`
+	HasMore    bool           `json:"has_more"`
+	NextCursor *string        `json:"next_cursor,omitempty"`
+}
+
+type GroupedFilesPageResponse struct {
+	Dirs       []FileResponse `json:"dirs"`
+	Files      []FileResponse `json:"files"`
+	HasMore    bool           `json:"has_more"`
+	NextCursor *string        `json:"next_cursor,omitempty"`
+}
+
+type FindResponse struct {
+	Path      string `json:"path"`
+	IsDir     bool   `json:"is_dir"`
+	IsSymlink bool   `json:"is_symlink,omitempty"`
+	Size      *int64 `json:"size,omitempty"`
+}
+
+type RecentFileResponse struct {
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+type FileHashResponse struct {
+	SHA256 string  `json:"sha256"`
+	MD5    *string `json:"md5,omitempty"`
+	CRC32  *string `json:"crc32,omitempty"`
+	Size   int64   `json:"size"`
+}
+
+type FilePreviewResponse struct {
+	Content   string `json:"content"`
+	MimeType  string `json:"mime_type"`
+	Truncated bool   `json:"truncated"`
+}
+
+type FileRangeResponse struct {
+	Received     int64   `json:"received"`
+	Total        int64   `json:"total"`
+	Complete     bool    `json:"complete"`
+	SessionToken *string `json:"session_token"`
+}
+
+type ListTokenResponse struct {
+	Token string `json:"token"`
+}
+
+type VerifyFilesResponse struct {
+	Files []VerifiedFileResponse `json:"files"`
+}
+
+type VerifiedFileResponse struct {
+	Path   string `json:"path"`
+	Status string `json:"status"`
+}
+
+type FileVersionResponse struct {
+	VersionId string    `json:"version_id"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type RestoreFileVersionResponse struct {
+	Path              string  `json:"path"`
+	Size              int64   `json:"size"`
+	ArchivedVersionId *string `json:"archived_version_id"`
+}
+
+type SplitFileResponse struct {
+	Parts []FilePartResponse `json:"parts"`
+}
+
+type FilePartResponse struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+type ResolvePathResponse struct {
+	Path   string  `json:"path"`
+	Exists bool    `json:"exists"`
+	Type   *string `json:"type"`
+}
+
+type CheckPathResponse struct {
+	Path      string              `json:"path"`
+	Operation string              `json:"operation"`
+	Allowed   bool                `json:"allowed"`
+	Rule      *string             `json:"rule"`
+	Error     *string             `json:"error"`
+	Checks    []PathCheckResponse `json:"checks"`
+}
+
+type PathCheckResponse struct {
+	Rule   string `json:"rule"`
+	Passed bool   `json:"passed"`
 }
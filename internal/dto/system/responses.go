@@ -0,0 +1,9 @@
+package dto
+
+type VersionResponse struct {
+	ServiceName    string `json:"service_name"`
+	Version        string `json:"version"`
+	Commit         string `json:"commit"`
+	StoreAvailable bool   `json:"store_available"`
+	StoreReadOnly  bool   `json:"store_read_only"`
+}
@@ -0,0 +1,16 @@
+package errors
+
+import (
+	"errors"
+
+	sdkErrors "github.com/flash-go/sdk/errors"
+)
+
+var (
+	ErrPreconditionFailed  sdkErrors.Error = errors.New("precondition_failed")
+	ErrPayloadTooLarge     sdkErrors.Error = errors.New("payload_too_large")
+	ErrInsufficientStorage sdkErrors.Error = errors.New("insufficient_storage")
+	ErrGatewayTimeout      sdkErrors.Error = errors.New("gateway_timeout")
+	ErrRangeNotSatisfiable sdkErrors.Error = errors.New("range_not_satisfiable")
+	ErrBadGateway          sdkErrors.Error = errors.New("bad_gateway")
+)
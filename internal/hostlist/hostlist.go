@@ -0,0 +1,98 @@
+package hostlist
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+/*
+List matches hosts against a set of entries, each one of:
+
+  - "*", matching any host
+  - a host name, matching itself case-insensitively ("example.com")
+  - "*." followed by a domain, matching its subdomains ("*.example.com")
+  - an IP address or CIDR block, matching IP hosts and resolved addresses
+    inside it ("::1", "10.0.0.0/8")
+
+Matching addresses as well as names lets a list reject a host name that
+resolves into a private range, not just the literal address.
+*/
+type List struct {
+	any     bool
+	names   map[string]bool
+	domains []string
+	nets    []*net.IPNet
+}
+
+// Parse list entries, failing on a malformed IP address or CIDR block
+func Parse(entries []string) (*List, error) {
+	l := &List{names: make(map[string]bool)}
+	for _, entry := range entries {
+		entry = strings.ToLower(entry)
+		switch {
+		case entry == "*":
+			l.any = true
+		case strings.HasPrefix(entry, "*."):
+			l.domains = append(l.domains, entry[1:])
+		case strings.Contains(entry, "/"):
+			_, block, err := net.ParseCIDR(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid host list entry %q", entry)
+			}
+			l.nets = append(l.nets, block)
+		case net.ParseIP(entry) != nil:
+			ip := net.ParseIP(entry)
+			bits := 8 * len(ip)
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 32
+			}
+			l.nets = append(l.nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+		default:
+			l.names[entry] = true
+		}
+	}
+	return l, nil
+}
+
+// Report whether the list has no entries
+func (l *List) Empty() bool {
+	return !l.any && len(l.names) == 0 && len(l.domains) == 0 && len(l.nets) == 0
+}
+
+// Report whether a host name, or any of the addresses it resolves to, matches
+// an entry
+func (l *List) Match(host string, addrs []net.IP) bool {
+	if l.any {
+		return true
+	}
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if l.names[host] {
+		return true
+	}
+	for _, domain := range l.domains {
+		if strings.HasSuffix(host, domain) {
+			return true
+		}
+	}
+	for _, addr := range addrs {
+		for _, block := range l.nets {
+			if block.Contains(addr) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Report whether an address lies in an IP address or CIDR block entry. Host
+// names, domains and "*" are not considered, so a match means the address
+// itself was listed.
+func (l *List) MatchAddr(addr net.IP) bool {
+	for _, block := range l.nets {
+		if block.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package httpctx
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/flash-go/flash/http/server"
+)
+
+// Error response body
+type ErrorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+/*
+WriteError writes an error response with a JSON ErrorResponse body.
+
+The status code and message come from ctx.WriteErrorResponse (so the server's
+error status map still applies); Code is the code the error was created with
+(see errorCode), so clients can branch on it without parsing the whole string:
+
+| Error                                   | Status | Code                  |
+|-----------------------------------------|--------|-----------------------|
+| errors.New(ErrBadRequest, "file_exist") | 400    | "file_exist"          |
+| errors.ErrBadRequest                    | 400    | "bad_request"         |
+| unmapped error                          | 503    | "service_unavailable" |
+*/
+func WriteError(ctx server.ReqCtx, err error) {
+	ctx.WriteErrorResponse(err)
+	response := ctx.Response()
+	message := string(response.Body())
+	code := message
+	if message == err.Error() {
+		code = errorCode(err)
+	}
+	body, err := json.Marshal(ErrorResponse{
+		Code:    code,
+		Message: message,
+	})
+	if err != nil {
+		return
+	}
+	response.Header.SetContentType("application/json; charset=utf-8")
+	response.SetBody(body)
+}
+
+// Return the code of err: the code the sdk's errors.New joined to a base
+// error as "<base>:<code>" ("file_exist" for errors.New(ErrBadRequest,
+// "file_exist")), found at the end of err's chain, or the message of the
+// base error itself ("bad_request" for errors.ErrBadRequest)
+func errorCode(err error) string {
+	for {
+		base := errors.Unwrap(err)
+		if base == nil {
+			return err.Error()
+		}
+		if errors.Unwrap(base) == nil {
+			if code, ok := strings.CutPrefix(err.Error(), base.Error()+":"); ok {
+				return code
+			}
+			return base.Error()
+		}
+		err = base
+	}
+}
+
+// Parse optional If-Unmodified-Since precondition header
+func UnmodifiedSince(ctx server.ReqCtx) *time.Time {
+	header := ctx.GetHeader("If-Unmodified-Since")
+	if header == "" {
+		return nil
+	}
+	t, err := http.ParseTime(header)
+	if err != nil {
+		// Invalid dates must be ignored (RFC 9110, section 13.1.4)
+		return nil
+	}
+	return &t
+}
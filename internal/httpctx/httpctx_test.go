@@ -0,0 +1,68 @@
+package httpctx
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/flash-go/flash/http/server"
+	"github.com/flash-go/sdk/errors"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+)
+
+func TestWriteError(t *testing.T) {
+	errFileExist := errors.New(errors.ErrBadRequest, "file_exist")
+	tests := []struct {
+		name    string
+		err     error
+		status  int
+		code    string
+		message string
+	}{
+		{"sdk error", errFileExist, 400, "file_exist", "bad_request:file_exist"},
+		{"base error", errors.ErrBadRequest, 400, "bad_request", "bad_request"},
+		{"unmapped error", fmt.Errorf("disk on fire"), 503, "service_unavailable", "service_unavailable"},
+		{"wrapped base error", fmt.Errorf("open: %w", errors.ErrBadRequest), 400, "bad_request", "open: bad_request"},
+		{"wrapped sdk error", fmt.Errorf("%w: at offset 3", errFileExist), 400, "file_exist", "bad_request:file_exist: at offset 3"},
+	}
+
+	// Serve WriteError of the error selected by the request path
+	listener := fasthttputil.NewInmemoryListener()
+	httpServer := server.New()
+	httpServer.DisableLogo(true)
+	httpServer.SetErrorResponseStatusMap(&server.ErrorResponseStatusMap{
+		errors.ErrBadRequest: 400,
+	})
+	for i, tt := range tests {
+		httpServer.AddRoute("GET", fmt.Sprintf("/%d", i), func(ctx server.ReqCtx) {
+			WriteError(ctx, tt.err)
+		})
+	}
+	httpServer.SetListener(listener)
+	httpServer.Serve("", 0, make(chan error, 1))
+	defer httpServer.Shutdown()
+	client := &fasthttp.Client{
+		Dial: func(string) (net.Conn, error) { return listener.Dial() },
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body, err := client.Get(nil, fmt.Sprintf("http://test/%d", i))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if status != tt.status {
+				t.Errorf("status = %d, want %d", status, tt.status)
+			}
+			var response ErrorResponse
+			if err := json.Unmarshal(body, &response); err != nil {
+				t.Fatalf("body %q: %v", body, err)
+			}
+			if response.Code != tt.code || response.Message != tt.message {
+				t.Errorf("body = %+v, want code %q and message %q", response, tt.code, tt.message)
+			}
+		})
+	}
+}
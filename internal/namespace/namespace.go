@@ -0,0 +1,54 @@
+package namespace
+
+import (
+	"context"
+
+	"github.com/flash-go/files-service/internal/httpctx"
+	"github.com/flash-go/flash/http/server"
+	"github.com/flash-go/sdk/errors"
+)
+
+// Request header selecting the store namespace
+const Header = "X-Store-Namespace"
+
+var ErrUnknownNamespace = errors.New(errors.ErrBadRequest, "unknown_namespace")
+
+type contextKey struct{}
+
+// Return a copy of ctx selecting the named store namespace
+func WithName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, contextKey{}, name)
+}
+
+// Return the store namespace selected in ctx ("" for the default one)
+func Name(ctx context.Context) string {
+	name, _ := ctx.Value(contextKey{}).(string)
+	return name
+}
+
+/*
+Middleware selects the store namespace named by the X-Store-Namespace header
+for the rest of the request, rejecting names not in names with
+ErrUnknownNamespace. Requests without the header use the default namespace.
+
+The selection is stored in the request context returned by ctx.Context(),
+which the framework reads from the "ctx" user value.
+*/
+func Middleware(names []string) func(handler server.ReqHandler) server.ReqHandler {
+	known := make(map[string]bool, len(names))
+	for _, name := range names {
+		known[name] = true
+	}
+	return func(handler server.ReqHandler) server.ReqHandler {
+		return func(ctx server.ReqCtx) {
+			if name := ctx.GetHeader(Header); name != "" {
+				if !known[name] {
+					httpctx.WriteError(ctx, ErrUnknownNamespace)
+					return
+				}
+				ctx.SetUserValue("ctx", WithName(ctx.Context(), name))
+			}
+			handler(ctx)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package pathlock
+
+import (
+	"hash/fnv"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Number of lock stripes
+const stripes = 256
+
+/*
+Locks serializes operations on overlapping store paths.
+
+Locking a path write-locks it and read-locks every ancestor, so an operation
+on "a" (e.g. renaming the directory) excludes operations on "a/b" and below,
+while operations on unrelated paths run concurrently. Paths are hashed onto a
+fixed set of striped RWMutexes, so memory stays constant; unrelated paths may
+occasionally share a stripe and serialize needlessly, but never deadlock, as
+stripes are always acquired in ascending order.
+*/
+type Locks struct {
+	stripes [stripes]sync.RWMutex
+}
+
+func New() *Locks {
+	return &Locks{}
+}
+
+// Lock the given paths for writing and their ancestors for reading. Returns
+// the function releasing all of them.
+func (l *Locks) Lock(paths ...string) func() {
+	// Collect stripes, a write on a stripe wins over a read
+	write := make(map[int]bool)
+	for _, p := range paths {
+		p = clean(p)
+		write[stripe(p)] = true
+		for p != "" {
+			p = parent(p)
+			if s := stripe(p); !write[s] {
+				write[s] = false
+			}
+		}
+	}
+
+	// Acquire in ascending order
+	order := make([]int, 0, len(write))
+	for s := range write {
+		order = append(order, s)
+	}
+	sort.Ints(order)
+	for _, s := range order {
+		if write[s] {
+			l.stripes[s].Lock()
+		} else {
+			l.stripes[s].RLock()
+		}
+	}
+
+	return func() {
+		for i := len(order) - 1; i >= 0; i-- {
+			if s := order[i]; write[s] {
+				l.stripes[s].Unlock()
+			} else {
+				l.stripes[s].RUnlock()
+			}
+		}
+	}
+}
+
+// Normalize a store path so equivalent spellings share a lock ("" is the root)
+func clean(p string) string {
+	return strings.Trim(path.Clean("/"+p), "/")
+}
+
+// Return the parent of a cleaned path
+func parent(p string) string {
+	if i := strings.LastIndex(p, "/"); i >= 0 {
+		return p[:i]
+	}
+	return ""
+}
+
+func stripe(p string) int {
+	h := fnv.New32a()
+	h.Write([]byte(p))
+	return int(h.Sum32() % stripes)
+}
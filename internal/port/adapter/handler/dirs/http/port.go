@@ -8,4 +8,14 @@ type Interface interface {
 	AdminCreateDir(ctx server.ReqCtx)
 	AdminDeleteDir(ctx server.ReqCtx)
 	AdminRenameDir(ctx server.ReqCtx)
+	AdminSnapshotDir(ctx server.ReqCtx)
+	AdminPruneDirs(ctx server.ReqCtx)
+	AdminDirTree(ctx server.ReqCtx)
+	AdminDirTreeText(ctx server.ReqCtx)
+	AdminDigestDir(ctx server.ReqCtx)
+	AdminFlattenDir(ctx server.ReqCtx)
+	AdminEmptyDir(ctx server.ReqCtx)
+	AdminDirSize(ctx server.ReqCtx)
+	AdminRefreshDirSizes(ctx server.ReqCtx)
+	AdminEnsureDirLayout(ctx server.ReqCtx)
 }
@@ -7,6 +7,28 @@ import (
 type Interface interface {
 	AdminCreateFile(ctx server.ReqCtx)
 	AdminListFiles(ctx server.ReqCtx)
+	AdminListToken(ctx server.ReqCtx)
+	AdminFind(ctx server.ReqCtx)
+	AdminRecentFiles(ctx server.ReqCtx)
 	AdminDeleteFile(ctx server.ReqCtx)
 	AdminRenameFile(ctx server.ReqCtx)
+	AdminMoveFile(ctx server.ReqCtx)
+	AdminCopyFile(ctx server.ReqCtx)
+	AdminWriteAt(ctx server.ReqCtx)
+	AdminWriteRange(ctx server.ReqCtx)
+	AdminAllocateFile(ctx server.ReqCtx)
+	AdminFileHash(ctx server.ReqCtx)
+	AdminMove(ctx server.ReqCtx)
+	AdminPreviewFile(ctx server.ReqCtx)
+	AdminDownloadFile(ctx server.ReqCtx)
+	AdminConcatFiles(ctx server.ReqCtx)
+	AdminVerifyFiles(ctx server.ReqCtx)
+	AdminListFileVersions(ctx server.ReqCtx)
+	AdminDownloadFileVersion(ctx server.ReqCtx)
+	AdminRestoreFileVersion(ctx server.ReqCtx)
+	AdminSplitFile(ctx server.ReqCtx)
+	AdminResolvePath(ctx server.ReqCtx)
+	AdminCheckPath(ctx server.ReqCtx)
+	AdminImportFile(ctx server.ReqCtx)
+	AdminStatFile(ctx server.ReqCtx)
 }
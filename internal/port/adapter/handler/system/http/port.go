@@ -0,0 +1,9 @@
+package port
+
+import (
+	"github.com/flash-go/flash/http/server"
+)
+
+type Interface interface {
+	Version(ctx server.ReqCtx)
+}
@@ -1,11 +1,23 @@
 package port
 
-import "github.com/flash-go/sdk/errors"
+import (
+	internalErrors "github.com/flash-go/files-service/internal/errors"
+	"github.com/flash-go/sdk/errors"
+)
 
 var (
-	ErrInvalidPath    = errors.New(errors.ErrBadRequest, "invalid_path")
-	ErrDirExist       = errors.New(errors.ErrBadRequest, "dir_exist")
-	ErrDirNotFound    = errors.New(errors.ErrBadRequest, "dir_not_found")
-	ErrDirOldNotFound = errors.New(errors.ErrBadRequest, "old_dir_not_found")
-	ErrDirNewExist    = errors.New(errors.ErrBadRequest, "new_dir_exist")
+	ErrInvalidPath      = errors.New(errors.ErrBadRequest, "invalid_path")
+	ErrPathEscape       = errors.New(errors.ErrForbidden, "path_escape")
+	ErrTopDirNotAllowed = errors.New(errors.ErrForbidden, "top_dir_not_allowed")
+	ErrDirExist         = errors.New(errors.ErrBadRequest, "dir_exist")
+	ErrDirNotFound      = errors.New(errors.ErrBadRequest, "dir_not_found")
+	ErrDirOldNotFound   = errors.New(errors.ErrBadRequest, "old_dir_not_found")
+	ErrDirNewExist      = errors.New(errors.ErrBadRequest, "new_dir_exist")
+	ErrDirModified      = errors.New(internalErrors.ErrPreconditionFailed, "dir_modified")
+	ErrDirFull          = errors.New(errors.ErrBadRequest, "dir_full")
+	ErrDirTooLarge      = errors.New(errors.ErrBadRequest, "dir_too_large")
+	ErrSamePath         = errors.New(errors.ErrBadRequest, "same_path")
+	ErrInvalidPolicy    = errors.New(errors.ErrBadRequest, "invalid_policy")
+	ErrOperationTimeout = errors.New(internalErrors.ErrGatewayTimeout, "operation_timeout")
+	ErrCrossNamespace   = errors.New(errors.ErrBadRequest, "cross_namespace")
 )
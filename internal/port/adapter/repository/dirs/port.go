@@ -2,25 +2,163 @@ package port
 
 import (
 	"context"
+	"time"
 )
 
 type Interface interface {
-	CreateDir(ctx context.Context, data *CreateDirData) error
+	CreateDir(ctx context.Context, data *CreateDirData) (*CreateDirResult, error)
 	DeleteDir(ctx context.Context, data *DeleteDirData) error
 	RenameDir(ctx context.Context, data *RenameDirData) error
+	GetDirTree(ctx context.Context, data *GetDirTreeData) (*DirTreeResult, error)
+	SnapshotDir(ctx context.Context, data *SnapshotDirData) (*SnapshotDirResult, error)
+	PruneDirs(ctx context.Context, data *PruneDirsData) (*PruneDirsResult, error)
+	DigestDir(ctx context.Context, data *DigestDirData) (*DigestDirResult, error)
+	FlattenDir(ctx context.Context, data *FlattenDirData) (*FlattenDirResult, error)
+	EmptyDir(ctx context.Context, data *EmptyDirData) (*EmptyDirResult, error)
+	GetDirSize(ctx context.Context, data *GetDirSizeData) (*DirSizeResult, error)
+	RefreshDirSizes(ctx context.Context, data *RefreshDirSizesData) (*DirSizeResult, error)
+	InvalidateDirSizes(ctx context.Context, data *InvalidateDirSizesData) error
 }
 
+// Snapshot strategies
+const (
+	SnapshotStrategyReflink = "reflink" // File contents cloned (copy on write)
+	SnapshotStrategyCopy    = "copy"    // File contents copied
+)
+
+// Flatten collision policies
+const (
+	FlattenConflictRename    = "rename"    // Move under a free numbered name (default)
+	FlattenConflictSkip      = "skip"      // Leave the file in place
+	FlattenConflictOverwrite = "overwrite" // Replace the existing file
+)
+
+// Flatten file statuses
+const (
+	FlattenStatusMoved       = "moved"
+	FlattenStatusOverwritten = "overwritten"
+	FlattenStatusSkipped     = "skipped"
+	FlattenStatusFailed      = "failed"
+)
+
 // Args
 
 type CreateDirData struct {
-	Path string
+	Path        string
+	GetOrCreate bool
 }
 
 type DeleteDirData struct {
-	Path string
+	Path            string
+	UnmodifiedSince *time.Time
 }
 
 type RenameDirData struct {
+	OldPath         string
+	NewPath         string
+	UnmodifiedSince *time.Time
+}
+
+type GetDirTreeData struct {
+	Path      string
+	Depth     int
+	WithFiles bool
+}
+
+type SnapshotDirData struct {
 	OldPath string
 	NewPath string
 }
+
+type PruneDirsData struct {
+	Path           string
+	ModifiedBefore time.Time
+	WithContents   bool
+	DryRun         bool
+}
+
+type DigestDirData struct {
+	Path string
+}
+
+type FlattenDirData struct {
+	Path        string
+	TargetPath  string
+	OnConflict  string
+	PrefixNames bool
+}
+
+type EmptyDirData struct {
+	Path       string
+	BestEffort bool
+}
+
+type GetDirSizeData struct {
+	Path string
+}
+
+type RefreshDirSizesData struct {
+	Path string
+}
+
+type InvalidateDirSizesData struct {
+	Paths []string
+}
+
+// Results
+
+type CreateDirResult struct {
+	Path    string
+	Created bool
+}
+
+type DirTreeResult struct {
+	Name     string
+	IsDir    bool
+	Children []DirTreeResult
+}
+
+type SnapshotDirResult struct {
+	Strategy string
+}
+
+type PruneDirsResult struct {
+	Deleted []string
+	Skipped []string
+}
+
+type DigestDirResult struct {
+	Digest string
+	Files  int
+	Dirs   int
+}
+
+type FlattenDirResult struct {
+	Files []FlattenedFile
+}
+
+type FlattenedFile struct {
+	OldPath string
+	NewPath *string
+	Status  string
+	Error   *string
+}
+
+type EmptyDirResult struct {
+	Files  int
+	Dirs   int
+	Failed []EmptyDirFailure
+}
+
+type EmptyDirFailure struct {
+	Path  string
+	Error string
+}
+
+type DirSizeResult struct {
+	Path       string
+	Size       int64
+	Files      int
+	Dirs       int
+	ComputedAt time.Time
+}
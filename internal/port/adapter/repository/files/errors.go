@@ -1,13 +1,42 @@
 package port
 
-import "github.com/flash-go/sdk/errors"
+import (
+	internalErrors "github.com/flash-go/files-service/internal/errors"
+	"github.com/flash-go/sdk/errors"
+)
 
 var (
-	ErrInvalidPath     = errors.New(errors.ErrBadRequest, "invalid_path")
-	ErrInvalidFile     = errors.New(errors.ErrBadRequest, "invalid_file")
-	ErrFileExist       = errors.New(errors.ErrBadRequest, "file_exist")
-	ErrDirNotFound     = errors.New(errors.ErrBadRequest, "dir_not_found")
-	ErrFileNotFound    = errors.New(errors.ErrBadRequest, "file_not_found")
-	ErrFileOldNotFound = errors.New(errors.ErrBadRequest, "old_file_not_found")
-	ErrFileNewExist    = errors.New(errors.ErrBadRequest, "new_file_exist")
+	ErrInvalidPath       = errors.New(errors.ErrBadRequest, "invalid_path")
+	ErrPathEscape        = errors.New(errors.ErrForbidden, "path_escape")
+	ErrTopDirNotAllowed  = errors.New(errors.ErrForbidden, "top_dir_not_allowed")
+	ErrInvalidFile       = errors.New(errors.ErrBadRequest, "invalid_file")
+	ErrFileExist         = errors.New(errors.ErrBadRequest, "file_exist")
+	ErrDirNotFound       = errors.New(errors.ErrBadRequest, "dir_not_found")
+	ErrFileNotFound      = errors.New(errors.ErrBadRequest, "file_not_found")
+	ErrFileOldNotFound   = errors.New(errors.ErrBadRequest, "old_file_not_found")
+	ErrFileNewExist      = errors.New(errors.ErrBadRequest, "new_file_exist")
+	ErrFileModified      = errors.New(internalErrors.ErrPreconditionFailed, "file_modified")
+	ErrDirFull           = errors.New(errors.ErrBadRequest, "dir_full")
+	ErrFileTooLarge      = errors.New(internalErrors.ErrPayloadTooLarge, "file_too_large")
+	ErrStorageFull       = errors.New(internalErrors.ErrInsufficientStorage, "storage_full")
+	ErrTooManyEntries    = errors.New(errors.ErrBadRequest, "too_many_entries")
+	ErrFileNotText       = errors.New(errors.ErrBadRequest, "file_not_text")
+	ErrMissingExtension  = errors.New(errors.ErrBadRequest, "missing_extension")
+	ErrInvalidPattern    = errors.New(errors.ErrBadRequest, "invalid_pattern")
+	ErrInvalidRange      = errors.New(errors.ErrBadRequest, "invalid_range")
+	ErrOperationTimeout  = errors.New(internalErrors.ErrGatewayTimeout, "operation_timeout")
+	ErrInvalidCharacters = errors.New(errors.ErrBadRequest, "invalid_characters")
+	ErrIsDirectory       = errors.New(errors.ErrBadRequest, "is_directory")
+	ErrSamePath          = errors.New(errors.ErrBadRequest, "same_path")
+	ErrBackupUnavailable = errors.New(errors.ErrBadRequest, "backup_unavailable")
+	ErrVersionsDisabled  = errors.New(errors.ErrBadRequest, "versions_disabled")
+	ErrVersionNotFound   = errors.New(errors.ErrBadRequest, "version_not_found")
+	ErrInvalidChunkSize  = errors.New(errors.ErrBadRequest, "invalid_chunk_size")
+	ErrTooManyParts      = errors.New(errors.ErrBadRequest, "too_many_parts")
+	ErrExpiryUnavailable = errors.New(errors.ErrBadRequest, "expiry_unavailable")
+	ErrDuplicateContent  = errors.New(errors.ErrBadRequest, "duplicate_content")
+	ErrCrossNamespace    = errors.New(errors.ErrBadRequest, "cross_namespace")
+	ErrInvalidImage      = errors.New(errors.ErrBadRequest, "invalid_image")
+	ErrImageUnsupported  = errors.New(errors.ErrBadRequest, "unsupported_image")
+	ErrImageTooLarge     = errors.New(internalErrors.ErrPayloadTooLarge, "image_too_large")
 )
@@ -2,41 +2,391 @@ package port
 
 import (
 	"context"
+	"io"
 	"mime/multipart"
+	"os"
+	"time"
 )
 
 type Interface interface {
-	CreateFile(ctx context.Context, data *CreateFileData) error
-	GetFiles(ctx context.Context, data *GetFilesData) (*[]FileResult, error)
+	CreateFile(ctx context.Context, data *CreateFileData) (*CreateFileResult, error)
+	GetFiles(ctx context.Context, data *GetFilesData) (*GetFilesResult, error)
+	FindFiles(ctx context.Context, data *FindFilesData) error
+	RecentFiles(ctx context.Context, data *RecentFilesData) (*[]RecentFileResult, error)
 	DeleteFile(ctx context.Context, data *DeleteFileData) error
 	RenameFile(ctx context.Context, data *RenameFileData) error
+	MoveFile(ctx context.Context, data *MoveFileData) (*MoveFileResult, error)
+	CopyFile(ctx context.Context, data *CopyFileData) (*CopyFileResult, error)
+	WriteFileAt(ctx context.Context, data *WriteFileAtData) error
+	AllocateFile(ctx context.Context, data *AllocateFileData) error
+	HashFile(ctx context.Context, data *HashFileData) (*HashFileResult, error)
+	WriteFileRange(ctx context.Context, data *WriteFileRangeData) error
+	PreviewFile(ctx context.Context, data *PreviewFileData) (*PreviewResult, error)
+	StatFile(ctx context.Context, data *StatFileData) (*FileResult, error)
+	PurgeTrash(ctx context.Context, data *PurgeTrashData) (*PurgeTrashResult, error)
+	PurgeBackups(ctx context.Context, data *PurgeBackupsData) (*PurgeBackupsResult, error)
+	ListToken(ctx context.Context, data *ListTokenData) (*ListTokenResult, error)
+	OpenFile(ctx context.Context, data *OpenFileData) (*OpenFileResult, error)
+	ListFileVersions(ctx context.Context, data *ListFileVersionsData) (*[]FileVersionResult, error)
+	OpenFileVersion(ctx context.Context, data *OpenFileVersionData) (*OpenFileResult, error)
+	RestoreFileVersion(ctx context.Context, data *RestoreFileVersionData) (*RestoreFileVersionResult, error)
+	SplitFile(ctx context.Context, data *SplitFileData) (*SplitFileResult, error)
+	ResolvePath(ctx context.Context, data *ResolvePathData) (*ResolvePathResult, error)
+	CheckPath(ctx context.Context, data *CheckPathData) (*CheckPathResult, error)
+	ListExpiredFiles(ctx context.Context, data *ListExpiredFilesData) (*[]string, error)
+	DeleteExpiredFile(ctx context.Context, data *DeleteExpiredFileData) (*DeleteExpiredFileResult, error)
 }
 
+// Create file modes
+const (
+	CreateModeCreate  = "create"  // Create only if absent (default)
+	CreateModeReplace = "replace" // Replace only if present
+	CreateModeUpsert  = "upsert"  // Create or replace
+)
+
+// Listing orders
+const (
+	ListOrderDirsFirst  = "dirs_first"  // Dirs, then files, both by name (default)
+	ListOrderFilesFirst = "files_first" // Files, then dirs, both by name
+	ListOrderMixed      = "mixed"       // Dirs and files interleaved by name
+)
+
+// Duplicate content policies of an upload
+const (
+	DedupAllow  = "allow"  // Store the upload regardless (default)
+	DedupReject = "reject" // Reject the upload with ErrDuplicateContent
+	DedupLink   = "link"   // Store nothing and return the existing file
+)
+
+// Operations a path can be checked for
+const (
+	PathOpRead      = "read"       // Read, stat or rename an entry
+	PathOpDelete    = "delete"     // Delete an entry
+	PathOpList      = "list"       // List a directory ("" is the root)
+	PathOpUpload    = "upload"     // Upload into a directory, creating missing ones
+	PathOpCreateDir = "create_dir" // Create a directory
+)
+
+// Path safety rules, in the order they are checked
+const (
+	PathRuleCharacters = "characters" // No NUL or other control characters
+	PathRuleEmpty      = "empty"      // Not the root, unless the operation takes a directory
+	PathRuleAbsolute   = "absolute"   // Absolute paths are anchored at the root
+	PathRuleTraversal  = "traversal"  // No ".." escaping the root
+	PathRuleDepth      = "depth"      // Nesting within the walk and creation limits
+	PathRuleSymlink    = "symlink"    // No symlinked parent directory
+)
+
+// Entry types
+const (
+	EntryTypeFile    = "file"    // Regular file
+	EntryTypeDir     = "dir"     // Directory
+	EntryTypeSymlink = "symlink" // Symbolic link (not followed)
+	EntryTypeOther   = "other"   // Device, socket, pipe, ...
+)
+
 // Args
 
 type CreateFileData struct {
-	Path string
-	File *multipart.FileHeader
+	Path         string
+	File         *multipart.FileHeader
+	Stream       *FileStream
+	DeclaredSize *int64
+	Mode         string
+	CreateDir    bool
+	Backup       bool
+	ExpiresAt    *time.Time
+	Dedup        string
+}
+
+// Uploaded file read from the request as it arrives, used instead of File.
+// Its size is unknown until Reader is exhausted.
+type FileStream struct {
+	Filename string
+	Reader   io.Reader
 }
 
 type GetFilesData struct {
-	Path string
+	Path           string
+	WithPath       bool
+	SkipErrors     bool
+	WithEncoding   bool
+	WithContent    bool
+	WithDirStats   bool
+	WithDirSize    bool
+	Limit          int
+	AfterDir       bool
+	AfterName      string
+	Order          string
+	EmptyIfMissing bool
+	MimeCategory   string
+	IncludeSelf    bool
+	Since          string
+}
+
+type FindFilesData struct {
+	Path    string
+	Pattern string
+	Depth   int
+	Found   func(FindResult) error
+}
+
+type RecentFilesData struct {
+	Path  string
+	Limit int
+	Depth int
 }
 
 type DeleteFileData struct {
-	Path string
+	Path            string
+	UnmodifiedSince *time.Time
 }
 
 type RenameFileData struct {
-	OldPath string
-	NewPath string
+	OldPath         string
+	NewPath         string
+	UnmodifiedSince *time.Time
+}
+
+type MoveFileData struct {
+	SourcePath      string
+	DestPath        string
+	Overwrite       bool
+	UnmodifiedSince *time.Time
+}
+
+type CopyFileData struct {
+	SourcePath string
+	DestPath   string
+}
+
+type WriteFileAtData struct {
+	Path   string
+	Offset int64
+	File   *multipart.FileHeader
+}
+
+type WriteFileRangeData struct {
+	Path     string
+	UploadId string
+	Mode     string
+	Start    int64
+	Total    int64
+	Content  []byte
+	Complete bool
+}
+
+type StatFileData struct {
+	Path         string
+	WithEncoding bool
+}
+
+type PreviewFileData struct {
+	Path  string
+	Bytes int64
+	Lines int
+}
+
+type PurgeTrashData struct {
+	DeletedBefore time.Time
+}
+
+type PurgeBackupsData struct {
+	CreatedBefore time.Time
+}
+
+type ListTokenData struct {
+	Path string
+}
+
+type OpenFileData struct {
+	Path      string
+	IndexName string
+}
+
+type ListFileVersionsData struct {
+	Path string
+}
+
+type OpenFileVersionData struct {
+	Path      string
+	VersionId string
+}
+
+type RestoreFileVersionData struct {
+	Path      string
+	VersionId string
+}
+
+type SplitFileData struct {
+	Path       string
+	TargetPath string
+	ChunkSize  int64
+}
+
+type ResolvePathData struct {
+	Path string
+}
+
+type CheckPathData struct {
+	Path      string
+	Operation string
+}
+
+type ListExpiredFilesData struct {
+	Now time.Time
+}
+
+type DeleteExpiredFileData struct {
+	Path string
+	Now  time.Time
+}
+
+type AllocateFileData struct {
+	Path   string
+	Size   int64
+	Sparse bool
+}
+
+type HashFileData struct {
+	Path      string
+	WithMD5   bool
+	WithCRC32 bool
 }
 
 // Results
 
+type CreateFileResult struct {
+	Path       string
+	BackupPath *string
+	Duplicate  bool
+}
+
+type MoveFileResult struct {
+	Path   string
+	Moved  bool
+	Copied bool
+}
+
+type CopyFileResult struct {
+	Path string
+	Size int64
+}
+
+type GetFilesResult struct {
+	Files     []FileResult
+	Token     string
+	Unchanged bool
+}
+
 type FileResult struct {
+	Name         string
+	IsDir        bool
+	Size         *int64
+	MimeType     *string
+	Selected     bool
+	Path         *string
+	Error        *string
+	Encoding     *string
+	AccessTime   *time.Time
+	IsSymlink    bool
+	Content      *string
+	ChildCount   *int
+	ChildrenSize *int64
+	ExpiresAt    *time.Time
+	ExpiresIn    *int64
+	ModTime      *time.Time
+	IsSelf       bool
+}
+
+type FindResult struct {
+	Path      string
+	IsDir     bool
+	IsSymlink bool
+	Size      *int64
+}
+
+type RecentFileResult struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+}
+
+type HashFileResult struct {
+	SHA256 string
+	MD5    *string
+	CRC32  *string
+	Size   int64
+}
+
+type PreviewResult struct {
+	Content   string
+	MimeType  string
+	Truncated bool
+}
+
+type PurgeTrashResult struct {
+	Purged int
+}
+
+type PurgeBackupsResult struct {
+	Purged int
+}
+
+type ListTokenResult struct {
+	Token string
+}
+
+type FileVersionResult struct {
+	VersionId string
+	Size      int64
+	CreatedAt time.Time
+}
+
+type RestoreFileVersionResult struct {
+	Path              string
+	Size              int64
+	ArchivedVersionId *string
+}
+
+type SplitFileResult struct {
+	Parts []FilePart
+}
+
+type FilePart struct {
+	Path   string
+	Size   int64
+	SHA256 string
+}
+
+type ResolvePathResult struct {
+	Path   string
+	Exists bool
+	Type   *string
+}
+
+type CheckPathResult struct {
+	Path      string
+	Operation string
+	Allowed   bool
+	Rule      *string
+	Error     *string
+	Checks    []PathCheck
+}
+
+type PathCheck struct {
+	Rule   string
+	Passed bool
+}
+
+type DeleteExpiredFileResult struct {
+	Deleted bool
+}
+
+type OpenFileResult struct {
+	File     *os.File
 	Name     string
-	IsDir    bool
-	Size     *int64
-	MimeType *string
+	Size     int64
+	MimeType string
+	ModTime  time.Time
 }
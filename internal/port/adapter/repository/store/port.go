@@ -0,0 +1,16 @@
+package port
+
+import (
+	"context"
+)
+
+type Interface interface {
+	GetStatus(ctx context.Context) (*StatusResult, error)
+}
+
+// Results
+
+type StatusResult struct {
+	Available bool
+	ReadOnly  bool
+}
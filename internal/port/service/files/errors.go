@@ -0,0 +1,28 @@
+package port
+
+import (
+	internalErrors "github.com/flash-go/files-service/internal/errors"
+	"github.com/flash-go/sdk/errors"
+)
+
+var (
+	ErrIdempotencyKeyReused = errors.New(errors.ErrBadRequest, "idempotency_key_reused")
+	ErrIsDirectory          = errors.New(errors.ErrBadRequest, "is_directory")
+	ErrIndexNotFound        = errors.New(errors.ErrNotFound, "index_not_found")
+	ErrNotDirectory         = errors.New(errors.ErrBadRequest, "not_directory")
+	ErrRangeOutOfOrder      = errors.New(errors.ErrBadRequest, "range_out_of_order")
+	ErrRangeOverlap         = errors.New(errors.ErrBadRequest, "range_overlap")
+	ErrRangeTotalMismatch   = errors.New(errors.ErrBadRequest, "range_total_mismatch")
+	ErrUploadTimeout        = errors.New(internalErrors.ErrGatewayTimeout, "upload_timeout")
+	ErrConcatTooLarge       = errors.New(internalErrors.ErrPayloadTooLarge, "concat_too_large")
+	ErrImportDisabled       = errors.New(errors.ErrForbidden, "import_disabled")
+	ErrInvalidUrl           = errors.New(errors.ErrBadRequest, "invalid_url")
+	ErrHostNotAllowed       = errors.New(errors.ErrForbidden, "host_not_allowed")
+	ErrFetchFailed          = errors.New(internalErrors.ErrBadGateway, "fetch_failed")
+	ErrImportTooLarge       = errors.New(internalErrors.ErrPayloadTooLarge, "import_too_large")
+	ErrMimeNotAllowed       = errors.New(errors.ErrBadRequest, "mime_not_allowed")
+	ErrNotImage             = errors.New(errors.ErrBadRequest, "not_image")
+	ErrImageTooLarge        = errors.New(internalErrors.ErrPayloadTooLarge, "image_too_large")
+	ErrInvalidSessionToken  = errors.New(errors.ErrBadRequest, "invalid_session_token")
+	ErrSessionTokenExpired  = errors.New(errors.ErrBadRequest, "session_token_expired")
+)
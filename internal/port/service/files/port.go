@@ -2,41 +2,401 @@ package port
 
 import (
 	"context"
+	"io"
 	"mime/multipart"
+	"os"
+	"time"
 )
 
 type Interface interface {
-	CreateFile(ctx context.Context, data *CreateFileData) error
-	GetFiles(ctx context.Context, data *GetFilesData) (*[]FileResult, error)
+	CreateFile(ctx context.Context, data *CreateFileData) (*CreateFileResult, error)
+	GetFiles(ctx context.Context, data *GetFilesData) (*GetFilesResult, error)
+	FindFiles(ctx context.Context, data *FindFilesData) error
+	RecentFiles(ctx context.Context, data *RecentFilesData) (*[]RecentFileResult, error)
 	DeleteFile(ctx context.Context, data *DeleteFileData) error
 	RenameFile(ctx context.Context, data *RenameFileData) error
+	MoveFile(ctx context.Context, data *MoveFileData) (*MoveFileResult, error)
+	CopyFile(ctx context.Context, data *CopyFileData) (*CopyFileResult, error)
+	WriteFileAt(ctx context.Context, data *WriteFileAtData) error
+	AllocateFile(ctx context.Context, data *AllocateFileData) error
+	HashFile(ctx context.Context, data *HashFileData) (*HashFileResult, error)
+	WriteFileRange(ctx context.Context, data *WriteFileRangeData) (*WriteFileRangeResult, error)
+	PreviewFile(ctx context.Context, data *PreviewFileData) (*PreviewResult, error)
+	Move(ctx context.Context, data *MoveData) error
+	PurgeTrash(ctx context.Context) (*PurgeTrashResult, error)
+	PurgeBackups(ctx context.Context) (*PurgeBackupsResult, error)
+	PurgeExpired(ctx context.Context) (*PurgeExpiredResult, error)
+	StatFile(ctx context.Context, data *StatFileData) (*FileResult, error)
+	ListToken(ctx context.Context, data *ListTokenData) (*ListTokenResult, error)
+	DownloadFile(ctx context.Context, data *DownloadFileData) (*DownloadResult, error)
+	ConcatFiles(ctx context.Context, data *ConcatFilesData) (*ConcatFilesResult, error)
+	VerifyFiles(ctx context.Context, data *VerifyFilesData) (*VerifyFilesResult, error)
+	ListFileVersions(ctx context.Context, data *ListFileVersionsData) (*[]FileVersionResult, error)
+	DownloadFileVersion(ctx context.Context, data *DownloadFileVersionData) (*DownloadResult, error)
+	RestoreFileVersion(ctx context.Context, data *RestoreFileVersionData) (*RestoreFileVersionResult, error)
+	SplitFile(ctx context.Context, data *SplitFileData) (*SplitFileResult, error)
+	ResolvePath(ctx context.Context, data *ResolvePathData) (*ResolvePathResult, error)
+	CheckPath(ctx context.Context, data *CheckPathData) (*CheckPathResult, error)
+	ImportFile(ctx context.Context, data *ImportFileData) (*CreateFileResult, error)
 }
 
+// Image formats a download can be converted to
+const (
+	ImageFormatJpeg = "jpeg"
+	ImageFormatPng  = "png"
+	ImageFormatGif  = "gif"
+)
+
+// Outcomes of a file checksum verification
+const (
+	VerifyStatusMatch    = "match"
+	VerifyStatusMismatch = "mismatch"
+	VerifyStatusMissing  = "missing"
+)
+
 // Args
 
 type CreateFileData struct {
-	Path string
-	File *multipart.FileHeader
+	Path           string
+	File           *multipart.FileHeader
+	Stream         *FileStream
+	DeclaredSize   *int64
+	Mode           string
+	CreateDir      bool
+	Backup         bool
+	Ttl            *int64
+	Dedup          string
+	IdempotencyKey string
+}
+
+// Uploaded file read from the request as it arrives, used instead of File.
+// Its size is unknown until Reader is exhausted.
+type FileStream struct {
+	Filename string
+	Reader   io.Reader
 }
 
 type GetFilesData struct {
-	Path string
+	Path           string
+	WithPath       bool
+	SkipErrors     bool
+	WithEncoding   bool
+	WithContent    bool
+	WithDirStats   bool
+	WithDirSize    bool
+	Limit          int
+	AfterDir       bool
+	AfterName      string
+	Order          string
+	EmptyIfMissing bool
+	MimeCategory   string
+	IncludeSelf    bool
+	Since          string
+}
+
+type FindFilesData struct {
+	Path    string
+	Pattern string
+	Depth   int
+	Found   func(FindResult) error
+}
+
+type RecentFilesData struct {
+	Path  string
+	Limit int
+	Depth int
 }
 
 type DeleteFileData struct {
-	Path string
+	Path            string
+	UnmodifiedSince *time.Time
 }
 
 type RenameFileData struct {
-	OldPath string
-	NewPath string
+	OldPath         string
+	NewPath         string
+	UnmodifiedSince *time.Time
+}
+
+type MoveFileData struct {
+	SourcePath      string
+	DestPath        string
+	Overwrite       bool
+	UnmodifiedSince *time.Time
+}
+
+type CopyFileData struct {
+	SourcePath string
+	DestPath   string
+}
+
+type WriteFileAtData struct {
+	Path   string
+	Offset int64
+	File   *multipart.FileHeader
+}
+
+type WriteFileRangeData struct {
+	Path         string
+	Mode         string
+	Start        int64
+	Total        int64
+	Content      []byte
+	SessionToken string
+}
+
+type MoveData struct {
+	OldPath         string
+	NewPath         string
+	UnmodifiedSince *time.Time
+}
+
+type PreviewFileData struct {
+	Path  string
+	Bytes int64
+	Lines int
+}
+
+type ListTokenData struct {
+	Path string
+}
+
+type DownloadFileData struct {
+	Path      string
+	Format    string
+	Quality   int
+	AutoIndex bool
+	Limit     int
+	AfterDir  bool
+	AfterName string
+}
+
+type ListFileVersionsData struct {
+	Path string
+}
+
+type DownloadFileVersionData struct {
+	Path      string
+	VersionId string
+}
+
+type RestoreFileVersionData struct {
+	Path      string
+	VersionId string
+}
+
+type SplitFileData struct {
+	Path       string
+	TargetPath string
+	ChunkSize  int64
+}
+
+type ResolvePathData struct {
+	Path string
+}
+
+type CheckPathData struct {
+	Path      string
+	Operation string
+}
+
+type StatFileData struct {
+	Path         string
+	WithEncoding bool
+}
+
+type ImportFileData struct {
+	Url       string
+	Path      string
+	Name      string
+	Mode      string
+	CreateDir bool
+	Backup    bool
+}
+
+type ConcatFilesData struct {
+	Paths []string
+}
+
+type VerifyFilesData struct {
+	Files []VerifyFileData
+}
+
+type VerifyFileData struct {
+	Path   string
+	SHA256 string
+}
+
+type AllocateFileData struct {
+	Path   string
+	Size   int64
+	Sparse bool
+}
+
+type HashFileData struct {
+	Path      string
+	WithMD5   bool
+	WithCRC32 bool
 }
 
 // Results
 
+type CreateFileResult struct {
+	Path       string
+	BackupPath *string
+	Duplicate  bool
+}
+
+type MoveFileResult struct {
+	Path   string
+	Moved  bool
+	Copied bool
+}
+
+type CopyFileResult struct {
+	Path string
+	Size int64
+}
+
+type GetFilesResult struct {
+	Files     []FileResult
+	Token     string
+	Unchanged bool
+}
+
 type FileResult struct {
+	Name         string
+	IsDir        bool
+	Size         *int64
+	MimeType     *string
+	Selected     bool
+	Path         *string
+	Error        *string
+	Encoding     *string
+	AccessTime   *time.Time
+	IsSymlink    bool
+	Content      *string
+	ChildCount   *int
+	ChildrenSize *int64
+	ExpiresAt    *time.Time
+	ExpiresIn    *int64
+	ModTime      *time.Time
+	IsSelf       bool
+}
+
+type FindResult struct {
+	Path      string
+	IsDir     bool
+	IsSymlink bool
+	Size      *int64
+}
+
+type RecentFileResult struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+}
+
+type FileVersionResult struct {
+	VersionId string
+	Size      int64
+	CreatedAt time.Time
+}
+
+type RestoreFileVersionResult struct {
+	Path              string
+	Size              int64
+	ArchivedVersionId *string
+}
+
+type HashFileResult struct {
+	SHA256 string
+	MD5    *string
+	CRC32  *string
+	Size   int64
+}
+
+type PreviewResult struct {
+	Content   string
+	MimeType  string
+	Truncated bool
+}
+
+type PurgeTrashResult struct {
+	Purged int
+}
+
+type PurgeBackupsResult struct {
+	Purged int
+}
+
+type PurgeExpiredResult struct {
+	Purged int
+}
+
+type WriteFileRangeResult struct {
+	Received     int64
+	Total        int64
+	Complete     bool
+	SessionToken *string
+}
+
+type ListTokenResult struct {
+	Token string
+}
+
+// Either File (owned by the caller, who must close it) or, for a directory
+// without an index file, Listing is set
+type ConcatFilesResult struct {
+	Reader io.ReadCloser
+	Size   int64
+}
+
+type SplitFileResult struct {
+	Parts []FilePart
+}
+
+type FilePart struct {
+	Path   string
+	Size   int64
+	SHA256 string
+}
+
+type ResolvePathResult struct {
+	Path   string
+	Exists bool
+	Type   *string
+}
+
+type CheckPathResult struct {
+	Path      string
+	Operation string
+	Allowed   bool
+	Rule      *string
+	Error     *string
+	Checks    []PathCheck
+}
+
+type PathCheck struct {
+	Rule   string
+	Passed bool
+}
+
+type VerifyFilesResult struct {
+	Files []VerifiedFile
+}
+
+type VerifiedFile struct {
+	Path   string
+	Status string
+}
+
+type DownloadResult struct {
+	File     *os.File
+	Content  []byte
 	Name     string
-	IsDir    bool
-	Size     *int64
-	MimeType *string
+	Size     int64
+	MimeType string
+	ModTime  time.Time
+	Listing  *[]FileResult
 }
@@ -0,0 +1,19 @@
+package port
+
+import (
+	"context"
+)
+
+type Interface interface {
+	GetVersion(ctx context.Context) (*VersionResult, error)
+}
+
+// Results
+
+type VersionResult struct {
+	ServiceName    string
+	Version        string
+	Commit         string
+	StoreAvailable bool
+	StoreReadOnly  bool
+}
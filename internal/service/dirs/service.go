@@ -2,36 +2,204 @@ package service
 
 import (
 	"context"
+	"path"
+	"time"
 
+	"github.com/flash-go/files-service/internal/pathlock"
 	dirsRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/dirs"
 	dirsServicePort "github.com/flash-go/files-service/internal/port/service/dirs"
 )
 
 type Config struct {
 	DirsRepository dirsRepositoryAdapterPort.Interface
+	PathLocks      *pathlock.Locks
 }
 
 func New(config *Config) dirsServicePort.Interface {
-	return &service{
+	s := &service{
 		config.DirsRepository,
+		config.PathLocks,
 	}
+	if s.pathLocks == nil {
+		s.pathLocks = pathlock.New()
+	}
+	return s
 }
 
 type service struct {
 	dirsRepository dirsRepositoryAdapterPort.Interface
+	pathLocks      *pathlock.Locks
 }
 
-func (s *service) CreateDir(ctx context.Context, data *dirsServicePort.CreateDirData) error {
+// Lock paths for a change, returning the function that drops the cached
+// sizes of the changed paths and their ancestors (even if ctx is done by
+// then) and releases the locks
+func (s *service) lock(ctx context.Context, paths ...string) func() {
+	unlock := s.pathLocks.Lock(paths...)
+	return func() {
+		s.dirsRepository.InvalidateDirSizes(
+			context.WithoutCancel(ctx),
+			&dirsRepositoryAdapterPort.InvalidateDirSizesData{Paths: paths},
+		)
+		unlock()
+	}
+}
+
+func (s *service) CreateDir(ctx context.Context, data *dirsServicePort.CreateDirData) (*dirsServicePort.CreateDirResult, error) {
+	defer s.lock(ctx, data.Path)()
 	d := dirsRepositoryAdapterPort.CreateDirData(*data)
-	return s.dirsRepository.CreateDir(ctx, &d)
+	result, err := s.dirsRepository.CreateDir(ctx, &d)
+	return (*dirsServicePort.CreateDirResult)(result), err
 }
 
 func (s *service) DeleteDir(ctx context.Context, data *dirsServicePort.DeleteDirData) error {
+	defer s.lock(ctx, data.Path)()
 	d := dirsRepositoryAdapterPort.DeleteDirData(*data)
 	return s.dirsRepository.DeleteDir(ctx, &d)
 }
 
 func (s *service) RenameDir(ctx context.Context, data *dirsServicePort.RenameDirData) error {
+	defer s.lock(ctx, data.OldPath, data.NewPath)()
 	d := dirsRepositoryAdapterPort.RenameDirData(*data)
 	return s.dirsRepository.RenameDir(ctx, &d)
 }
+
+func (s *service) GetDirTree(ctx context.Context, data *dirsServicePort.GetDirTreeData) (*dirsServicePort.DirTreeResult, error) {
+	d := dirsRepositoryAdapterPort.GetDirTreeData(*data)
+	if tree, err := s.dirsRepository.GetDirTree(ctx, &d); err != nil {
+		return nil, err
+	} else {
+		t := convertDirTree(tree)
+		return &t, nil
+	}
+}
+
+func (s *service) SnapshotDir(ctx context.Context, data *dirsServicePort.SnapshotDirData) (*dirsServicePort.SnapshotDirResult, error) {
+	defer s.lock(ctx, data.OldPath, data.NewPath)()
+	d := dirsRepositoryAdapterPort.SnapshotDirData(*data)
+	if result, err := s.dirsRepository.SnapshotDir(ctx, &d); err != nil {
+		return nil, err
+	} else {
+		return (*dirsServicePort.SnapshotDirResult)(result), nil
+	}
+}
+
+// Delete the subdirectories of a path older than MaxAge
+func (s *service) PruneDirs(ctx context.Context, data *dirsServicePort.PruneDirsData) (*dirsServicePort.PruneDirsResult, error) {
+	defer s.lock(ctx, data.Path)()
+	result, err := s.dirsRepository.PruneDirs(ctx, &dirsRepositoryAdapterPort.PruneDirsData{
+		Path:           data.Path,
+		ModifiedBefore: time.Now().Add(-data.MaxAge),
+		WithContents:   data.WithContents,
+		DryRun:         data.DryRun,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return (*dirsServicePort.PruneDirsResult)(result), nil
+}
+
+func (s *service) DigestDir(ctx context.Context, data *dirsServicePort.DigestDirData) (*dirsServicePort.DigestDirResult, error) {
+	d := dirsRepositoryAdapterPort.DigestDirData(*data)
+	result, err := s.dirsRepository.DigestDir(ctx, &d)
+	if err != nil {
+		return nil, err
+	}
+	return (*dirsServicePort.DigestDirResult)(result), nil
+}
+
+func (s *service) FlattenDir(ctx context.Context, data *dirsServicePort.FlattenDirData) (*dirsServicePort.FlattenDirResult, error) {
+	defer s.lock(ctx, data.Path, data.TargetPath)()
+	d := dirsRepositoryAdapterPort.FlattenDirData(*data)
+	result, err := s.dirsRepository.FlattenDir(ctx, &d)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]dirsServicePort.FlattenedFile, len(result.Files))
+	for i, file := range result.Files {
+		files[i] = dirsServicePort.FlattenedFile(file)
+	}
+	return &dirsServicePort.FlattenDirResult{Files: files}, nil
+}
+
+func (s *service) EmptyDir(ctx context.Context, data *dirsServicePort.EmptyDirData) (*dirsServicePort.EmptyDirResult, error) {
+	defer s.lock(ctx, data.Path)()
+	d := dirsRepositoryAdapterPort.EmptyDirData(*data)
+	result, err := s.dirsRepository.EmptyDir(ctx, &d)
+	if err != nil {
+		return nil, err
+	}
+	failed := make([]dirsServicePort.EmptyDirFailure, len(result.Failed))
+	for i, failure := range result.Failed {
+		failed[i] = dirsServicePort.EmptyDirFailure(failure)
+	}
+	return &dirsServicePort.EmptyDirResult{
+		Files:  result.Files,
+		Dirs:   result.Dirs,
+		Failed: failed,
+	}, nil
+}
+
+func (s *service) GetDirSize(ctx context.Context, data *dirsServicePort.GetDirSizeData) (*dirsServicePort.DirSizeResult, error) {
+	d := dirsRepositoryAdapterPort.GetDirSizeData(*data)
+	result, err := s.dirsRepository.GetDirSize(ctx, &d)
+	if err != nil {
+		return nil, err
+	}
+	return (*dirsServicePort.DirSizeResult)(result), nil
+}
+
+func (s *service) RefreshDirSizes(ctx context.Context, data *dirsServicePort.RefreshDirSizesData) (*dirsServicePort.DirSizeResult, error) {
+	d := dirsRepositoryAdapterPort.RefreshDirSizesData(*data)
+	result, err := s.dirsRepository.RefreshDirSizes(ctx, &d)
+	if err != nil {
+		return nil, err
+	}
+	return (*dirsServicePort.DirSizeResult)(result), nil
+}
+
+// Convert a repository dir tree node and its children into service results
+func convertDirTree(node *dirsRepositoryAdapterPort.DirTreeResult) dirsServicePort.DirTreeResult {
+	children := make([]dirsServicePort.DirTreeResult, len(node.Children))
+	for i := range node.Children {
+		children[i] = convertDirTree(&node.Children[i])
+	}
+	return dirsServicePort.DirTreeResult{
+		Name:     node.Name,
+		IsDir:    node.IsDir,
+		Children: children,
+	}
+}
+
+// Create every directory of the layout that does not exist yet, parents
+// first, reporting the paths that were created
+func (s *service) EnsureDirLayout(ctx context.Context, data *dirsServicePort.EnsureDirLayoutData) (*dirsServicePort.EnsureDirLayoutResult, error) {
+	defer s.lock(ctx, data.Path)()
+	created := []string{}
+	var ensure func(parent string, nodes []dirsServicePort.DirLayoutNode) error
+	ensure = func(parent string, nodes []dirsServicePort.DirLayoutNode) error {
+		for _, node := range nodes {
+			p := path.Join(parent, node.Name)
+			result, err := s.dirsRepository.CreateDir(ctx, &dirsRepositoryAdapterPort.CreateDirData{
+				Path:        p,
+				GetOrCreate: true,
+			})
+			if err != nil {
+				return err
+			}
+			if result.Created {
+				created = append(created, p)
+			}
+			if err := ensure(p, node.Children); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := ensure(data.Path, data.Tree); err != nil {
+		return nil, err
+	}
+	return &dirsServicePort.EnsureDirLayoutResult{
+		Created: created,
+	}, nil
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"io"
+	"os"
+
+	filesRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/files"
+	filesServicePort "github.com/flash-go/files-service/internal/port/service/files"
+)
+
+/*
+ConcatFiles opens the files at Paths, in order, and returns a reader
+streaming their concatenated content.
+
+ 1. Opens every file through OpenFile before anything is read, so a missing
+    input fails the whole request (ErrFileNotFound, ErrIsDirectory for a
+    dir) before the response starts.
+ 2. Rejects the request with ErrConcatTooLarge once the sizes add up to more
+    than concatMaxSize (if set).
+ 3. Reads each file up to the size it had when opened, so Size stays exact
+    if a file grows meanwhile.
+
+The caller must close the reader, which closes every file.
+*/
+func (s *service) ConcatFiles(ctx context.Context, data *filesServicePort.ConcatFilesData) (*filesServicePort.ConcatFilesResult, error) {
+	files := make([]*os.File, 0, len(data.Paths))
+	closeAll := func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}
+
+	readers := make([]io.Reader, 0, len(data.Paths))
+	var size int64
+	for _, p := range data.Paths {
+		opened, err := s.filesRepository.OpenFile(ctx, &filesRepositoryAdapterPort.OpenFileData{Path: p})
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		files = append(files, opened.File)
+		size += opened.Size
+		if s.concatMaxSize > 0 && size > s.concatMaxSize {
+			closeAll()
+			return nil, filesServicePort.ErrConcatTooLarge
+		}
+		readers = append(readers, io.LimitReader(opened.File, opened.Size))
+	}
+
+	return &filesServicePort.ConcatFilesResult{
+		Reader: concatReader{io.MultiReader(readers...), closeAll},
+		Size:   size,
+	}, nil
+}
+
+// Reader over the concatenated files, closing all of them on Close
+type concatReader struct {
+	io.Reader
+	closeAll func()
+}
+
+func (r concatReader) Close() error {
+	r.closeAll()
+	return nil
+}
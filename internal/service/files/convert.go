@@ -0,0 +1,179 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/flash-go/files-service/internal/namespace"
+	filesServicePort "github.com/flash-go/files-service/internal/port/service/files"
+)
+
+// Media types of the image formats that can be converted, and of their output
+var imageMimeTypes = map[string]string{
+	filesServicePort.ImageFormatJpeg: "image/jpeg",
+	filesServicePort.ImageFormatPng:  "image/png",
+	filesServicePort.ImageFormatGif:  "image/gif",
+}
+
+// Converted image kept in the cache, valid while the original keeps its size
+// and modification time
+type imageEntry struct {
+	size     int64
+	modTime  time.Time
+	content  []byte
+	mimeType string
+}
+
+// Cache of converted images, bounded by the total size of their content
+type imageCache struct {
+	mu      sync.Mutex
+	entries map[string]*imageEntry
+	size    int64
+	maxSize int64
+}
+
+/*
+convertImage re-encodes an opened image file into data.Format, returning the
+download result of the converted content. The file is closed in any case.
+
+ 1. Serves the original unchanged if it already has the requested format and
+    no quality is requested.
+ 2. Looks the conversion up in the image cache, keyed by store namespace,
+    path, format and quality. An entry is used only if the original still has
+    the same size and modification time.
+ 3. Rejects originals that are not JPEG, PNG or GIF images (ErrNotImage), and
+    images with more than imageMaxPixels pixels (ErrImageTooLarge, unless
+    zero), before decoding them.
+ 4. Decodes and re-encodes the image (JPEG at data.Quality, or the default
+    quality if zero; animated GIFs keep their first frame only) and stores the
+    result in the cache.
+
+The result keeps the name and modification time of the original.
+*/
+func (s *service) convertImage(ctx context.Context, file *filesServicePort.DownloadResult, data *filesServicePort.DownloadFileData) (*filesServicePort.DownloadResult, error) {
+	mimeType := imageMimeTypes[data.Format]
+	if file.MimeType == mimeType && data.Quality == 0 {
+		return file, nil
+	}
+	defer file.File.Close()
+
+	// Use a cached conversion of an unchanged original
+	key := namespace.Name(ctx) + "\x00" + data.Path + "\x00" + data.Format + "\x00" + strconv.Itoa(data.Quality)
+	if entry := s.imageCache.get(key, file.Size, file.ModTime); entry != nil {
+		return imageResult(file, entry), nil
+	}
+
+	// Check the image type and dimensions before decoding
+	switch file.MimeType {
+	case "image/jpeg", "image/png", "image/gif":
+	default:
+		return nil, filesServicePort.ErrNotImage
+	}
+	config, _, err := image.DecodeConfig(file.File)
+	if err != nil {
+		return nil, filesServicePort.ErrNotImage
+	}
+	if s.imageMaxPixels > 0 && int64(config.Width)*int64(config.Height) > s.imageMaxPixels {
+		return nil, filesServicePort.ErrImageTooLarge
+	}
+	if _, err := file.File.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(file.File)
+	if err != nil {
+		return nil, filesServicePort.ErrNotImage
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Encode the image
+	var buf bytes.Buffer
+	switch data.Format {
+	case filesServicePort.ImageFormatJpeg:
+		quality := data.Quality
+		if quality == 0 {
+			quality = jpeg.DefaultQuality
+		}
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality})
+	case filesServicePort.ImageFormatPng:
+		err = png.Encode(&buf, img)
+	case filesServicePort.ImageFormatGif:
+		err = gif.Encode(&buf, img, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &imageEntry{
+		size:     file.Size,
+		modTime:  file.ModTime,
+		content:  buf.Bytes(),
+		mimeType: mimeType,
+	}
+	s.imageCache.store(key, entry)
+
+	return imageResult(file, entry), nil
+}
+
+// Build the download result of a converted image
+func imageResult(file *filesServicePort.DownloadResult, entry *imageEntry) *filesServicePort.DownloadResult {
+	return &filesServicePort.DownloadResult{
+		Content:  entry.content,
+		Name:     file.Name,
+		Size:     int64(len(entry.content)),
+		MimeType: entry.mimeType,
+		ModTime:  file.ModTime,
+	}
+}
+
+// Return the cached conversion of an original if it did not change since
+func (c *imageCache) get(key string, size int64, modTime time.Time) *imageEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	if entry.size != size || !entry.modTime.Equal(modTime) {
+		c.remove(key)
+		return nil
+	}
+	return entry
+}
+
+// Cache a conversion, evicting arbitrary entries until the content fits in
+// maxSize. Conversions larger than maxSize on their own are not cached.
+func (c *imageCache) store(key string, entry *imageEntry) {
+	size := int64(len(entry.content))
+	if size > c.maxSize {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.remove(key)
+	for k := range c.entries {
+		if c.size+size <= c.maxSize {
+			break
+		}
+		c.remove(k)
+	}
+	c.entries[key] = entry
+	c.size += size
+}
+
+// Remove an entry, if present. The caller must hold mu.
+func (c *imageCache) remove(key string) {
+	if entry, ok := c.entries[key]; ok {
+		c.size -= int64(len(entry.content))
+		delete(c.entries, key)
+	}
+}
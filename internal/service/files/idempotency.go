@@ -0,0 +1,100 @@
+package service
+
+import (
+	"fmt"
+	"path"
+	"time"
+
+	filesServicePort "github.com/flash-go/files-service/internal/port/service/files"
+)
+
+// Upload attempt tracked under an idempotency key
+type idempotencyEntry struct {
+	fingerprint string
+	done        chan struct{}
+	result      *filesServicePort.CreateFileResult
+	err         error
+	expiresAt   time.Time
+}
+
+/*
+idempotent runs fn at most once per idempotency key within idempotencyTtl.
+
+  - A retry with a key whose upload succeeded replays the success (and its
+    result) instead of failing with ErrFileExist.
+  - A retry that arrives while the first attempt is still running waits for it
+    and shares its result.
+  - Failed attempts are forgotten, so a retry after a failure runs again.
+  - Reusing a key for a different upload (path, name or size) is rejected
+    with ErrIdempotencyKeyReused.
+*/
+func (s *service) idempotent(key, fingerprint string, fn func() (*filesServicePort.CreateFileResult, error)) (*filesServicePort.CreateFileResult, error) {
+	now := time.Now()
+
+	s.idempotencyMu.Lock()
+	for k, e := range s.idempotencyKeys {
+		if !e.expiresAt.IsZero() && now.After(e.expiresAt) {
+			delete(s.idempotencyKeys, k)
+		}
+	}
+	if e, ok := s.idempotencyKeys[key]; ok {
+		s.idempotencyMu.Unlock()
+		if e.fingerprint != fingerprint {
+			return nil, filesServicePort.ErrIdempotencyKeyReused
+		}
+		<-e.done
+		if e.err != nil {
+			// The first attempt failed, so run this one on its own
+			return s.idempotent(key, fingerprint, fn)
+		}
+		return e.result, nil
+	}
+	entry := &idempotencyEntry{
+		fingerprint: fingerprint,
+		done:        make(chan struct{}),
+	}
+	s.idempotencyKeys[key] = entry
+	s.idempotencyMu.Unlock()
+
+	entry.result, entry.err = fn()
+
+	s.idempotencyMu.Lock()
+	if entry.err != nil {
+		delete(s.idempotencyKeys, key)
+	} else {
+		entry.expiresAt = time.Now().Add(s.idempotencyTtl)
+	}
+	s.idempotencyMu.Unlock()
+	close(entry.done)
+
+	return entry.result, entry.err
+}
+
+// Identify an upload by its target, so a reused key can be detected. Paths
+// are cleaned, so equivalent spellings of a target share a fingerprint. A
+// streamed upload is identified by its declared size, if any.
+func uploadFingerprint(data *filesServicePort.CreateFileData) string {
+	name, ok := uploadName(data)
+	if !ok {
+		return path.Clean(data.Path)
+	}
+	size := int64(-1)
+	switch {
+	case data.File != nil:
+		size = data.File.Size
+	case data.DeclaredSize != nil:
+		size = *data.DeclaredSize
+	}
+	return fmt.Sprintf("%s\x00%s\x00%d\x00%s\x00%t", path.Clean(data.Path), path.Base(name), size, data.Mode, data.Backup)
+}
+
+// Return the file name of an upload (File, else Stream), if it has content
+func uploadName(data *filesServicePort.CreateFileData) (string, bool) {
+	switch {
+	case data.File != nil:
+		return data.File.Filename, true
+	case data.Stream != nil:
+		return data.Stream.Filename, true
+	}
+	return "", false
+}
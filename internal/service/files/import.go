@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	filesRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/files"
+	filesServicePort "github.com/flash-go/files-service/internal/port/service/files"
+)
+
+// Time to wait for the response headers of an import
+const importHeaderTimeout = 10 * time.Second
+
+/*
+ImportFile fetches the content of a remote URL and stores it like an upload.
+
+ 1. Fails with ErrImportDisabled unless an allowed hosts list is configured.
+ 2. Accepts only absolute http and https URLs without credentials.
+ 3. Fetches the URL with a GET through importClient, which connects only to
+    addresses that pass the host checks (see dialImport): a host not
+    allowed fails with ErrHostNotAllowed. Redirects are not followed; a
+    non-2xx status or a transport error fails with ErrFetchFailed.
+ 4. Before reading the body, rejects a Content-Length over importMaxSize
+    with ErrImportTooLarge and, if importMimeTypes is set, a Content-Type
+    outside it with ErrMimeNotAllowed ("type/*" entries match any subtype).
+ 5. Streams the body through CreateFile under Name, or else the last segment
+    of the URL path, so the create mode, size limit, MIME routing, backup
+    and atomic rename all apply as for an upload. A body growing past
+    importMaxSize fails with ErrImportTooLarge once it does, without being
+    stored.
+*/
+func (s *service) ImportFile(ctx context.Context, data *filesServicePort.ImportFileData) (*filesServicePort.CreateFileResult, error) {
+	if s.importAllowedHosts == nil || s.importAllowedHosts.Empty() {
+		return nil, filesServicePort.ErrImportDisabled
+	}
+
+	// Validate url
+	u, err := url.Parse(data.Url)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" || u.User != nil {
+		return nil, filesServicePort.ErrInvalidUrl
+	}
+
+	// Fetch content
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, filesServicePort.ErrInvalidUrl
+	}
+	response, err := s.importClient.Do(request)
+	if err != nil {
+		if errors.Is(err, filesServicePort.ErrHostNotAllowed) {
+			return nil, filesServicePort.ErrHostNotAllowed
+		}
+		return nil, filesServicePort.ErrFetchFailed
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return nil, filesServicePort.ErrFetchFailed
+	}
+
+	// Check the declared size and type before reading the body
+	if s.importMaxSize > 0 && response.ContentLength > s.importMaxSize {
+		return nil, filesServicePort.ErrImportTooLarge
+	}
+	if len(s.importMimeTypes) > 0 && !s.importMimeAllowed(response.Header.Get("Content-Type")) {
+		return nil, filesServicePort.ErrMimeNotAllowed
+	}
+
+	// Resolve file name
+	name := data.Name
+	if name == "" {
+		name = path.Base(u.Path)
+	}
+	if name == "" || name == "." || name == "/" {
+		return nil, filesRepositoryAdapterPort.ErrInvalidFile
+	}
+
+	// Store content as a streamed upload
+	body := &importReader{reader: response.Body, maxSize: s.importMaxSize}
+	if s.importMaxSize > 0 {
+		body.reader = io.LimitReader(response.Body, s.importMaxSize+1)
+	}
+	var declaredSize *int64
+	if response.ContentLength >= 0 {
+		declaredSize = &response.ContentLength
+	}
+	return s.CreateFile(ctx, &filesServicePort.CreateFileData{
+		Path:         data.Path,
+		Stream:       &filesServicePort.FileStream{Filename: name, Reader: body},
+		DeclaredSize: declaredSize,
+		Mode:         data.Mode,
+		CreateDir:    data.CreateDir,
+		Backup:       data.Backup,
+	})
+}
+
+// Return the http client fetching imports. It does not follow redirects,
+// ignores proxy settings (a proxy would connect on its behalf) and dials
+// through dialImport.
+func (s *service) newImportClient() *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext:           s.dialImport,
+			ForceAttemptHTTP2:     true,
+			TLSHandshakeTimeout:   importHeaderTimeout,
+			ResponseHeaderTimeout: importHeaderTimeout,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+/*
+dialImport connects to the host of an import, resolving it once and dialing
+only the addresses checked, so a host whose records change after the check
+(DNS rebinding) cannot lead the connection elsewhere.
+
+The host fails with ErrHostNotAllowed unless the host or one of its addresses
+is in importAllowedHosts, and neither is in importDeniedHosts. Only addresses
+allowed on their own (or all of them, for a host allowed by name) are dialed,
+in the order resolved. Internal addresses (see internalAddr) are skipped
+unless listed in importAllowedHosts as an address or block, so a wildcard or
+host name entry never reaches local services, whatever the denied hosts.
+*/
+func (s *service) dialImport(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resolve host
+	var addrs []net.IP
+	if ip := net.ParseIP(host); ip != nil {
+		addrs = []net.IP{ip}
+	} else {
+		resolved, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		for _, addr := range resolved {
+			addrs = append(addrs, addr.IP)
+		}
+	}
+
+	// Check host against the allowed and denied hosts
+	if !s.importAllowedHosts.Match(host, addrs) {
+		return nil, filesServicePort.ErrHostNotAllowed
+	}
+	if s.importDeniedHosts != nil && s.importDeniedHosts.Match(host, addrs) {
+		return nil, filesServicePort.ErrHostNotAllowed
+	}
+
+	// Dial the checked addresses
+	var dialer net.Dialer
+	err = filesServicePort.ErrHostNotAllowed
+	for _, addr := range addrs {
+		if !s.importAllowedHosts.Match(host, []net.IP{addr}) {
+			continue
+		}
+		if internalAddr(addr) && !s.importAllowedHosts.MatchAddr(addr) {
+			continue
+		}
+		var conn net.Conn
+		if conn, err = dialer.DialContext(ctx, network, net.JoinHostPort(addr.String(), port)); err == nil {
+			return conn, nil
+		}
+	}
+	return nil, err
+}
+
+// NAT64 prefixes embedding an IPv4 address in their last 32 bits
+var nat64Prefixes = []netip.Prefix{
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+}
+
+// Report whether an address is loopback, unspecified (which connects to the
+// local host), private or link-local, looking through IPv4-mapped and NAT64
+// forms at the embedded IPv4 address
+func internalAddr(ip net.IP) bool {
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return true
+	}
+	addr = addr.Unmap()
+	for _, prefix := range nat64Prefixes {
+		if prefix.Contains(addr) {
+			b := addr.As16()
+			addr = netip.AddrFrom4([4]byte(b[12:]))
+			break
+		}
+	}
+	return addr.IsLoopback() || addr.IsUnspecified() || addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast()
+}
+
+// Response body of an import, failing with ErrImportTooLarge once more than
+// maxSize bytes (unless 0) are read and with ErrFetchFailed on transport
+// errors
+type importReader struct {
+	reader  io.Reader
+	maxSize int64
+	read    int64
+}
+
+func (r *importReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.read += int64(n)
+	if r.maxSize > 0 && r.read > r.maxSize {
+		return n, filesServicePort.ErrImportTooLarge
+	}
+	if err != nil && err != io.EOF {
+		return n, filesServicePort.ErrFetchFailed
+	}
+	return n, err
+}
+
+// Report whether a Content-Type is in importMimeTypes
+func (s *service) importMimeAllowed(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, allowed := range s.importMimeTypes {
+		allowed = strings.ToLower(allowed)
+		if allowed == mediaType || (strings.HasSuffix(allowed, "/*") && strings.HasPrefix(mediaType, allowed[:len(allowed)-1])) {
+			return true
+		}
+	}
+	return false
+}
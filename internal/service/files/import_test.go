@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/flash-go/files-service/internal/hostlist"
+	filesServicePort "github.com/flash-go/files-service/internal/port/service/files"
+)
+
+func TestInternalAddr(t *testing.T) {
+	tests := []struct {
+		addr     string
+		internal bool
+	}{
+		{"127.0.0.1", true},
+		{"::1", true},
+		{"0.0.0.0", true},
+		{"::", true},
+		{"10.1.2.3", true},
+		{"192.168.1.1", true},
+		{"fd00::1", true},
+		{"169.254.169.254", true},
+		{"fe80::1", true},
+		{"ff02::1", true},
+		{"::ffff:127.0.0.1", true},
+		{"::ffff:10.0.0.1", true},
+		{"64:ff9b::7f00:1", true},
+		{"64:ff9b::a00:1", true},
+		{"93.184.216.34", false},
+		{"::ffff:93.184.216.34", false},
+		{"64:ff9b::5db8:d822", false},
+		{"2606:2800:220:1::1", false},
+	}
+	for _, tt := range tests {
+		if got := internalAddr(net.ParseIP(tt.addr)); got != tt.internal {
+			t.Errorf("internalAddr(%s) = %t, want %t", tt.addr, got, tt.internal)
+		}
+	}
+}
+
+func TestImportSkipsInternalAddrs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("content"))
+	}))
+	defer server.Close()
+	serverUrl, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := serverUrl.Port()
+
+	tests := []struct {
+		allowed string
+		host    string
+	}{
+		{"*", "127.0.0.1"},
+		{"*", "[::]"},
+		{"*", "[::ffff:127.0.0.1]"},
+		{"localhost", "localhost"},
+	}
+	for _, tt := range tests {
+		allowed, err := hostlist.Parse([]string{tt.allowed})
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := New(&Config{ImportAllowedHosts: allowed}).(*service)
+		_, err = s.ImportFile(context.Background(), &filesServicePort.ImportFileData{
+			Url:  "http://" + tt.host + ":" + port + "/file.txt",
+			Path: "imports",
+		})
+		if !errors.Is(err, filesServicePort.ErrHostNotAllowed) {
+			t.Errorf("import from %s allowed by %q = %v, want ErrHostNotAllowed", tt.host, tt.allowed, err)
+		}
+	}
+
+	// An internal address listed explicitly is dialed
+	allowed, err := hostlist.Parse([]string{"127.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := New(&Config{ImportAllowedHosts: allowed}).(*service)
+	conn, err := s.dialImport(context.Background(), "tcp", serverUrl.Host)
+	if err != nil {
+		t.Fatalf("dial explicitly allowed address: %v", err)
+	}
+	conn.Close()
+}
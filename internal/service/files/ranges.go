@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/flash-go/files-service/internal/namespace"
+	filesRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/files"
+	filesServicePort "github.com/flash-go/files-service/internal/port/service/files"
+)
+
+// Time after which an unfinished range upload is forgotten
+const rangeUploadTtl = 24 * time.Hour
+
+// Range upload in progress, received bytes form a contiguous prefix
+type rangeUpload struct {
+	id        string
+	mode      string
+	total     int64
+	received  int64
+	writing   bool
+	expiresAt time.Time
+}
+
+/*
+WriteFileRange writes one Content-Range chunk of a resumable upload.
+
+  - A range starting at 0 (re)starts the upload of the path in the create
+    mode of the data, which applies to the whole upload. The ranges are
+    staged in a temp file that replaces the file only once the upload is
+    complete (see the repository's WriteFileRange).
+  - Every following range must start exactly where the received bytes end:
+    earlier starts are rejected with ErrRangeOverlap, later starts (or a range
+    sent while another one is still being written) with ErrRangeOutOfOrder.
+  - The upload is complete once all Total bytes are received. Unfinished
+    uploads are forgotten after rangeUploadTtl.
+  - If sessionSecret is set, every incomplete result holds a session token
+    carrying the state of the upload (see signSession). A following range
+    sent with the token continues the upload from that state on any
+    instance sharing the secret and the store, even one that never saw it,
+    unless this instance is further along in the same upload. Invalid tokens
+    are rejected with ErrInvalidSessionToken, expired ones with
+    ErrSessionTokenExpired. Tokens are ignored if sessionSecret is not set.
+*/
+func (s *service) WriteFileRange(ctx context.Context, data *filesServicePort.WriteFileRangeData) (*filesServicePort.WriteFileRangeResult, error) {
+	size := int64(len(data.Content))
+	now := time.Now()
+
+	// Uploads are tracked per store namespace and path
+	key := namespace.Name(ctx) + "\x00" + data.Path
+
+	// Check the session token of a continued upload
+	var session *sessionState
+	if data.SessionToken != "" && data.Start > 0 && len(s.sessionSecret) > 0 {
+		var err error
+		if session, err = s.verifySession(data.SessionToken, namespace.Name(ctx), data.Path); err != nil {
+			return nil, err
+		}
+	}
+
+	s.rangeMu.Lock()
+	for k, u := range s.rangeUploads {
+		if !u.writing && now.After(u.expiresAt) {
+			delete(s.rangeUploads, k)
+		}
+	}
+	upload, ok := s.rangeUploads[key]
+
+	// Take over the state of the session unless this instance is further
+	// along in the same upload
+	if session != nil && (!ok || !upload.writing && (upload.id != session.Id || upload.received < session.Received)) {
+		upload = &rangeUpload{
+			id:       session.Id,
+			mode:     session.Mode,
+			total:    session.Total,
+			received: session.Received,
+		}
+		s.rangeUploads[key] = upload
+		ok = true
+	}
+
+	switch {
+	case ok && upload.writing:
+		s.rangeMu.Unlock()
+		return nil, filesServicePort.ErrRangeOutOfOrder
+	case data.Start == 0:
+		upload = &rangeUpload{id: newUploadId(), mode: data.Mode, total: data.Total}
+		s.rangeUploads[key] = upload
+	case !ok || data.Start > upload.received:
+		s.rangeMu.Unlock()
+		return nil, filesServicePort.ErrRangeOutOfOrder
+	case data.Start < upload.received:
+		s.rangeMu.Unlock()
+		return nil, filesServicePort.ErrRangeOverlap
+	case data.Total != upload.total:
+		s.rangeMu.Unlock()
+		return nil, filesServicePort.ErrRangeTotalMismatch
+	}
+	upload.writing = true
+	s.rangeMu.Unlock()
+
+	d := filesRepositoryAdapterPort.WriteFileRangeData{
+		Path:     data.Path,
+		UploadId: upload.id,
+		Mode:     upload.mode,
+		Start:    data.Start,
+		Total:    data.Total,
+		Content:  data.Content,
+		Complete: upload.received+size == upload.total,
+	}
+	unlock := s.lock(ctx, data.Path)
+	err := s.filesRepository.WriteFileRange(ctx, &d)
+	unlock()
+
+	s.rangeMu.Lock()
+	defer s.rangeMu.Unlock()
+	upload.writing = false
+	upload.expiresAt = time.Now().Add(rangeUploadTtl)
+	if err != nil {
+		if data.Start == 0 {
+			delete(s.rangeUploads, key)
+		}
+		return nil, err
+	}
+	upload.received += size
+	complete := upload.received == upload.total
+	if complete && s.rangeUploads[key] == upload {
+		delete(s.rangeUploads, key)
+	}
+	result := filesServicePort.WriteFileRangeResult{
+		Received: upload.received,
+		Total:    upload.total,
+		Complete: complete,
+	}
+	if !complete && len(s.sessionSecret) > 0 {
+		token := s.signSession(sessionState{
+			Namespace: namespace.Name(ctx),
+			Path:      data.Path,
+			Id:        upload.id,
+			Mode:      upload.mode,
+			Total:     upload.total,
+			Received:  upload.received,
+		})
+		result.SessionToken = &token
+	}
+	return &result, nil
+}
@@ -2,49 +2,510 @@ package service
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"path"
+	"sync"
+	"time"
 
+	"github.com/flash-go/files-service/internal/hostlist"
+	"github.com/flash-go/files-service/internal/namespace"
+	"github.com/flash-go/files-service/internal/pathlock"
+	dirsRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/dirs"
 	filesRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/files"
 	filesServicePort "github.com/flash-go/files-service/internal/port/service/files"
 )
 
 type Config struct {
-	FilesRepository filesRepositoryAdapterPort.Interface
+	FilesRepository    filesRepositoryAdapterPort.Interface
+	DirsRepository     dirsRepositoryAdapterPort.Interface
+	IdempotencyTtl     time.Duration
+	TrashTtl           time.Duration
+	BackupTtl          time.Duration
+	PathLocks          *pathlock.Locks
+	IndexFile          string
+	DirListing         bool
+	UploadMaxDuration  time.Duration
+	ConcatMaxSize      int64
+	ImportAllowedHosts *hostlist.List
+	ImportDeniedHosts  *hostlist.List
+	ImportMaxSize      int64
+	ImportMimeTypes    []string
+	ImageMaxPixels     int64
+	ImageCacheSize     int64
+	SessionSecret      []byte
+	SessionTokenTtl    time.Duration
 }
 
 func New(config *Config) filesServicePort.Interface {
-	return &service{
-		config.FilesRepository,
+	s := &service{
+		filesRepository:    config.FilesRepository,
+		dirsRepository:     config.DirsRepository,
+		idempotencyTtl:     config.IdempotencyTtl,
+		idempotencyKeys:    make(map[string]*idempotencyEntry),
+		trashTtl:           config.TrashTtl,
+		backupTtl:          config.BackupTtl,
+		rangeUploads:       make(map[string]*rangeUpload),
+		pathLocks:          config.PathLocks,
+		indexFile:          config.IndexFile,
+		dirListing:         config.DirListing,
+		uploadMaxDuration:  config.UploadMaxDuration,
+		concatMaxSize:      config.ConcatMaxSize,
+		importAllowedHosts: config.ImportAllowedHosts,
+		importDeniedHosts:  config.ImportDeniedHosts,
+		importMaxSize:      config.ImportMaxSize,
+		importMimeTypes:    config.ImportMimeTypes,
+		imageMaxPixels:     config.ImageMaxPixels,
+		sessionSecret:      config.SessionSecret,
+		sessionTokenTtl:    config.SessionTokenTtl,
+		imageCache: &imageCache{
+			entries: make(map[string]*imageEntry),
+			maxSize: config.ImageCacheSize,
+		},
 	}
+	if s.pathLocks == nil {
+		s.pathLocks = pathlock.New()
+	}
+	s.importClient = s.newImportClient()
+	return s
 }
 
 type service struct {
-	filesRepository filesRepositoryAdapterPort.Interface
+	filesRepository    filesRepositoryAdapterPort.Interface
+	dirsRepository     dirsRepositoryAdapterPort.Interface
+	idempotencyTtl     time.Duration
+	idempotencyMu      sync.Mutex
+	idempotencyKeys    map[string]*idempotencyEntry
+	trashTtl           time.Duration
+	backupTtl          time.Duration
+	rangeMu            sync.Mutex
+	rangeUploads       map[string]*rangeUpload
+	pathLocks          *pathlock.Locks
+	indexFile          string
+	dirListing         bool
+	uploadMaxDuration  time.Duration
+	concatMaxSize      int64
+	importClient       *http.Client
+	importAllowedHosts *hostlist.List
+	importDeniedHosts  *hostlist.List
+	importMaxSize      int64
+	importMimeTypes    []string
+	imageMaxPixels     int64
+	imageCache         *imageCache
+	sessionSecret      []byte
+	sessionTokenTtl    time.Duration
+}
+
+// Lock paths for a change, returning the function that drops the cached
+// sizes of the changed paths and their ancestors and releases the locks
+func (s *service) lock(ctx context.Context, paths ...string) func() {
+	unlock := s.pathLocks.Lock(paths...)
+	return func() {
+		s.invalidateDirSizes(ctx, paths...)
+		unlock()
+	}
 }
 
-func (s *service) CreateFile(ctx context.Context, data *filesServicePort.CreateFileData) error {
-	d := filesRepositoryAdapterPort.CreateFileData(*data)
-	return s.filesRepository.CreateFile(ctx, &d)
+// Drop the cached sizes of changed paths and their ancestors, even if ctx
+// is done by then
+func (s *service) invalidateDirSizes(ctx context.Context, paths ...string) {
+	s.dirsRepository.InvalidateDirSizes(
+		context.WithoutCancel(ctx),
+		&dirsRepositoryAdapterPort.InvalidateDirSizesData{Paths: paths},
+	)
 }
 
-func (s *service) GetFiles(ctx context.Context, data *filesServicePort.GetFilesData) (*[]filesServicePort.FileResult, error) {
+func (s *service) CreateFile(ctx context.Context, data *filesServicePort.CreateFileData) (*filesServicePort.CreateFileResult, error) {
+	d := filesRepositoryAdapterPort.CreateFileData{
+		Path:         data.Path,
+		File:         data.File,
+		Stream:       (*filesRepositoryAdapterPort.FileStream)(data.Stream),
+		DeclaredSize: data.DeclaredSize,
+		Mode:         data.Mode,
+		CreateDir:    data.CreateDir,
+		Backup:       data.Backup,
+		Dedup:        data.Dedup,
+	}
+	if data.Ttl != nil {
+		expiresAt := time.Now().Add(time.Duration(*data.Ttl) * time.Second)
+		d.ExpiresAt = &expiresAt
+	}
+	if name, ok := uploadName(data); ok {
+		defer s.pathLocks.Lock(path.Join(data.Path, path.Base(name)))()
+	}
+	if s.uploadMaxDuration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, s.uploadMaxDuration, filesServicePort.ErrUploadTimeout)
+		defer cancel()
+	}
+	create := func() (*filesServicePort.CreateFileResult, error) {
+		result, err := s.filesRepository.CreateFile(ctx, &d)
+		if err == nil {
+			// The stored path may differ from the requested one (MIME routing)
+			s.invalidateDirSizes(ctx, result.Path)
+		}
+		return (*filesServicePort.CreateFileResult)(result), err
+	}
+	if data.IdempotencyKey == "" || s.idempotencyTtl <= 0 {
+		return create()
+	}
+	// Keys are scoped to the store namespace
+	key := namespace.Name(ctx) + "\x00" + data.IdempotencyKey
+	return s.idempotent(key, uploadFingerprint(data), create)
+}
+
+func (s *service) GetFiles(ctx context.Context, data *filesServicePort.GetFilesData) (*filesServicePort.GetFilesResult, error) {
 	d := filesRepositoryAdapterPort.GetFilesData(*data)
-	if files, err := s.filesRepository.GetFiles(ctx, &d); err != nil {
+	if result, err := s.filesRepository.GetFiles(ctx, &d); err != nil {
 		return nil, err
 	} else {
-		f := make([]filesServicePort.FileResult, len(*files))
-		for i, file := range *files {
+		f := make([]filesServicePort.FileResult, len(result.Files))
+		for i, file := range result.Files {
 			f[i] = filesServicePort.FileResult(file)
 		}
-		return &f, nil
+		return &filesServicePort.GetFilesResult{
+			Files:     f,
+			Token:     result.Token,
+			Unchanged: result.Unchanged,
+		}, nil
+	}
+}
+
+func (s *service) ListToken(ctx context.Context, data *filesServicePort.ListTokenData) (*filesServicePort.ListTokenResult, error) {
+	d := filesRepositoryAdapterPort.ListTokenData(*data)
+	result, err := s.filesRepository.ListToken(ctx, &d)
+	if err != nil {
+		return nil, err
+	}
+	return (*filesServicePort.ListTokenResult)(result), nil
+}
+
+// Open a file for download. A directory is served by its index file, or
+// else by its listing if dirListing or AutoIndex is set (ErrIndexNotFound
+// otherwise), paged by Limit, AfterDir and AfterName like GetFiles.
+func (s *service) DownloadFile(ctx context.Context, data *filesServicePort.DownloadFileData) (*filesServicePort.DownloadResult, error) {
+	file, err := s.filesRepository.OpenFile(ctx, &filesRepositoryAdapterPort.OpenFileData{
+		Path:      data.Path,
+		IndexName: s.indexFile,
+	})
+	if errors.Is(err, filesRepositoryAdapterPort.ErrIsDirectory) {
+		if !s.dirListing && !data.AutoIndex {
+			return nil, filesServicePort.ErrIndexNotFound
+		}
+		listing, err := s.GetFiles(ctx, &filesServicePort.GetFilesData{
+			Path:      data.Path,
+			Limit:     data.Limit,
+			AfterDir:  data.AfterDir,
+			AfterName: data.AfterName,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &filesServicePort.DownloadResult{Listing: &listing.Files}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	result := &filesServicePort.DownloadResult{
+		File:     file.File,
+		Name:     file.Name,
+		Size:     file.Size,
+		MimeType: file.MimeType,
+		ModTime:  file.ModTime,
+	}
+	if data.Format != "" {
+		return s.convertImage(ctx, result, data)
+	}
+	return result, nil
+}
+
+func (s *service) ListFileVersions(ctx context.Context, data *filesServicePort.ListFileVersionsData) (*[]filesServicePort.FileVersionResult, error) {
+	d := filesRepositoryAdapterPort.ListFileVersionsData(*data)
+	if versions, err := s.filesRepository.ListFileVersions(ctx, &d); err != nil {
+		return nil, err
+	} else {
+		v := make([]filesServicePort.FileVersionResult, len(*versions))
+		for i, version := range *versions {
+			v[i] = filesServicePort.FileVersionResult(version)
+		}
+		return &v, nil
+	}
+}
+
+// Open a kept version of a file for download
+func (s *service) DownloadFileVersion(ctx context.Context, data *filesServicePort.DownloadFileVersionData) (*filesServicePort.DownloadResult, error) {
+	d := filesRepositoryAdapterPort.OpenFileVersionData(*data)
+	file, err := s.filesRepository.OpenFileVersion(ctx, &d)
+	if err != nil {
+		return nil, err
+	}
+	return &filesServicePort.DownloadResult{
+		File:     file.File,
+		Name:     file.Name,
+		Size:     file.Size,
+		MimeType: file.MimeType,
+		ModTime:  file.ModTime,
+	}, nil
+}
+
+func (s *service) RestoreFileVersion(ctx context.Context, data *filesServicePort.RestoreFileVersionData) (*filesServicePort.RestoreFileVersionResult, error) {
+	defer s.lock(ctx, data.Path)()
+	d := filesRepositoryAdapterPort.RestoreFileVersionData(*data)
+	result, err := s.filesRepository.RestoreFileVersion(ctx, &d)
+	if err != nil {
+		return nil, err
+	}
+	return (*filesServicePort.RestoreFileVersionResult)(result), nil
+}
+
+func (s *service) SplitFile(ctx context.Context, data *filesServicePort.SplitFileData) (*filesServicePort.SplitFileResult, error) {
+	defer s.lock(ctx, data.Path)()
+	d := filesRepositoryAdapterPort.SplitFileData(*data)
+	result, err := s.filesRepository.SplitFile(ctx, &d)
+	if err != nil {
+		return nil, err
+	}
+	parts := make([]filesServicePort.FilePart, len(result.Parts))
+	for i, part := range result.Parts {
+		parts[i] = filesServicePort.FilePart(part)
+	}
+	return &filesServicePort.SplitFileResult{Parts: parts}, nil
+}
+
+func (s *service) ResolvePath(ctx context.Context, data *filesServicePort.ResolvePathData) (*filesServicePort.ResolvePathResult, error) {
+	d := filesRepositoryAdapterPort.ResolvePathData(*data)
+	result, err := s.filesRepository.ResolvePath(ctx, &d)
+	if err != nil {
+		return nil, err
+	}
+	return (*filesServicePort.ResolvePathResult)(result), nil
+}
+
+func (s *service) CheckPath(ctx context.Context, data *filesServicePort.CheckPathData) (*filesServicePort.CheckPathResult, error) {
+	d := filesRepositoryAdapterPort.CheckPathData(*data)
+	result, err := s.filesRepository.CheckPath(ctx, &d)
+	if err != nil {
+		return nil, err
+	}
+	checks := make([]filesServicePort.PathCheck, len(result.Checks))
+	for i, check := range result.Checks {
+		checks[i] = filesServicePort.PathCheck(check)
+	}
+	return &filesServicePort.CheckPathResult{
+		Path:      result.Path,
+		Operation: result.Operation,
+		Allowed:   result.Allowed,
+		Rule:      result.Rule,
+		Error:     result.Error,
+		Checks:    checks,
+	}, nil
+}
+
+func (s *service) StatFile(ctx context.Context, data *filesServicePort.StatFileData) (*filesServicePort.FileResult, error) {
+	d := filesRepositoryAdapterPort.StatFileData(*data)
+	result, err := s.filesRepository.StatFile(ctx, &d)
+	if err != nil {
+		return nil, err
+	}
+	return (*filesServicePort.FileResult)(result), nil
+}
+
+func (s *service) FindFiles(ctx context.Context, data *filesServicePort.FindFilesData) error {
+	return s.filesRepository.FindFiles(ctx, &filesRepositoryAdapterPort.FindFilesData{
+		Path:    data.Path,
+		Pattern: data.Pattern,
+		Depth:   data.Depth,
+		Found: func(result filesRepositoryAdapterPort.FindResult) error {
+			return data.Found(filesServicePort.FindResult(result))
+		},
+	})
+}
+
+func (s *service) RecentFiles(ctx context.Context, data *filesServicePort.RecentFilesData) (*[]filesServicePort.RecentFileResult, error) {
+	d := filesRepositoryAdapterPort.RecentFilesData(*data)
+	results, err := s.filesRepository.RecentFiles(ctx, &d)
+	if err != nil {
+		return nil, err
+	}
+	r := make([]filesServicePort.RecentFileResult, len(*results))
+	for i, result := range *results {
+		r[i] = filesServicePort.RecentFileResult(result)
 	}
+	return &r, nil
 }
 
 func (s *service) DeleteFile(ctx context.Context, data *filesServicePort.DeleteFileData) error {
+	defer s.lock(ctx, data.Path)()
 	d := filesRepositoryAdapterPort.DeleteFileData(*data)
 	return s.filesRepository.DeleteFile(ctx, &d)
 }
 
 func (s *service) RenameFile(ctx context.Context, data *filesServicePort.RenameFileData) error {
+	defer s.lock(ctx, data.OldPath, data.NewPath)()
 	d := filesRepositoryAdapterPort.RenameFileData(*data)
 	return s.filesRepository.RenameFile(ctx, &d)
 }
+
+func (s *service) MoveFile(ctx context.Context, data *filesServicePort.MoveFileData) (*filesServicePort.MoveFileResult, error) {
+	defer s.lock(ctx, data.SourcePath, data.DestPath)()
+	d := filesRepositoryAdapterPort.MoveFileData(*data)
+	result, err := s.filesRepository.MoveFile(ctx, &d)
+	if err != nil {
+		return nil, err
+	}
+	return (*filesServicePort.MoveFileResult)(result), nil
+}
+
+func (s *service) CopyFile(ctx context.Context, data *filesServicePort.CopyFileData) (*filesServicePort.CopyFileResult, error) {
+	defer s.lock(ctx, data.SourcePath, data.DestPath)()
+	d := filesRepositoryAdapterPort.CopyFileData(*data)
+	result, err := s.filesRepository.CopyFile(ctx, &d)
+	if err != nil {
+		return nil, err
+	}
+	return (*filesServicePort.CopyFileResult)(result), nil
+}
+
+func (s *service) WriteFileAt(ctx context.Context, data *filesServicePort.WriteFileAtData) error {
+	defer s.lock(ctx, data.Path)()
+	d := filesRepositoryAdapterPort.WriteFileAtData(*data)
+	return s.filesRepository.WriteFileAt(ctx, &d)
+}
+
+func (s *service) AllocateFile(ctx context.Context, data *filesServicePort.AllocateFileData) error {
+	defer s.lock(ctx, data.Path)()
+	d := filesRepositoryAdapterPort.AllocateFileData(*data)
+	return s.filesRepository.AllocateFile(ctx, &d)
+}
+
+func (s *service) HashFile(ctx context.Context, data *filesServicePort.HashFileData) (*filesServicePort.HashFileResult, error) {
+	d := filesRepositoryAdapterPort.HashFileData(*data)
+	result, err := s.filesRepository.HashFile(ctx, &d)
+	if err != nil {
+		return nil, err
+	}
+	return (*filesServicePort.HashFileResult)(result), nil
+}
+
+func (s *service) PreviewFile(ctx context.Context, data *filesServicePort.PreviewFileData) (*filesServicePort.PreviewResult, error) {
+	d := filesRepositoryAdapterPort.PreviewFileData(*data)
+	if preview, err := s.filesRepository.PreviewFile(ctx, &d); err != nil {
+		return nil, err
+	} else {
+		p := filesServicePort.PreviewResult(*preview)
+		return &p, nil
+	}
+}
+
+func (s *service) Move(ctx context.Context, data *filesServicePort.MoveData) error {
+	defer s.lock(ctx, data.OldPath, data.NewPath)()
+
+	// Detect source type
+	src, err := s.filesRepository.StatFile(ctx, &filesRepositoryAdapterPort.StatFileData{Path: data.OldPath})
+	if err != nil {
+		if errors.Is(err, filesRepositoryAdapterPort.ErrFileNotFound) {
+			return filesRepositoryAdapterPort.ErrFileOldNotFound
+		}
+		return err
+	}
+
+	// Reject a destination of the other type
+	dst, err := s.filesRepository.StatFile(ctx, &filesRepositoryAdapterPort.StatFileData{Path: data.NewPath})
+	if err == nil {
+		if src.IsDir && !dst.IsDir {
+			return filesServicePort.ErrNotDirectory
+		}
+		if !src.IsDir && dst.IsDir {
+			return filesServicePort.ErrIsDirectory
+		}
+	} else if !errors.Is(err, filesRepositoryAdapterPort.ErrFileNotFound) {
+		return err
+	}
+
+	// Route to the matching rename
+	if src.IsDir {
+		return s.dirsRepository.RenameDir(ctx, &dirsRepositoryAdapterPort.RenameDirData{
+			OldPath:         data.OldPath,
+			NewPath:         data.NewPath,
+			UnmodifiedSince: data.UnmodifiedSince,
+		})
+	}
+	return s.filesRepository.RenameFile(ctx, &filesRepositoryAdapterPort.RenameFileData{
+		OldPath:         data.OldPath,
+		NewPath:         data.NewPath,
+		UnmodifiedSince: data.UnmodifiedSince,
+	})
+}
+
+// Permanently delete trashed items older than trashTtl
+func (s *service) PurgeTrash(ctx context.Context) (*filesServicePort.PurgeTrashResult, error) {
+	if s.trashTtl <= 0 {
+		return &filesServicePort.PurgeTrashResult{}, nil
+	}
+	result, err := s.filesRepository.PurgeTrash(
+		ctx,
+		&filesRepositoryAdapterPort.PurgeTrashData{
+			DeletedBefore: time.Now().Add(-s.trashTtl),
+		},
+	)
+	if result == nil {
+		return nil, err
+	}
+	return (*filesServicePort.PurgeTrashResult)(result), err
+}
+
+// Permanently delete backups of replaced files older than backupTtl
+func (s *service) PurgeBackups(ctx context.Context) (*filesServicePort.PurgeBackupsResult, error) {
+	if s.backupTtl <= 0 {
+		return &filesServicePort.PurgeBackupsResult{}, nil
+	}
+	result, err := s.filesRepository.PurgeBackups(
+		ctx,
+		&filesRepositoryAdapterPort.PurgeBackupsData{
+			CreatedBefore: time.Now().Add(-s.backupTtl),
+		},
+	)
+	return (*filesServicePort.PurgeBackupsResult)(result), err
+}
+
+// Delete files whose expiry has passed, moving them to the trash if it is
+// enabled. Each file is deleted under its path lock and only if still
+// expired then, so an upload replacing it meanwhile is kept.
+func (s *service) PurgeExpired(ctx context.Context) (*filesServicePort.PurgeExpiredResult, error) {
+	result := filesServicePort.PurgeExpiredResult{}
+	now := time.Now()
+	paths, err := s.filesRepository.ListExpiredFiles(
+		ctx,
+		&filesRepositoryAdapterPort.ListExpiredFilesData{
+			Now: now,
+		},
+	)
+	if err != nil {
+		return &result, err
+	}
+	for _, p := range *paths {
+		deleted, err := s.deleteExpiredFile(ctx, p, now)
+		if err != nil {
+			return &result, err
+		}
+		if deleted {
+			result.Purged++
+		}
+	}
+	return &result, nil
+}
+
+func (s *service) deleteExpiredFile(ctx context.Context, p string, now time.Time) (bool, error) {
+	defer s.lock(ctx, p)()
+	result, err := s.filesRepository.DeleteExpiredFile(
+		ctx,
+		&filesRepositoryAdapterPort.DeleteExpiredFileData{
+			Path: p,
+			Now:  now,
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.Deleted, nil
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	filesServicePort "github.com/flash-go/files-service/internal/port/service/files"
+)
+
+// State of a range upload carried by a session token
+type sessionState struct {
+	Namespace string `json:"ns"`
+	Path      string `json:"path"`
+	Id        string `json:"id"`
+	Mode      string `json:"mode,omitempty"`
+	Total     int64  `json:"total"`
+	Received  int64  `json:"received"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// Return a random id telling apart the uploads of one path
+func newUploadId() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+/*
+signSession encodes the state of a range upload as a session token, valid for
+sessionTokenTtl: the base64url (unpadded) JSON state and its HMAC-SHA256 under
+sessionSecret, joined by a dot.
+*/
+func (s *service) signSession(state sessionState) string {
+	state.ExpiresAt = time.Now().Add(s.sessionTokenTtl).Unix()
+	payload, _ := json.Marshal(state)
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(s.sessionMac(encoded))
+}
+
+/*
+verifySession decodes a session token, returning its state if it was signed
+with sessionSecret (ErrInvalidSessionToken otherwise, or if it was issued for
+another namespace or path) and has not expired (ErrSessionTokenExpired).
+Signatures are compared in constant time, before the state is decoded.
+*/
+func (s *service) verifySession(token, ns, path string) (*sessionState, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, filesServicePort.ErrInvalidSessionToken
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, s.sessionMac(encoded)) {
+		return nil, filesServicePort.ErrInvalidSessionToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, filesServicePort.ErrInvalidSessionToken
+	}
+	var state sessionState
+	if err := json.Unmarshal(payload, &state); err != nil {
+		return nil, filesServicePort.ErrInvalidSessionToken
+	}
+	if state.Namespace != ns || state.Path != path || state.Id == "" ||
+		state.Total < 0 || state.Received < 0 || state.Received > state.Total {
+		return nil, filesServicePort.ErrInvalidSessionToken
+	}
+	if time.Now().Unix() >= state.ExpiresAt {
+		return nil, filesServicePort.ErrSessionTokenExpired
+	}
+	return &state, nil
+}
+
+// Compute the signature of an encoded session state
+func (s *service) sessionMac(encoded string) []byte {
+	mac := hmac.New(sha256.New, s.sessionSecret)
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
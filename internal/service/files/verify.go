@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	filesRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/files"
+	filesServicePort "github.com/flash-go/files-service/internal/port/service/files"
+)
+
+// Maximum number of files hashed concurrently by a single VerifyFiles call
+const verifyWorkers = 8
+
+/*
+VerifyFiles compares the current SHA-256 of each file against the expected
+value and reports, in request order, whether it matches.
+
+ 1. Hashes the files through HashFile, so the same path, symlink and type
+    checks apply and unchanged files are served from the hash cache.
+ 2. Runs at most verifyWorkers hashes at a time.
+ 3. Reports a file that does not exist, or is a directory, as missing.
+ 4. Fails the whole call on any other error (e.g. a path escaping the store)
+    and stops the remaining hashes.
+
+Expected hashes are compared ignoring case.
+*/
+func (s *service) VerifyFiles(ctx context.Context, data *filesServicePort.VerifyFilesData) (*filesServicePort.VerifyFilesResult, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	files := make([]filesServicePort.VerifiedFile, len(data.Files))
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	slots := make(chan struct{}, verifyWorkers)
+	for i, file := range data.Files {
+		slots <- struct{}{}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			status, err := s.verifyFile(ctx, file)
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			files[i] = filesServicePort.VerifiedFile{
+				Path:   file.Path,
+				Status: status,
+			}
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return &filesServicePort.VerifyFilesResult{Files: files}, nil
+}
+
+// Hash a single file and compare it against the expected hash
+func (s *service) verifyFile(ctx context.Context, file filesServicePort.VerifyFileData) (string, error) {
+	result, err := s.filesRepository.HashFile(ctx, &filesRepositoryAdapterPort.HashFileData{Path: file.Path})
+	switch {
+	case errors.Is(err, filesRepositoryAdapterPort.ErrFileNotFound),
+		errors.Is(err, filesRepositoryAdapterPort.ErrIsDirectory):
+		return filesServicePort.VerifyStatusMissing, nil
+	case err != nil:
+		return "", err
+	case strings.EqualFold(result.SHA256, file.SHA256):
+		return filesServicePort.VerifyStatusMatch, nil
+	default:
+		return filesServicePort.VerifyStatusMismatch, nil
+	}
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+
+	storeRepositoryAdapterPort "github.com/flash-go/files-service/internal/port/adapter/repository/store"
+	systemServicePort "github.com/flash-go/files-service/internal/port/service/system"
+)
+
+type Config struct {
+	ServiceName     string
+	Version         string
+	Commit          string
+	StoreRepository storeRepositoryAdapterPort.Interface
+}
+
+func New(config *Config) systemServicePort.Interface {
+	return &service{
+		config.ServiceName,
+		config.Version,
+		config.Commit,
+		config.StoreRepository,
+	}
+}
+
+type service struct {
+	serviceName     string
+	version         string
+	commit          string
+	storeRepository storeRepositoryAdapterPort.Interface
+}
+
+func (s *service) GetVersion(ctx context.Context) (*systemServicePort.VersionResult, error) {
+	if status, err := s.storeRepository.GetStatus(ctx); err != nil {
+		return nil, err
+	} else {
+		return &systemServicePort.VersionResult{
+			ServiceName:    s.serviceName,
+			Version:        s.version,
+			Commit:         s.commit,
+			StoreAvailable: status.Available,
+			StoreReadOnly:  status.ReadOnly,
+		}, nil
+	}
+}
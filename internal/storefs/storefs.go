@@ -0,0 +1,74 @@
+// Package storefs holds the filesystem checks shared by the files and dirs
+// repositories.
+package storefs
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Maximum number of parent directories walked by CheckParents
+const MaxParentWalk = 1024
+
+// Walk from start up to the base directory, rejecting symlinked components
+// with errEscape. Containment is verified via Rel at every step and the
+// walk is capped, so platform path quirks (case-insensitive or normalized
+// volumes) can never make it run past the base or up to the filesystem root.
+func CheckParents(baseAbs, start string, errEscape error) error {
+	current := start
+	for i := 0; i < MaxParentWalk; i++ {
+		rel, err := filepath.Rel(baseAbs, current)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return errEscape
+		}
+		if rel == "." {
+			return nil
+		}
+		info, err := os.Lstat(current)
+		if err != nil {
+			return fmt.Errorf("failed to stat %q: %w", current, err)
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return errEscape
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return errEscape
+		}
+		current = parent
+	}
+	return errEscape
+}
+
+// Report whether the directory already holds maxEntries entries (never if
+// maxEntries is not positive). Reads at most maxEntries names, so the check
+// stays cheap.
+func DirFull(dirAbs string, maxEntries int) (bool, error) {
+	if maxEntries <= 0 {
+		return false, nil
+	}
+	dir, err := os.Open(dirAbs)
+	if err != nil {
+		return false, err
+	}
+	defer dir.Close()
+	names, err := dir.Readdirnames(maxEntries)
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+	return len(names) >= maxEntries, nil
+}
+
+// Report whether a name matches one of the hidden names glob patterns
+func Hidden(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,13 @@
+package http
+
+const (
+	MethodGet     = "GET"     // RFC 7231
+	MethodHead    = "HEAD"    // RFC 7231
+	MethodPost    = "POST"    // RFC 7231
+	MethodPut     = "PUT"     // RFC 7231
+	MethodPatch   = "PATCH"   // RFC 5789
+	MethodDelete  = "DELETE"  // RFC 7231
+	MethodConnect = "CONNECT" // RFC 7231
+	MethodOptions = "OPTIONS" // RFC 7231
+	MethodTrace   = "TRACE"   // RFC 7231
+)